@@ -26,6 +26,7 @@ var (
 	_ DDLNode = &DropDatabaseStmt{}
 	_ DDLNode = &DropIndexStmt{}
 	_ DDLNode = &DropTableStmt{}
+	_ DDLNode = &RenameTableStmt{}
 	_ DDLNode = &TruncateTableStmt{}
 
 	_ Node = &AlterTableSpec{}
@@ -35,6 +36,7 @@ var (
 	_ Node = &Constraint{}
 	_ Node = &IndexColName{}
 	_ Node = &ReferenceDef{}
+	_ Node = &TableToTable{}
 )
 
 // CharsetOpt is used for parsing charset option from SQL.
@@ -399,6 +401,8 @@ type CreateTableStmt struct {
 	Cols        []*ColumnDef
 	Constraints []*Constraint
 	Options     []*TableOption
+	// Partition is the PARTITION BY clause, it's nil if the table isn't partitioned.
+	Partition *PartitionOptions
 }
 
 // Accept implements Node Accept interface.
@@ -554,6 +558,23 @@ type TableOption struct {
 	UintValue uint64
 }
 
+// PartitionDefinition defines a partition of the table partitioned by RANGE.
+type PartitionDefinition struct {
+	Name string
+	// LessThan is the value of VALUES LESS THAN, it's nil for MAXVALUE.
+	LessThan ExprNode
+}
+
+// PartitionOptions is the PARTITION BY clause of CREATE TABLE.
+// See: https://dev.mysql.com/doc/refman/5.7/en/partitioning-types.html
+type PartitionOptions struct {
+	Tp   model.PartitionType
+	Expr ExprNode
+	// Num is the number of the partitions of the table partitioned by HASH.
+	Num         uint64
+	Definitions []*PartitionDefinition
+}
+
 // ColumnPositionType is the type for ColumnPosition.
 type ColumnPositionType int
 
@@ -602,6 +623,13 @@ const (
 	AlterTableDropPrimaryKey
 	AlterTableDropIndex
 	AlterTableDropForeignKey
+	AlterTableModifyColumn
+	AlterTableChangeColumn
+	AlterTableAlterColumn
+	AlterTableRenameTable
+	AlterTableAddPartition
+	AlterTableDropPartition
+	AlterTableTruncatePartition
 
 // TODO: Add more actions
 )
@@ -616,7 +644,13 @@ type AlterTableSpec struct {
 	Options    []*TableOption
 	Column     *ColumnDef
 	DropColumn *ColumnName
-	Position   *ColumnPosition
+	// OldColumnName is the column changed by CHANGE COLUMN.
+	OldColumnName *ColumnName
+	Position      *ColumnPosition
+	// NewTable is the new name of the table renamed by RENAME.
+	NewTable *TableName
+	// PartDefinitions are the partitions added by ADD PARTITION.
+	PartDefinitions []*PartitionDefinition
 }
 
 // Accept implements Node Accept interface.
@@ -647,6 +681,13 @@ func (n *AlterTableSpec) Accept(v Visitor) (Node, bool) {
 		}
 		n.DropColumn = node.(*ColumnName)
 	}
+	if n.OldColumnName != nil {
+		node, ok := n.OldColumnName.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.OldColumnName = node.(*ColumnName)
+	}
 	if n.Position != nil {
 		node, ok := n.Position.Accept(v)
 		if !ok {
@@ -654,6 +695,13 @@ func (n *AlterTableSpec) Accept(v Visitor) (Node, bool) {
 		}
 		n.Position = node.(*ColumnPosition)
 	}
+	if n.NewTable != nil {
+		node, ok := n.NewTable.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.NewTable = node.(*TableName)
+	}
 	return v.Leave(n)
 }
 
@@ -688,6 +736,59 @@ func (n *AlterTableStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// RenameTableStmt is a statement to rename one or more tables.
+// See: https://dev.mysql.com/doc/refman/5.7/en/rename-table.html
+type RenameTableStmt struct {
+	ddlNode
+
+	TableToTables []*TableToTable
+}
+
+// Accept implements Node Accept interface.
+func (n *RenameTableStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*RenameTableStmt)
+	for i, val := range n.TableToTables {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.TableToTables[i] = node.(*TableToTable)
+	}
+	return v.Leave(n)
+}
+
+// TableToTable represents renaming the old table to the new table in RenameTableStmt.
+type TableToTable struct {
+	node
+
+	OldTable *TableName
+	NewTable *TableName
+}
+
+// Accept implements Node Accept interface.
+func (n *TableToTable) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*TableToTable)
+	node, ok := n.OldTable.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.OldTable = node.(*TableName)
+	node, ok = n.NewTable.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.NewTable = node.(*TableName)
+	return v.Leave(n)
+}
+
 // TruncateTableStmt is a statement to empty a table completely.
 // See: https://dev.mysql.com/doc/refman/5.7/en/truncate-table.html
 type TruncateTableStmt struct {
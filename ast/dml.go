@@ -438,6 +438,8 @@ type SelectStmt struct {
 	dmlNode
 	resultSetNode
 
+	// TableHints are the optimizer hints after the SELECT keyword.
+	TableHints []*TableOptimizerHint
 	// Distinct represents if the select has distinct option.
 	Distinct bool
 	// From is the from clause of the query.
@@ -705,6 +707,8 @@ func (n *InsertStmt) Accept(v Visitor) (Node, bool) {
 type DeleteStmt struct {
 	dmlNode
 
+	// TableHints are the optimizer hints after the DELETE keyword.
+	TableHints []*TableOptimizerHint
 	// Used in both single table and multiple table delete statement.
 	TableRefs *TableRefsClause
 	// Only used in multiple table delete statement.
@@ -768,6 +772,8 @@ func (n *DeleteStmt) Accept(v Visitor) (Node, bool) {
 type UpdateStmt struct {
 	dmlNode
 
+	// TableHints are the optimizer hints after the UPDATE keyword.
+	TableHints    []*TableOptimizerHint
 	TableRefs     *TableRefsClause
 	List          []*Assignment
 	Where         ExprNode
@@ -860,6 +866,8 @@ const (
 	ShowTriggers
 	ShowProcedureStatus
 	ShowIndex
+	ShowProcessList
+	ShowStatsMeta
 )
 
 // ShowStmt is a statement to provide information about databases, tables, columns and so on.
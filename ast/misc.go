@@ -32,6 +32,7 @@ var (
 	_ StmtNode = &ExecuteStmt{}
 	_ StmtNode = &ExplainStmt{}
 	_ StmtNode = &GrantStmt{}
+	_ StmtNode = &KillStmt{}
 	_ StmtNode = &PrepareStmt{}
 	_ StmtNode = &RollbackStmt{}
 	_ StmtNode = &SetCharsetStmt{}
@@ -73,8 +74,21 @@ type ExplainStmt struct {
 	stmtNode
 
 	Stmt StmtNode
+	// Analyze is true for EXPLAIN ANALYZE, which executes the statement and shows the runtime statistics
+	// of its executors.
+	Analyze bool
+	// Format is the output format of EXPLAIN ANALYZE, it's ExplainFormatRow or ExplainFormatJSON.
+	Format string
 }
 
+// The output formats of EXPLAIN ANALYZE.
+const (
+	// ExplainFormatRow shows a row for every executor.
+	ExplainFormatRow = "row"
+	// ExplainFormatJSON shows the executors in a JSON document.
+	ExplainFormatJSON = "json"
+)
+
 // Accept implements Node Accept interface.
 func (n *ExplainStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -363,6 +377,28 @@ func (n *DoStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// KillStmt is a statement to kill a query or connection.
+// See: https://dev.mysql.com/doc/refman/5.7/en/kill.html
+type KillStmt struct {
+	stmtNode
+
+	// Query indicates whether terminate a single query on this connection or the whole connection.
+	// If Query is true, terminates the statement the connection is currently executing, but leaves the connection itself intact.
+	// If Query is false, terminates the connection associated with the given ConnectionID, after terminating any statement the connection is executing.
+	Query        bool
+	ConnectionID uint64
+}
+
+// Accept implements Node Accept interface.
+func (n *KillStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*KillStmt)
+	return v.Leave(n)
+}
+
 // AdminStmtType is the type for admin statement.
 type AdminStmtType int
 
@@ -370,6 +406,8 @@ type AdminStmtType int
 const (
 	AdminShowDDL = iota + 1
 	AdminCheckTable
+	AdminShowDDLJobs
+	AdminCancelDDLJobs
 )
 
 // AdminStmt is the struct for Admin statement.
@@ -378,6 +416,10 @@ type AdminStmt struct {
 
 	Tp     AdminStmtType
 	Tables []*TableName
+	// JobIDs are the IDs of the DDL jobs cancelled by ADMIN CANCEL DDL JOBS.
+	JobIDs []int64
+	// JobNumber is the number of the history DDL jobs shown by ADMIN SHOW DDL JOBS, 0 means the default number.
+	JobNumber int64
 }
 
 // Accept implements Node Accpet interface.
@@ -530,3 +572,26 @@ func (n *AnalyzeTableStmt) Accept(v Visitor) (Node, bool) {
 	}
 	return v.Leave(n)
 }
+
+// TableOptimizerHint is an optimizer hint in the /*+ ... */ comment after the SELECT keyword.
+// See: https://dev.mysql.com/doc/refman/5.7/en/optimizer-hints.html
+type TableOptimizerHint struct {
+	node
+
+	// HintName is the name of the hint.
+	HintName model.CIStr
+	// Tables are the tables the hint applies to.
+	Tables []model.CIStr
+	// MaxExecutionTime is the statement execution timeout in milliseconds for the MAX_EXECUTION_TIME hint.
+	MaxExecutionTime uint64
+}
+
+// Accept implements Node Accept interface.
+func (n *TableOptimizerHint) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*TableOptimizerHint)
+	return v.Leave(n)
+}
@@ -20,6 +20,7 @@ package tidb
 import (
 	"fmt"
 	"runtime/debug"
+	"strconv"
 	"strings"
 	"time"
 
@@ -100,15 +101,31 @@ const (
 		VARIABLE_NAME  VARCHAR(64) Not Null PRIMARY KEY,
 		VARIABLE_VALUE VARCHAR(1024) DEFAULT Null,
 		COMMENT VARCHAR(1024));`
+	// CreateStatsMetaTable is the SQL statement creates stats_meta table in system db,
+	// it stores the row count and version of the table statistics.
+	CreateStatsMetaTable = `CREATE TABLE if not exists mysql.stats_meta (
+		table_id	bigint(64) NOT NULL,
+		version		bigint(64) unsigned NOT NULL DEFAULT 0,
+		count		bigint(64) NOT NULL DEFAULT 0,
+		modify_count	bigint(64) NOT NULL DEFAULT 0,
+		PRIMARY KEY (table_id));`
+	// CreateStatsHistogramsTable is the SQL statement creates stats_histograms table in system db,
+	// it stores the histograms of the table statistics, data is the encoded statistics.TablePB.
+	CreateStatsHistogramsTable = `CREATE TABLE if not exists mysql.stats_histograms (
+		table_id	bigint(64) NOT NULL,
+		version		bigint(64) unsigned NOT NULL DEFAULT 0,
+		data		longblob,
+		PRIMARY KEY (table_id));`
 )
 
-// Bootstrap initiates system DB for a store.
+// Bootstrap initiates system DB for a store, or upgrades it if it's bootstrapped by an old version.
 func bootstrap(s Session) {
 	b, err := checkBootstrapped(s)
 	if err != nil {
 		log.Fatal(err)
 	}
 	if b {
+		upgrade(s)
 		return
 	}
 	doDDLWorks(s)
@@ -118,8 +135,20 @@ func bootstrap(s Session) {
 const (
 	bootstrappedVar     = "bootstrapped"
 	bootstrappedVarTrue = "True"
+	// tidbServerVersionVar is the variable in mysql.TiDB table, it's the bootstrap version of the store.
+	tidbServerVersionVar = "tidb_server_version"
 )
 
+// The bootstrap versions, the store bootstrapped by the servers without the version is version1.
+const (
+	version1 = 1
+	// version2 adds the statistics tables.
+	version2 = 2
+)
+
+// currentBootstrapVersion is the bootstrap version of this server.
+var currentBootstrapVersion int64 = version2
+
 func checkBootstrapped(s Session) (bool, error) {
 	//  Check if system db exists.
 	_, err := s.Execute(fmt.Sprintf("USE %s;", mysql.SystemDB))
@@ -166,6 +195,63 @@ func checkBootstrappedVar(s Session) (bool, error) {
 	return isBootstrapped, nil
 }
 
+// getBootstrapVersion returns the bootstrap version of the store in mysql.TiDB table.
+func getBootstrapVersion(s Session) (int64, error) {
+	sql := fmt.Sprintf(`SELECT VARIABLE_VALUE FROM %s.%s WHERE VARIABLE_NAME="%s"`,
+		mysql.SystemDB, mysql.TiDBTable, tidbServerVersionVar)
+	rs, err := s.Execute(sql)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if len(rs) != 1 {
+		return 0, errors.New("Wrong number of Recordset")
+	}
+	row, err := rs[0].Next()
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	ver := int64(version1)
+	if row != nil {
+		ver, err = strconv.ParseInt(row.Data[0].GetString(), 10, 64)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+	}
+	// Make sure that doesn't affect the following operations.
+	return ver, errors.Trace(s.CommitTxn())
+}
+
+// upgrade runs the upgrade steps of the versions newer than the bootstrap version of the store.
+// The steps must be reentrant, as the servers may upgrade the store at the same time.
+func upgrade(s Session) {
+	ver, err := getBootstrapVersion(s)
+	if err != nil {
+		log.Fatal(errors.ErrorStack(err))
+	}
+	if ver >= currentBootstrapVersion {
+		return
+	}
+	log.Infof("[bootstrap] upgrade from version %d to %d", ver, currentBootstrapVersion)
+	if ver < version2 {
+		upgradeToVer2(s)
+	}
+	updateBootstrapVer(s)
+}
+
+// upgradeToVer2 creates the statistics tables.
+func upgradeToVer2(s Session) {
+	mustExecute(s, CreateStatsMetaTable)
+	mustExecute(s, CreateStatsHistogramsTable)
+}
+
+// updateBootstrapVer writes currentBootstrapVersion to mysql.TiDB table.
+func updateBootstrapVer(s Session) {
+	sql := fmt.Sprintf(`INSERT INTO %s.%s VALUES("%s", "%d", "Bootstrap version. Do not delete.")
+		ON DUPLICATE KEY UPDATE VARIABLE_VALUE="%d"`,
+		mysql.SystemDB, mysql.TiDBTable, tidbServerVersionVar, currentBootstrapVersion, currentBootstrapVersion)
+	mustExecute(s, sql)
+}
+
 // Execute DDL statements in bootstrap stage.
 func doDDLWorks(s Session) {
 	// Create a test database.
@@ -182,6 +268,8 @@ func doDDLWorks(s Session) {
 	mustExecute(s, CreateGloablVariablesTable)
 	// Create TiDB table.
 	mustExecute(s, CreateTiDBTable)
+	mustExecute(s, CreateStatsMetaTable)
+	mustExecute(s, CreateStatsHistogramsTable)
 }
 
 // Execute DML statements in bootstrap stage.
@@ -207,6 +295,7 @@ func doDMLWorks(s Session) {
 		ON DUPLICATE KEY UPDATE VARIABLE_VALUE="%s"`,
 		mysql.SystemDB, mysql.TiDBTable, bootstrappedVar, bootstrappedVarTrue, bootstrappedVarTrue)
 	mustExecute(s, sql)
+	updateBootstrapVer(s)
 	_, err := s.Execute("COMMIT")
 	if err != nil {
 		time.Sleep(1 * time.Second)
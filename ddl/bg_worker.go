@@ -81,7 +81,8 @@ func (d *ddl) runBgJob(t *meta.Meta, job *model.Job) {
 	switch job.Type {
 	case model.ActionDropSchema:
 		err = d.delReorgSchema(t, job)
-	case model.ActionDropTable:
+	case model.ActionDropTable, model.ActionDropTablePartition, model.ActionTruncateTablePartition:
+		// The args of the partition jobs are the physical tables of the partitions.
 		err = d.delReorgTable(t, job)
 	default:
 		job.State = model.JobCancelled
@@ -121,7 +122,8 @@ func (d *ddl) prepareBgJob(ddlJob *model.Job) error {
 // startBgJob starts a background job.
 func (d *ddl) startBgJob(tp model.ActionType) {
 	switch tp {
-	case model.ActionDropSchema, model.ActionDropTable:
+	case model.ActionDropSchema, model.ActionDropTable, model.ActionDropTablePartition,
+		model.ActionTruncateTablePartition:
 		asyncNotify(d.bgJobCh)
 	}
 }
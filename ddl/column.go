@@ -21,9 +21,11 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/types"
 )
 
 func (d *ddl) adjustColumnOffset(columns []*model.ColumnInfo, indices []*model.IndexInfo, offset int, added bool) {
@@ -430,3 +432,597 @@ func (d *ddl) dropTableColumn(t table.Table, colInfo *model.ColumnInfo, reorgInf
 		}
 	}
 }
+
+func (d *ddl) onSetDefaultValue(t *meta.Meta, job *model.Job) error {
+	schemaID := job.SchemaID
+	tblInfo, err := d.getTableInfo(t, job)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	newCol := &model.ColumnInfo{}
+	err = job.DecodeArgs(newCol)
+	if err != nil {
+		job.State = model.JobCancelled
+		return errors.Trace(err)
+	}
+
+	colInfo := findCol(tblInfo.Columns, newCol.Name.L)
+	if colInfo == nil || colInfo.State != model.StatePublic {
+		job.State = model.JobCancelled
+		return infoschema.ErrColumnNotExists.Gen("column %s doesn't exist", newCol.Name)
+	}
+
+	_, err = t.GenSchemaVersion()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	colInfo.DefaultValue = newCol.DefaultValue
+	colInfo.Flag = colInfo.Flag&^mysql.NoDefaultValueFlag | newCol.Flag&mysql.NoDefaultValueFlag
+	if err = t.UpdateTable(schemaID, tblInfo); err != nil {
+		return errors.Trace(err)
+	}
+
+	// finish this job
+	job.SchemaState = model.StatePublic
+	job.State = model.JobDone
+	return nil
+}
+
+// modifiable checks whether the values of the column type origin are still valid values of the column type to,
+// then MODIFY/CHANGE COLUMN only changes the metadata of the column, or the values must be converted.
+func modifiable(origin *types.FieldType, to *types.FieldType) bool {
+	if !mysql.HasNotNullFlag(origin.Flag) && mysql.HasNotNullFlag(to.Flag) {
+		// The NULL values must be checked.
+		return false
+	}
+	if mysql.HasUnsignedFlag(origin.Flag) != mysql.HasUnsignedFlag(to.Flag) {
+		return false
+	}
+	switch origin.Tp {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
+		// The integers are stored as 64-bit integers, the type can be widened.
+		return intTypeOrder(to.Tp) >= intTypeOrder(origin.Tp)
+	case mysql.TypeVarchar, mysql.TypeTinyBlob, mysql.TypeBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob:
+		switch to.Tp {
+		case mysql.TypeVarchar, mysql.TypeTinyBlob, mysql.TypeBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob:
+			return to.Charset == origin.Charset && to.Collate == origin.Collate && to.Flen >= origin.Flen
+		}
+		return false
+	case mysql.TypeString:
+		return to.Tp == origin.Tp && to.Charset == origin.Charset && to.Collate == origin.Collate &&
+			to.Flen >= origin.Flen
+	case mysql.TypeNewDecimal:
+		return to.Tp == origin.Tp && to.Decimal == origin.Decimal && to.Flen >= origin.Flen
+	case mysql.TypeEnum, mysql.TypeSet:
+		// The enum and set values are stored by the indices of the elements, the elements can be appended.
+		if to.Tp != origin.Tp || len(to.Elems) < len(origin.Elems) {
+			return false
+		}
+		for i, elem := range origin.Elems {
+			if to.Elems[i] != elem {
+				return false
+			}
+		}
+		return true
+	}
+	return to.Tp == origin.Tp && to.Flen == origin.Flen && to.Decimal == origin.Decimal
+}
+
+// intTypeOrder returns the order of the integer type by size, it returns -1 if tp isn't an integer type.
+func intTypeOrder(tp byte) int {
+	switch tp {
+	case mysql.TypeTiny:
+		return 0
+	case mysql.TypeShort:
+		return 1
+	case mysql.TypeInt24:
+		return 2
+	case mysql.TypeLong:
+		return 3
+	case mysql.TypeLonglong:
+		return 4
+	}
+	return -1
+}
+
+// replaceColumn replaces the public column oldCol of the table with newCol in the position pos, and resets the
+// offsets of the public columns. The column is renamed in the indices and the foreign keys of the table too.
+func replaceColumn(tblInfo *model.TableInfo, oldCol *model.ColumnInfo, newCol *model.ColumnInfo, pos *ast.ColumnPosition) {
+	cols := make([]*model.ColumnInfo, 0, len(tblInfo.Columns))
+	for _, col := range tblInfo.Columns {
+		if col == oldCol && pos.Tp == ast.ColumnPositionNone {
+			cols = append(cols, newCol)
+		} else if col != oldCol && col != newCol {
+			cols = append(cols, col)
+		}
+	}
+	if pos.Tp == ast.ColumnPositionFirst {
+		cols = append([]*model.ColumnInfo{newCol}, cols...)
+	} else if pos.Tp == ast.ColumnPositionAfter {
+		// The relative column is checked before the job runs.
+		position := findCol(cols, pos.RelativeColumn.Name.L).Offset + 1
+		cols = append(cols[:position], append([]*model.ColumnInfo{newCol}, cols[position:]...)...)
+	}
+	for i, col := range cols {
+		col.Offset = i
+	}
+	tblInfo.Columns = cols
+
+	for _, idx := range tblInfo.Indices {
+		for _, ic := range idx.Columns {
+			if ic.Name.L == oldCol.Name.L {
+				ic.Name = newCol.Name
+			}
+			ic.Offset = findCol(cols, ic.Name.L).Offset
+		}
+	}
+	for _, fk := range tblInfo.ForeignKeys {
+		for i, name := range fk.Cols {
+			if name.L == oldCol.Name.L {
+				fk.Cols[i] = newCol.Name
+			}
+		}
+	}
+}
+
+// checkModifyColumn checks whether the column can be changed by the job, the columns are checked again because the
+// table may be changed by the jobs before it.
+func checkModifyColumn(tblInfo *model.TableInfo, oldColName model.CIStr, newCol *model.ColumnInfo, pos *ast.ColumnPosition) error {
+	oldCol := findCol(tblInfo.Columns, oldColName.L)
+	if oldCol == nil || oldCol.State != model.StatePublic {
+		return infoschema.ErrColumnNotExists.Gen("column %s doesn't exist", oldColName)
+	}
+	if newCol.Name.L != oldCol.Name.L && findCol(tblInfo.Columns, newCol.Name.L) != nil {
+		return infoschema.ErrColumnExists.Gen("column %s already exists", newCol.Name)
+	}
+	if pos.Tp == ast.ColumnPositionAfter {
+		relative := findCol(tblInfo.Columns, pos.RelativeColumn.Name.L)
+		if relative == nil || relative == oldCol {
+			return infoschema.ErrColumnNotExists.Gen("no such column: %v", pos.RelativeColumn)
+		}
+	}
+	return nil
+}
+
+// checkConvertColumn checks whether the values of the column can be converted to the changed type, the column
+// covered by an index can't be converted now.
+func checkConvertColumn(tblInfo *model.TableInfo, oldCol *model.ColumnInfo) error {
+	if tblInfo.PKIsHandle && mysql.HasPriKeyFlag(oldCol.Flag) {
+		return errUnsupportedModifyColumn.Gen("can't convert the type of the primary key column %s now", oldCol.Name)
+	}
+	for _, idx := range tblInfo.Indices {
+		for _, ic := range idx.Columns {
+			if ic.Name.L == oldCol.Name.L {
+				return errUnsupportedModifyColumn.Gen("can't convert the type of column %s with index %s covered now",
+					oldCol.Name, idx.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// How to modify a column?
+// If the values of the column are valid values of the new column type, only the metadata of the column is changed.
+// Otherwise the new column is added as a non-public column which is changed from the old column, the writes of the
+// old column are written to it too, and the values of the old column are converted to it in reorganization. Then the
+// new column replaces the old one, the old column is dropped after being written in the same way for a schema lease.
+// If the values can't be converted, the job is rolled back by rollbackModifyColumn.
+func (d *ddl) onModifyColumn(t *meta.Meta, job *model.Job) error {
+	schemaID := job.SchemaID
+	tblInfo, err := d.getTableInfo(t, job)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	newCol := &model.ColumnInfo{}
+	var oldColName model.CIStr
+	pos := &ast.ColumnPosition{}
+	err = job.DecodeArgs(newCol, &oldColName, pos)
+	if err != nil {
+		job.State = model.JobCancelled
+		return errors.Trace(err)
+	}
+
+	changingCol := findColByID(tblInfo.Columns, newCol.ID)
+	if changingCol == nil {
+		if err = checkModifyColumn(tblInfo, oldColName, newCol, pos); err != nil {
+			job.State = model.JobCancelled
+			return errors.Trace(err)
+		}
+		oldCol := findCol(tblInfo.Columns, oldColName.L)
+
+		if modifiable(&oldCol.FieldType, &newCol.FieldType) {
+			_, err = t.GenSchemaVersion()
+			if err != nil {
+				return errors.Trace(err)
+			}
+
+			newCol.ID = oldCol.ID
+			newCol.State = model.StatePublic
+			replaceColumn(tblInfo, oldCol, newCol, pos)
+			if err = t.UpdateTable(schemaID, tblInfo); err != nil {
+				return errors.Trace(err)
+			}
+
+			// finish this job
+			job.SchemaState = model.StatePublic
+			job.State = model.JobDone
+			return nil
+		}
+
+		if err = checkConvertColumn(tblInfo, oldCol); err != nil {
+			job.State = model.JobCancelled
+			return errors.Trace(err)
+		}
+
+		// Add the new column as the last column, it replaces the old column after reorganization.
+		newCol.State = model.StateNone
+		newCol.Offset = len(tblInfo.Columns)
+		newCol.ChangeFromID = oldCol.ID
+		tblInfo.Columns = append(tblInfo.Columns, newCol)
+		changingCol = newCol
+	}
+
+	if changingCol.State == model.StatePublic {
+		// The new column has replaced the old column, drop the old column.
+		return d.dropChangedColumn(t, job, tblInfo, changingCol)
+	}
+
+	_, err = t.GenSchemaVersion()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	switch changingCol.State {
+	case model.StateNone:
+		// none -> delete only
+		job.SchemaState = model.StateDeleteOnly
+		changingCol.State = model.StateDeleteOnly
+		err = t.UpdateTable(schemaID, tblInfo)
+		return errors.Trace(err)
+	case model.StateDeleteOnly:
+		// delete only -> write only
+		job.SchemaState = model.StateWriteOnly
+		changingCol.State = model.StateWriteOnly
+		err = t.UpdateTable(schemaID, tblInfo)
+		return errors.Trace(err)
+	case model.StateWriteOnly:
+		// write only -> reorganization
+		job.SchemaState = model.StateWriteReorganization
+		changingCol.State = model.StateWriteReorganization
+		// initialize SnapshotVer to 0 for later reorganization check.
+		job.SnapshotVer = 0
+		err = t.UpdateTable(schemaID, tblInfo)
+		return errors.Trace(err)
+	case model.StateWriteReorganization:
+		// reorganization -> replace the old column
+		reorgInfo, err := d.getReorgInfo(t, job)
+		if err != nil || reorgInfo.first {
+			// if we run reorg firstly, we should update the job snapshot version
+			// and then run the reorg next time.
+			return errors.Trace(err)
+		}
+
+		tbl, err := d.getTable(schemaID, tblInfo)
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		oldCol := findColByID(tblInfo.Columns, changingCol.ChangeFromID)
+		err = d.runReorgJob(func() error {
+			return d.backfillChangedColumn(tbl, oldCol, changingCol, reorgInfo)
+		})
+
+		if terror.ErrorEqual(err, errWaitReorgTimeout) {
+			// if timeout, we should return, check for the owner and re-wait job done.
+			return nil
+		}
+		if terror.ErrorEqual(err, errCantConvertColumn) {
+			// The values can't be converted, the new column is dropped by the rollback, and the job is cancelled
+			// with the error after that. The error is saved in the job arguments, as the job error is overwritten
+			// by the errors of the rollback.
+			job.State = model.JobCancelling
+			job.Args = append(job.Args, err.Error())
+			return errors.Trace(err)
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		// The old column is written with the values of the new column until it's dropped, as the schema of the
+		// servers may be the last one in which the old column is public.
+		changingCol.State = model.StatePublic
+		changingCol.ChangeFromID = 0
+		replaceColumn(tblInfo, oldCol, changingCol, pos)
+		oldCol.State = model.StateWriteOnly
+		oldCol.Offset = len(tblInfo.Columns)
+		oldCol.ChangeFromID = changingCol.ID
+		tblInfo.Columns = append(tblInfo.Columns, oldCol)
+
+		job.SchemaState = model.StateWriteOnly
+		err = t.UpdateTable(schemaID, tblInfo)
+		return errors.Trace(err)
+	default:
+		return ErrInvalidColumnState.Gen("invalid column state %v", changingCol.State)
+	}
+}
+
+// dropChangedColumn drops the old column which is replaced by the new column changed from it.
+func (d *ddl) dropChangedColumn(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, newCol *model.ColumnInfo) error {
+	schemaID := job.SchemaID
+	var oldCol *model.ColumnInfo
+	for _, col := range tblInfo.Columns {
+		if col.ChangeFromID == newCol.ID {
+			oldCol = col
+		}
+	}
+	if oldCol == nil {
+		job.State = model.JobCancelled
+		return ErrInvalidColumnState.Gen("the column %s changed from doesn't exist", newCol.Name)
+	}
+
+	_, err := t.GenSchemaVersion()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	switch oldCol.State {
+	case model.StateWriteOnly:
+		// write only -> delete only
+		job.SchemaState = model.StateDeleteOnly
+		oldCol.State = model.StateDeleteOnly
+		err = t.UpdateTable(schemaID, tblInfo)
+		return errors.Trace(err)
+	case model.StateDeleteOnly:
+		// delete only -> reorganization
+		job.SchemaState = model.StateDeleteReorganization
+		oldCol.State = model.StateDeleteReorganization
+		// initialize SnapshotVer to 0 for later reorganization check.
+		job.SnapshotVer = 0
+		err = t.UpdateTable(schemaID, tblInfo)
+		return errors.Trace(err)
+	case model.StateDeleteReorganization:
+		// reorganization -> absent
+		reorgInfo, err := d.getReorgInfo(t, job)
+		if err != nil || reorgInfo.first {
+			// if we run reorg firstly, we should update the job snapshot version
+			// and then run the reorg next time.
+			return errors.Trace(err)
+		}
+
+		tbl, err := d.getTable(schemaID, tblInfo)
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		err = d.runReorgJob(func() error {
+			return d.dropTableColumn(tbl, oldCol, reorgInfo)
+		})
+
+		if terror.ErrorEqual(err, errWaitReorgTimeout) {
+			// if timeout, we should return, check for the owner and re-wait job done.
+			return nil
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		// all reorganization jobs done, drop the old column
+		tblInfo.Columns = tblInfo.Columns[:len(tblInfo.Columns)-1]
+		if err = t.UpdateTable(schemaID, tblInfo); err != nil {
+			return errors.Trace(err)
+		}
+
+		// finish this job
+		job.SchemaState = model.StatePublic
+		job.State = model.JobDone
+		return nil
+	default:
+		return ErrInvalidColumnState.Gen("invalid column state %v", oldCol.State)
+	}
+}
+
+// rollbackModifyColumn rolls back the modify column job whose values can't be converted, the new column is dropped
+// like DROP COLUMN: write only/reorganization -> delete only -> delete reorganization -> absent.
+// The job cancelled by ADMIN CANCEL DDL JOBS hasn't added the new column, it's cancelled directly.
+func (d *ddl) rollbackModifyColumn(t *meta.Meta, job *model.Job) error {
+	schemaID := job.SchemaID
+	tblInfo, err := d.getTableInfo(t, job)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	newCol := &model.ColumnInfo{}
+	var (
+		oldColName model.CIStr
+		convertErr string
+	)
+	pos := &ast.ColumnPosition{}
+	if err = job.DecodeArgs(newCol, &oldColName, pos, &convertErr); err != nil {
+		job.State = model.JobCancelled
+		return errors.Trace(err)
+	}
+
+	changingCol := findColByID(tblInfo.Columns, newCol.ID)
+	if changingCol == nil || changingCol.ChangeFromID == 0 || changingCol.State == model.StatePublic {
+		// the new column isn't added yet or it's dropped, the job is cancelled.
+		job.State = model.JobCancelled
+		if convertErr != "" {
+			return errors.New(convertErr)
+		}
+		return errCancelledDDLJob.Gen("cancelled DDL job %d", job.ID)
+	}
+
+	_, err = t.GenSchemaVersion()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	switch changingCol.State {
+	case model.StateWriteOnly, model.StateWriteReorganization:
+		// write only/reorganization -> delete only
+		job.SchemaState = model.StateDeleteOnly
+		changingCol.State = model.StateDeleteOnly
+		err = t.UpdateTable(schemaID, tblInfo)
+		return errors.Trace(err)
+	case model.StateDeleteOnly:
+		// delete only -> reorganization
+		job.SchemaState = model.StateDeleteReorganization
+		changingCol.State = model.StateDeleteReorganization
+		// initialize SnapshotVer to 0 for later reorganization check.
+		job.SnapshotVer = 0
+		err = t.UpdateTable(schemaID, tblInfo)
+		return errors.Trace(err)
+	case model.StateDeleteReorganization:
+		// reorganization -> absent
+		reorgInfo, err := d.getReorgInfo(t, job)
+		if err != nil || reorgInfo.first {
+			// if we run reorg firstly, we should update the job snapshot version
+			// and then run the reorg next time.
+			return errors.Trace(err)
+		}
+
+		tbl, err := d.getTable(schemaID, tblInfo)
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		// the values backfilled to the new column are deleted.
+		err = d.runReorgJob(func() error {
+			return d.dropTableColumn(tbl, changingCol, reorgInfo)
+		})
+
+		if terror.ErrorEqual(err, errWaitReorgTimeout) {
+			// if timeout, we should return, check for the owner and re-wait job done.
+			return nil
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		// the new column is the last column.
+		tblInfo.Columns = tblInfo.Columns[:len(tblInfo.Columns)-1]
+		if err = t.UpdateTable(schemaID, tblInfo); err != nil {
+			return errors.Trace(err)
+		}
+		if err = t.RemoveDDLReorgChunks(job); err != nil {
+			return errors.Trace(err)
+		}
+
+		// the job is cancelled when it runs next time, after the other servers drop the new column.
+		job.SchemaState = model.StateNone
+		return nil
+	default:
+		return ErrInvalidColumnState.Gen("invalid column state %v", changingCol.State)
+	}
+}
+
+// How to backfill the column changed from the old column in reorganization state?
+//  1. Generate a snapshot with special version.
+//  2. Traverse the snapshot, get every row in the table.
+//  3. For one row, if the row has been already deleted, skip to next row.
+//  4. If not deleted, convert the latest value of the old column to the type of the new column, and write it to the
+//     new column. The row is locked, so the concurrent update of the row conflicts with it.
+func (d *ddl) backfillChangedColumn(t table.Table, oldCol *model.ColumnInfo, newCol *model.ColumnInfo, reorgInfo *reorgInfo) error {
+	seekHandle := reorgInfo.Handle
+	version := reorgInfo.SnapshotVer
+
+	from := &table.Column{ColumnInfo: *oldCol}
+	to := &table.Column{ColumnInfo: *newCol}
+	for {
+		handles, err := d.getSnapshotRows(t, version, seekHandle)
+		if err != nil {
+			return errors.Trace(err)
+		} else if len(handles) == 0 {
+			return nil
+		}
+
+		seekHandle = handles[len(handles)-1] + 1
+
+		err = kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
+			if err1 := d.isReorgRunnable(txn); err1 != nil {
+				return errors.Trace(err1)
+			}
+
+			for _, h := range handles {
+				exist, err1 := checkRowExist(txn, t, h)
+				if err1 != nil {
+					return errors.Trace(err1)
+				} else if !exist {
+					// If row doesn't exist, skip it.
+					continue
+				}
+
+				value, err1 := convertColumnValue(txn, t, h, from, to)
+				if err1 != nil {
+					return errors.Trace(err1)
+				}
+
+				if err1 = lockRow(txn, t, h); err1 != nil {
+					return errors.Trace(err1)
+				}
+				if value.IsNull() {
+					continue
+				}
+				if err1 = tables.SetColValue(txn, t.RecordKey(h, to), value); err1 != nil {
+					return errors.Trace(err1)
+				}
+			}
+			return errors.Trace(reorgInfo.UpdateHandle(txn, handles[len(handles)-1]))
+		})
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+}
+
+// convertColumnValue reads the value of the column from of the row, and converts it to the type of the column to.
+func convertColumnValue(txn kv.Transaction, t table.Table, h int64, from *table.Column, to *table.Column) (types.Datum, error) {
+	var value types.Datum
+	data, err := txn.Get(t.RecordKey(h, from))
+	if err != nil && !terror.ErrorEqual(err, kv.ErrNotExist) {
+		return value, errors.Trace(err)
+	}
+	if data != nil {
+		value, err = tables.DecodeValue(data, &from.FieldType)
+		if err != nil {
+			return value, errors.Trace(err)
+		}
+	}
+
+	if value.IsNull() {
+		if mysql.HasNotNullFlag(to.Flag) {
+			return value, errCantConvertColumn.Gen("invalid NULL value of column %s for NOT NULL", from.Name)
+		}
+		return value, nil
+	}
+	converted, err := value.ConvertTo(&to.FieldType)
+	if err != nil {
+		return converted, errCantConvertColumn.Gen("can't convert the value of column %s: %v", from.Name, err)
+	}
+	if k := converted.Kind(); (k == types.KindString || k == types.KindBytes) && to.Flen != types.UnspecifiedLength {
+		// ConvertTo truncates the string to the length of the column silently.
+		tp := to.FieldType
+		tp.Flen = types.UnspecifiedLength
+		full, err := value.ConvertTo(&tp)
+		if err != nil {
+			return converted, errCantConvertColumn.Gen("can't convert the value of column %s: %v", from.Name, err)
+		}
+		if len(full.GetString()) > len(converted.GetString()) {
+			return converted, errCantConvertColumn.Gen("data too long for column %s", from.Name)
+		}
+	}
+	return converted, nil
+}
+
+// findColByID finds column in cols by ID.
+func findColByID(cols []*model.ColumnInfo, id int64) *model.ColumnInfo {
+	for _, col := range cols {
+		if col.ID == id {
+			return col
+		}
+	}
+	return nil
+}
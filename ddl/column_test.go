@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
@@ -684,3 +685,251 @@ func (s *testColumnSuite) TestDropColumn(c *C) {
 	d.close()
 	s.d.start()
 }
+
+func testModifyColumn(c *C, ctx context.Context, d *ddl, dbInfo *model.DBInfo, tblInfo *model.TableInfo, colName string, tp byte) *model.Job {
+	col := &model.ColumnInfo{Name: model.NewCIStr(colName)}
+	var err error
+	col.ID, err = d.genGlobalID()
+	c.Assert(err, IsNil)
+	col.FieldType = *types.NewFieldType(tp)
+	col.Flen = 10
+
+	job := &model.Job{
+		SchemaID: dbInfo.ID,
+		TableID:  tblInfo.ID,
+		Type:     model.ActionModifyColumn,
+		Args:     []interface{}{col, col.Name, &ast.ColumnPosition{Tp: ast.ColumnPositionNone}},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	if err == nil {
+		testCheckJobDone(c, d, job, true)
+	} else {
+		testCheckJobCancelled(c, d, job)
+	}
+	return job
+}
+
+func (s *testColumnSuite) TestModifyColumn(c *C) {
+	defer testleak.AfterTest(c)()
+	d := newDDL(s.store, nil, nil, 100*time.Millisecond)
+	tblInfo := testTableInfo(c, d, "t", 3)
+	ctx := testNewContext(c, d)
+
+	_, err := ctx.GetTxn(true)
+	c.Assert(err, IsNil)
+
+	testCreateTable(c, ctx, d, s.dbInfo, tblInfo)
+
+	t := testGetTable(c, d, s.dbInfo.ID, tblInfo.ID)
+	handle, err := t.AddRecord(ctx, types.MakeDatums(int64(1), int64(2), int64(3)))
+	c.Assert(err, IsNil)
+
+	err = ctx.CommitTxn()
+	c.Assert(err, IsNil)
+
+	var checkedStates []model.SchemaState
+	checkOK := false
+	tc := &testDDLCallback{}
+	tc.onJobUpdated = func(job *model.Job) {
+		if checkOK {
+			return
+		}
+
+		t := testGetTable(c, d, s.dbInfo.ID, tblInfo.ID).(*tables.Table)
+		var changingCol *table.Column
+		for _, col := range t.Columns {
+			if col.ChangeFromID != 0 && (col.State == model.StateWriteOnly || col.State == model.StateWriteReorganization) {
+				changingCol = col
+			}
+		}
+		if changingCol == nil {
+			return
+		}
+		checkedStates = append(checkedStates, job.SchemaState)
+
+		// The column changed from the other column is written with the converted values of the other column.
+		ctx := testNewContext(c, d)
+		txn, err := ctx.GetTxn(true)
+		c.Assert(err, IsNil)
+		row := types.MakeDatums(int64(10), int64(20), int64(30))
+		c.Assert(table.CastValues(ctx, row, t.Cols()), IsNil)
+		h, err := t.AddRecord(ctx, row)
+		c.Assert(err, IsNil)
+		oldRow, err := t.Row(ctx, handle)
+		c.Assert(err, IsNil)
+		newRow := types.MakeDatums(oldRow[0].GetValue(), int64(200), oldRow[2].GetValue())
+		c.Assert(table.CastValues(ctx, newRow, t.Cols()), IsNil)
+		err = t.UpdateRecord(ctx, handle, oldRow, newRow, map[int]bool{1: true})
+		c.Assert(err, IsNil)
+		expected := []struct {
+			h int64
+			v string
+		}{{h, "20"}, {handle, "200"}}
+		for _, e := range expected {
+			data, err := txn.Get(t.RecordKey(e.h, changingCol))
+			c.Assert(err, IsNil)
+			v, err := tables.DecodeValue(data, &changingCol.FieldType)
+			c.Assert(err, IsNil)
+			s, err := v.ToString()
+			c.Assert(err, IsNil)
+			c.Assert(s, Equals, e.v)
+		}
+		if changingCol.Tp != mysql.TypeVarchar {
+			// The value which can't be converted back to the old column is written as NULL.
+			h, err = t.AddRecord(ctx, types.MakeDatums(int64(11), "abc", int64(31)))
+			c.Assert(err, IsNil)
+			_, err = txn.Get(t.RecordKey(h, changingCol))
+			c.Assert(terror.ErrorEqual(err, kv.ErrNotExist), IsTrue)
+		}
+		err = ctx.CommitTxn()
+		c.Assert(err, IsNil)
+	}
+
+	d.hook = tc
+
+	// Use local ddl for callback test.
+	s.d.close()
+
+	d.close()
+	d.start()
+
+	testModifyColumn(c, ctx, d, s.dbInfo, tblInfo, "c2", mysql.TypeVarchar)
+	// The new column is written in the write only and reorganization states, then the old column is written in the
+	// write only state.
+	c.Assert(checkedStates, DeepEquals, []model.SchemaState{model.StateWriteOnly, model.StateWriteReorganization,
+		model.StateWriteReorganization, model.StateWriteOnly})
+	checkOK = true
+
+	t = testGetTable(c, d, s.dbInfo.ID, tblInfo.ID)
+	c.Assert(t.Cols(), HasLen, 3)
+	c.Assert(t.Cols()[1].Tp, Equals, mysql.TypeVarchar)
+	_, err = ctx.GetTxn(true)
+	c.Assert(err, IsNil)
+	var values []string
+	err = t.IterRecords(ctx, t.FirstKey(), t.Cols(), func(h int64, data []types.Datum, cols []*table.Column) (bool, error) {
+		c.Assert(data[1].Kind(), Equals, types.KindBytes)
+		values = append(values, data[1].GetString())
+		return true, nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(values, DeepEquals, []string{"200", "20", "20", "20", "20", "abc"})
+
+	// The job is cancelled if the values can't be converted.
+	_, err = t.AddRecord(ctx, types.MakeDatums(int64(1), "abc", int64(3)))
+	c.Assert(err, IsNil)
+	err = ctx.CommitTxn()
+	c.Assert(err, IsNil)
+	testModifyColumn(c, ctx, d, s.dbInfo, tblInfo, "c2", mysql.TypeLong)
+	t = testGetTable(c, d, s.dbInfo.ID, tblInfo.ID)
+	c.Assert(t.(*tables.Table).Columns, HasLen, 3)
+	c.Assert(t.Cols()[1].Tp, Equals, mysql.TypeVarchar)
+
+	_, err = ctx.GetTxn(true)
+	c.Assert(err, IsNil)
+
+	job := testDropTable(c, ctx, d, s.dbInfo, tblInfo)
+	testCheckJobDone(c, d, job, false)
+
+	err = ctx.CommitTxn()
+	c.Assert(err, IsNil)
+
+	d.close()
+	s.d.start()
+}
+
+func (s *testColumnSuite) TestModifyColumnRollback(c *C) {
+	defer testleak.AfterTest(c)()
+	d := newDDL(s.store, nil, nil, 100*time.Millisecond)
+	tblInfo := testTableInfo(c, d, "t", 3)
+	ctx := testNewContext(c, d)
+
+	_, err := ctx.GetTxn(true)
+	c.Assert(err, IsNil)
+
+	testCreateTable(c, ctx, d, s.dbInfo, tblInfo)
+
+	// The first batch of the rows is backfilled before the NULL value of the last row fails the conversion.
+	t := testGetTable(c, d, s.dbInfo.ID, tblInfo.ID)
+	var handles []int64
+	for i := 0; i <= maxBatchSize; i++ {
+		var c2 interface{}
+		if i < maxBatchSize {
+			c2 = int64(i)
+		}
+		h, err1 := t.AddRecord(ctx, types.MakeDatums(int64(i), c2, int64(i)))
+		c.Assert(err1, IsNil)
+		handles = append(handles, h)
+	}
+
+	err = ctx.CommitTxn()
+	c.Assert(err, IsNil)
+
+	newCol := &model.ColumnInfo{Name: model.NewCIStr("c2")}
+	newCol.ID, err = d.genGlobalID()
+	c.Assert(err, IsNil)
+	newCol.FieldType = *types.NewFieldType(mysql.TypeVarchar)
+	newCol.Flen = 10
+	newCol.Flag = mysql.NotNullFlag
+	backfillKey := t.RecordKey(handles[0], &table.Column{ColumnInfo: *newCol})
+
+	var rollbackStates []model.SchemaState
+	backfilled := false
+	tc := &testDDLCallback{}
+	tc.onJobUpdated = func(job *model.Job) {
+		if job.State != model.JobCancelling {
+			return
+		}
+		if n := len(rollbackStates); n == 0 || rollbackStates[n-1] != job.SchemaState {
+			rollbackStates = append(rollbackStates, job.SchemaState)
+		}
+		if job.SchemaState == model.StateDeleteOnly {
+			kv.RunInNewTxn(s.store, false, func(txn kv.Transaction) error {
+				_, err1 := txn.Get(backfillKey)
+				backfilled = err1 == nil
+				return nil
+			})
+		}
+	}
+
+	d.hook = tc
+
+	// Use local ddl for callback test.
+	s.d.close()
+
+	d.close()
+	d.start()
+
+	job := &model.Job{
+		SchemaID: s.dbInfo.ID,
+		TableID:  tblInfo.ID,
+		Type:     model.ActionModifyColumn,
+		Args:     []interface{}{newCol, newCol.Name, &ast.ColumnPosition{Tp: ast.ColumnPositionNone}},
+	}
+	err = d.doDDLJob(ctx, job)
+	c.Assert(err, ErrorMatches, ".*invalid NULL value of column c2 for NOT NULL.*")
+	testCheckJobCancelled(c, d, job)
+
+	// The new column is dropped like DROP COLUMN, and its backfilled values are deleted.
+	c.Assert(rollbackStates, DeepEquals, []model.SchemaState{model.StateWriteReorganization, model.StateDeleteOnly,
+		model.StateDeleteReorganization, model.StateNone})
+	c.Assert(backfilled, IsTrue)
+	t = testGetTable(c, d, s.dbInfo.ID, tblInfo.ID)
+	c.Assert(t.(*tables.Table).Columns, HasLen, 3)
+	c.Assert(t.Cols()[1].Tp, Equals, mysql.TypeLong)
+	txn, err := ctx.GetTxn(true)
+	c.Assert(err, IsNil)
+	for _, h := range handles {
+		_, err = txn.Get(t.RecordKey(h, &table.Column{ColumnInfo: *newCol}))
+		c.Assert(terror.ErrorEqual(err, kv.ErrNotExist), IsTrue)
+	}
+
+	job = testDropTable(c, ctx, d, s.dbInfo, tblInfo)
+	testCheckJobDone(c, d, job, false)
+
+	err = ctx.CommitTxn()
+	c.Assert(err, IsNil)
+
+	d.close()
+	s.d.start()
+}
@@ -53,10 +53,18 @@ var (
 	errRunMultiSchemaChanges = terror.ClassDDL.New(codeRunMultiSchemaChanges, "can't run multi schema change")
 	errWaitReorgTimeout      = terror.ClassDDL.New(codeWaitReorgTimeout, "wait for reorganization timeout")
 	errInvalidStoreVer       = terror.ClassDDL.New(codeInvalidStoreVer, "invalid storage current version")
+	// errCancelledDDLJob is the error of the job cancelled by ADMIN CANCEL DDL JOBS.
+	errCancelledDDLJob = terror.ClassDDL.New(codeCancelledDDLJob, "cancelled DDL job")
 
 	// we don't support drop column with index covered now.
 	errCantDropColWithIndex = terror.ClassDDL.New(codeCantDropColWithIndex, "can't drop column with index")
 	errUnsupportedAddColumn = terror.ClassDDL.New(codeUnsupportedAddColumn, "unsupported add column")
+	// errUnsupportedModifyColumn is returned for the column changes which aren't supported by MODIFY/CHANGE COLUMN.
+	errUnsupportedModifyColumn = terror.ClassDDL.New(codeUnsupportedModifyColumn, "unsupported modify column")
+	// errCantConvertColumn is returned if the values of the column can't be converted to the changed type.
+	errCantConvertColumn = terror.ClassDDL.New(codeCantConvertColumn, "can't convert the values of the column")
+	// errUnsupportedPartition is returned for the schema changes which aren't supported on the partitioned tables.
+	errUnsupportedPartition = terror.ClassDDL.New(codeUnsupportedPartition, "unsupported partition")
 
 	// ErrInvalidDBState returns for invalid database state.
 	ErrInvalidDBState = terror.ClassDDL.New(codeInvalidDBState, "invalid database state")
@@ -77,6 +85,35 @@ var (
 	ErrCantDropFieldOrKey = terror.ClassDDL.New(codeCantDropFieldOrKey, "can't drop field; check that column/key exists")
 	// ErrInvalidOnUpdate returns for invalid ON UPDATE clause.
 	ErrInvalidOnUpdate = terror.ClassDDL.New(codeInvalidOnUpdate, "invalid ON UPDATE clause for the column")
+
+	// ErrPartitionMaxvalue returns for MAXVALUE which isn't in the last partition.
+	ErrPartitionMaxvalue = terror.ClassDDL.New(codePartitionMaxvalue, "MAXVALUE can only be used in last partition definition")
+	// ErrRangeNotIncreasing returns for the upper bounds of the RANGE partitions which aren't strictly increasing.
+	ErrRangeNotIncreasing = terror.ClassDDL.New(codeRangeNotIncreasing, "VALUES LESS THAN value must be strictly increasing for each partition")
+	// ErrTooManyPartitions returns for too many partitions.
+	ErrTooManyPartitions = terror.ClassDDL.New(codeTooManyPartitions, "Too many partitions (including subpartitions) were defined")
+	// ErrUniqueKeyNeedAllFieldsInPf returns for the primary key or the unique index without the partition column.
+	ErrUniqueKeyNeedAllFieldsInPf = terror.ClassDDL.New(codeUniqueKeyNeedAllFieldsInPf, "A unique key must include all columns in the table's partitioning function")
+	// ErrNoParts returns for PARTITIONS 0.
+	ErrNoParts = terror.ClassDDL.New(codeNoParts, "Number of partitions = 0 is not an allowed value")
+	// ErrPartitionMgmtOnNonpartitioned returns for the partition management on the table which isn't partitioned.
+	ErrPartitionMgmtOnNonpartitioned = terror.ClassDDL.New(codePartitionMgmtOnNonpartitioned, "Partition management on a not partitioned table is not possible")
+	// ErrForeignKeyOnPartitioned returns for the foreign key of the partitioned table.
+	ErrForeignKeyOnPartitioned = terror.ClassDDL.New(codeForeignKeyOnPartitioned, "Foreign key clause is not yet supported in conjunction with partitioning")
+	// ErrDropPartitionNonExistent returns for dropping or truncating a non-existent partition.
+	ErrDropPartitionNonExistent = terror.ClassDDL.New(codeDropPartitionNonExistent, "Error in list of partitions")
+	// ErrDropLastPartition returns for dropping the only partition of the table.
+	ErrDropLastPartition = terror.ClassDDL.New(codeDropLastPartition, "Cannot remove all partitions, use DROP TABLE instead")
+	// ErrOnlyOnRangeListPartition returns for adding or dropping a partition of the table which isn't partitioned by RANGE.
+	ErrOnlyOnRangeListPartition = terror.ClassDDL.New(codeOnlyOnRangeListPartition, "PARTITION can only be used on RANGE/LIST partitions")
+	// ErrSameNamePartition returns for the duplicate partition names.
+	ErrSameNamePartition = terror.ClassDDL.New(codeSameNamePartition, "Duplicate partition name")
+	// ErrPartitionFunctionIsNotAllowed returns for the partition expression which isn't a column.
+	ErrPartitionFunctionIsNotAllowed = terror.ClassDDL.New(codePartitionFunctionIsNotAllowed, "This partition function is not allowed")
+	// ErrFieldTypeNotAllowedAsPartitionField returns for the partition column which isn't of integer type.
+	ErrFieldTypeNotAllowedAsPartitionField = terror.ClassDDL.New(codeFieldTypeNotAllowedAsPartitionField, "Field is of a not allowed type for this type of partitioning")
+	// ErrValuesIsNotIntType returns for the value of VALUES LESS THAN which isn't an integer.
+	ErrValuesIsNotIntType = terror.ClassDDL.New(codeValuesIsNotIntType, "VALUES value for partition must have type INT")
 )
 
 // DDL is responsible for updating schema in data store and maintaining in-memory InfoSchema cache.
@@ -84,8 +121,9 @@ type DDL interface {
 	CreateSchema(ctx context.Context, name model.CIStr, charsetInfo *ast.CharsetOpt) error
 	DropSchema(ctx context.Context, schema model.CIStr) error
 	CreateTable(ctx context.Context, ident ast.Ident, cols []*ast.ColumnDef,
-		constrs []*ast.Constraint, options []*ast.TableOption) error
+		constrs []*ast.Constraint, options []*ast.TableOption, partition *ast.PartitionOptions) error
 	DropTable(ctx context.Context, tableIdent ast.Ident) (err error)
+	RenameTable(ctx context.Context, oldTableIdents, newTableIdents []ast.Ident) error
 	CreateIndex(ctx context.Context, tableIdent ast.Ident, unique bool, indexName model.CIStr,
 		columnNames []*ast.IndexColName) error
 	DropIndex(ctx context.Context, tableIdent ast.Ident, indexName model.CIStr) error
@@ -104,6 +142,9 @@ type DDL interface {
 	Stop() error
 	// Start starts DDL worker.
 	Start() error
+	// IsOwner checks if the server is the owner of the DDL jobs, the background tasks which should run on
+	// only one server, e.g. auto analyze, are run by the owner.
+	IsOwner() (bool, error)
 }
 
 type ddl struct {
@@ -269,6 +310,21 @@ func (d *ddl) GetLease() time.Duration {
 	return lease
 }
 
+// IsOwner implements DDL IsOwner interface.
+func (d *ddl) IsOwner() (bool, error) {
+	var isOwner bool
+	err := kv.RunInNewTxn(d.store, false, func(txn kv.Transaction) error {
+		owner, err := meta.NewMeta(txn).GetDDLJobOwner()
+		if err != nil || owner == nil {
+			return errors.Trace(err)
+		}
+		// The owner updates its status every 2 * lease, it's timed out after 4 * lease as checkOwner does.
+		isOwner = owner.OwnerID == d.uuid && time.Now().UnixNano()-owner.LastUpdateTS <= int64(4*d.GetLease())
+		return nil
+	})
+	return isOwner, errors.Trace(err)
+}
+
 func (d *ddl) GetInformationSchema() infoschema.InfoSchema {
 	return d.infoHandle.Get()
 }
@@ -805,7 +861,7 @@ func (d *ddl) buildTableInfo(tableName model.CIStr, cols []*table.Column, constr
 }
 
 func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.ColumnDef,
-	constraints []*ast.Constraint, options []*ast.TableOption) (err error) {
+	constraints []*ast.Constraint, options []*ast.TableOption, partition *ast.PartitionOptions) (err error) {
 	is := d.GetInformationSchema()
 	schema, ok := is.SchemaByName(ident.Schema)
 	if !ok {
@@ -832,6 +888,15 @@ func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.C
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if partition != nil {
+		if len(tbInfo.ForeignKeys) > 0 {
+			return errors.Trace(ErrForeignKeyOnPartitioned)
+		}
+		tbInfo.Partition, err = d.buildPartitionInfo(ctx, tbInfo, partition)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
 
 	job := &model.Job{
 		SchemaID: schema.ID,
@@ -915,6 +980,19 @@ func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.Alte
 			}
 		case ast.AlterTableDropForeignKey:
 			err = d.DropForeignKey(ctx, ident, model.NewCIStr(spec.Name))
+		case ast.AlterTableModifyColumn, ast.AlterTableChangeColumn:
+			err = d.ModifyColumn(ctx, ident, spec)
+		case ast.AlterTableAlterColumn:
+			err = d.AlterColumn(ctx, ident, spec)
+		case ast.AlterTableRenameTable:
+			newIdent := ast.Ident{Schema: spec.NewTable.Schema, Name: spec.NewTable.Name}
+			err = d.RenameTable(ctx, []ast.Ident{ident}, []ast.Ident{newIdent})
+		case ast.AlterTableAddPartition:
+			err = d.AddTablePartitions(ctx, ident, spec.PartDefinitions)
+		case ast.AlterTableDropPartition:
+			err = d.DropTablePartition(ctx, ident, model.NewCIStr(spec.Name))
+		case ast.AlterTableTruncatePartition:
+			err = d.TruncateTablePartition(ctx, ident, model.NewCIStr(spec.Name))
 		default:
 			// nothing to do now.
 		}
@@ -956,6 +1034,9 @@ func (d *ddl) AddColumn(ctx context.Context, ti ast.Ident, spec *ast.AlterTableS
 	if err != nil {
 		return errors.Trace(infoschema.ErrTableNotExists)
 	}
+	if err = checkNotPartitioned(t, model.ActionAddColumn); err != nil {
+		return errors.Trace(err)
+	}
 
 	// Check whether added column has existed.
 	colName := spec.Column.Name.Name.O
@@ -996,6 +1077,9 @@ func (d *ddl) DropColumn(ctx context.Context, ti ast.Ident, colName model.CIStr)
 	if err != nil {
 		return errors.Trace(infoschema.ErrTableNotExists)
 	}
+	if err = checkNotPartitioned(t, model.ActionDropColumn); err != nil {
+		return errors.Trace(err)
+	}
 
 	// Check whether dropped column has existed.
 	col := table.FindCol(t.Cols(), colName.L)
@@ -1015,6 +1099,116 @@ func (d *ddl) DropColumn(ctx context.Context, ti ast.Ident, colName model.CIStr)
 	return errors.Trace(err)
 }
 
+// ModifyColumn changes the definition of a column, CHANGE COLUMN renames the column too.
+// The column can't be changed to a primary key or unique key column, the indices are added by ADD INDEX.
+func (d *ddl) ModifyColumn(ctx context.Context, ti ast.Ident, spec *ast.AlterTableSpec) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return errors.Trace(infoschema.ErrDatabaseNotExists)
+	}
+
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists)
+	}
+	if err = checkNotPartitioned(t, model.ActionModifyColumn); err != nil {
+		return errors.Trace(err)
+	}
+
+	oldColName := spec.Column.Name.Name
+	if spec.Tp == ast.AlterTableChangeColumn {
+		oldColName = spec.OldColumnName.Name
+	}
+	oldCol := table.FindCol(t.Cols(), oldColName.L)
+	if oldCol == nil {
+		return infoschema.ErrColumnNotExists.Gen("column %s doesn't exist", oldColName)
+	}
+	newColName := spec.Column.Name.Name
+	if newColName.L != oldColName.L && table.FindCol(t.Cols(), newColName.L) != nil {
+		return infoschema.ErrColumnExists.Gen("column %s already exists", newColName)
+	}
+	if spec.Position.Tp == ast.ColumnPositionAfter {
+		relative := table.FindCol(t.Cols(), spec.Position.RelativeColumn.Name.L)
+		if relative == nil || relative == oldCol {
+			return infoschema.ErrColumnNotExists.Gen("no such column: %v", spec.Position.RelativeColumn)
+		}
+	}
+
+	col, constraints, err := d.buildColumnAndConstraint(ctx, oldCol.Offset, spec.Column)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(constraints) > 0 {
+		return errUnsupportedModifyColumn.Gen("unsupported modify column constraint - %v", constraints[0].Tp)
+	}
+	if mysql.HasAutoIncrementFlag(col.Flag) && !mysql.HasAutoIncrementFlag(oldCol.Flag) {
+		return errUnsupportedModifyColumn.Gen("unsupported modify column constraint - auto_increment")
+	}
+	// The key flags are set by the indices of the column.
+	col.Flag |= oldCol.Flag & (mysql.PriKeyFlag | mysql.UniqueKeyFlag | mysql.MultipleKeyFlag)
+	if mysql.HasPriKeyFlag(col.Flag) {
+		col.Flag |= mysql.NotNullFlag
+	}
+
+	job := &model.Job{
+		SchemaID: schema.ID,
+		TableID:  t.Meta().ID,
+		Type:     model.ActionModifyColumn,
+		Args:     []interface{}{&col.ColumnInfo, oldColName, spec.Position},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.hook.OnChanged(err)
+	return errors.Trace(err)
+}
+
+// AlterColumn sets the default value of a column by ALTER COLUMN SET DEFAULT, or drops it by ALTER COLUMN DROP DEFAULT.
+func (d *ddl) AlterColumn(ctx context.Context, ti ast.Ident, spec *ast.AlterTableSpec) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return errors.Trace(infoschema.ErrDatabaseNotExists)
+	}
+
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists)
+	}
+
+	colName := spec.Column.Name.Name
+	oldCol := table.FindCol(t.Cols(), colName.L)
+	if oldCol == nil {
+		return infoschema.ErrColumnNotExists.Gen("column %s doesn't exist", colName)
+	}
+
+	col := &table.Column{ColumnInfo: *oldCol.Clone()}
+	col.DefaultValue = nil
+	col.Flag &= ^uint(mysql.NoDefaultValueFlag)
+	hasDefaultValue := len(spec.Column.Options) > 0
+	if hasDefaultValue {
+		col.DefaultValue, err = getDefaultValue(ctx, spec.Column.Options[0], col.Tp, col.Decimal)
+		if err != nil {
+			return ErrColumnBadNull.Gen("invalid default value - %s", err)
+		}
+	}
+	setNoDefaultValueFlag(col, hasDefaultValue)
+	if err = checkDefaultValue(col, hasDefaultValue); err != nil {
+		return errors.Trace(err)
+	}
+
+	job := &model.Job{
+		SchemaID: schema.ID,
+		TableID:  t.Meta().ID,
+		Type:     model.ActionSetDefaultValue,
+		Args:     []interface{}{&col.ColumnInfo},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.hook.OnChanged(err)
+	return errors.Trace(err)
+}
+
 // DropTable will proceed even if some table in the list does not exists.
 func (d *ddl) DropTable(ctx context.Context, ti ast.Ident) (err error) {
 	is := d.GetInformationSchema()
@@ -1039,6 +1233,64 @@ func (d *ddl) DropTable(ctx context.Context, ti ast.Ident) (err error) {
 	return errors.Trace(err)
 }
 
+// RenameTable renames the old tables to the new tables in one DDL job, so all of them are renamed at once.
+// The tables are renamed in order, a table can be renamed to the name of a table renamed before it,
+// e.g. RENAME TABLE t TO t_old, t_new TO t swaps the tables.
+func (d *ddl) RenameTable(ctx context.Context, oldIdents, newIdents []ast.Ident) error {
+	is := d.GetInformationSchema()
+	// renamed maps the names changed by the renames before to the table IDs, the ID is 0 if the table is renamed away.
+	renamed := make(map[string]int64)
+	tableID := func(ti ast.Ident) int64 {
+		if id, ok := renamed[ti.Schema.L+"."+ti.Name.L]; ok {
+			return id
+		}
+		tb, err := is.TableByName(ti.Schema, ti.Name)
+		if err != nil {
+			return 0
+		}
+		return tb.Meta().ID
+	}
+
+	var oldSchemaIDs, newSchemaIDs, tableIDs []int64
+	var newNames []model.CIStr
+	for i, oldIdent := range oldIdents {
+		newIdent := newIdents[i]
+		oldSchema, ok := is.SchemaByName(oldIdent.Schema)
+		if !ok {
+			return infoschema.ErrDatabaseNotExists.Gen("database %s not exists", oldIdent.Schema)
+		}
+		newSchema, ok := is.SchemaByName(newIdent.Schema)
+		if !ok {
+			return infoschema.ErrDatabaseNotExists.Gen("database %s not exists", newIdent.Schema)
+		}
+		id := tableID(oldIdent)
+		if id == 0 {
+			return infoschema.ErrTableNotExists.Gen("table %s does not exist", oldIdent)
+		}
+		if tableID(newIdent) != 0 {
+			return infoschema.ErrTableExists.Gen("table %s already exists", newIdent)
+		}
+		renamed[oldIdent.Schema.L+"."+oldIdent.Name.L] = 0
+		renamed[newIdent.Schema.L+"."+newIdent.Name.L] = id
+
+		oldSchemaIDs = append(oldSchemaIDs, oldSchema.ID)
+		newSchemaIDs = append(newSchemaIDs, newSchema.ID)
+		tableIDs = append(tableIDs, id)
+		newNames = append(newNames, newIdent.Name)
+	}
+
+	job := &model.Job{
+		SchemaID: oldSchemaIDs[0],
+		TableID:  tableIDs[0],
+		Type:     model.ActionRenameTable,
+		Args:     []interface{}{oldSchemaIDs, newSchemaIDs, tableIDs, newNames},
+	}
+
+	err := d.doDDLJob(ctx, job)
+	err = d.hook.OnChanged(err)
+	return errors.Trace(err)
+}
+
 func (d *ddl) CreateIndex(ctx context.Context, ti ast.Ident, unique bool, indexName model.CIStr, idxColNames []*ast.IndexColName) error {
 	is := d.infoHandle.Get()
 	schema, ok := is.SchemaByName(ti.Schema)
@@ -1050,16 +1302,23 @@ func (d *ddl) CreateIndex(ctx context.Context, ti ast.Ident, unique bool, indexN
 	if err != nil {
 		return errors.Trace(infoschema.ErrTableNotExists)
 	}
+	if err = checkNotPartitioned(t, model.ActionAddIndex); err != nil {
+		return errors.Trace(err)
+	}
 	indexID, err := d.genGlobalID()
 	if err != nil {
 		return errors.Trace(err)
 	}
+	opts, err := getReorgOptions(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
 
 	job := &model.Job{
 		SchemaID: schema.ID,
 		TableID:  t.Meta().ID,
 		Type:     model.ActionAddIndex,
-		Args:     []interface{}{unique, indexName, indexID, idxColNames},
+		Args:     []interface{}{unique, indexName, indexID, idxColNames, opts},
 	}
 
 	err = d.doDDLJob(ctx, job)
@@ -1106,6 +1365,9 @@ func (d *ddl) CreateForeignKey(ctx context.Context, ti ast.Ident, fkName model.C
 	if err != nil {
 		return errors.Trace(infoschema.ErrTableNotExists)
 	}
+	if t.Meta().Partition != nil {
+		return errors.Trace(ErrForeignKeyOnPartitioned)
+	}
 
 	fkInfo, err := d.buildFKInfo(fkName, keys, refer)
 	if err != nil {
@@ -1160,6 +1422,9 @@ func (d *ddl) DropIndex(ctx context.Context, ti ast.Ident, indexName model.CIStr
 	if err != nil {
 		return errors.Trace(infoschema.ErrTableNotExists)
 	}
+	if err = checkNotPartitioned(t, model.ActionDropIndex); err != nil {
+		return errors.Trace(err)
+	}
 
 	job := &model.Job{
 		SchemaID: schema.ID,
@@ -1173,6 +1438,126 @@ func (d *ddl) DropIndex(ctx context.Context, ti ast.Ident, indexName model.CIStr
 	return errors.Trace(err)
 }
 
+// AddTablePartitions adds the RANGE partitions after the last partition of the table.
+func (d *ddl) AddTablePartitions(ctx context.Context, ti ast.Ident, defs []*ast.PartitionDefinition) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return errors.Trace(infoschema.ErrDatabaseNotExists)
+	}
+
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists)
+	}
+	pi := t.Meta().Partition
+	if pi == nil {
+		return errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	if pi.Type != model.PartitionTypeRange {
+		return ErrOnlyOnRangeListPartition.Gen("ADD PARTITION can only be used on RANGE/LIST partitions")
+	}
+
+	partDefs, err := buildRangePartitionDefinitions(ctx, defs)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	newPI := pi.Clone()
+	newPI.Definitions = append(newPI.Definitions, partDefs...)
+	if err = checkPartitionDefinitions(newPI); err != nil {
+		return errors.Trace(err)
+	}
+	if err = d.allocPartitionIDs(partDefs); err != nil {
+		return errors.Trace(err)
+	}
+
+	job := &model.Job{
+		SchemaID: schema.ID,
+		TableID:  t.Meta().ID,
+		Type:     model.ActionAddTablePartition,
+		Args:     []interface{}{partDefs},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.hook.OnChanged(err)
+	return errors.Trace(err)
+}
+
+// DropTablePartition drops a RANGE partition of the table, the rows of the partition are deleted in the background.
+func (d *ddl) DropTablePartition(ctx context.Context, ti ast.Ident, partName model.CIStr) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return errors.Trace(infoschema.ErrDatabaseNotExists)
+	}
+
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists)
+	}
+	pi := t.Meta().Partition
+	if pi == nil {
+		return errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	if pi.Type != model.PartitionTypeRange {
+		return ErrOnlyOnRangeListPartition.Gen("DROP PARTITION can only be used on RANGE/LIST partitions")
+	}
+	if pi.FindPartition(partName.L) < 0 {
+		return ErrDropPartitionNonExistent.Gen("Error in list of partitions to DROP")
+	}
+	if len(pi.Definitions) == 1 {
+		return errors.Trace(ErrDropLastPartition)
+	}
+
+	job := &model.Job{
+		SchemaID: schema.ID,
+		TableID:  t.Meta().ID,
+		Type:     model.ActionDropTablePartition,
+		Args:     []interface{}{partName.L},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.hook.OnChanged(err)
+	return errors.Trace(err)
+}
+
+// TruncateTablePartition deletes all the rows of a partition of the table. The partition gets a new ID, and the rows
+// of the old ID are deleted in the background.
+func (d *ddl) TruncateTablePartition(ctx context.Context, ti ast.Ident, partName model.CIStr) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return errors.Trace(infoschema.ErrDatabaseNotExists)
+	}
+
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists)
+	}
+	pi := t.Meta().Partition
+	if pi == nil {
+		return errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	if pi.FindPartition(partName.L) < 0 {
+		return ErrDropPartitionNonExistent.Gen("Error in list of partitions to TRUNCATE")
+	}
+	partitionID, err := d.genGlobalID()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	job := &model.Job{
+		SchemaID: schema.ID,
+		TableID:  t.Meta().ID,
+		Type:     model.ActionTruncateTablePartition,
+		Args:     []interface{}{partName.L, partitionID},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.hook.OnChanged(err)
+	return errors.Trace(err)
+}
+
 // findCol finds column in cols by name.
 func findCol(cols []*model.ColumnInfo, name string) *model.ColumnInfo {
 	name = strings.ToLower(name)
@@ -1195,6 +1580,7 @@ const (
 	codeRunMultiSchemaChanges                = 6
 	codeWaitReorgTimeout                     = 7
 	codeInvalidStoreVer                      = 8
+	codeCancelledDDLJob                      = 9
 
 	codeInvalidDBState         = 100
 	codeInvalidTableState      = 101
@@ -1202,13 +1588,31 @@ const (
 	codeInvalidIndexState      = 103
 	codeInvalidForeignKeyState = 104
 
-	codeCantDropColWithIndex = 201
-	codeUnsupportedAddColumn = 202
+	codeCantDropColWithIndex    = 201
+	codeUnsupportedAddColumn    = 202
+	codeUnsupportedModifyColumn = 203
+	codeCantConvertColumn       = 204
+	codeUnsupportedPartition    = 205
 
 	codeBadNull             = 1048
 	codeCantRemoveAllFields = 1090
 	codeCantDropFieldOrKey  = 1091
 	codeInvalidOnUpdate     = 1294
+
+	codePartitionMaxvalue                   = 1481
+	codeRangeNotIncreasing                  = 1493
+	codeTooManyPartitions                   = 1499
+	codeUniqueKeyNeedAllFieldsInPf          = 1503
+	codeNoParts                             = 1504
+	codePartitionMgmtOnNonpartitioned       = 1505
+	codeForeignKeyOnPartitioned             = 1506
+	codeDropPartitionNonExistent            = 1507
+	codeDropLastPartition                   = 1508
+	codeOnlyOnRangeListPartition            = 1512
+	codeSameNamePartition                   = 1517
+	codePartitionFunctionIsNotAllowed       = 1564
+	codeFieldTypeNotAllowedAsPartitionField = 1659
+	codeValuesIsNotIntType                  = 1697
 )
 
 func init() {
@@ -1217,6 +1621,21 @@ func init() {
 		codeCantRemoveAllFields: mysql.ErrCantRemoveAllFields,
 		codeCantDropFieldOrKey:  mysql.ErrCantDropFieldOrKey,
 		codeInvalidOnUpdate:     mysql.ErrInvalidOnUpdate,
+
+		codePartitionMaxvalue:                   mysql.ErrPartitionMaxvalue,
+		codeRangeNotIncreasing:                  mysql.ErrRangeNotIncreasing,
+		codeTooManyPartitions:                   mysql.ErrTooManyPartitions,
+		codeUniqueKeyNeedAllFieldsInPf:          mysql.ErrUniqueKeyNeedAllFieldsInPf,
+		codeNoParts:                             mysql.ErrNoParts,
+		codePartitionMgmtOnNonpartitioned:       mysql.ErrPartitionMgmtOnNonpartitioned,
+		codeForeignKeyOnPartitioned:             mysql.ErrForeignKeyOnPartitioned,
+		codeDropPartitionNonExistent:            mysql.ErrDropPartitionNonExistent,
+		codeDropLastPartition:                   mysql.ErrDropLastPartition,
+		codeOnlyOnRangeListPartition:            mysql.ErrOnlyOnRangeListPartition,
+		codeSameNamePartition:                   mysql.ErrSameNamePartition,
+		codePartitionFunctionIsNotAllowed:       mysql.ErrPartitionFunctionIsNotAllowed,
+		codeFieldTypeNotAllowedAsPartitionField: mysql.ErrFieldTypeNotAllowedAsPartitionField,
+		codeValuesIsNotIntType:                  mysql.ErrValuesIsNotIntType,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassDDL] = ddlMySQLERrCodes
 }
@@ -25,10 +25,14 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
 	_ "github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/terror"
@@ -474,6 +478,369 @@ LOOP:
 	c.Assert(i, LessEqual, num+step)
 }
 
+func (s *testDBSuite) TestModifyColumn(c *C) {
+	defer testleak.AfterTest(c)()
+	s.mustExec(c, "create table t3 (c1 int primary key, c2 int, c3 varchar(10))")
+	s.testConvertColumn(c)
+	s.testChangeColumn(c)
+	s.testAlterColumn(c)
+}
+
+func (s *testDBSuite) testConvertColumn(c *C) {
+	done := make(chan struct{}, 1)
+
+	num := 100
+	// expected values of c2 by c1
+	values := make(map[int]int)
+	for i := 0; i < num; i++ {
+		s.mustExec(c, "insert into t3 values (?, ?, ?)", i, i, i)
+		values[i] = i
+	}
+
+	ctx := s.s.(context.Context)
+	t := s.testGetTable(c, "t3")
+	col := t.Cols()[1]
+
+	go func() {
+		s.mustExec(c, "alter table t3 modify c2 varchar(20)")
+		done <- struct{}{}
+	}()
+
+	ticker := time.NewTicker(s.lease / 2)
+	defer ticker.Stop()
+	step := 10
+LOOP:
+	for {
+		select {
+		case <-done:
+			break LOOP
+		case <-ticker.C:
+			// delete, update and add some rows
+			for i := num; i < num+step; i++ {
+				n := rand.Intn(num)
+				s.mustExec(c, "delete from t3 where c1 = ?", n)
+				delete(values, n)
+				n = rand.Intn(num)
+				if _, ok := values[n]; ok {
+					s.mustExec(c, "update t3 set c2 = ? where c1 = ?", n+1000, n)
+					values[n] = n + 1000
+				}
+				s.mustExec(c, "insert into t3 values (?, ?, ?)", i, i, i)
+				values[i] = i
+			}
+			num += step
+		}
+	}
+
+	rows := s.mustQuery(c, "show columns from t3 where field = 'c2'")
+	matchRows(c, rows, [][]interface{}{{"c2", "varchar(20)", "YES", "", nil, ""}})
+
+	t = s.testGetTable(c, "t3")
+	c.Assert(t.Cols()[1].ID, Not(Equals), col.ID)
+	txn, err := ctx.GetTxn(true)
+	c.Assert(err, IsNil)
+	defer ctx.CommitTxn()
+
+	i := 0
+	err = t.IterRecords(ctx, t.FirstKey(), t.Cols(), func(h int64, data []types.Datum, cols []*table.Column) (bool, error) {
+		i++
+		// c2 must be converted to string, and the old column must be dropped.
+		c.Assert(data[1].Kind(), Equals, types.KindBytes)
+		c.Assert(data[1].GetString(), Equals, fmt.Sprintf("%d", values[int(h)]))
+		_, err1 := txn.Get(t.RecordKey(h, col))
+		c.Assert(terror.ErrorEqual(err1, kv.ErrNotExist), IsTrue)
+		return true, nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(i, Equals, len(values))
+
+	// The values which can't be converted cancel the change.
+	s.mustExec(c, "insert into t3 values (?, ?, ?)", -1, "abc", "abc")
+	_, err = s.db.Exec("alter table t3 modify c2 int")
+	c.Assert(err, NotNil)
+	s.mustExec(c, "insert into t3 values (?, ?, ?)", -2, nil, nil)
+	_, err = s.db.Exec("alter table t3 modify c2 varchar(20) not null")
+	c.Assert(err, NotNil)
+	// The strings aren't truncated.
+	_, err = s.db.Exec("alter table t3 modify c2 varchar(2)")
+	c.Assert(err, NotNil)
+	rows = s.mustQuery(c, "show columns from t3 where field = 'c2'")
+	matchRows(c, rows, [][]interface{}{{"c2", "varchar(20)", "YES", "", nil, ""}})
+	rows = s.mustQuery(c, "select c2 from t3 where c1 = -1")
+	matchRows(c, rows, [][]interface{}{{[]byte("abc")}})
+	s.mustExec(c, "delete from t3 where c1 < 0")
+}
+
+func (s *testDBSuite) testChangeColumn(c *C) {
+	// The integer can be widened and the column can be moved without converting the values.
+	t := s.testGetTable(c, "t3")
+	s.mustExec(c, "alter table t3 modify c1 bigint first")
+	s.mustExec(c, "alter table t3 change c3 c4 varchar(30) default 'x' after c1")
+	newTable := s.testGetTable(c, "t3")
+	c.Assert(newTable.Cols()[0].ID, Equals, t.Cols()[0].ID)
+	c.Assert(newTable.Cols()[1].ID, Equals, t.Cols()[2].ID)
+	rows := s.mustQuery(c, "show columns from t3")
+	matchRows(c, rows, [][]interface{}{
+		{"c1", "bigint(21)", "NO", "PRI", nil, ""},
+		{"c4", "varchar(30)", "YES", "", "x", ""},
+		{"c2", "varchar(20)", "YES", "", nil, ""},
+	})
+	s.mustExec(c, "insert into t3 (c1, c2) values (-1, 'a')")
+	rows = s.mustQuery(c, "select * from t3 where c1 = -1")
+	matchRows(c, rows, [][]interface{}{{-1, []byte("x"), []byte("a")}})
+
+	// The indexed column is renamed in the index, but its values can't be converted.
+	s.mustExec(c, "create index c4_index on t3 (c4)")
+	s.mustExec(c, "alter table t3 change c4 c3 varchar(40)")
+	rows = s.mustQuery(c, "select c1 from t3 use index (c4_index) where c3 = 'x'")
+	matchRows(c, rows, [][]interface{}{{-1}})
+	_, err := s.db.Exec("alter table t3 modify c3 int")
+	c.Assert(err, NotNil)
+	_, err = s.db.Exec("alter table t3 modify c3 varchar(40) unique")
+	c.Assert(err, NotNil)
+	_, err = s.db.Exec("alter table t3 change c3 c2 varchar(40)")
+	c.Assert(err, NotNil)
+	_, err = s.db.Exec("alter table t3 modify c5 int")
+	c.Assert(err, NotNil)
+}
+
+func (s *testDBSuite) testAlterColumn(c *C) {
+	s.mustExec(c, "alter table t3 alter c2 set default 'y'")
+	s.mustExec(c, "insert into t3 (c1) values (-2)")
+	rows := s.mustQuery(c, "select c2 from t3 where c1 = -2")
+	matchRows(c, rows, [][]interface{}{{[]byte("y")}})
+	s.mustExec(c, "alter table t3 alter column c2 drop default")
+	s.mustExec(c, "insert into t3 (c1) values (-3)")
+	rows = s.mustQuery(c, "select c2 from t3 where c1 = -3")
+	matchRows(c, rows, [][]interface{}{{nil}})
+
+	// The NOT NULL column can't be set to NULL by default.
+	_, err := s.db.Exec("alter table t3 alter c1 set default null")
+	c.Assert(err, NotNil)
+	_, err = s.db.Exec("alter table t3 alter c5 set default 1")
+	c.Assert(err, NotNil)
+}
+
+func (s *testDBSuite) TestRenameTable(c *C) {
+	defer testleak.AfterTest(c)()
+	s.mustExec(c, "create table t4 (c1 int primary key auto_increment, c2 int)")
+	s.mustExec(c, "create table t4_new (c1 int primary key auto_increment, c2 int)")
+	s.mustExec(c, "insert into t4 (c2) values (1)")
+	s.mustExec(c, "insert into t4_new (c2) values (2), (2)")
+
+	// The tables are swapped by one statement.
+	s.mustExec(c, "rename table t4 to t4_old, t4_new to t4")
+	rows := s.mustQuery(c, "select c2 from t4")
+	matchRows(c, rows, [][]interface{}{{2}, {2}})
+	rows = s.mustQuery(c, "select c2 from t4_old")
+	matchRows(c, rows, [][]interface{}{{1}})
+	_, err := s.db.Exec("select * from t4_new")
+	c.Assert(err, NotNil)
+
+	// The table is moved to the other database with its rows and auto increment ID.
+	s.mustExec(c, "create database test_rename")
+	s.mustExec(c, "alter table t4_old rename to test_rename.t4")
+	s.mustExec(c, "insert into test_rename.t4 (c2) values (3)")
+	rows = s.mustQuery(c, "select c2 from test_rename.t4 where c1 > 1")
+	matchRows(c, rows, [][]interface{}{{3}})
+	rows = s.mustQuery(c, "select count(*) from test_rename.t4")
+	matchRows(c, rows, [][]interface{}{{2}})
+
+	// None of the tables is renamed if any of them can't be renamed.
+	_, err = s.db.Exec("rename table t4 to t5, t2 to t5")
+	c.Assert(err, NotNil)
+	rows = s.mustQuery(c, "select count(*) from t4")
+	matchRows(c, rows, [][]interface{}{{2}})
+	_, err = s.db.Exec("rename table t4 to t2")
+	c.Assert(err, NotNil)
+	_, err = s.db.Exec("rename table t5 to t6")
+	c.Assert(err, NotNil)
+	_, err = s.db.Exec("rename table t4 to test_not_exist.t4")
+	c.Assert(err, NotNil)
+
+	s.mustExec(c, "drop table t4")
+	s.mustExec(c, "drop database test_rename")
+}
+
+func (s *testDBSuite) TestAddIndexConcurrently(c *C) {
+	defer testleak.AfterTest(c)()
+	// the handles of the rows are sparse.
+	s.mustExec(c, "create table t6 (c1 int primary key, c2 int)")
+	for i := 0; i < 50; i++ {
+		s.mustExec(c, "insert into t6 values (?, ?)", i*1000-20000, i)
+	}
+
+	s.mustExec(c, "set @@global.tidb_ddl_reorg_worker_cnt = 3")
+	s.mustExec(c, "set @@global.tidb_ddl_reorg_batch_size = 4")
+	s.mustExec(c, "set @@global.tidb_ddl_reorg_rate_limit = 1000")
+	s.mustExec(c, "create index c2_index on t6 (c2)")
+	s.mustExec(c, "set @@global.tidb_ddl_reorg_worker_cnt = 4")
+	s.mustExec(c, "set @@global.tidb_ddl_reorg_batch_size = 128")
+	s.mustExec(c, "set @@global.tidb_ddl_reorg_rate_limit = 0")
+	// the values of the options must be integers.
+	_, err := s.db.Exec("set @@global.tidb_ddl_reorg_worker_cnt = 'abc'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongTypeForVar), IsTrue, Commentf("err %v", err))
+	_, err = s.db.Exec("set @@session.tidb_ddl_reorg_batch_size = 'x'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongTypeForVar), IsTrue, Commentf("err %v", err))
+
+	s.mustExec(c, "admin check table t6")
+	rows := s.mustQuery(c, "select c1 from t6 where c2 = 10")
+	matchRows(c, rows, [][]interface{}{{-10000}})
+
+	// the options of the reorganization are saved in the job, and its chunks are removed.
+	ctx := s.s.(context.Context)
+	err = kv.RunInNewTxn(sessionctx.GetDomain(ctx).Store(), false, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		jobs, err1 := t.GetAllHistoryDDLJobs()
+		c.Assert(err1, IsNil)
+		job := jobs[len(jobs)-1]
+		c.Assert(job.Type, Equals, model.ActionAddIndex)
+		var (
+			unique      bool
+			indexName   model.CIStr
+			indexID     int64
+			idxColNames []*ast.IndexColName
+			opts        map[string]int64
+		)
+		err1 = job.DecodeArgs(&unique, &indexName, &indexID, &idxColNames, &opts)
+		c.Assert(err1, IsNil)
+		c.Assert(opts, DeepEquals, map[string]int64{"worker_cnt": 3, "batch_size": 4, "rate_limit": 1000})
+		chunks, err1 := t.GetDDLReorgChunks(job)
+		c.Assert(err1, IsNil)
+		c.Assert(chunks, HasLen, 0)
+		return nil
+	})
+	c.Assert(err, IsNil)
+
+	s.mustExec(c, "drop table t6")
+}
+
+func (s *testDBSuite) TestPartition(c *C) {
+	defer testleak.AfterTest(c)()
+	s.mustExec(c, `create table t7 (c1 int primary key, c2 int, unique key(c1, c2)) partition by range (c1) (
+		partition p0 values less than (10),
+		partition p1 values less than (20),
+		partition p2 values less than (30))`)
+	s.mustExec(c, "insert into t7 values (1, 1), (11, 11), (21, 21), (22, 22)")
+	_, err := s.db.Exec("insert into t7 values (30, 30)")
+	c.Assert(err, NotNil)
+	rows := s.mustQuery(c, "select c2 from t7 where c1 > 5")
+	matchRows(c, rows, [][]interface{}{{11}, {21}, {22}})
+
+	// The rows of the dropped partition are deleted in the background.
+	pt := s.testGetTable(c, "t7").(table.PartitionedTable)
+	p0 := pt.Partitions()[0]
+	c.Assert(s.prefixExists(c, p0.RecordPrefix()), IsTrue)
+	s.mustExec(c, "alter table t7 drop partition p0")
+	rows = s.mustQuery(c, "select c2 from t7")
+	matchRows(c, rows, [][]interface{}{{11}, {21}, {22}})
+	s.checkPrefixDeleted(c, p0.RecordPrefix())
+	// The rows of the dropped partition are in the next partition now.
+	s.mustExec(c, "insert into t7 values (2, 2)")
+	rows = s.mustQuery(c, "select c2 from t7 where c1 < 20")
+	matchRows(c, rows, [][]interface{}{{2}, {11}})
+
+	s.mustExec(c, "alter table t7 add partition (partition p3 values less than (40), partition p4 values less than maxvalue)")
+	s.mustExec(c, "insert into t7 values (30, 30), (100, 100)")
+	rows = s.mustQuery(c, "select count(*) from t7")
+	matchRows(c, rows, [][]interface{}{{6}})
+
+	pt = s.testGetTable(c, "t7").(table.PartitionedTable)
+	p2 := pt.Partitions()[1]
+	c.Assert(s.prefixExists(c, p2.RecordPrefix()), IsTrue)
+	s.mustExec(c, "alter table t7 truncate partition p2")
+	rows = s.mustQuery(c, "select c2 from t7")
+	matchRows(c, rows, [][]interface{}{{2}, {11}, {30}, {100}})
+	s.checkPrefixDeleted(c, p2.RecordPrefix())
+	s.mustExec(c, "insert into t7 values (21, 21)")
+	s.mustExec(c, "admin check table t7")
+
+	_, err = s.db.Exec("alter table t7 add partition (partition p5 values less than (200))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrPartitionMaxvalue), IsTrue, Commentf("err %v", err))
+	_, err = s.db.Exec("alter table t7 drop partition p0")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrDropPartitionNonExistent), IsTrue, Commentf("err %v", err))
+	_, err = s.db.Exec("alter table t7 add column c3 int")
+	c.Assert(err, NotNil)
+	_, err = s.db.Exec("alter table t2 drop partition p0")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrPartitionMgmtOnNonpartitioned), IsTrue, Commentf("err %v", err))
+
+	s.mustExec(c, "create table t8 (c1 int, c2 int) partition by hash (c1) partitions 4")
+	s.mustExec(c, "insert into t8 values (1, 1), (-2, 2), (3, 3), (4, 4), (null, 5)")
+	rows = s.mustQuery(c, "select c2 from t8 where c1 = -2")
+	matchRows(c, rows, [][]interface{}{{2}})
+	s.mustExec(c, "alter table t8 truncate partition p1")
+	rows = s.mustQuery(c, "select count(*) from t8")
+	matchRows(c, rows, [][]interface{}{{4}})
+	_, err = s.db.Exec("alter table t8 drop partition p0")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrOnlyOnRangeListPartition), IsTrue, Commentf("err %v", err))
+
+	// Invalid partitions.
+	_, err = s.db.Exec("create table t9 (c1 int, c2 int) partition by range (c1) (partition p0 values less than (10), partition p1 values less than (5))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrRangeNotIncreasing), IsTrue, Commentf("err %v", err))
+	_, err = s.db.Exec("create table t9 (c1 int, c2 int) partition by range (c1) (partition p0 values less than maxvalue, partition p1 values less than (5))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrPartitionMaxvalue), IsTrue, Commentf("err %v", err))
+	_, err = s.db.Exec("create table t9 (c1 int, c2 int) partition by range (c1) (partition p0 values less than (5), partition P0 values less than (10))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrSameNamePartition), IsTrue, Commentf("err %v", err))
+	_, err = s.db.Exec("create table t9 (c1 int, c2 int, primary key(c2)) partition by hash (c1) partitions 2")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrUniqueKeyNeedAllFieldsInPf), IsTrue, Commentf("err %v", err))
+	_, err = s.db.Exec("create table t9 (c1 int, c2 int, unique key(c2)) partition by hash (c1) partitions 2")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrUniqueKeyNeedAllFieldsInPf), IsTrue, Commentf("err %v", err))
+	_, err = s.db.Exec("create table t9 (c1 varchar(10)) partition by hash (c1) partitions 2")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrFieldTypeNotAllowedAsPartitionField), IsTrue, Commentf("err %v", err))
+	_, err = s.db.Exec("create table t9 (c1 int) partition by hash (c1 + 1) partitions 2")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrPartitionFunctionIsNotAllowed), IsTrue, Commentf("err %v", err))
+	_, err = s.db.Exec("create table t9 (c1 int) partition by hash (c1) partitions 0")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrNoParts), IsTrue, Commentf("err %v", err))
+	_, err = s.db.Exec("create table t9 (c1 int) partition by range (c1) (partition p0 values less than ('a'))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrValuesIsNotIntType), IsTrue, Commentf("err %v", err))
+	_, err = s.db.Exec("create table t9 (c1 int) partition by range (c1) (partition p0 values less than (c1))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrPartitionFunctionIsNotAllowed), IsTrue, Commentf("err %v", err))
+	_, err = s.db.Exec("create table t9 (c1 datetime) partition by range (c1) (partition p0 values less than (10))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrFieldTypeNotAllowedAsPartitionField), IsTrue, Commentf("err %v", err))
+	_, err = s.db.Exec("create table t9 (c1 int) partition by range (to_days(c1)) (partition p0 values less than (10))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrFieldTypeNotAllowedAsPartitionField), IsTrue, Commentf("err %v", err))
+	_, err = s.db.Exec("create table t9 (c1 date) partition by range (month(c1)) (partition p0 values less than (10))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrPartitionFunctionIsNotAllowed), IsTrue, Commentf("err %v", err))
+	// The schema changes of the columns and the indices aren't supported on the partitioned tables.
+	s.mustExec(c, "create table t9 (c1 date, c2 int) partition by range (to_days(c1)) (partition p0 values less than (to_days('2017-01-01')))")
+	s.mustExec(c, "alter table t9 add partition (partition p1 values less than (to_days('2018-01-01')))")
+	_, err = s.db.Exec("alter table t9 add index idx_c2 (c2)")
+	c.Assert(err, NotNil)
+	_, err = s.db.Exec("alter table t9 add column c3 int")
+	c.Assert(err, NotNil)
+	s.mustExec(c, "drop table t9")
+
+	s.mustExec(c, "drop table t7")
+	s.mustExec(c, "drop table t8")
+}
+
+// checkPrefixDeleted waits for the keys with the prefix to be deleted by the background job.
+func (s *testDBSuite) checkPrefixDeleted(c *C, prefix kv.Key) {
+	for i := 0; i < 50; i++ {
+		if !s.prefixExists(c, prefix) {
+			return
+		}
+		time.Sleep(s.lease / 5)
+	}
+	c.Fatalf("the keys with prefix %q are not deleted", prefix)
+}
+
+func (s *testDBSuite) prefixExists(c *C, prefix kv.Key) bool {
+	var exists bool
+	ctx := s.s.(context.Context)
+	err := kv.RunInNewTxn(sessionctx.GetDomain(ctx).Store(), false, func(txn kv.Transaction) error {
+		it, err1 := txn.Seek(prefix)
+		if err1 != nil {
+			return err1
+		}
+		defer it.Close()
+		exists = it.Valid() && it.Key().HasPrefix(prefix)
+		return nil
+	})
+	c.Assert(err, IsNil)
+	return exists
+}
+
 func (s *testDBSuite) mustExec(c *C, query string, args ...interface{}) sql.Result {
 	r, err := s.db.Exec(query, args...)
 	c.Assert(err, IsNil, Commentf("query %s, args %v", query, args))
@@ -25,14 +25,22 @@ import (
 	"github.com/pingcap/tidb/terror"
 )
 
-func (d *ddl) doDDLJob(ctx context.Context, job *model.Job) error {
+func (d *ddl) doDDLJob(ctx context.Context, job *model.Job) (err error) {
 	// for every DDL, we must commit current transaction.
-	if err := ctx.CommitTxn(); err != nil {
+	if err = ctx.CommitTxn(); err != nil {
 		return errors.Trace(err)
 	}
 
+	defer func(start time.Time) {
+		result := "ok"
+		if err != nil {
+			result = "error"
+		}
+		jobHistogram.WithLabelValues(job.Type.String(), result).Observe(time.Since(start).Seconds())
+	}(time.Now())
+
 	// Create a new job and queue it.
-	err := kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
+	err = kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
 		t := meta.NewMeta(txn)
 		var err error
 		job.ID, err = t.GenGlobalID()
@@ -175,7 +183,8 @@ func (d *ddl) finishDDLJob(t *meta.Meta, job *model.Job) error {
 		return errors.Trace(err)
 	}
 	switch job.Type {
-	case model.ActionDropSchema, model.ActionDropTable:
+	case model.ActionDropSchema, model.ActionDropTable, model.ActionDropTablePartition,
+		model.ActionTruncateTablePartition:
 		if err = d.prepareBgJob(job); err != nil {
 			return errors.Trace(err)
 		}
@@ -280,7 +289,7 @@ func (d *ddl) handleDDLJobQueue() error {
 		// here means the job enters another state (delete only, write only, public, etc...) or is cancelled.
 		// if the job is done or still running, we will wait 2 * lease time to guarantee other servers to update
 		// the newest schema.
-		if job.State == model.JobRunning || job.State == model.JobDone {
+		if job.IsRunning() || job.State == model.JobDone {
 			d.waitSchemaChanged(waitTime)
 		}
 
@@ -332,8 +341,29 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) {
 		return
 	}
 
-	job.State = model.JobRunning
+	var err error
+	if job.State == model.JobCancelling {
+		// the job is cancelled by ADMIN CANCEL DDL JOBS, undo what it has done.
+		err = d.rollbackDDLJob(t, job)
+	} else {
+		job.State = model.JobRunning
+		err = d.runDDLJobStep(t, job)
+	}
+
+	// saves error in job, so that others can know error happens.
+	if err != nil {
+		// if job is not cancelled, we should log this error.
+		if job.State != model.JobCancelled {
+			log.Errorf("run ddl job err %v", errors.ErrorStack(err))
+		}
 
+		job.Error = err.Error()
+		job.ErrorCount++
+	}
+}
+
+// runDDLJobStep runs the job to its next state.
+func (d *ddl) runDDLJobStep(t *meta.Meta, job *model.Job) error {
 	var err error
 	switch job.Type {
 	case model.ActionCreateSchema:
@@ -356,22 +386,39 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) {
 		err = d.onCreateForeignKey(t, job)
 	case model.ActionDropForeignKey:
 		err = d.onDropForeignKey(t, job)
+	case model.ActionModifyColumn:
+		err = d.onModifyColumn(t, job)
+	case model.ActionSetDefaultValue:
+		err = d.onSetDefaultValue(t, job)
+	case model.ActionRenameTable:
+		err = d.onRenameTable(t, job)
+	case model.ActionAddTablePartition:
+		err = d.onAddTablePartition(t, job)
+	case model.ActionDropTablePartition:
+		err = d.onDropTablePartition(t, job)
+	case model.ActionTruncateTablePartition:
+		err = d.onTruncateTablePartition(t, job)
 	default:
 		// invalid job, cancel it.
 		job.State = model.JobCancelled
 		err = errInvalidDDLJob.Gen("invalid ddl job %v", job)
 	}
+	return errors.Trace(err)
+}
 
-	// saves error in job, so that others can know error happens.
-	if err != nil {
-		// if job is not cancelled, we should log this error.
-		if job.State != model.JobCancelled {
-			log.Errorf("run ddl job err %v", errors.ErrorStack(err))
-		}
-
-		job.Error = err.Error()
-		job.ErrorCount++
+// rollbackDDLJob rolls back the cancelling job step by step like running it, the job is cancelled after the
+// rollback is done. The add index jobs and the modify column jobs whose values can't be converted are rolled back,
+// the other jobs are cancelled directly because they can be cancelled only before they change the schema.
+func (d *ddl) rollbackDDLJob(t *meta.Meta, job *model.Job) error {
+	switch job.Type {
+	case model.ActionAddIndex:
+		return d.rollbackCreateIndex(t, job)
+	case model.ActionModifyColumn:
+		return d.rollbackModifyColumn(t, job)
 	}
+
+	job.State = model.JobCancelled
+	return errCancelledDDLJob.Gen("cancelled DDL job %d", job.ID)
 }
 
 // for every lease seconds, we will re-update the whole schema, so we will wait 2 * lease time
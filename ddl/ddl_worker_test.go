@@ -65,6 +65,12 @@ func testCheckOwner(c *C, d *ddl, isOwner bool, flag JobType) {
 	c.Assert(terror.ErrorEqual(err, errNotOwner), IsTrue)
 }
 
+func testIsOwner(c *C, d *ddl, isOwner bool) {
+	ok, err := d.IsOwner()
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, isOwner)
+}
+
 func (s *testDDLSuite) TestCheckOwner(c *C) {
 	defer testleak.AfterTest(c)()
 	store := testCreateStore(c, "test_owner")
@@ -84,12 +90,17 @@ func (s *testDDLSuite) TestCheckOwner(c *C) {
 
 	testCheckOwner(c, d2, false, ddlJobFlag)
 	testCheckOwner(c, d2, false, bgJobFlag)
+	testIsOwner(c, d1, true)
+	testIsOwner(c, d2, false)
 	d1.close()
 
 	time.Sleep(6 * lease)
 
+	// The owner is timed out.
+	testIsOwner(c, d1, false)
 	testCheckOwner(c, d2, true, ddlJobFlag)
 	testCheckOwner(c, d2, true, bgJobFlag)
+	testIsOwner(c, d2, true)
 
 	d2.SetLease(1 * time.Second)
 
@@ -14,6 +14,8 @@
 package ddl
 
 import (
+	"math"
+
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
@@ -102,9 +104,10 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) error {
 		indexName   model.CIStr
 		indexID     int64
 		idxColNames []*ast.IndexColName
+		opts        reorgOptions
 	)
 
-	err = job.DecodeArgs(&unique, &indexName, &indexID, &idxColNames)
+	err = job.DecodeArgs(&unique, &indexName, &indexID, &idxColNames, &opts)
 	if err != nil {
 		job.State = model.JobCancelled
 		return errors.Trace(err)
@@ -161,9 +164,7 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) error {
 	case model.StateWriteReorganization:
 		// reorganization -> public
 		reorgInfo, err := d.getReorgInfo(t, job)
-		if err != nil || reorgInfo.first {
-			// if we run reorg firstly, we should update the job snapshot version
-			// and then run the reorg next time.
+		if err != nil {
 			return errors.Trace(err)
 		}
 
@@ -173,8 +174,17 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) error {
 			return errors.Trace(err)
 		}
 
+		// the jobs submitted without the options are reorganized with the default options.
+		opts.adjust()
+		if reorgInfo.first {
+			// if we run reorg firstly, we should update the job snapshot version,
+			// split the table into the chunks and then run the reorg next time.
+			err = d.splitReorgChunks(t, tbl, reorgInfo, &opts, math.MinInt64)
+			return errors.Trace(err)
+		}
+
 		err = d.runReorgJob(func() error {
-			return d.addTableIndex(tbl, indexInfo, reorgInfo)
+			return d.addTableIndex(tbl, indexInfo, reorgInfo, &opts)
 		})
 
 		if terror.ErrorEqual(err, errWaitReorgTimeout) {
@@ -201,6 +211,106 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) error {
 	}
 }
 
+// rollbackCreateIndex rolls back the cancelled add index job, the index is dropped like DROP INDEX:
+// write only/reorganization -> delete only -> delete reorganization -> absent.
+func (d *ddl) rollbackCreateIndex(t *meta.Meta, job *model.Job) error {
+	schemaID := job.SchemaID
+	tblInfo, err := d.getTableInfo(t, job)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	var (
+		unique    bool
+		indexName model.CIStr
+	)
+	if err = job.DecodeArgs(&unique, &indexName); err != nil {
+		job.State = model.JobCancelled
+		return errors.Trace(err)
+	}
+
+	var indexInfo *model.IndexInfo
+	for _, idx := range tblInfo.Indices {
+		if idx.Name.L == indexName.L && idx.State != model.StatePublic {
+			indexInfo = idx
+		}
+	}
+
+	if indexInfo == nil {
+		// the index isn't added yet or it's dropped, the job is cancelled.
+		job.State = model.JobCancelled
+		return errCancelledDDLJob.Gen("cancelled DDL job %d", job.ID)
+	}
+
+	_, err = t.GenSchemaVersion()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	switch indexInfo.State {
+	case model.StateWriteOnly, model.StateWriteReorganization:
+		if d.reorgDoneCh != nil {
+			// wait for the running reorganization, it stops as the job is cancelled.
+			err = d.runReorgJob(nil)
+			if terror.ErrorEqual(err, errWaitReorgTimeout) {
+				return nil
+			}
+			// the reorganization is stopped or done, the error is ignored because the index is dropped.
+			log.Warnf("[ddl] the reorganization of the cancelled job %d stops, err %v", job.ID, err)
+		}
+
+		// write only/reorganization -> delete only
+		job.SchemaState = model.StateDeleteOnly
+		indexInfo.State = model.StateDeleteOnly
+		err = t.UpdateTable(schemaID, tblInfo)
+		return errors.Trace(err)
+	case model.StateDeleteOnly:
+		// delete only -> reorganization
+		job.SchemaState = model.StateDeleteReorganization
+		indexInfo.State = model.StateDeleteReorganization
+		err = t.UpdateTable(schemaID, tblInfo)
+		return errors.Trace(err)
+	case model.StateDeleteReorganization:
+		// reorganization -> absent
+		tbl, err := d.getTable(schemaID, tblInfo)
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		err = d.runReorgJob(func() error {
+			return d.dropTableIndex(tbl, indexInfo)
+		})
+
+		if terror.ErrorEqual(err, errWaitReorgTimeout) {
+			// if timeout, we should return, check for the owner and re-wait job done.
+			return nil
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		newIndices := make([]*model.IndexInfo, 0, len(tblInfo.Indices))
+		for _, idx := range tblInfo.Indices {
+			if idx != indexInfo {
+				newIndices = append(newIndices, idx)
+			}
+		}
+		tblInfo.Indices = newIndices
+		if err = t.UpdateTable(schemaID, tblInfo); err != nil {
+			return errors.Trace(err)
+		}
+		if err = t.RemoveDDLReorgChunks(job); err != nil {
+			return errors.Trace(err)
+		}
+
+		// the job is cancelled when it runs next time, after the other servers drop the index.
+		job.SchemaState = model.StateNone
+		return nil
+	default:
+		return ErrInvalidIndexState.Gen("invalid index state %v", indexInfo.State)
+	}
+}
+
 func (d *ddl) onDropIndex(t *meta.Meta, job *model.Job) error {
 	schemaID := job.SchemaID
 	tblInfo, err := d.getTableInfo(t, job)
@@ -333,32 +443,27 @@ func fetchRowColVals(txn kv.Transaction, t table.Table, handle int64, indexInfo
 const maxBatchSize = 1024
 
 // How to add index in reorganization state?
-//  1. Generate a snapshot with special version.
-//  2. Traverse the snapshot, get every row in the table.
+//  1. Generate a snapshot with special version, and split the handles of the snapshot into the chunks.
+//  2. The workers traverse the chunks concurrently, get the rows of a chunk in batches.
 //  3. For one row, if the row has been already deleted, skip to next row.
 //  4. If not deleted, check whether index has existed, if existed, skip to next row.
 //  5. If index doesn't exist, create the index and then continue to handle next row.
-func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo *reorgInfo) error {
-	seekHandle := reorgInfo.Handle
-	version := reorgInfo.SnapshotVer
-	for {
-		handles, err := d.getSnapshotRows(t, version, seekHandle)
-		if err != nil {
-			return errors.Trace(err)
-		} else if len(handles) == 0 {
-			return nil
-		}
-
-		seekHandle = handles[len(handles)-1] + 1
-
-		err = d.backfillTableIndex(t, indexInfo, handles, reorgInfo)
-		if err != nil {
-			return errors.Trace(err)
-		}
-	}
+//  6. The index entries of a batch are created in a transaction, which saves the checkpoint of the chunk too.
+func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo *reorgInfo, opts *reorgOptions) error {
+	kvX := tables.NewIndex(t.IndexPrefix(), indexInfo.Name.L, indexInfo.ID, indexInfo.Unique)
+	err := d.reorgChunks(t, reorgInfo, opts, func(txn kv.Transaction, handles []int64) error {
+		return errors.Trace(backfillTableIndex(txn, t, indexInfo, kvX, handles))
+	})
+	return errors.Trace(err)
 }
 
 func (d *ddl) getSnapshotRows(t table.Table, version uint64, seekHandle int64) ([]int64, error) {
+	handles, err := d.getSnapshotRowsInRange(t, version, seekHandle, math.MaxInt64, maxBatchSize)
+	return handles, errors.Trace(err)
+}
+
+// getSnapshotRowsInRange gets at most limit handles of the rows in the range [seekHandle, endHandle] of the snapshot.
+func (d *ddl) getSnapshotRowsInRange(t table.Table, version uint64, seekHandle, endHandle int64, limit int) ([]int64, error) {
 	ver := kv.Version{Ver: version}
 
 	snap, err := d.store.GetSnapshot(ver)
@@ -376,7 +481,7 @@ func (d *ddl) getSnapshotRows(t table.Table, version uint64, seekHandle int64) (
 	}
 	defer it.Close()
 
-	handles := make([]int64, 0, maxBatchSize)
+	handles := make([]int64, 0, limit)
 
 	for it.Valid() {
 		if !it.Key().HasPrefix(t.RecordPrefix()) {
@@ -388,11 +493,14 @@ func (d *ddl) getSnapshotRows(t table.Table, version uint64, seekHandle int64) (
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		if handle > endHandle {
+			break
+		}
 
 		rk := t.RecordKey(handle, nil)
 
 		handles = append(handles, handle)
-		if len(handles) == maxBatchSize {
+		if len(handles) == limit {
 			break
 		}
 
@@ -415,55 +523,41 @@ func lockRow(txn kv.Transaction, t table.Table, h int64) error {
 	return errors.Trace(err)
 }
 
-func (d *ddl) backfillTableIndex(t table.Table, indexInfo *model.IndexInfo, handles []int64, reorgInfo *reorgInfo) error {
-	kvX := tables.NewIndex(t.IndexPrefix(), indexInfo.Name.L, indexInfo.ID, indexInfo.Unique)
-
+// backfillTableIndex creates the index entries of a batch of rows in the transaction.
+func backfillTableIndex(txn kv.Transaction, t table.Table, indexInfo *model.IndexInfo, kvX table.Index, handles []int64) error {
 	for _, handle := range handles {
 		log.Debug("[ddl] building index...", handle)
 
-		err := kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
-			if err := d.isReorgRunnable(txn); err != nil {
-				return errors.Trace(err)
-			}
-
-			// first check row exists
-			exist, err := checkRowExist(txn, t, handle)
-			if err != nil {
-				return errors.Trace(err)
-			} else if !exist {
-				// row doesn't exist, skip it.
-				return nil
-			}
-
-			var vals []types.Datum
-			vals, err = fetchRowColVals(txn, t, handle, indexInfo)
-			if err != nil {
-				return errors.Trace(err)
-			}
-
-			exist, _, err = kvX.Exist(txn, vals, handle)
-			if err != nil {
-				return errors.Trace(err)
-			} else if exist {
-				// index already exists, skip it.
-				return nil
-			}
+		// first check row exists
+		exist, err := checkRowExist(txn, t, handle)
+		if err != nil {
+			return errors.Trace(err)
+		} else if !exist {
+			// row doesn't exist, skip it.
+			continue
+		}
 
-			err = lockRow(txn, t, handle)
-			if err != nil {
-				return errors.Trace(err)
-			}
+		var vals []types.Datum
+		vals, err = fetchRowColVals(txn, t, handle, indexInfo)
+		if err != nil {
+			return errors.Trace(err)
+		}
 
-			// create the index.
-			err = kvX.Create(txn, vals, handle)
-			if err != nil {
-				return errors.Trace(err)
-			}
+		exist, _, err = kvX.Exist(txn, vals, handle)
+		if err != nil {
+			return errors.Trace(err)
+		} else if exist {
+			// index already exists, skip it.
+			continue
+		}
 
-			// update reorg next handle
-			return errors.Trace(reorgInfo.UpdateHandle(txn, handle))
-		})
+		err = lockRow(txn, t, handle)
+		if err != nil {
+			return errors.Trace(err)
+		}
 
+		// create the index.
+		err = kvX.Create(txn, vals, handle)
 		if err != nil {
 			return errors.Trace(err)
 		}
@@ -17,10 +17,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/juju/errors"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/inspectkv"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
@@ -766,3 +769,131 @@ func (s *testIndexSuite) TestAddIndexWithNullColumn(c *C) {
 	d.close()
 	s.d.start()
 }
+
+func (s *testIndexSuite) countIndexKeys(c *C, t table.Table) int {
+	var cnt int
+	err := kv.RunInNewTxn(s.store, false, func(txn kv.Transaction) error {
+		it, err := txn.Seek(t.IndexPrefix())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		defer it.Close()
+		for it.Valid() && it.Key().HasPrefix(t.IndexPrefix()) {
+			cnt++
+			if err = it.Next(); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return nil
+	})
+	c.Assert(err, IsNil)
+	return cnt
+}
+
+func (s *testIndexSuite) TestCancelAddIndex(c *C) {
+	defer testleak.AfterTest(c)()
+	d := newDDL(s.store, nil, nil, 100*time.Millisecond)
+	tblInfo := testTableInfo(c, d, "t", 3)
+	ctx := testNewContext(c, d)
+
+	_, err := ctx.GetTxn(true)
+	c.Assert(err, IsNil)
+
+	testCreateTable(c, ctx, d, s.dbInfo, tblInfo)
+
+	t := testGetTable(c, d, s.dbInfo.ID, tblInfo.ID)
+	for i := 0; i < 20; i++ {
+		_, err = t.AddRecord(ctx, types.MakeDatums(i, i, i))
+		c.Assert(err, IsNil)
+	}
+
+	err = ctx.CommitTxn()
+	c.Assert(err, IsNil)
+
+	var (
+		cancelled     bool
+		states        []model.SchemaState
+		backfilledCnt int
+	)
+	tc := &testDDLCallback{}
+	tc.onJobUpdated = func(job *model.Job) {
+		if job.Type != model.ActionAddIndex {
+			return
+		}
+		if cancelled {
+			// the job may wait for the backfill to stop in the reorganization state.
+			if job.SchemaState != model.StateWriteReorganization &&
+				(len(states) == 0 || states[len(states)-1] != job.SchemaState) {
+				states = append(states, job.SchemaState)
+			}
+			if job.SchemaState == model.StateDeleteOnly && backfilledCnt == 0 {
+				// the backfill is stopped, the index is built partly.
+				backfilledCnt = s.countIndexKeys(c, t)
+			}
+			return
+		}
+
+		// cancel the job when the index is being backfilled.
+		if job.SchemaState != model.StateWriteReorganization || d.reorgDoneCh == nil {
+			return
+		}
+		err1 := kv.RunInNewTxn(s.store, false, func(txn kv.Transaction) error {
+			errs, err2 := inspectkv.CancelJobs(txn, []int64{job.ID})
+			if err2 != nil {
+				return errors.Trace(err2)
+			}
+			return errors.Trace(errs[0])
+		})
+		c.Assert(err1, IsNil)
+		cancelled = true
+	}
+
+	d.hook = tc
+
+	// Use local ddl for callback test.
+	s.d.close()
+
+	d.close()
+	d.start()
+
+	id, err := d.genGlobalID()
+	c.Assert(err, IsNil)
+	// backfill the index slowly so that it's cancelled before it's done.
+	opts := reorgOptions{WorkerCount: 1, BatchSize: 1, RateLimit: 10}
+	job := &model.Job{
+		SchemaID: s.dbInfo.ID,
+		TableID:  tblInfo.ID,
+		Type:     model.ActionAddIndex,
+		Args:     []interface{}{false, model.NewCIStr("c1"), id, []*ast.IndexColName{{Column: &ast.ColumnName{Name: model.NewCIStr("c1")}, Length: 256}}, opts},
+	}
+	err = d.doDDLJob(ctx, job)
+	c.Assert(err, ErrorMatches, ".*cancelled DDL job.*")
+	testCheckJobCancelled(c, d, job)
+
+	c.Assert(states, DeepEquals, []model.SchemaState{model.StateDeleteOnly, model.StateDeleteReorganization, model.StateNone})
+	c.Assert(backfilledCnt, Greater, 0)
+	c.Assert(backfilledCnt, Less, 20)
+
+	// the index and the index data are dropped.
+	t = testGetTable(c, d, s.dbInfo.ID, tblInfo.ID)
+	c.Assert(getIndex(t, "c1"), IsNil)
+	c.Assert(s.countIndexKeys(c, t), Equals, 0)
+	err = kv.RunInNewTxn(s.store, false, func(txn kv.Transaction) error {
+		chunks, err1 := meta.NewMeta(txn).GetDDLReorgChunks(job)
+		c.Assert(chunks, HasLen, 0)
+		return errors.Trace(err1)
+	})
+	c.Assert(err, IsNil)
+
+	_, err = ctx.GetTxn(true)
+	c.Assert(err, IsNil)
+
+	job = testDropTable(c, ctx, d, s.dbInfo, tblInfo)
+	testCheckJobDone(c, d, job, false)
+
+	err = ctx.CommitTxn()
+	c.Assert(err, IsNil)
+
+	d.close()
+	s.d.start()
+}
@@ -0,0 +1,375 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/evaluator"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// maxPartitions is the max number of the partitions of a table, it's the same as MySQL.
+const maxPartitions = 1024
+
+// buildPartitionInfo builds the partitioning of the table by the PARTITION BY clause.
+// The partition expression must be a column of integer type, or YEAR or TO_DAYS of a column of DATE or DATETIME
+// type, other expressions aren't supported now. The primary key and the unique indices must include the column,
+// because the uniqueness is only checked in a partition.
+func (d *ddl) buildPartitionInfo(ctx context.Context, tbInfo *model.TableInfo, opts *ast.PartitionOptions) (*model.PartitionInfo, error) {
+	expr := opts.Expr
+	var fn string
+	if funcExpr, ok := expr.(*ast.FuncCallExpr); ok {
+		fn = funcExpr.FnName.L
+		if (fn != model.PartitionFuncYear && fn != model.PartitionFuncToDays) || len(funcExpr.Args) != 1 {
+			return nil, errors.Trace(ErrPartitionFunctionIsNotAllowed)
+		}
+		expr = funcExpr.Args[0]
+	}
+	colExpr, ok := expr.(*ast.ColumnNameExpr)
+	if !ok {
+		return nil, errors.Trace(ErrPartitionFunctionIsNotAllowed)
+	}
+	col := findCol(tbInfo.Columns, colExpr.Name.Name.L)
+	if col == nil {
+		return nil, infoschema.ErrColumnNotExists.Gen("no such column: %s", colExpr.Name.Name)
+	}
+	var allowed bool
+	switch col.Tp {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
+		allowed = fn == ""
+	case mysql.TypeDate, mysql.TypeDatetime:
+		allowed = fn != ""
+	}
+	if !allowed {
+		return nil, ErrFieldTypeNotAllowedAsPartitionField.Gen("Field '%s' is of a not allowed type for this type of partitioning", col.Name)
+	}
+
+	pi := &model.PartitionInfo{Type: opts.Tp, Column: col.Name, Func: fn}
+	switch opts.Tp {
+	case model.PartitionTypeHash:
+		if opts.Num == 0 {
+			return nil, ErrNoParts.Gen("Number of partitions = 0 is not an allowed value")
+		}
+		if opts.Num > maxPartitions {
+			return nil, errors.Trace(ErrTooManyPartitions)
+		}
+		for i := uint64(0); i < opts.Num; i++ {
+			pi.Definitions = append(pi.Definitions, &model.PartitionDefinition{Name: model.NewCIStr(fmt.Sprintf("p%d", i))})
+		}
+	case model.PartitionTypeRange:
+		defs, err := buildRangePartitionDefinitions(ctx, opts.Definitions)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		pi.Definitions = defs
+	}
+	if err := checkPartitionDefinitions(pi); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := checkPartitionKeys(tbInfo, pi); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := d.allocPartitionIDs(pi.Definitions); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return pi, nil
+}
+
+// buildRangePartitionDefinitions builds the RANGE partitions, the values of VALUES LESS THAN must be constant
+// expressions of integer values, e.g. TO_DAYS('2017-01-01').
+func buildRangePartitionDefinitions(ctx context.Context, defs []*ast.PartitionDefinition) ([]*model.PartitionDefinition, error) {
+	pdefs := make([]*model.PartitionDefinition, 0, len(defs))
+	for _, def := range defs {
+		pdef := &model.PartitionDefinition{Name: model.NewCIStr(def.Name)}
+		if def.LessThan != nil {
+			ast.SetFlag(def.LessThan)
+			if def.LessThan.GetFlag()&^ast.FlagHasFunc != ast.FlagConstant {
+				return nil, errors.Trace(ErrPartitionFunctionIsNotAllowed)
+			}
+			v, err := evaluator.Eval(ctx, def.LessThan)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			var lessThan int64
+			switch v.Kind() {
+			case types.KindInt64:
+				lessThan = v.GetInt64()
+			case types.KindUint64:
+				if v.GetUint64() > math.MaxInt64 {
+					return nil, ErrValuesIsNotIntType.Gen("VALUES value for partition '%s' must have type INT", def.Name)
+				}
+				lessThan = int64(v.GetUint64())
+			default:
+				return nil, ErrValuesIsNotIntType.Gen("VALUES value for partition '%s' must have type INT", def.Name)
+			}
+			pdef.LessThan = &lessThan
+		}
+		pdefs = append(pdefs, pdef)
+	}
+	return pdefs, nil
+}
+
+// allocPartitionIDs allocates the IDs of the partitions, they're the IDs of the physical tables of the partitions.
+func (d *ddl) allocPartitionIDs(defs []*model.PartitionDefinition) error {
+	for _, def := range defs {
+		id, err := d.genGlobalID()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		def.ID = id
+	}
+	return nil
+}
+
+// checkPartitionDefinitions checks the names of the partitions are unique, and the upper bounds of the RANGE
+// partitions are strictly increasing, only the last one can be MAXVALUE.
+func checkPartitionDefinitions(pi *model.PartitionInfo) error {
+	defs := pi.Definitions
+	if len(defs) > maxPartitions {
+		return errors.Trace(ErrTooManyPartitions)
+	}
+	names := make(map[string]bool, len(defs))
+	for i, def := range defs {
+		if names[def.Name.L] {
+			return ErrSameNamePartition.Gen("Duplicate partition name %s", def.Name)
+		}
+		names[def.Name.L] = true
+		if pi.Type != model.PartitionTypeRange {
+			continue
+		}
+		if def.LessThan == nil {
+			if i != len(defs)-1 {
+				return errors.Trace(ErrPartitionMaxvalue)
+			}
+			continue
+		}
+		if i > 0 && *def.LessThan <= *defs[i-1].LessThan {
+			return errors.Trace(ErrRangeNotIncreasing)
+		}
+	}
+	return nil
+}
+
+// checkPartitionKeys checks the primary key and the unique indices include the partition column.
+func checkPartitionKeys(tbInfo *model.TableInfo, pi *model.PartitionInfo) error {
+	if tbInfo.PKIsHandle {
+		for _, col := range tbInfo.Columns {
+			if mysql.HasPriKeyFlag(col.Flag) && col.Name.L != pi.Column.L {
+				return ErrUniqueKeyNeedAllFieldsInPf.Gen("A PRIMARY KEY must include all columns in the table's partitioning function")
+			}
+		}
+	}
+	for _, idx := range tbInfo.Indices {
+		if !idx.Unique || findIndexCol(idx, pi.Column.L) {
+			continue
+		}
+		if idx.Primary {
+			return ErrUniqueKeyNeedAllFieldsInPf.Gen("A PRIMARY KEY must include all columns in the table's partitioning function")
+		}
+		return ErrUniqueKeyNeedAllFieldsInPf.Gen("A UNIQUE INDEX must include all columns in the table's partitioning function")
+	}
+	return nil
+}
+
+func findIndexCol(idx *model.IndexInfo, name string) bool {
+	for _, col := range idx.Columns {
+		if col.Name.L == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNotPartitioned returns an error for the partitioned table. ADD/DROP COLUMN, MODIFY COLUMN and ADD/DROP INDEX
+// aren't supported on the partitioned tables now, because they would have to reorganize the rows or the indices
+// of every partition, so the columns and the indices of a partitioned table are fixed when it's created.
+func checkNotPartitioned(t table.Table, tp model.ActionType) error {
+	if t.Meta().Partition == nil {
+		return nil
+	}
+	return errUnsupportedPartition.Gen("unsupported %s on partitioned table %s", tp, t.Meta().Name)
+}
+
+// partitionTableInfo returns the meta of the physical table of the partition, it's used to delete the rows of
+// the partition by the background job.
+func partitionTableInfo(tblInfo *model.TableInfo, partitionID int64) *model.TableInfo {
+	partInfo := tblInfo.Clone()
+	partInfo.ID = partitionID
+	partInfo.Partition = nil
+	return partInfo
+}
+
+func (d *ddl) onAddTablePartition(t *meta.Meta, job *model.Job) error {
+	schemaID := job.SchemaID
+	tblInfo, err := d.getTableInfo(t, job)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	var defs []*model.PartitionDefinition
+	err = job.DecodeArgs(&defs)
+	if err != nil {
+		job.State = model.JobCancelled
+		return errors.Trace(err)
+	}
+
+	pi := tblInfo.Partition
+	if pi == nil {
+		job.State = model.JobCancelled
+		return errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	pi.Definitions = append(pi.Definitions, defs...)
+	if err = checkPartitionDefinitions(pi); err != nil {
+		job.State = model.JobCancelled
+		return errors.Trace(err)
+	}
+
+	_, err = t.GenSchemaVersion()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err = t.UpdateTable(schemaID, tblInfo); err != nil {
+		return errors.Trace(err)
+	}
+
+	// finish this job
+	job.SchemaState = model.StatePublic
+	job.State = model.JobDone
+	return nil
+}
+
+// How to drop or truncate a partition?
+// The physical table of the partition is removed from the partitioned table first, the servers with the new schema
+// don't read or write it, but the servers with the previous schema may still write it. After all the servers load the
+// new schema, the job is done and the rows of the physical table are deleted by the background job, as no one writes
+// it any more.
+func (d *ddl) onDropTablePartition(t *meta.Meta, job *model.Job) error {
+	schemaID := job.SchemaID
+	tblInfo, err := d.getTableInfo(t, job)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	var (
+		name        string
+		partitionID int64
+	)
+	err = job.DecodeArgs(&name, &partitionID)
+	if err != nil {
+		job.State = model.JobCancelled
+		return errors.Trace(err)
+	}
+
+	if job.SchemaState == model.StateDeleteOnly {
+		// finish this job, the rows of the partition are deleted by the background job.
+		job.Args = []interface{}{partitionTableInfo(tblInfo, partitionID)}
+		job.SchemaState = model.StateNone
+		job.State = model.JobDone
+		return nil
+	}
+
+	pi := tblInfo.Partition
+	if pi == nil {
+		job.State = model.JobCancelled
+		return errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	i := pi.FindPartition(name)
+	if i < 0 {
+		job.State = model.JobCancelled
+		return ErrDropPartitionNonExistent.Gen("Error in list of partitions to DROP")
+	}
+	if len(pi.Definitions) == 1 {
+		job.State = model.JobCancelled
+		return errors.Trace(ErrDropLastPartition)
+	}
+	partitionID = pi.Definitions[i].ID
+	pi.Definitions = append(pi.Definitions[:i], pi.Definitions[i+1:]...)
+
+	_, err = t.GenSchemaVersion()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err = t.UpdateTable(schemaID, tblInfo); err != nil {
+		return errors.Trace(err)
+	}
+
+	// public -> delete only, the partition is only written by the servers with the previous schema.
+	job.Args = []interface{}{name, partitionID}
+	job.SchemaState = model.StateDeleteOnly
+	return nil
+}
+
+func (d *ddl) onTruncateTablePartition(t *meta.Meta, job *model.Job) error {
+	schemaID := job.SchemaID
+	tblInfo, err := d.getTableInfo(t, job)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	var (
+		name           string
+		partitionID    int64
+		oldPartitionID int64
+	)
+	err = job.DecodeArgs(&name, &partitionID, &oldPartitionID)
+	if err != nil {
+		job.State = model.JobCancelled
+		return errors.Trace(err)
+	}
+
+	if job.SchemaState == model.StateDeleteOnly {
+		// finish this job, the rows of the old physical table are deleted by the background job.
+		job.Args = []interface{}{partitionTableInfo(tblInfo, oldPartitionID)}
+		job.SchemaState = model.StatePublic
+		job.State = model.JobDone
+		return nil
+	}
+
+	pi := tblInfo.Partition
+	if pi == nil {
+		job.State = model.JobCancelled
+		return errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	i := pi.FindPartition(name)
+	if i < 0 {
+		job.State = model.JobCancelled
+		return ErrDropPartitionNonExistent.Gen("Error in list of partitions to TRUNCATE")
+	}
+	// The partition is truncated by replacing its physical table with an empty one.
+	oldPartitionID = pi.Definitions[i].ID
+	pi.Definitions[i].ID = partitionID
+
+	_, err = t.GenSchemaVersion()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err = t.UpdateTable(schemaID, tblInfo); err != nil {
+		return errors.Trace(err)
+	}
+
+	// public -> delete only, the old physical table is only written by the servers with the previous schema.
+	job.Args = []interface{}{name, partitionID, oldPartitionID}
+	job.SchemaState = model.StateDeleteOnly
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func testPartitionJob(c *C, ctx context.Context, d *ddl, dbInfo *model.DBInfo, tblInfo *model.TableInfo,
+	tp model.ActionType, args ...interface{}) *model.Job {
+	job := &model.Job{
+		SchemaID: dbInfo.ID,
+		TableID:  tblInfo.ID,
+		Type:     tp,
+		Args:     args,
+	}
+
+	err := d.doDDLJob(ctx, job)
+	c.Assert(err, IsNil)
+	return job
+}
+
+func (s *testTableSuite) TestDropTablePartition(c *C) {
+	defer testleak.AfterTest(c)()
+	d := newDDL(s.store, nil, nil, 100*time.Millisecond)
+	tblInfo := testTableInfo(c, d, "t_partition", 2)
+	tblInfo.Partition = &model.PartitionInfo{Type: model.PartitionTypeRange, Column: model.NewCIStr("c1")}
+	lessThan := []int64{10, 20}
+	for i := range lessThan {
+		id, err := d.genGlobalID()
+		c.Assert(err, IsNil)
+		tblInfo.Partition.Definitions = append(tblInfo.Partition.Definitions, &model.PartitionDefinition{
+			ID:       id,
+			Name:     model.NewCIStr(fmt.Sprintf("p%d", i)),
+			LessThan: &lessThan[i],
+		})
+	}
+	ctx := testNewContext(c, d)
+	testCreateTable(c, ctx, d, s.dbInfo, tblInfo)
+
+	t := testGetTable(c, d, s.dbInfo.ID, tblInfo.ID).(table.PartitionedTable)
+	_, err := ctx.GetTxn(true)
+	c.Assert(err, IsNil)
+	h, err := t.AddRecord(ctx, types.MakeDatums(1, 1))
+	c.Assert(err, IsNil)
+	h1, err := t.AddRecord(ctx, types.MakeDatums(11, 11))
+	c.Assert(err, IsNil)
+	c.Assert(ctx.CommitTxn(), IsNil)
+	p0, p1 := t.Partitions()[0], t.Partitions()[1]
+
+	var checkedStates []model.SchemaState
+	tc := &testDDLCallback{}
+	tc.onJobUpdated = func(job *model.Job) {
+		if job.IsFinished() {
+			return
+		}
+		checkedStates = append(checkedStates, job.SchemaState)
+		// The physical table of the partition is removed from the table first, but its rows aren't deleted until
+		// the job is done, as the servers with the previous schema may still write it.
+		t := testGetTable(c, d, s.dbInfo.ID, tblInfo.ID).(table.PartitionedTable)
+		c.Assert(t.GetPartition(p0.Meta().ID), IsNil)
+		ctx := testNewContext(c, d)
+		_, err1 := p0.Row(ctx, h)
+		c.Assert(err1, IsNil)
+		c.Assert(ctx.RollbackTxn(), IsNil)
+	}
+	d.hook = tc
+
+	// Use local ddl for callback test.
+	s.d.close()
+
+	d.close()
+	d.start()
+
+	job := testPartitionJob(c, ctx, d, s.dbInfo, tblInfo, model.ActionDropTablePartition, "p0")
+	testCheckJobDone(c, d, job, false)
+	c.Assert(checkedStates, DeepEquals, []model.SchemaState{model.StateDeleteOnly})
+	t = testGetTable(c, d, s.dbInfo.ID, tblInfo.ID).(table.PartitionedTable)
+	c.Assert(t.Partitions(), HasLen, 1)
+
+	checkedStates = nil
+	partitionID, err := d.genGlobalID()
+	c.Assert(err, IsNil)
+	p0, h = p1, h1
+	job = testPartitionJob(c, ctx, d, s.dbInfo, tblInfo, model.ActionTruncateTablePartition, "p1", partitionID)
+	testCheckJobDone(c, d, job, true)
+	c.Assert(checkedStates, DeepEquals, []model.SchemaState{model.StateDeleteOnly})
+	t = testGetTable(c, d, s.dbInfo.ID, tblInfo.ID).(table.PartitionedTable)
+	c.Assert(t.GetPartition(partitionID), NotNil)
+
+	job = testDropTable(c, ctx, d, s.dbInfo, tblInfo)
+	testCheckJobDone(c, d, job, false)
+
+	d.close()
+	s.d.start()
+}
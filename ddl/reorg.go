@@ -15,6 +15,9 @@ package ddl
 
 import (
 	"fmt"
+	"math"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/juju/errors"
@@ -22,6 +25,9 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/terror"
 )
 
@@ -159,6 +165,19 @@ func (d *ddl) isReorgRunnable(txn kv.Transaction) error {
 	return nil
 }
 
+// isReorgCancelled returns an error if the running job is cancelled by ADMIN CANCEL DDL JOBS,
+// its reorganization stops so that it can be rolled back.
+func isReorgCancelled(txn kv.Transaction, job *model.Job) error {
+	first, err := meta.NewMeta(txn).GetDDLJob(0)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if first != nil && first.ID == job.ID && first.State == model.JobCancelling {
+		return errors.Trace(errCancelledDDLJob.Gen("cancelled DDL job %d", job.ID))
+	}
+	return nil
+}
+
 func (d *ddl) delKeysWithPrefix(prefix kv.Key) error {
 	for {
 		keys := make([]kv.Key, 0, maxBatchSize)
@@ -235,6 +254,8 @@ func (d *ddl) getReorgInfo(t *meta.Meta, job *model.Job) (*reorgInfo, error) {
 		}
 
 		job.SnapshotVer = ver.Ver
+		// the rows are handled from the minimum handle, the negative handles are included.
+		err = t.UpdateDDLReorgHandle(job, math.MinInt64)
 	} else {
 		info.Handle, err = t.GetDDLReorgHandle(job)
 		if err != nil {
@@ -242,7 +263,7 @@ func (d *ddl) getReorgInfo(t *meta.Meta, job *model.Job) (*reorgInfo, error) {
 		}
 	}
 
-	if info.Handle > 0 {
+	if info.Handle > math.MinInt64 {
 		// we have already handled this handle, so use next
 		info.Handle++
 	}
@@ -254,3 +275,348 @@ func (r *reorgInfo) UpdateHandle(txn kv.Transaction, handle int64) error {
 	t := meta.NewMeta(txn)
 	return errors.Trace(t.UpdateDDLReorgHandle(r.Job, handle))
 }
+
+const (
+	// reorgChunksPerWorker is the number of the chunks split for a worker of the reorganization, so the
+	// workers which finish their chunks early reorganize the remaining ones.
+	reorgChunksPerWorker = 4
+	// the maximum options of the reorganization, the larger options are adjusted to them.
+	maxReorgWorkerCount = 256
+	maxReorgBatchSize   = 10240
+	// reorgHandleChunked is saved as the reorganization handle of the job after its handles are split into the
+	// chunks. The jobs reorganized by the old versions which don't split the chunks save the last reorganized
+	// handle instead.
+	reorgHandleChunked = math.MaxInt64
+	// reorgCancelCheckInterval is the interval to check whether the job is cancelled while a worker waits for
+	// the rate limit.
+	reorgCancelCheckInterval = time.Second
+)
+
+// reorgOptions are the options of the concurrent reorganization of a job, they're read from the system variables
+// of the session which submits the job and saved in the job arguments, so they're kept after the owner changes.
+type reorgOptions struct {
+	WorkerCount int64 `json:"worker_cnt"`
+	BatchSize   int64 `json:"batch_size"`
+	// RateLimit is the maximum number of the rows reorganized by all the workers per second, 0 means no limit.
+	RateLimit int64 `json:"rate_limit"`
+}
+
+// adjust sets the invalid options to the valid ones, the zero options of the jobs submitted without the
+// options are set to the default values.
+func (o *reorgOptions) adjust() {
+	if o.WorkerCount <= 0 {
+		o.WorkerCount = defaultReorgSysVar(variable.TiDBDDLReorgWorkerCount)
+	} else if o.WorkerCount > maxReorgWorkerCount {
+		o.WorkerCount = maxReorgWorkerCount
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = defaultReorgSysVar(variable.TiDBDDLReorgBatchSize)
+	} else if o.BatchSize > maxReorgBatchSize {
+		o.BatchSize = maxReorgBatchSize
+	}
+	if o.RateLimit < 0 {
+		o.RateLimit = 0
+	}
+}
+
+func defaultReorgSysVar(name string) int64 {
+	v, err := strconv.ParseInt(variable.GetSysVar(name).Value, 10, 64)
+	if err != nil || v <= 0 {
+		return 1
+	}
+	return v
+}
+
+// getReorgOptions reads the options of the reorganization from the system variables, the session values of the
+// variables take precedence over the global values.
+func getReorgOptions(ctx context.Context) (*reorgOptions, error) {
+	opts := &reorgOptions{}
+	vars := []struct {
+		name  string
+		value *int64
+	}{
+		{variable.TiDBDDLReorgWorkerCount, &opts.WorkerCount},
+		{variable.TiDBDDLReorgBatchSize, &opts.BatchSize},
+		{variable.TiDBDDLReorgRateLimit, &opts.RateLimit},
+	}
+	for _, v := range vars {
+		var value string
+		sessionValue := variable.GetSessionVars(ctx).GetSystemVar(v.name)
+		if !sessionValue.IsNull() {
+			value = sessionValue.GetString()
+		} else {
+			var err error
+			value, err = variable.GetGlobalVarAccessor(ctx).GetGlobalSysVar(ctx, v.name)
+			if terror.ErrorEqual(err, variable.UnknownSystemVar) {
+				// The store is bootstrapped by an old version without the variable.
+				value = variable.GetSysVar(v.name).Value
+			} else if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, errors.Trace(variable.ErrWrongTypeForVar.Gen("Incorrect argument type to variable '%s'", v.name))
+		}
+		*v.value = n
+	}
+	opts.adjust()
+	return opts, nil
+}
+
+// reorgLimiter limits the rate of the rows reorganized by all the workers of a job.
+type reorgLimiter struct {
+	mu   sync.Mutex
+	rate int64
+	// next is the time when the next rows can be reorganized.
+	next time.Time
+}
+
+// reserve reserves the rows to be reorganized, and returns the duration to wait before reorganizing them.
+func (l *reorgLimiter) reserve(rows int) time.Duration {
+	if l.rate <= 0 || rows == 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(int64(rows) * int64(time.Second) / l.rate))
+	return wait
+}
+
+// seekSnapshotHandle returns the first handle of the table which isn't less than the handle in the snapshot,
+// false is returned if there isn't one.
+func seekSnapshotHandle(snap kv.Snapshot, t table.Table, handle int64) (int64, bool, error) {
+	it, err := snap.Seek(t.RecordKey(handle, nil))
+	if err != nil {
+		return 0, false, errors.Trace(err)
+	}
+	defer it.Close()
+
+	if !it.Valid() || !it.Key().HasPrefix(t.RecordPrefix()) {
+		return 0, false, nil
+	}
+	handle, err = tables.DecodeRecordKeyHandle(it.Key())
+	if err != nil {
+		return 0, false, errors.Trace(err)
+	}
+	return handle, true, nil
+}
+
+// splitReorgChunks splits the handles of the table which aren't less than startHandle in the snapshot of the job
+// into the chunks of even ranges, and saves them as the checkpoints of the chunks. It runs in the transaction of
+// the job, so the chunks are saved before the workers update them concurrently.
+func (d *ddl) splitReorgChunks(m *meta.Meta, t table.Table, reorgInfo *reorgInfo, opts *reorgOptions, startHandle int64) error {
+	if err := m.UpdateDDLReorgHandle(reorgInfo.Job, reorgHandleChunked); err != nil {
+		return errors.Trace(err)
+	}
+	snap, err := d.store.GetSnapshot(kv.Version{Ver: reorgInfo.SnapshotVer})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer snap.Release()
+
+	minHandle, ok, err := seekSnapshotHandle(snap, t, startHandle)
+	if err != nil || !ok {
+		// There isn't any row to reorganize if the table is empty.
+		return errors.Trace(err)
+	}
+	// The snapshot can't be sought in reverse order, so the maximum handle is searched by binary search.
+	maxHandle, hi := minHandle, int64(math.MaxInt64)
+	for maxHandle < hi {
+		mid := int64(uint64(maxHandle) + uint64(hi-maxHandle)/2 + 1)
+		h, ok, err := seekSnapshotHandle(snap, t, mid)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if ok {
+			maxHandle = h
+		} else {
+			hi = mid - 1
+		}
+	}
+
+	// span is the number of the handles in the range minus one, it doesn't overflow.
+	span := uint64(maxHandle - minHandle)
+	cnt := uint64(opts.WorkerCount * reorgChunksPerWorker)
+	if span < cnt {
+		cnt = span + 1
+	}
+	step := span/cnt + 1
+	for i := uint64(0); i < cnt; i++ {
+		offset := i * step
+		if offset > span {
+			break
+		}
+		chunk := &model.ReorgChunk{StartHandle: minHandle + int64(offset), EndHandle: maxHandle}
+		if span-offset >= step {
+			chunk.EndHandle = chunk.StartHandle + int64(step) - 1
+		}
+		chunk.NextHandle = chunk.StartHandle
+		if err = m.UpdateDDLReorgChunk(reorgInfo.Job, int(i), chunk); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// reorgChunks reorganizes the chunks of the job concurrently by the workers, the rows in a chunk are read from the
+// snapshot of the job in batches, and every batch is reorganized by the function in a transaction, which saves
+// the checkpoint of the chunk too. The checkpoints are removed after all the chunks are reorganized.
+func (d *ddl) reorgChunks(t table.Table, reorgInfo *reorgInfo, opts *reorgOptions, reorg func(txn kv.Transaction, handles []int64) error) error {
+	var chunks []*model.ReorgChunk
+	err := kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
+		m := meta.NewMeta(txn)
+		var err1 error
+		chunks, err1 = m.GetDDLReorgChunks(reorgInfo.Job)
+		if err1 != nil || len(chunks) > 0 {
+			return errors.Trace(err1)
+		}
+		// The job reorganized by an old version has no chunks, it's resumed from the next handle of its last
+		// reorganized handle.
+		handle, err1 := m.GetDDLReorgHandle(reorgInfo.Job)
+		if err1 != nil || handle == reorgHandleChunked {
+			return errors.Trace(err1)
+		}
+		if handle > math.MinInt64 {
+			handle++
+		}
+		if err1 = d.splitReorgChunks(m, t, reorgInfo, opts, handle); err1 != nil {
+			return errors.Trace(err1)
+		}
+		chunks, err1 = m.GetDDLReorgChunks(reorgInfo.Job)
+		return errors.Trace(err1)
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	idxCh := make(chan int, len(chunks))
+	for i, chunk := range chunks {
+		if !chunk.Done {
+			idxCh <- i
+		}
+	}
+	close(idxCh)
+	workerCnt := int(opts.WorkerCount)
+	if workerCnt > len(idxCh) {
+		workerCnt = len(idxCh)
+	}
+
+	limiter := &reorgLimiter{rate: opts.RateLimit}
+	// stopCh is closed when a worker fails, the other workers stop too and the first error is returned.
+	stopCh := make(chan struct{})
+	var (
+		wg       sync.WaitGroup
+		stopOnce sync.Once
+	)
+	for i := 0; i < workerCnt; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range idxCh {
+				select {
+				case <-stopCh:
+					return
+				default:
+				}
+				err1 := d.reorgChunk(t, reorgInfo, opts, limiter, stopCh, idx, chunks[idx], reorg)
+				if err1 != nil {
+					stopOnce.Do(func() {
+						err = errors.Trace(err1)
+						close(stopCh)
+					})
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	// the checkpoints aren't needed after all the chunks are reorganized. They're removed in a new transaction
+	// instead of the transaction of the job, which may begin before the checkpoints are updated.
+	err = kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
+		return errors.Trace(meta.NewMeta(txn).RemoveDDLReorgChunks(reorgInfo.Job))
+	})
+	return errors.Trace(err)
+}
+
+// waitReorgLimit waits for the duration reserved from the rate limiter. The job may be cancelled during a long wait,
+// so its cancellation is checked every reorgCancelCheckInterval. It returns false if another worker fails.
+func (d *ddl) waitReorgLimit(job *model.Job, wait time.Duration, stopCh <-chan struct{}) (bool, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		interval := deadline.Sub(time.Now())
+		if interval > reorgCancelCheckInterval {
+			interval = reorgCancelCheckInterval
+		}
+		select {
+		case <-stopCh:
+			return false, nil
+		case <-d.quitCh:
+			return false, errors.Trace(errInvalidWorker.Gen("worker is closed"))
+		case <-time.After(interval):
+		}
+		if !time.Now().Before(deadline) {
+			return true, nil
+		}
+		err := kv.RunInNewTxn(d.store, false, func(txn kv.Transaction) error {
+			return errors.Trace(isReorgCancelled(txn, job))
+		})
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+	}
+}
+
+// reorgChunk reorganizes the rows in the chunk from its checkpoint.
+func (d *ddl) reorgChunk(t table.Table, reorgInfo *reorgInfo, opts *reorgOptions, limiter *reorgLimiter,
+	stopCh <-chan struct{}, idx int, chunk *model.ReorgChunk, reorg func(txn kv.Transaction, handles []int64) error) error {
+	for !chunk.Done {
+		handles, err := d.getSnapshotRowsInRange(t, reorgInfo.SnapshotVer, chunk.NextHandle, chunk.EndHandle, int(opts.BatchSize))
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		ok, err := d.waitReorgLimit(reorgInfo.Job, limiter.reserve(len(handles)), stopCh)
+		if err != nil || !ok {
+			// if another worker fails, its error is returned.
+			return errors.Trace(err)
+		}
+
+		next := *chunk
+		next.RowCount += int64(len(handles))
+		if len(handles) < int(opts.BatchSize) || handles[len(handles)-1] == chunk.EndHandle {
+			next.Done = true
+		} else {
+			next.NextHandle = handles[len(handles)-1] + 1
+		}
+		err = kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
+			if err1 := d.isReorgRunnable(txn); err1 != nil {
+				return errors.Trace(err1)
+			}
+			if err1 := isReorgCancelled(txn, reorgInfo.Job); err1 != nil {
+				return errors.Trace(err1)
+			}
+			if len(handles) > 0 {
+				if err1 := reorg(txn, handles); err1 != nil {
+					return errors.Trace(err1)
+				}
+			}
+			// save the checkpoint of the chunk in the same transaction.
+			return errors.Trace(meta.NewMeta(txn).UpdateDDLReorgChunk(reorgInfo.Job, idx, &next))
+		})
+		if err != nil {
+			return errors.Trace(err)
+		}
+		*chunk = next
+	}
+	return nil
+}
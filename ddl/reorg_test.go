@@ -14,12 +14,16 @@
 package ddl
 
 import (
+	"math"
+	"sync"
 	"time"
 
+	"github.com/juju/errors"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -167,3 +171,230 @@ func (s *testDDLSuite) TestReorgOwner(c *C) {
 	})
 	c.Assert(err, IsNil)
 }
+
+func (s *testDDLSuite) TestReorgChunks(c *C) {
+	defer testleak.AfterTest(c)()
+	store := testCreateStore(c, "test_reorg_chunks")
+	defer store.Close()
+
+	lease := 50 * time.Millisecond
+	d := newDDL(store, nil, nil, lease)
+	defer d.close()
+
+	testCheckOwner(c, d, true, ddlJobFlag)
+	ctx := testNewContext(c, d)
+	dbInfo := testSchemaInfo(c, d, "test")
+	testCreateSchema(c, ctx, d, dbInfo)
+	tblInfo := testTableInfo(c, d, "t", 3)
+	testCreateTable(c, ctx, d, dbInfo, tblInfo)
+	t := testGetTable(c, d, dbInfo.ID, tblInfo.ID)
+
+	// the handles of the rows are 1, 3, ..., 99.
+	var rows int64
+	for i := 1; i <= 100; i++ {
+		h, err := t.AddRecord(ctx, types.MakeDatums(i, i, i))
+		c.Assert(err, IsNil)
+		if i%2 == 0 {
+			err = t.RemoveRecord(ctx, h, types.MakeDatums(i, i, i))
+			c.Assert(err, IsNil)
+			continue
+		}
+		rows++
+	}
+	err := ctx.CommitTxn()
+	c.Assert(err, IsNil)
+
+	job := &model.Job{ID: 1, SchemaID: dbInfo.ID, TableID: tblInfo.ID, Type: model.ActionAddIndex}
+	opts := &reorgOptions{WorkerCount: 2, BatchSize: 3}
+	var info *reorgInfo
+	err = kv.RunInNewTxn(d.store, false, func(txn kv.Transaction) error {
+		m := meta.NewMeta(txn)
+		var err1 error
+		info, err1 = d.getReorgInfo(m, job)
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		return errors.Trace(d.splitReorgChunks(m, t, info, opts, math.MinInt64))
+	})
+	c.Assert(err, IsNil)
+
+	getChunks := func() []*model.ReorgChunk {
+		var chunks []*model.ReorgChunk
+		err1 := kv.RunInNewTxn(d.store, false, func(txn kv.Transaction) error {
+			var err2 error
+			chunks, err2 = meta.NewMeta(txn).GetDDLReorgChunks(job)
+			return errors.Trace(err2)
+		})
+		c.Assert(err1, IsNil)
+		return chunks
+	}
+	chunks := getChunks()
+	c.Assert(chunks, HasLen, 8)
+	c.Assert(chunks[0].StartHandle, Equals, int64(1))
+	c.Assert(chunks[len(chunks)-1].EndHandle, Equals, int64(99))
+	for i := 1; i < len(chunks); i++ {
+		c.Assert(chunks[i].StartHandle, Equals, chunks[i-1].EndHandle+1)
+	}
+	done, total := model.ReorgProgress(chunks)
+	c.Assert(done, Equals, int64(0))
+	c.Assert(total, Equals, int64(99))
+
+	// the first chunk is reorganized partly before the owner changes.
+	chunks[0].NextHandle = 6
+	chunks[0].RowCount = 3
+	err = kv.RunInNewTxn(d.store, false, func(txn kv.Transaction) error {
+		return errors.Trace(meta.NewMeta(txn).UpdateDDLReorgChunk(job, 0, chunks[0]))
+	})
+	c.Assert(err, IsNil)
+
+	var (
+		mu       sync.Mutex
+		maxBatch int
+	)
+	handles := make(map[int64]int)
+	err = d.reorgChunks(t, info, opts, func(txn kv.Transaction, hs []int64) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(hs) > maxBatch {
+			maxBatch = len(hs)
+		}
+		for _, h := range hs {
+			handles[h]++
+		}
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(maxBatch, Equals, 3)
+	c.Assert(handles, HasLen, int(rows)-3)
+	for h, cnt := range handles {
+		c.Assert(h, GreaterEqual, int64(7))
+		c.Assert(cnt, Equals, 1)
+	}
+	// the checkpoints are removed after all the chunks are reorganized.
+	c.Assert(getChunks(), HasLen, 0)
+	// the job isn't reorganized again.
+	handles = make(map[int64]int)
+	err = d.reorgChunks(t, info, opts, func(txn kv.Transaction, hs []int64) error {
+		for _, h := range hs {
+			handles[h]++
+		}
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(handles, HasLen, 0)
+
+	// the job reorganized by an old version without the chunks is resumed from its handle.
+	err = kv.RunInNewTxn(d.store, false, func(txn kv.Transaction) error {
+		return errors.Trace(meta.NewMeta(txn).UpdateDDLReorgHandle(job, 50))
+	})
+	c.Assert(err, IsNil)
+	err = d.reorgChunks(t, info, opts, func(txn kv.Transaction, hs []int64) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, h := range hs {
+			handles[h]++
+		}
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(handles, HasLen, 25)
+	for h, cnt := range handles {
+		c.Assert(h, Greater, int64(50))
+		c.Assert(cnt, Equals, 1)
+	}
+	c.Assert(getChunks(), HasLen, 0)
+
+	// a worker fails, the others stop and the error is returned.
+	err = kv.RunInNewTxn(d.store, false, func(txn kv.Transaction) error {
+		return errors.Trace(d.splitReorgChunks(meta.NewMeta(txn), t, info, opts, math.MinInt64))
+	})
+	c.Assert(err, IsNil)
+	err = d.reorgChunks(t, info, opts, func(txn kv.Transaction, hs []int64) error {
+		if hs[0] > 50 {
+			return errors.New("mock reorg error")
+		}
+		return nil
+	})
+	c.Assert(err, ErrorMatches, ".*mock reorg error")
+	chunks = getChunks()
+	c.Assert(chunks, HasLen, 8)
+	for _, chunk := range chunks {
+		if chunk.StartHandle > 50 {
+			c.Assert(chunk.Done, IsFalse)
+			c.Assert(chunk.RowCount, Equals, int64(0))
+		} else if chunk.Done {
+			// the rows of the odd handles are reorganized.
+			c.Assert(chunk.RowCount, Equals, (chunk.EndHandle+1)/2-chunk.StartHandle/2)
+		}
+	}
+
+	// the reorganization stops if the job is cancelled.
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	c.Assert(isReorgCancelled(txn, job), IsNil)
+	cancelling := *job
+	cancelling.State = model.JobCancelling
+	err = meta.NewMeta(txn).EnQueueDDLJob(&cancelling)
+	c.Assert(err, IsNil)
+	c.Assert(terror.ErrorEqual(isReorgCancelled(txn, job), errCancelledDDLJob), IsTrue)
+
+}
+
+func (s *testDDLSuite) TestReorgLimiter(c *C) {
+	defer testleak.AfterTest(c)()
+	l := &reorgLimiter{}
+	c.Assert(l.reserve(100), Equals, time.Duration(0))
+	c.Assert(l.reserve(100), Equals, time.Duration(0))
+
+	l = &reorgLimiter{rate: 100}
+	c.Assert(l.reserve(50), Equals, time.Duration(0))
+	wait := l.reserve(50)
+	c.Assert(wait, LessEqual, 500*time.Millisecond)
+	c.Assert(wait, Greater, 400*time.Millisecond)
+	wait = l.reserve(10)
+	c.Assert(wait, LessEqual, time.Second)
+	c.Assert(wait, Greater, 900*time.Millisecond)
+}
+
+func (s *testDDLSuite) TestWaitReorgLimit(c *C) {
+	defer testleak.AfterTest(c)()
+	store := testCreateStore(c, "test_wait_reorg_limit")
+	defer store.Close()
+
+	d := &ddl{store: store, quitCh: make(chan struct{})}
+	job := &model.Job{ID: 1, Type: model.ActionAddIndex}
+	stopCh := make(chan struct{})
+	ok, err := d.waitReorgLimit(job, 10*time.Millisecond, stopCh)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+
+	// the cancellation is checked while the worker waits for the rate limit.
+	err = kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+		cancelling := *job
+		cancelling.State = model.JobCancelling
+		return errors.Trace(meta.NewMeta(txn).EnQueueDDLJob(&cancelling))
+	})
+	c.Assert(err, IsNil)
+	start := time.Now()
+	_, err = d.waitReorgLimit(job, 10*time.Second, stopCh)
+	c.Assert(terror.ErrorEqual(err, errCancelledDDLJob), IsTrue, Commentf("err %v", err))
+	c.Assert(time.Since(start) < 5*time.Second, IsTrue)
+
+	// the worker stops if another worker fails.
+	close(stopCh)
+	ok, err = d.waitReorgLimit(job, 10*time.Second, stopCh)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+}
+
+func (s *testDDLSuite) TestReorgOptions(c *C) {
+	defer testleak.AfterTest(c)()
+	opts := &reorgOptions{RateLimit: -1}
+	opts.adjust()
+	c.Assert(*opts, Equals, reorgOptions{WorkerCount: 4, BatchSize: 128})
+
+	opts = &reorgOptions{WorkerCount: 1 << 20, BatchSize: 1 << 20, RateLimit: 100}
+	opts.adjust()
+	c.Assert(*opts, Equals, reorgOptions{WorkerCount: maxReorgWorkerCount, BatchSize: maxReorgBatchSize, RateLimit: 100})
+}
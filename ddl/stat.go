@@ -17,6 +17,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/inspectkv"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/sessionctx/variable"
 )
 
@@ -35,6 +36,8 @@ var (
 	ddlJobTableID        = "ddl_job_table_id"
 	ddlJobSnapshotVer    = "ddl_job_snapshot_ver"
 	ddlJobReorgHandle    = "ddl_job_reorg_handle"
+	ddlJobReorgRowCount  = "ddl_job_reorg_row_count"
+	ddlJobReorgRowTotal  = "ddl_job_reorg_row_total"
 	ddlJobArgs           = "ddl_job_args"
 	bgSchemaVersion      = "bg_schema_version"
 	bgOwnerID            = "bg_owner_id"
@@ -95,6 +98,9 @@ func (d *ddl) Stats() (map[string]interface{}, error) {
 		m[ddlJobTableID] = ddlInfo.Job.TableID
 		m[ddlJobSnapshotVer] = ddlInfo.Job.SnapshotVer
 		m[ddlJobReorgHandle] = ddlInfo.ReorgHandle
+		if len(ddlInfo.ReorgChunks) > 0 {
+			m[ddlJobReorgRowCount], m[ddlJobReorgRowTotal] = model.ReorgProgress(ddlInfo.ReorgChunks)
+		}
 		m[ddlJobArgs] = ddlInfo.Job.Args
 	}
 
@@ -152,6 +152,124 @@ func (d *ddl) onDropTable(t *meta.Meta, job *model.Job) error {
 	return errors.Trace(err)
 }
 
+func (d *ddl) onRenameTable(t *meta.Meta, job *model.Job) error {
+	var oldSchemaIDs, newSchemaIDs, tableIDs []int64
+	var newNames []model.CIStr
+	if err := job.DecodeArgs(&oldSchemaIDs, &newSchemaIDs, &tableIDs, &newNames); err != nil {
+		// arg error, cancel this job.
+		job.State = model.JobCancelled
+		return errors.Trace(err)
+	}
+
+	// The tables are renamed in order, tblInfos and schemaIDs are the renamed tables and their schemas after
+	// the renames before. All the renames are checked before any table is updated, so either all of them are
+	// done or the job is cancelled.
+	var renamedIDs []int64
+	tblInfos := make(map[int64]*model.TableInfo)
+	schemaIDs := make(map[int64]int64)
+	fromSchemaIDs := make(map[int64]int64)
+	for i, tableID := range tableIDs {
+		tblInfo, ok := tblInfos[tableID]
+		if !ok {
+			var err error
+			tblInfo, err = t.GetTable(oldSchemaIDs[i], tableID)
+			if terror.ErrorEqual(err, meta.ErrDBNotExists) {
+				job.State = model.JobCancelled
+				return errors.Trace(infoschema.ErrDatabaseNotExists)
+			} else if err != nil {
+				return errors.Trace(err)
+			}
+			if tblInfo == nil {
+				job.State = model.JobCancelled
+				return errors.Trace(infoschema.ErrTableNotExists)
+			}
+			if tblInfo.State != model.StatePublic {
+				job.State = model.JobCancelled
+				return ErrInvalidTableState.Gen("table %s is not in public, but %s", tblInfo.Name.L, tblInfo.State)
+			}
+			renamedIDs = append(renamedIDs, tableID)
+			tblInfos[tableID] = tblInfo
+			schemaIDs[tableID] = oldSchemaIDs[i]
+			fromSchemaIDs[tableID] = oldSchemaIDs[i]
+		} else if schemaIDs[tableID] != oldSchemaIDs[i] {
+			job.State = model.JobCancelled
+			return errors.Trace(infoschema.ErrTableNotExists)
+		}
+
+		exists, err := renamedTableExists(t, tblInfos, schemaIDs, newSchemaIDs[i], newNames[i])
+		if terror.ErrorEqual(err, meta.ErrDBNotExists) {
+			job.State = model.JobCancelled
+			return errors.Trace(infoschema.ErrDatabaseNotExists)
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+		if exists {
+			job.State = model.JobCancelled
+			return errors.Trace(infoschema.ErrTableExists)
+		}
+		tblInfo.Name = newNames[i]
+		schemaIDs[tableID] = newSchemaIDs[i]
+	}
+
+	_, err := t.GenSchemaVersion()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	for _, tableID := range renamedIDs {
+		tblInfo, schemaID, oldSchemaID := tblInfos[tableID], schemaIDs[tableID], fromSchemaIDs[tableID]
+		if schemaID == oldSchemaID {
+			err = t.UpdateTable(schemaID, tblInfo)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			continue
+		}
+		// The table is moved to the other schema, its auto ID is moved too.
+		baseID, err := t.GetAutoTableID(oldSchemaID, tableID)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = t.DropTable(oldSchemaID, tableID); err != nil {
+			return errors.Trace(err)
+		}
+		if err = t.CreateTable(schemaID, tblInfo); err != nil {
+			return errors.Trace(err)
+		}
+		if baseID > 0 {
+			if _, err = t.GenAutoTableID(schemaID, tableID, baseID); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+
+	// finish this job
+	job.SchemaState = model.StatePublic
+	job.State = model.JobDone
+	return nil
+}
+
+// renamedTableExists checks whether there is a table of the name in the schema, the tables in tblInfos are in
+// the schemas of schemaIDs with their new names.
+func renamedTableExists(t *meta.Meta, tblInfos map[int64]*model.TableInfo, schemaIDs map[int64]int64,
+	schemaID int64, name model.CIStr) (bool, error) {
+	tables, err := t.ListTables(schemaID)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	for _, tbl := range tables {
+		if _, ok := tblInfos[tbl.ID]; !ok && tbl.Name.L == name.L {
+			return true, nil
+		}
+	}
+	for id, tbl := range tblInfos {
+		if schemaIDs[id] == schemaID && tbl.Name.L == name.L {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (d *ddl) getTable(schemaID int64, tblInfo *model.TableInfo) (table.Table, error) {
 	alloc := autoid.NewAllocator(d.store, schemaID)
 	tbl, err := table.TableFromMeta(alloc, tblInfo)
@@ -181,6 +299,14 @@ func (d *ddl) getTableInfo(t *meta.Meta, job *model.Job) (*model.TableInfo, erro
 }
 
 func (d *ddl) dropTableData(t table.Table) error {
+	if pt, ok := t.(table.PartitionedTable); ok {
+		for _, p := range pt.Partitions() {
+			if err := d.dropTableData(p); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+
 	// delete table data
 	err := d.delKeysWithPrefix(t.RecordPrefix())
 	if err != nil {
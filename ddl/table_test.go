@@ -186,6 +186,97 @@ func (s *testTableSuite) TestTable(c *C) {
 	testCheckJobDone(c, d, job, false)
 }
 
+func testRenameTable(ctx context.Context, d *ddl, oldSchemaIDs, newSchemaIDs, tableIDs []int64,
+	newNames ...string) (*model.Job, error) {
+	names := make([]model.CIStr, 0, len(newNames))
+	for _, name := range newNames {
+		names = append(names, model.NewCIStr(name))
+	}
+	job := &model.Job{
+		SchemaID: oldSchemaIDs[0],
+		TableID:  tableIDs[0],
+		Type:     model.ActionRenameTable,
+		Args:     []interface{}{oldSchemaIDs, newSchemaIDs, tableIDs, names},
+	}
+
+	err := d.doDDLJob(ctx, job)
+	return job, err
+}
+
+func testCheckTableName(c *C, d *ddl, schemaID int64, tableID int64, name string) {
+	kv.RunInNewTxn(d.store, false, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		info, err := t.GetTable(schemaID, tableID)
+		c.Assert(err, IsNil)
+		c.Assert(info, NotNil)
+		c.Assert(info.Name.L, Equals, name)
+		return nil
+	})
+}
+
+func (s *testTableSuite) TestRenameTable(c *C) {
+	defer testleak.AfterTest(c)()
+	d := s.d
+
+	ctx := testNewContext(c, d)
+	defer ctx.RollbackTxn()
+
+	dbInfo2 := testSchemaInfo(c, d, "test_rename")
+	testCreateSchema(c, ctx, d, dbInfo2)
+	tblInfo1 := testTableInfo(c, d, "t1", 3)
+	testCreateTable(c, ctx, d, s.dbInfo, tblInfo1)
+	tblInfo2 := testTableInfo(c, d, "t2", 3)
+	testCreateTable(c, ctx, d, s.dbInfo, tblInfo2)
+	dbID, dbID2, id1, id2 := s.dbInfo.ID, dbInfo2.ID, tblInfo1.ID, tblInfo2.ID
+
+	job, err := testRenameTable(ctx, d, []int64{dbID}, []int64{dbID}, []int64{id1}, "t3")
+	c.Assert(err, IsNil)
+	testCheckJobDone(c, d, job, true)
+	testCheckTableName(c, d, dbID, id1, "t3")
+
+	// The tables are swapped in one job.
+	job, err = testRenameTable(ctx, d, []int64{dbID, dbID, dbID}, []int64{dbID, dbID, dbID}, []int64{id1, id2, id1},
+		"t", "t3", "t2")
+	c.Assert(err, IsNil)
+	testCheckJobDone(c, d, job, true)
+	testCheckTableName(c, d, dbID, id1, "t2")
+	testCheckTableName(c, d, dbID, id2, "t3")
+
+	// None of the tables is renamed if any of them can't be renamed.
+	job, err = testRenameTable(ctx, d, []int64{dbID, dbID}, []int64{dbID, dbID}, []int64{id1, id2}, "t4", "t4")
+	c.Assert(err, NotNil)
+	testCheckJobCancelled(c, d, job)
+	testCheckTableName(c, d, dbID, id1, "t2")
+	job, err = testRenameTable(ctx, d, []int64{dbID, dbID2}, []int64{dbID, dbID}, []int64{id1, id2}, "t4", "t5")
+	c.Assert(err, NotNil)
+	testCheckJobCancelled(c, d, job)
+	testCheckTableName(c, d, dbID, id1, "t2")
+
+	// The table is moved to the other schema with its auto ID.
+	err = kv.RunInNewTxn(d.store, false, func(txn kv.Transaction) error {
+		_, err1 := meta.NewMeta(txn).GenAutoTableID(dbID, id1, 10)
+		return err1
+	})
+	c.Assert(err, IsNil)
+	job, err = testRenameTable(ctx, d, []int64{dbID}, []int64{dbID2}, []int64{id1}, "t1")
+	c.Assert(err, IsNil)
+	testCheckJobDone(c, d, job, true)
+	testCheckTableName(c, d, dbID2, id1, "t1")
+	testCheckTableState(c, d, s.dbInfo, tblInfo1, model.StateNone)
+	kv.RunInNewTxn(d.store, false, func(txn kv.Transaction) error {
+		autoID, err1 := meta.NewMeta(txn).GetAutoTableID(dbID2, id1)
+		c.Assert(err1, IsNil)
+		c.Assert(autoID, Equals, int64(10))
+		return nil
+	})
+
+	job = testDropTable(c, ctx, d, dbInfo2, tblInfo1)
+	testCheckJobDone(c, d, job, false)
+	job = testDropTable(c, ctx, d, s.dbInfo, tblInfo2)
+	testCheckJobDone(c, d, job, false)
+	testDropSchema(c, ctx, d, dbInfo2)
+}
+
 func (s *testTableSuite) TestTableResume(c *C) {
 	defer testleak.AfterTest(c)()
 	d := s.d
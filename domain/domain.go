@@ -20,12 +20,14 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/perfschema"
+	"github.com/pingcap/tidb/plan/statistics"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/store/localstore"
 	"github.com/pingcap/tidb/terror"
@@ -42,6 +44,7 @@ type Domain struct {
 	leaseCh     chan time.Duration
 	lastLeaseTS int64 // nano seconds
 	m           sync.Mutex
+	statsHandle atomic.Value // *statistics.Handle
 }
 
 func (do *Domain) loadInfoSchema(txn kv.Transaction) (err error) {
@@ -112,6 +115,53 @@ func (do *Domain) Store() kv.Storage {
 	return do.store
 }
 
+// StatsHandle returns the statistics handle of the domain, it's nil if the statistics are not loaded.
+func (do *Domain) StatsHandle() *statistics.Handle {
+	h, _ := do.statsHandle.Load().(*statistics.Handle)
+	return h
+}
+
+// UpdateTableStatsLoop creates the statistics handle which reads the statistics with ctx,
+// loads the statistics and keeps them updated every lease. The row count changes are dumped
+// in the same loop, and the tables modified a lot are analyzed by the DDL owner, so only one
+// server analyzes them.
+func (do *Domain) UpdateTableStatsLoop(ctx context.Context) error {
+	statsHandle := statistics.NewHandle(ctx)
+	do.statsHandle.Store(statsHandle)
+	err := statsHandle.Update(do.InfoSchema())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// If the store is local, the statistics are only updated by the sessions of the domain.
+	lease := do.ddl.GetLease()
+	if lease <= 0 {
+		return nil
+	}
+	go func() {
+		ticker := time.NewTicker(lease)
+		defer ticker.Stop()
+		for range ticker.C {
+			err := statsHandle.DumpStatsDeltaToKV()
+			if err == nil {
+				err = statsHandle.Update(do.InfoSchema())
+			}
+			var isOwner bool
+			if err == nil {
+				isOwner, err = do.ddl.IsOwner()
+			}
+			if err == nil && isOwner {
+				err = statsHandle.HandleAutoAnalyze(do.InfoSchema())
+			}
+			if terror.ErrorEqual(err, localstore.ErrDBClosed) {
+				return
+			} else if err != nil {
+				log.Errorf("[stats] update statistics err %v", errors.ErrorStack(err))
+			}
+		}
+	}()
+	return nil
+}
+
 // SetLease will reset the lease time for online DDL change.
 func (do *Domain) SetLease(lease time.Duration) {
 	if lease <= 0 {
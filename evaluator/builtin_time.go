@@ -506,6 +506,23 @@ func builtinDayOfYear(args []types.Datum, _ context.Context) (types.Datum, error
 	return d, nil
 }
 
+// See http://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_to-days
+func builtinToDays(args []types.Datum, _ context.Context) (types.Datum, error) {
+	d, err := convertToTime(args[0], mysql.TypeDate)
+	if err != nil || d.IsNull() {
+		return d, errors.Trace(err)
+	}
+
+	t := d.GetMysqlTime()
+	if t.IsZero() {
+		d.SetNull()
+		return d, nil
+	}
+
+	d.SetInt64(t.ToDays())
+	return d, nil
+}
+
 // See http://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_week
 func builtinWeek(args []types.Datum, _ context.Context) (types.Datum, error) {
 	d, err := convertToTime(args[0], mysql.TypeDate)
@@ -186,6 +186,27 @@ func (s *testEvaluatorSuite) TestDate(c *C) {
 	}
 }
 
+func (s *testEvaluatorSuite) TestToDays(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		Input  interface{}
+		Expect interface{}
+	}{
+		{"0000-01-01", int64(1)},
+		{"2007-10-07", int64(733321)},
+		{"2008-10-07 12:34:56", int64(733687)},
+		{"0000-00-00", nil},
+		{nil, nil},
+	}
+
+	dtbl := tblToDtbl(tbl)
+	for _, t := range dtbl {
+		v, err := builtinToDays(t["Input"], nil)
+		c.Assert(err, IsNil)
+		c.Assert(v, testutil.DatumEquals, t["Expect"][0])
+	}
+}
+
 func (s *testEvaluatorSuite) TestDateFormat(c *C) {
 	defer testleak.AfterTest(c)()
 
@@ -14,12 +14,17 @@
 package executor
 
 import (
+	"sync/atomic"
+	"time"
+
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
 )
 
 // recordSet wraps an executor, implements ast.RecordSet interface
@@ -27,6 +32,7 @@ type recordSet struct {
 	fields   []*ast.ResultField
 	executor Executor
 	schema   expression.Schema
+	ctx      context.Context
 }
 
 func (a *recordSet) Fields() ([]*ast.ResultField, error) {
@@ -34,6 +40,9 @@ func (a *recordSet) Fields() ([]*ast.ResultField, error) {
 }
 
 func (a *recordSet) Next() (*ast.Row, error) {
+	if err := checkKilled(a.ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
 	row, err := a.executor.Next()
 	if err != nil || row == nil {
 		return nil, errors.Trace(err)
@@ -72,18 +81,32 @@ func (a *statement) Exec(ctx context.Context) (ast.RecordSet, error) {
 		return nil, errors.Trace(b.err)
 	}
 
+	p := a.plan
 	if executorExec, ok := e.(*ExecuteExec); ok {
 		err := executorExec.Build()
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
 		e = executorExec.StmtExec
+		p = executorExec.Plan
+	}
+	if vars := variable.GetSessionVars(ctx); vars != nil {
+		vars.StmtStats.Plan = p
+	}
+	if explain, ok := e.(*ExplainExec); ok && explain.stmtExec != nil {
+		// EXPLAIN ANALYZE executes the statement before it's committed.
+		if err := explain.analyze(); err != nil {
+			return nil, errors.Trace(err)
+		}
 	}
 
 	if len(e.Fields()) == 0 && len(e.Schema()) == 0 {
 		// No result fields means no Recordset.
 		defer e.Close()
 		for {
+			if err := checkKilled(ctx); err != nil {
+				return nil, errors.Trace(err)
+			}
 			row, err := e.Next()
 			if err != nil {
 				return nil, errors.Trace(err)
@@ -103,5 +126,30 @@ func (a *statement) Exec(ctx context.Context) (ast.RecordSet, error) {
 	return &recordSet{
 		executor: e,
 		fields:   fs,
+		ctx:      ctx,
 	}, nil
 }
+
+// addRowsExamined increases the number of rows examined by the running statement,
+// it's called by the executors which read rows from the storage.
+func addRowsExamined(ctx context.Context) {
+	if vars := variable.GetSessionVars(ctx); vars != nil {
+		atomic.AddUint64(&vars.StmtStats.RowsExamined, 1)
+	}
+}
+
+// checkKilled returns ErrQueryInterrupted if the session is killed,
+// or kv.ErrQueryTimeout if the statement runs longer than its max execution time.
+func checkKilled(ctx context.Context) error {
+	vars := variable.GetSessionVars(ctx)
+	if vars == nil {
+		return nil
+	}
+	if vars.Killed != nil && atomic.LoadUint32(vars.Killed) == 1 {
+		return ErrQueryInterrupted
+	}
+	if !vars.StmtDeadline.IsZero() && time.Now().After(vars.StmtDeadline) {
+		return kv.ErrQueryTimeout
+	}
+	return nil
+}
@@ -65,6 +65,10 @@ func (m *mockExec) Close() error {
 	return nil
 }
 
+func (m *mockExec) Init() {
+	m.curRowIdx = 0
+}
+
 func (s *testAggFuncSuite) TestCount(c *C) {
 	defer testleak.AfterTest(c)()
 	// Compose aggregate exec for "select c1, count(c2) from t";
@@ -43,6 +43,12 @@ func (n *finalAggregater) update(count uint64, value types.Datum) error {
 		return n.updateCount(count)
 	case ast.AggFuncFirstRow:
 		return n.updateFirst(value)
+	case ast.AggFuncSum, ast.AggFuncAvg:
+		return n.updateSum(count, value)
+	case ast.AggFuncMax:
+		return n.updateMaxMin(value, true)
+	case ast.AggFuncMin:
+		return n.updateMaxMin(value, false)
 	}
 	return nil
 }
@@ -75,6 +81,40 @@ func (n *finalAggregater) updateFirst(val types.Datum) error {
 	return nil
 }
 
+func (n *finalAggregater) updateSum(count uint64, val types.Datum) error {
+	ctx := n.getContext()
+	if val.IsNull() {
+		return nil
+	}
+	var err error
+	ctx.Value, err = types.CalculateSum(ctx.Value, val.GetValue())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ctx.Count += int64(count)
+	return nil
+}
+
+func (n *finalAggregater) updateMaxMin(val types.Datum, max bool) error {
+	ctx := n.getContext()
+	if val.IsNull() {
+		return nil
+	}
+	if !ctx.Evaluated {
+		ctx.Value = val.GetValue()
+		ctx.Evaluated = true
+		return nil
+	}
+	c, err := types.Compare(ctx.Value, val.GetValue())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if (max && c < 0) || (!max && c > 0) {
+		ctx.Value = val.GetValue()
+	}
+	return nil
+}
+
 // XAggregateExec deals with all the aggregate functions.
 // It is built from Aggregate Plan. When Next() is called, it reads all the data from Src and updates all the items in AggFuncs.
 // TODO: Support having.
@@ -14,9 +14,13 @@
 package executor
 
 import (
+	"strings"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/evaluator"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
@@ -178,3 +182,66 @@ func (s *testAggFuncSuite) TestXAPIFirstRow(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(val, testutil.DatumEquals, types.NewDatum(nil))
 }
+
+func (s *testAggFuncSuite) TestFinalAggregation(c *C) {
+	defer testleak.AfterTest(c)()
+	// Compose the final aggregation for "select count(c2), avg(c2), max(c2) from t group by c1".
+	// Every partial result row is: group key, count(c2), count and sum of avg(c2), max(c2).
+	var fields []*ast.ResultField
+	for i := 0; i < 5; i++ {
+		fields = append(fields, &ast.ResultField{Expr: ast.NewValueExpr(0)})
+	}
+	dec := func(i int64) mysql.Decimal {
+		return mysql.NewDecimalFromInt(i, 0)
+	}
+	data := []([]types.Datum){
+		// Partial results from region1.
+		types.MakeDatums([]byte{1}, uint64(2), uint64(2), dec(3), 2),
+		types.MakeDatums([]byte{2}, uint64(0), uint64(0), nil, nil),
+		// Partial results from region2.
+		types.MakeDatums([]byte{1}, uint64(1), uint64(1), dec(4), 4),
+	}
+	rows := make([]*Row, 0, len(data))
+	for _, d := range data {
+		rows = append(rows, &Row{Data: d})
+	}
+	col := func(i int) expression.Expression {
+		return &expression.Column{Index: i}
+	}
+	aggFuncs := []expression.AggregationFunction{
+		expression.NewAggFunction(ast.AggFuncCount, []expression.Expression{col(1)}, false),
+		expression.NewAggFunction(ast.AggFuncAvg, []expression.Expression{col(2), col(3)}, false),
+		expression.NewAggFunction(ast.AggFuncMax, []expression.Expression{col(4)}, false),
+	}
+	for _, af := range aggFuncs {
+		af.SetMode(expression.FinalMode)
+	}
+	agg := &AggregationExec{
+		Src:          &mockExec{rows: rows, fields: fields},
+		AggFuncs:     aggFuncs,
+		GroupByItems: []expression.Expression{col(0)},
+		ctx:          mock.NewContext(),
+	}
+	agg.Init()
+	var results []string
+	for {
+		row, err := agg.Next()
+		c.Assert(err, IsNil)
+		if row == nil {
+			break
+		}
+		var strs []string
+		for _, d := range row.Data {
+			if d.IsNull() {
+				strs = append(strs, "<nil>")
+				continue
+			}
+			str, err := d.ToString()
+			c.Assert(err, IsNil)
+			strs = append(strs, str)
+		}
+		results = append(results, strings.Join(strs, " "))
+	}
+	c.Assert(results, DeepEquals, []string{"3 2.3333 4", "0 <nil> <nil>"})
+	c.Assert(agg.Close(), IsNil)
+}
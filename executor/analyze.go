@@ -0,0 +1,95 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/plan/statistics"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+const (
+	// maxSampleSize is the max number of the rows sampled by ANALYZE TABLE.
+	maxSampleSize = 10000
+	// defaultBucketCount is the number of the histogram buckets built by ANALYZE TABLE.
+	defaultBucketCount = 256
+)
+
+// analyzeTable samples the rows of the table, builds the statistics of the columns and
+// indices and saves them. A sampled row has the column values followed by the encoded index values.
+func analyzeTable(ctx context.Context, t table.Table) error {
+	txn, err := ctx.GetTxn(false)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	tblInfo := t.Meta()
+	var indices []*model.IndexInfo
+	for _, idx := range tblInfo.Indices {
+		if idx.State == model.StatePublic {
+			indices = append(indices, idx)
+		}
+	}
+	cols := t.Cols()
+	collector := statistics.NewSampleCollector(maxSampleSize)
+	err = t.IterRecords(ctx, t.FirstKey(), cols, func(h int64, data []types.Datum, cols []*table.Column) (bool, error) {
+		row := make([]types.Datum, 0, len(data)+len(indices))
+		row = append(row, data...)
+		for _, idx := range indices {
+			vals := make([]types.Datum, len(idx.Columns))
+			for i, ic := range idx.Columns {
+				vals[i] = data[ic.Offset]
+			}
+			key, err := codec.EncodeKey(nil, vals...)
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			row = append(row, types.NewBytesDatum(key))
+		}
+		collector.Collect(row)
+		return true, nil
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	// The columns and indices which are not public have no samples.
+	columnSamples := make([][]types.Datum, len(tblInfo.Columns))
+	for i, col := range cols {
+		columnSamples[col.Offset] = collector.ColumnSamples(i)
+	}
+	indexSamples := make([][]types.Datum, len(tblInfo.Indices))
+	offset := len(cols)
+	for i, idx := range tblInfo.Indices {
+		if idx.State == model.StatePublic {
+			indexSamples[i] = collector.ColumnSamples(offset)
+			offset++
+		}
+	}
+	tbl, err := statistics.NewTable(tblInfo, int64(txn.StartTS()), collector.Count, defaultBucketCount, columnSamples, indexSamples)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err = statistics.SaveToStorage(ctx, tbl); err != nil {
+		return errors.Trace(err)
+	}
+	if h := sessionctx.GetDomain(ctx).StatsHandle(); h != nil {
+		h.UpdateTableStats(tbl)
+	}
+	return nil
+}
@@ -41,6 +41,16 @@ type executorBuilder struct {
 	ctx context.Context
 	is  infoschema.InfoSchema
 	err error
+
+	// analyzing is true if the executors are built for EXPLAIN ANALYZE, they're wrapped to collect the runtime
+	// statistics.
+	analyzing bool
+	// runtimeStats are the statistics of the built executors whose parents aren't built yet.
+	runtimeStats []*runtimeStats
+	// planStats are the statistics of the plans which the executors are built for.
+	planStats map[plan.Plan]*runtimeStats
+	// lazyParents are the statistics of the executors which build the executors of the plans when they're executed.
+	lazyParents map[plan.Plan]*runtimeStats
 }
 
 func newExecutorBuilder(ctx context.Context, is infoschema.InfoSchema) *executorBuilder {
@@ -51,6 +61,18 @@ func newExecutorBuilder(ctx context.Context, is infoschema.InfoSchema) *executor
 }
 
 func (b *executorBuilder) build(p plan.Plan) Executor {
+	if !b.analyzing {
+		return b.buildPlan(p)
+	}
+	mark := len(b.runtimeStats)
+	e := b.buildPlan(p)
+	if e == nil {
+		return nil
+	}
+	return b.analyze(p, e, mark)
+}
+
+func (b *executorBuilder) buildPlan(p plan.Plan) Executor {
 	switch v := p.(type) {
 	case nil:
 		return nil
@@ -93,6 +115,10 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildSelectLock(v)
 	case *plan.ShowDDL:
 		return b.buildShowDDL(v)
+	case *plan.ShowDDLJobs:
+		return b.buildShowDDLJobs(v)
+	case *plan.CancelDDLJobs:
+		return b.buildCancelDDLJobs(v)
 	case *plan.Show:
 		return b.buildShow(v)
 	case *plan.Simple:
@@ -148,12 +174,22 @@ func (b *executorBuilder) buildTableDual(v *plan.TableDual) Executor {
 }
 
 func (b *executorBuilder) buildTableScan(v *plan.TableScan) Executor {
+	tbl, _ := b.is.TableByID(v.Table.ID)
+	if pt, ok := tbl.(table.PartitionedTable); ok {
+		return b.buildPartitionUnion(pt, v.Partitions, v.Fields(), func(p table.Table) Executor {
+			return b.buildTableScanExec(v, p, pt)
+		})
+	}
+	return b.buildTableScanExec(v, tbl, nil)
+}
+
+// buildTableScanExec builds the scan of the table, the table is a partition of pt if the table is partitioned.
+func (b *executorBuilder) buildTableScanExec(v *plan.TableScan, table table.Table, pt table.PartitionedTable) Executor {
 	txn, err := b.ctx.GetTxn(false)
 	if err != nil {
 		b.err = err
 		return nil
 	}
-	table, _ := b.is.TableByID(v.Table.ID)
 	client := txn.GetClient()
 	var memDB bool
 	switch v.Fields()[0].DBName.L {
@@ -180,7 +216,7 @@ func (b *executorBuilder) buildTableScan(v *plan.TableScan) Executor {
 		if txn.IsReadOnly() {
 			ex = e
 		} else {
-			ex = b.buildUnionScanExec(e)
+			ex = b.buildUnionScanExec(e, pt)
 		}
 		return b.buildFilter(ex, remained)
 	}
@@ -207,6 +243,22 @@ func (b *executorBuilder) buildShowDDL(v *plan.ShowDDL) Executor {
 	}
 }
 
+func (b *executorBuilder) buildShowDDLJobs(v *plan.ShowDDLJobs) Executor {
+	return &ShowDDLJobsExec{
+		fields:    v.Fields(),
+		ctx:       b.ctx,
+		jobNumber: v.JobNumber,
+	}
+}
+
+func (b *executorBuilder) buildCancelDDLJobs(v *plan.CancelDDLJobs) Executor {
+	return &CancelDDLJobsExec{
+		fields: v.Fields(),
+		ctx:    b.ctx,
+		jobIDs: v.JobIDs,
+	}
+}
+
 func (b *executorBuilder) buildCheckTable(v *plan.CheckTable) Executor {
 	return &CheckTableExec{
 		tables: v.Tables,
@@ -222,12 +274,23 @@ func (b *executorBuilder) buildDeallocate(v *plan.Deallocate) Executor {
 }
 
 func (b *executorBuilder) buildIndexScan(v *plan.IndexScan) Executor {
+	tbl, _ := b.is.TableByID(v.Table.ID)
+	if pt, ok := tbl.(table.PartitionedTable); ok {
+		return b.buildPartitionUnion(pt, v.Partitions, v.Fields(), func(p table.Table) Executor {
+			return b.buildIndexScanExec(v, p, pt)
+		})
+	}
+	return b.buildIndexScanExec(v, tbl, nil)
+}
+
+// buildIndexScanExec builds the scan of the index of the table, the table is a partition of pt if the table is
+// partitioned.
+func (b *executorBuilder) buildIndexScanExec(v *plan.IndexScan, tbl table.Table, pt table.PartitionedTable) Executor {
 	txn, err := b.ctx.GetTxn(false)
 	if err != nil {
 		b.err = err
 		return nil
 	}
-	tbl, _ := b.is.TableByID(v.Table.ID)
 	client := txn.GetClient()
 	supportDesc := client.SupportRequestType(kv.ReqTypeIndex, kv.ReqSubTypeDesc)
 	var memDB bool
@@ -251,7 +314,7 @@ func (b *executorBuilder) buildIndexScan(v *plan.IndexScan) Executor {
 		if txn.IsReadOnly() {
 			ex = e
 		} else {
-			ex = b.buildUnionScanExec(e)
+			ex = b.buildUnionScanExec(e, pt)
 		}
 		return b.buildFilter(ex, remained)
 	}
@@ -367,16 +430,28 @@ func (b *executorBuilder) buildSelectFields(v *plan.SelectFields) Executor {
 
 func (b *executorBuilder) buildAggregate(v *plan.Aggregate) Executor {
 	src := b.build(v.GetChildByIndex(0))
-	e := &AggregateExec{
+	if v.PushDownHint != plan.NoAggToCop {
+		if xe := b.pushDownAggregate(v, src); xe != nil || b.err != nil {
+			return xe
+		}
+	}
+	if v.PushDownHint == plan.AggToCop {
+		b.appendInapplicableHintWarning(v.PushDownHint)
+	}
+	return &AggregateExec{
 		Src:          src,
 		ResultFields: v.Fields(),
 		ctx:          b.ctx,
 		AggFuncs:     v.AggFuncs,
 		GroupByItems: v.GroupByItems,
 	}
-	xSrc, ok := src.(XExecutor)
+}
+
+// pushDownAggregate pushes the aggregation down to the source, it returns nil if the aggregation can't be pushed down.
+func (b *executorBuilder) pushDownAggregate(v *plan.Aggregate, src Executor) Executor {
+	xSrc, ok := unwrapExec(src).(XExecutor)
 	if !ok {
-		return e
+		return nil
 	}
 	txn, err := b.ctx.GetTxn(false)
 	if err != nil {
@@ -385,7 +460,7 @@ func (b *executorBuilder) buildAggregate(v *plan.Aggregate) Executor {
 	}
 	client := txn.GetClient()
 	if len(v.GroupByItems) > 0 && !client.SupportRequestType(kv.ReqTypeSelect, kv.ReqSubTypeGroupBy) {
-		return e
+		return nil
 	}
 	log.Debugf("Use XAggregateExec with %d aggs", len(v.AggFuncs))
 	// Convert aggregate function exprs to pb.
@@ -393,11 +468,11 @@ func (b *executorBuilder) buildAggregate(v *plan.Aggregate) Executor {
 	for _, af := range v.AggFuncs {
 		if af.Distinct {
 			// We do not support distinct push down.
-			return e
+			return nil
 		}
 		pbAggFunc := b.aggFuncToPBExpr(client, af, xSrc.GetTableName())
 		if pbAggFunc == nil {
-			return e
+			return nil
 		}
 		pbAggFuncs = append(pbAggFuncs, pbAggFunc)
 	}
@@ -406,7 +481,7 @@ func (b *executorBuilder) buildAggregate(v *plan.Aggregate) Executor {
 	for _, item := range v.GroupByItems {
 		pbByItem := b.groupByItemToPB(client, item, xSrc.GetTableName())
 		if pbByItem == nil {
-			return e
+			return nil
 		}
 		pbByItems = append(pbByItems, pbByItem)
 	}
@@ -435,7 +510,11 @@ func (b *executorBuilder) buildAggregate(v *plan.Aggregate) Executor {
 		}
 		if needValue(name) {
 			// value partial result field
-			fields = append(fields, agg.GetType())
+			if name == ast.AggFuncSum || name == ast.AggFuncAvg {
+				fields = append(fields, partialSumType(agg.Args[0].GetType()))
+			} else {
+				fields = append(fields, agg.GetType())
+			}
 		}
 	}
 	xSrc.AddAggregate(pbAggFuncs, pbByItems, fields)
@@ -450,6 +529,12 @@ func (b *executorBuilder) buildAggregate(v *plan.Aggregate) Executor {
 	return xe
 }
 
+// appendInapplicableHintWarning appends the warning of the hint which can't be applied to the statement.
+func (b *executorBuilder) appendInapplicableHintWarning(hintName string) {
+	err := plan.ErrInapplicableHint.Gen("Optimizer hint %s() is inapplicable", strings.ToUpper(hintName))
+	variable.GetSessionVars(b.ctx).AppendWarning(err)
+}
+
 func (b *executorBuilder) buildHaving(v *plan.Having) Executor {
 	src := b.build(v.GetChildByIndex(0))
 	return b.buildFilter(src, v.Conditions)
@@ -467,7 +552,16 @@ func (b *executorBuilder) buildSort(v *plan.Sort) Executor {
 }
 
 func (b *executorBuilder) buildLimit(v *plan.Limit) Executor {
+	// The rows beyond offset+count are never needed, unless it overflows.
+	totalCount := v.Offset + v.Count
+	limited := totalCount >= v.Offset
+	if sort, ok := v.GetChildByIndex(0).(*plan.NewSort); ok && limited && totalCount <= maxTopNCount {
+		return b.buildTopN(v, sort, int(totalCount))
+	}
 	src := b.build(v.GetChildByIndex(0))
+	if tableScan := limitScanOf(src); tableScan != nil && limited {
+		b.pushDownLimit(tableScan, nil, totalCount)
+	}
 	e := &LimitExec{
 		Src:    src,
 		Offset: v.Offset,
@@ -618,20 +712,36 @@ func (b *executorBuilder) buildDDL(v *plan.DDL) Executor {
 }
 
 func (b *executorBuilder) buildExplain(v *plan.Explain) Executor {
-	return &ExplainExec{
+	e := &ExplainExec{
 		StmtPlan: v.StmtPlan,
 		fields:   v.Fields(),
 	}
+	if v.Analyze {
+		sb := newExecutorBuilder(b.ctx, b.is)
+		sb.analyzing = true
+		sb.planStats = make(map[plan.Plan]*runtimeStats)
+		sb.lazyParents = make(map[plan.Plan]*runtimeStats)
+		e.stmtExec = sb.build(v.StmtPlan)
+		if sb.err != nil {
+			b.err = errors.Trace(sb.err)
+			return nil
+		}
+		e.ctx = b.ctx
+		e.stmtStats = sb.runtimeStats[0]
+		e.format = v.Format
+	}
+	return e
 }
 
 // buildUnionScanExec builds a union scan executor, the src Executor is either
-// *XSelectTableExec or *XSelectIndexExec.
-func (b *executorBuilder) buildUnionScanExec(src Executor) *UnionScanExec {
-	us := &UnionScanExec{ctx: b.ctx, Src: src}
+// *XSelectTableExec or *XSelectIndexExec. If src reads a partition of the partitioned table pt, the rows of the
+// partition in the dirty table of pt are merged.
+func (b *executorBuilder) buildUnionScanExec(src Executor, pt table.PartitionedTable) *UnionScanExec {
+	us := &UnionScanExec{ctx: b.ctx, Src: src, partitioned: pt}
 	switch x := src.(type) {
 	case *XSelectTableExec:
 		us.desc = x.tablePlan.Desc
-		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.table.Meta().ID)
+		us.dirty = getDirtyDB(b.ctx).getDirtyTable(us.dirtyTableID(x.table))
 		us.condition = b.joinConditions(append(x.tablePlan.AccessConditions, x.tablePlan.FilterConditions...))
 		us.buildAndSortAddedRows(x.table, x.tablePlan.TableAsName)
 	case *XSelectIndexExec:
@@ -639,7 +749,7 @@ func (b *executorBuilder) buildUnionScanExec(src Executor) *UnionScanExec {
 		for _, ic := range x.indexPlan.Index.Columns {
 			us.usedIndex = append(us.usedIndex, ic.Offset)
 		}
-		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.table.Meta().ID)
+		us.dirty = getDirtyDB(b.ctx).getDirtyTable(us.dirtyTableID(x.table))
 		us.condition = b.joinConditions(append(x.indexPlan.AccessConditions, x.indexPlan.FilterConditions...))
 		us.buildAndSortAddedRows(x.table, x.indexPlan.TableAsName)
 	default:
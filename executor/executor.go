@@ -14,6 +14,7 @@
 package executor
 
 import (
+	"fmt"
 	"sort"
 
 	"github.com/juju/errors"
@@ -25,6 +26,7 @@ import (
 	"github.com/pingcap/tidb/inspectkv"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/db"
@@ -46,6 +48,8 @@ var (
 	_ Executor = &SelectFieldsExec{}
 	_ Executor = &SelectLockExec{}
 	_ Executor = &ShowDDLExec{}
+	_ Executor = &ShowDDLJobsExec{}
+	_ Executor = &CancelDDLJobsExec{}
 	_ Executor = &SortExec{}
 	_ Executor = &TableDualExec{}
 	_ Executor = &TableScanExec{}
@@ -59,6 +63,10 @@ var (
 	ErrSchemaChanged   = terror.ClassExecutor.New(CodeSchemaChanged, "Schema has changed")
 	ErrWrongParamCount = terror.ClassExecutor.New(CodeWrongParamCount, "Wrong parameter count")
 	ErrRowKeyCount     = terror.ClassExecutor.New(CodeRowKeyCount, "Wrong row key entry count")
+	// ErrQueryInterrupted is returned when the running statement is killed.
+	ErrQueryInterrupted = terror.ClassExecutor.New(CodeQueryInterrupted, "Query execution was interrupted")
+	ErrNoSuchThread     = terror.ClassExecutor.New(CodeNoSuchThread, "Unknown thread id")
+	ErrKillDenied       = terror.ClassExecutor.New(CodeKillDenied, "You are not owner of thread")
 )
 
 // Error codes.
@@ -69,6 +77,11 @@ const (
 	CodeSchemaChanged   terror.ErrCode = 4
 	CodeWrongParamCount terror.ErrCode = 5
 	CodeRowKeyCount     terror.ErrCode = 6
+
+	// MySQL error code
+	CodeNoSuchThread     terror.ErrCode = 1094
+	CodeKillDenied       terror.ErrCode = 1095
+	CodeQueryInterrupted terror.ErrCode = 1317
 )
 
 // Row represents a record row.
@@ -143,13 +156,18 @@ func (e *ShowDDLExec) Next() (*Row, error) {
 		return nil, errors.Trace(err)
 	}
 
-	var ddlOwner, ddlJob string
+	var ddlOwner, ddlJob, reorgProgress string
 	if ddlInfo.Owner != nil {
 		ddlOwner = ddlInfo.Owner.String()
 	}
 	if ddlInfo.Job != nil {
 		ddlJob = ddlInfo.Job.String()
 	}
+	if len(ddlInfo.ReorgChunks) > 0 {
+		// the rows reorganized and the estimated total rows.
+		done, total := model.ReorgProgress(ddlInfo.ReorgChunks)
+		reorgProgress = fmt.Sprintf("%d/%d", done, total)
+	}
 
 	var bgOwner, bgJob string
 	if bgInfo.Owner != nil {
@@ -167,6 +185,7 @@ func (e *ShowDDLExec) Next() (*Row, error) {
 		bgInfo.SchemaVer,
 		bgOwner,
 		bgJob,
+		reorgProgress,
 	)
 	for i, f := range e.fields {
 		f.Expr.SetValue(row.Data[i].GetValue())
@@ -181,6 +200,119 @@ func (e *ShowDDLExec) Close() error {
 	return nil
 }
 
+// ShowDDLJobsExec represents a show DDL jobs executor.
+type ShowDDLJobsExec struct {
+	fields    []*ast.ResultField
+	ctx       context.Context
+	jobNumber int64
+	rows      []*Row
+	cursor    int
+}
+
+// Schema implements Executor Schema interface.
+func (e *ShowDDLJobsExec) Schema() expression.Schema {
+	return nil
+}
+
+// Fields implements Executor Fields interface.
+func (e *ShowDDLJobsExec) Fields() []*ast.ResultField {
+	return e.fields
+}
+
+// Next implements Executor Next interface.
+// The jobs in the queue are shown first, then the recent history jobs, the latest job is the first.
+func (e *ShowDDLJobsExec) Next() (*Row, error) {
+	if e.rows == nil {
+		txn, err := e.ctx.GetTxn(false)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		jobs, err := inspectkv.GetDDLJobs(txn)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		historyJobs, err := inspectkv.GetHistoryDDLJobs(txn, e.jobNumber)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+
+		e.rows = make([]*Row, 0, len(jobs)+len(historyJobs))
+		for _, job := range append(jobs, historyJobs...) {
+			e.rows = append(e.rows, &Row{Data: types.MakeDatums(job.String(), job.State.String())})
+		}
+	}
+
+	if e.cursor >= len(e.rows) {
+		return nil, nil
+	}
+	row := e.rows[e.cursor]
+	for i, f := range e.fields {
+		f.Expr.SetValue(row.Data[i].GetValue())
+	}
+	e.cursor++
+
+	return row, nil
+}
+
+// Close implements Executor Close interface.
+func (e *ShowDDLJobsExec) Close() error {
+	return nil
+}
+
+// CancelDDLJobsExec represents a cancel DDL jobs executor.
+type CancelDDLJobsExec struct {
+	fields []*ast.ResultField
+	ctx    context.Context
+	jobIDs []int64
+	errs   []error
+	cursor int
+}
+
+// Schema implements Executor Schema interface.
+func (e *CancelDDLJobsExec) Schema() expression.Schema {
+	return nil
+}
+
+// Fields implements Executor Fields interface.
+func (e *CancelDDLJobsExec) Fields() []*ast.ResultField {
+	return e.fields
+}
+
+// Next implements Executor Next interface.
+// Every row is the result of cancelling a job, the DDL worker rolls back the cancelled jobs.
+func (e *CancelDDLJobsExec) Next() (*Row, error) {
+	if e.errs == nil {
+		txn, err := e.ctx.GetTxn(false)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		e.errs, err = inspectkv.CancelJobs(txn, e.jobIDs)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	if e.cursor >= len(e.jobIDs) {
+		return nil, nil
+	}
+	result := "successful"
+	if err := e.errs[e.cursor]; err != nil {
+		result = err.Error()
+	}
+	row := &Row{Data: types.MakeDatums(e.jobIDs[e.cursor], result)}
+	for i, f := range e.fields {
+		f.Expr.SetValue(row.Data[i].GetValue())
+	}
+	e.cursor++
+
+	return row, nil
+}
+
+// Close implements Executor Close interface.
+func (e *CancelDDLJobsExec) Close() error {
+	return nil
+}
+
 // CheckTableExec represents a check table executor.
 type CheckTableExec struct {
 	tables []*ast.TableName
@@ -212,14 +344,21 @@ func (e *CheckTableExec) Next() (*Row, error) {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		for _, idx := range tb.Indices() {
-			txn, err := e.ctx.GetTxn(false)
-			if err != nil {
-				return nil, errors.Trace(err)
-			}
-			err = inspectkv.CompareIndexData(txn, tb, idx)
-			if err != nil {
-				return nil, errors.Errorf("%v err:%v", t.Name, err)
+		tbs := []table.Table{tb}
+		if pt, ok := tb.(table.PartitionedTable); ok {
+			// The index data of a partition is checked with the rows of the partition.
+			tbs = pt.Partitions()
+		}
+		for _, tb := range tbs {
+			for _, idx := range tb.Indices() {
+				txn, err := e.ctx.GetTxn(false)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+				err = inspectkv.CompareIndexData(txn, tb, idx)
+				if err != nil {
+					return nil, errors.Errorf("%v err:%v", t.Name, err)
+				}
 			}
 		}
 	}
@@ -287,6 +426,9 @@ func (e *TableScanExec) Fields() []*ast.ResultField {
 
 // Next implements Executor Next interface.
 func (e *TableScanExec) Next() (*Row, error) {
+	if err := checkKilled(e.ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
 	for {
 		if e.cursor >= len(e.ranges) {
 			return nil, nil
@@ -322,6 +464,7 @@ func (e *TableScanExec) Next() (*Row, error) {
 			return nil, errors.Trace(err)
 		}
 		e.seekHandle = handle + 1
+		addRowsExamined(e.ctx)
 		return row, nil
 	}
 }
@@ -545,6 +688,9 @@ func (e *IndexScanExec) Fields() []*ast.ResultField {
 
 // Next implements Executor Next interface.
 func (e *IndexScanExec) Next() (*Row, error) {
+	if err := checkKilled(e.ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
 	for e.rangeIdx < len(e.Ranges) {
 		ran := e.Ranges[e.rangeIdx]
 		row, err := ran.Next()
@@ -552,6 +698,7 @@ func (e *IndexScanExec) Next() (*Row, error) {
 			return nil, errors.Trace(err)
 		}
 		if row != nil {
+			addRowsExamined(e.ctx)
 			for i, val := range row.Data {
 				e.fields[i].Expr.SetValue(val.GetValue())
 			}
@@ -932,7 +1079,7 @@ func (e *LimitExec) Next() (*Row, error) {
 		}
 		e.Idx++
 	}
-	if e.Idx >= e.Count+e.Offset {
+	if e.Idx-e.Offset >= e.Count {
 		return nil, nil
 	}
 	srcRow, err := e.Src.Next()
@@ -1374,6 +1521,12 @@ func (e *ReverseExec) Close() error {
 }
 
 func init() {
+	executorMySQLErrCodes := map[terror.ErrCode]uint16{
+		CodeNoSuchThread:     mysql.ErrNoSuchThread,
+		CodeKillDenied:       mysql.ErrKillDenied,
+		CodeQueryInterrupted: mysql.ErrQueryInterrupted,
+	}
+	terror.ErrClassToMySQLCodes[terror.ClassExecutor] = executorMySQLErrCodes
 	plan.EvalSubquery = func(p plan.Plan, is infoschema.InfoSchema, ctx context.Context) (d []types.Datum, err error) {
 		e := &executorBuilder{is: is, ctx: ctx}
 		exec := e.build(p)
@@ -67,6 +67,8 @@ func (e *DDLExec) Next() (*Row, error) {
 		err = e.executeDropTable(x)
 	case *ast.DropIndexStmt:
 		err = e.executeDropIndex(x)
+	case *ast.RenameTableStmt:
+		err = e.executeRenameTable(x)
 	case *ast.AlterTableStmt:
 		err = e.executeAlterTable(x)
 	}
@@ -119,7 +121,7 @@ func (e *DDLExec) executeCreateDatabase(s *ast.CreateDatabaseStmt) error {
 
 func (e *DDLExec) executeCreateTable(s *ast.CreateTableStmt) error {
 	ident := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
-	err := sessionctx.GetDomain(e.ctx).DDL().CreateTable(e.ctx, ident, s.Cols, s.Constraints, s.Options)
+	err := sessionctx.GetDomain(e.ctx).DDL().CreateTable(e.ctx, ident, s.Cols, s.Constraints, s.Options, s.Partition)
 	if terror.ErrorEqual(err, infoschema.ErrTableExists) {
 		if s.IfNotExists {
 			return nil
@@ -197,7 +199,62 @@ func (e *DDLExec) executeDropIndex(s *ast.DropIndexStmt) error {
 	return errors.Trace(err)
 }
 
+func (e *DDLExec) executeRenameTable(s *ast.RenameTableStmt) error {
+	oldIdents := make([]ast.Ident, 0, len(s.TableToTables))
+	newIdents := make([]ast.Ident, 0, len(s.TableToTables))
+	for _, tt := range s.TableToTables {
+		if err := e.checkRenameTablePriv(tt.OldTable, tt.NewTable); err != nil {
+			return errors.Trace(err)
+		}
+		oldIdents = append(oldIdents, ast.Ident{Schema: tt.OldTable.Schema, Name: tt.OldTable.Name})
+		newIdents = append(newIdents, ast.Ident{Schema: tt.NewTable.Schema, Name: tt.NewTable.Name})
+	}
+	err := sessionctx.GetDomain(e.ctx).DDL().RenameTable(e.ctx, oldIdents, newIdents)
+	return errors.Trace(err)
+}
+
+// checkRenameTablePriv checks the privileges to rename the table, like MySQL, ALTER and DROP privileges are
+// required on the old table, CREATE and INSERT privileges are required on the new table.
+// The tables which don't exist are checked by DDL.
+func (e *DDLExec) checkRenameTablePriv(oldTable, newTable *ast.TableName) error {
+	privChecker := privilege.GetPrivilegeChecker(e.ctx)
+	if schema, ok := e.is.SchemaByName(oldTable.Schema); ok {
+		tb, err := e.is.TableByName(oldTable.Schema, oldTable.Name)
+		if err == nil {
+			for _, priv := range []mysql.PrivilegeType{mysql.AlterPriv, mysql.DropPriv} {
+				hasPriv, err := privChecker.Check(e.ctx, schema, tb.Meta(), priv)
+				if err != nil {
+					return errors.Trace(err)
+				}
+				if !hasPriv {
+					return errors.Errorf("You do not have the privilege to rename table %s.%s.", oldTable.Schema, oldTable.Name)
+				}
+			}
+		}
+	}
+	if schema, ok := e.is.SchemaByName(newTable.Schema); ok {
+		tblInfo := &model.TableInfo{Name: newTable.Name}
+		for _, priv := range []mysql.PrivilegeType{mysql.CreatePriv, mysql.InsertPriv} {
+			hasPriv, err := privChecker.Check(e.ctx, schema, tblInfo, priv)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if !hasPriv {
+				return errors.Errorf("You do not have the privilege to rename table to %s.%s.", newTable.Schema, newTable.Name)
+			}
+		}
+	}
+	return nil
+}
+
 func (e *DDLExec) executeAlterTable(s *ast.AlterTableStmt) error {
+	for _, spec := range s.Specs {
+		if spec.Tp == ast.AlterTableRenameTable {
+			if err := e.checkRenameTablePriv(s.Table, spec.NewTable); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
 	ti := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
 	err := sessionctx.GetDomain(e.ctx).DDL().AlterTable(e.ctx, ti, s.Specs)
 	return errors.Trace(err)
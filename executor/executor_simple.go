@@ -82,6 +82,8 @@ func (e *SimpleExec) Next() (*Row, error) {
 		err = e.executeSetPwd(x)
 	case *ast.AnalyzeTableStmt:
 		err = e.executeAnalyzeTable(x)
+	case *ast.KillStmt:
+		err = e.executeKill(x)
 	}
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -166,6 +168,9 @@ func (e *SimpleExec) executeSet(s *ast.SetStmt) error {
 			if err != nil {
 				return errors.Trace(err)
 			}
+			if err = variable.ValidateSysVar(name, svalue); err != nil {
+				return errors.Trace(err)
+			}
 			err = globalVars.SetGlobalSysVar(e.ctx, name, svalue)
 			if err != nil {
 				return errors.Trace(err)
@@ -179,6 +184,15 @@ func (e *SimpleExec) executeSet(s *ast.SetStmt) error {
 			if err != nil {
 				return errors.Trace(err)
 			}
+			if !value.IsNull() {
+				svalue, err1 := value.ToString()
+				if err1 != nil {
+					return errors.Trace(err1)
+				}
+				if err = variable.ValidateSysVar(name, svalue); err != nil {
+					return errors.Trace(err)
+				}
+			}
 			err = sessionVars.SetSystemVar(name, value)
 			if err != nil {
 				return errors.Trace(err)
@@ -286,6 +300,34 @@ func parseUser(user string) (string, string) {
 	return strs[0], strs[1]
 }
 
+// executeKill kills the connection s.ConnectionID, or only its running statement for KILL QUERY.
+func (e *SimpleExec) executeKill(s *ast.KillStmt) error {
+	sm := util.GetSessionManager(e.ctx)
+	if sm == nil {
+		// The session is not created by a server, there is no connection to kill.
+		return ErrNoSuchThread.Gen("Unknown thread id: %d", s.ConnectionID)
+	}
+	var target *util.ProcessInfo
+	for _, pi := range sm.ShowProcessList() {
+		if pi.ID == s.ConnectionID {
+			target = &pi
+			break
+		}
+	}
+	if target == nil {
+		return ErrNoSuchThread.Gen("Unknown thread id: %d", s.ConnectionID)
+	}
+	// There is no SUPER privilege yet, so only the connections of the same user can be killed.
+	userName := strings.Split(variable.GetSessionVars(e.ctx).User, "@")[0]
+	if target.User != userName {
+		return ErrKillDenied.Gen("You are not owner of thread %d", s.ConnectionID)
+	}
+	if !sm.Kill(s.ConnectionID, s.Query) {
+		return ErrNoSuchThread.Gen("Unknown thread id: %d", s.ConnectionID)
+	}
+	return nil
+}
+
 func userExists(ctx context.Context, name string, host string) (bool, error) {
 	sql := fmt.Sprintf(`SELECT * FROM %s.%s WHERE User="%s" AND Host="%s";`, mysql.SystemDB, mysql.UserTable, name, host)
 	rs, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
@@ -310,6 +352,15 @@ func (e *SimpleExec) executeSetPwd(s *ast.SetPwdStmt) error {
 }
 
 func (e *SimpleExec) executeAnalyzeTable(s *ast.AnalyzeTableStmt) error {
-	// TODO: implement analyze table.
+	is := sessionctx.GetDomain(e.ctx).InfoSchema()
+	for _, tn := range s.TableNames {
+		t, err := is.TableByName(tn.Schema, tn.Name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = analyzeTable(e.ctx, t); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	return nil
 }
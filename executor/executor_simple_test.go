@@ -15,11 +15,19 @@ package executor_test
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	stats "github.com/pingcap/tidb/plan/statistics"
+	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
@@ -192,4 +200,173 @@ func (s *testSuite) TestAnalyzeTable(c *C) {
 	defer testleak.AfterTest(c)()
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec(`ANALYZE TABLE mysql.User`)
+
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b varchar(10), index b (b))")
+	tk.MustExec(`insert t values (1, "x"), (2, "y"), (2, NULL), (NULL, "x")`)
+	tk.MustExec("analyze table t")
+
+	do := sessionctx.GetDomain(tk.Se.(context.Context))
+	tblInfo, err := do.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	tableID := tblInfo.Meta().ID
+	tbl := do.StatsHandle().GetTableStats(tableID)
+	c.Assert(tbl, NotNil)
+	c.Assert(tbl.Count, Equals, int64(4))
+	c.Assert(tbl.Columns, HasLen, 2)
+	c.Assert(tbl.Columns[0].NDV, Equals, int64(2))
+	c.Assert(tbl.Columns[0].NullCount, Equals, int64(1))
+	c.Assert(tbl.Columns[1].NullCount, Equals, int64(1))
+	c.Assert(tbl.Indices, HasLen, 1)
+	c.Assert(tbl.Indices[0].NDV, Equals, int64(3))
+	tk.MustQuery(fmt.Sprintf("select count from mysql.stats_meta where table_id = %d", tableID)).Check(testkit.Rows("4"))
+
+	// The statistics are persisted, a new handle loads them.
+	se, err := tidb.CreateSession(s.store)
+	c.Assert(err, IsNil)
+	h := stats.NewHandle(se.(context.Context))
+	c.Assert(h.Update(do.InfoSchema()), IsNil)
+	loaded := h.GetTableStats(tableID)
+	c.Assert(loaded, NotNil)
+	c.Assert(loaded.String(), Equals, tbl.String())
+
+	// Analyze again, the handle only loads the new version.
+	tk.MustExec(`insert t values (3, "z")`)
+	tk.MustExec("analyze table t")
+	c.Assert(h.Update(do.InfoSchema()), IsNil)
+	c.Assert(h.GetTableStats(tableID).Count, Equals, int64(5))
+	c.Assert(do.StatsHandle().GetTableStats(tableID).Count, Equals, int64(5))
+
+	// The statistics committed late with a smaller version are loaded too.
+	tk.MustExec("create table t1 (a int)")
+	tk.MustExec("insert t1 values (1)")
+	tk.MustExec("analyze table t1")
+	tblInfo, err = do.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t1"))
+	c.Assert(err, IsNil)
+	tk.MustExec(fmt.Sprintf("update mysql.stats_histograms set version = 1 where table_id = %d", tblInfo.Meta().ID))
+	c.Assert(h.Update(do.InfoSchema()), IsNil)
+	c.Assert(h.GetTableStats(tblInfo.Meta().ID), NotNil)
+	tk.MustExec("drop table t, t1")
+}
+
+func (s *testSuite) TestAutoAnalyze(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int)")
+	do := sessionctx.GetDomain(tk.Se.(context.Context))
+	h := do.StatsHandle()
+
+	tk.MustExec("insert t values (1), (2), (3), (4)")
+	c.Assert(h.DumpStatsDeltaToKV(), IsNil)
+	tk.MustExec("analyze table t")
+	rows := tk.MustQuery("show stats_meta where table_name = 't'").Rows()
+	c.Assert(rows, HasLen, 1)
+	c.Assert(rows[0][0], Equals, "test")
+	c.Assert(rows[0][3], Equals, int64(0))
+	c.Assert(rows[0][4], Equals, int64(4))
+
+	// The changes of the rolled back transaction are discarded.
+	tk.MustExec("begin")
+	tk.MustExec("insert t values (5)")
+	tk.MustExec("rollback")
+	tk.MustExec("update t set a = a + 10 where a < 3")
+	tk.MustExec("delete from t where a = 4")
+	c.Assert(h.DumpStatsDeltaToKV(), IsNil)
+	rows = tk.MustQuery("show stats_meta where table_name = 't'").Rows()
+	c.Assert(rows[0][3], Equals, int64(3))
+	c.Assert(rows[0][4], Equals, int64(3))
+
+	// The modify count exceeds the default ratio 0.5 of the row count.
+	c.Assert(h.HandleAutoAnalyze(do.InfoSchema()), IsNil)
+	rows = tk.MustQuery("show stats_meta where table_name = 't'").Rows()
+	c.Assert(rows[0][3], Equals, int64(0))
+	c.Assert(rows[0][4], Equals, int64(3))
+
+	tk.MustExec("set @@global.tidb_auto_analyze_ratio = 0")
+	tk.MustExec("insert t values (5), (6), (7), (8)")
+	c.Assert(h.DumpStatsDeltaToKV(), IsNil)
+	c.Assert(h.HandleAutoAnalyze(do.InfoSchema()), IsNil)
+	rows = tk.MustQuery("show stats_meta where table_name = 't'").Rows()
+	c.Assert(rows[0][3], Equals, int64(4))
+	c.Assert(rows[0][4], Equals, int64(7))
+	tk.MustExec("set @@global.tidb_auto_analyze_ratio = 0.5")
+
+	// The changes of all the tables are kept if they fail to be dumped.
+	tk.MustExec("create table t1 (a int)")
+	tk.MustExec("insert t values (9)")
+	tk.MustExec("insert t1 values (1), (2)")
+	tk.MustExec("rename table mysql.stats_meta to mysql.stats_meta_bak")
+	c.Assert(h.DumpStatsDeltaToKV(), NotNil)
+	tk.MustExec("rename table mysql.stats_meta_bak to mysql.stats_meta")
+	c.Assert(h.DumpStatsDeltaToKV(), IsNil)
+	rows = tk.MustQuery("show stats_meta where table_name = 't'").Rows()
+	c.Assert(rows[0][3], Equals, int64(5))
+	c.Assert(rows[0][4], Equals, int64(8))
+	rows = tk.MustQuery("show stats_meta where table_name = 't1'").Rows()
+	c.Assert(rows[0][3], Equals, int64(2))
+	c.Assert(rows[0][4], Equals, int64(2))
+	tk.MustExec("drop table t, t1")
+}
+
+type mockSessionManager struct {
+	processes []util.ProcessInfo
+	killed    map[uint64]bool
+}
+
+func (sm *mockSessionManager) ShowProcessList() []util.ProcessInfo {
+	return sm.processes
+}
+
+func (sm *mockSessionManager) Kill(connectionID uint64, query bool) bool {
+	for _, pi := range sm.processes {
+		if pi.ID == connectionID {
+			sm.killed[connectionID] = query
+			return true
+		}
+	}
+	return false
+}
+
+func (s *testSuite) TestKillAndShowProcessList(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	// There is no connection to kill without a server.
+	_, err := tk.Exec("kill 1")
+	c.Assert(terror.ErrorEqual(err, executor.ErrNoSuchThread), IsTrue)
+	tk.MustQuery("show processlist").Check(nil)
+
+	ctx := tk.Se.(context.Context)
+	variable.GetSessionVars(ctx).User = "root@localhost"
+	sm := &mockSessionManager{
+		processes: []util.ProcessInfo{
+			{ID: 2, User: "other", Host: "127.0.0.1:2", Command: "Sleep", Time: time.Now()},
+			{ID: 1, User: "root", Host: "127.0.0.1:1", DB: "test", Command: "Query", Time: time.Now(), Info: strings.Repeat("a", 120)},
+		},
+		killed: make(map[uint64]bool),
+	}
+	util.BindSessionManager(ctx, sm)
+	tk.MustQuery("show processlist").Check(testkit.Rows(
+		fmt.Sprintf("1 root 127.0.0.1:1 test Query 0  %s", strings.Repeat("a", 100)),
+		"2 other 127.0.0.1:2 <nil> Sleep 0  <nil>",
+	))
+	tk.MustQuery("show full processlist").Check(testkit.Rows(
+		fmt.Sprintf("1 root 127.0.0.1:1 test Query 0  %s", strings.Repeat("a", 120)),
+		"2 other 127.0.0.1:2 <nil> Sleep 0  <nil>",
+	))
+	tk.MustQuery("select id, user, command from information_schema.processlist").Check(testkit.Rows(
+		"1 root Query",
+		"2 other Sleep",
+	))
+
+	tk.MustExec("kill query 1")
+	c.Assert(sm.killed[1], IsTrue)
+	tk.MustExec("kill connection 1")
+	c.Assert(sm.killed[1], IsFalse)
+	_, err = tk.Exec("kill 2")
+	c.Assert(terror.ErrorEqual(err, executor.ErrKillDenied), IsTrue)
+	_, err = tk.Exec("kill 3")
+	c.Assert(terror.ErrorEqual(err, executor.ErrNoSuchThread), IsTrue)
 }
@@ -80,7 +80,7 @@ func (s *testSuite) TestAdmin(c *C) {
 	c.Assert(err, IsNil)
 	row, err := r.Next()
 	c.Assert(err, IsNil)
-	c.Assert(row.Data, HasLen, 6)
+	c.Assert(row.Data, HasLen, 7)
 	txn, err := s.store.Begin()
 	c.Assert(err, IsNil)
 	ddlInfo, err := inspectkv.GetDDLInfo(txn)
@@ -97,10 +97,31 @@ func (s *testSuite) TestAdmin(c *C) {
 	ownerInfos = strings.Split(bgInfo.Owner.String(), ",")
 	c.Assert(rowOwnerInfos[0], Equals, ownerInfos[0])
 	c.Assert(row.Data[5].GetString(), Equals, "")
+	c.Assert(row.Data[6].GetString(), Equals, "")
 	row, err = r.Next()
 	c.Assert(err, IsNil)
 	c.Assert(row, IsNil)
 
+	// show DDL jobs test
+	r, err = tk.Exec("admin show ddl jobs")
+	c.Assert(err, IsNil)
+	row, err = r.Next()
+	c.Assert(err, IsNil)
+	c.Assert(row.Data, HasLen, 2)
+	historyJobs, err := inspectkv.GetHistoryDDLJobs(txn, 0)
+	c.Assert(err, IsNil)
+	c.Assert(historyJobs, Not(HasLen), 0)
+	c.Assert(row.Data[0].GetString(), Equals, historyJobs[0].String())
+	c.Assert(row.Data[1].GetString(), Equals, "done")
+	c.Assert(tk.MustQuery("admin show ddl jobs").Rows(), HasLen, len(historyJobs))
+	c.Assert(tk.MustQuery("admin show ddl jobs 1").Rows(), HasLen, 1)
+
+	// cancel DDL jobs test
+	result := tk.MustQuery(fmt.Sprintf("admin cancel ddl jobs %d, 0", historyJobs[0].ID))
+	c.Assert(result.Rows(), HasLen, 2)
+	c.Assert(result.Rows()[0][1], Matches, fmt.Sprintf(".*DDL job %d not found", historyJobs[0].ID))
+	c.Assert(result.Rows()[1][1], Matches, ".*DDL job 0 not found")
+
 	// check table test
 	tk.MustExec("create table admin_test1 (c1 int, c2 int default 1, index (c1))")
 	tk.MustExec("insert admin_test1 (c1) values (21),(22)")
@@ -1154,6 +1175,185 @@ func (s *testSuite) TestNewJoin(c *C) {
 	plan.UseNewPlanner = false
 }
 
+func (s *testSuite) TestIndexJoin(c *C) {
+	plan.UseNewPlanner = true
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2, t3")
+	tk.MustExec("create table t1 (id int primary key, a int, b varchar(10), index a_b (a, b))")
+	tk.MustExec("create table t2 (id int primary key, a int, b varchar(10))")
+	for i := 1; i <= 20; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t1 values (%d, %d, '%d')", i, i%5, i%2))
+	}
+	tk.MustExec("insert into t2 values (1, 1, '1'), (2, 2, '1'), (3, null, '0'), (30, 3, '1')")
+	tk.MustExec("analyze table t1, t2")
+
+	// t1 is looked up by the handle with the keys of the small outer table t2.
+	sql := "select t1.id, t2.id from t1 join t2 on t1.id = t2.id"
+	tk.MustQuery("explain " + sql).Check(testkit.RowsWithSep(" | ",
+		"1 | SIMPLE | t1 | eq_ref | PRIMARY | PRIMARY | 8 | <nil> | 20 | <nil>",
+		"1 | SIMPLE | t2 | ALL | <nil> | <nil> | <nil> | <nil> | 4 | <nil>",
+	))
+	tk.MustQuery(sql).Check(testkit.Rows("1 1", "2 2", "3 3"))
+
+	// t1 is looked up by the index a_b, the other equal condition is evaluated after the join.
+	sql = "select t1.id, t2.id from t2 join t1 on t1.a = t2.a and t1.b = t2.b and t1.id > t2.id"
+	tk.MustQuery("explain " + sql).Check(testkit.RowsWithSep(" | ",
+		"1 | SIMPLE | t1 | ref | a_b | a_b | <nil> | <nil> | 20 | <nil>",
+		"1 | SIMPLE | t2 | ALL | <nil> | <nil> | <nil> | <nil> | 4 | <nil>",
+	))
+	tk.MustQuery(sql).Check(testkit.Rows("11 1", "7 2", "17 2"))
+	tk.MustQuery("select count(*) from t2 join t1 on t1.a = t2.a").Check(testkit.Rows("12"))
+
+	// The unmatched rows of the outer table are returned with nulls.
+	sql = "select t2.id, t1.id from t2 left join t1 on t2.a = t1.a and t1.id < 10 and t2.b = '1'"
+	tk.MustQuery(sql).Check(testkit.Rows("1 1", "1 6", "2 2", "2 7", "3 <nil>", "30 3", "30 8"))
+	sql = "select t2.id, t1.id from t1 right join t2 on t2.id = t1.id and t1.a > 1"
+	tk.MustQuery(sql).Check(testkit.Rows("1 <nil>", "2 2", "3 3", "30 <nil>"))
+
+	// The hint makes t2 the inner table though it costs more.
+	sql = "select /*+ TIDB_INLJ(t2) */ t1.id, t2.id from t1 join t2 on t1.id = t2.id"
+	tk.MustQuery("explain " + sql).Check(testkit.RowsWithSep(" | ",
+		"1 | SIMPLE | t1 | ALL | <nil> | <nil> | <nil> | <nil> | 20 | <nil>",
+		"1 | SIMPLE | t2 | eq_ref | PRIMARY | PRIMARY | 8 | <nil> | 4 | <nil>",
+	))
+	tk.MustQuery(sql).Check(testkit.Rows("1 1", "2 2", "3 3"))
+
+	// The outer key out of the range of the inner column type matches no row.
+	tk.MustExec("create table t3 (id int primary key, b bigint)")
+	tk.MustExec("insert into t3 values (1, 1), (2, 3000000000)")
+	sql = "select /*+ TIDB_INLJ(t2) */ t3.id, t2.id from t3 join t2 on t3.b = t2.id"
+	tk.MustQuery(sql).Check(testkit.Rows("1 1"))
+	sql = "select /*+ TIDB_INLJ(t2) */ t3.id, t2.id from t3 left join t2 on t3.b = t2.id"
+	tk.MustQuery(sql).Check(testkit.Rows("1 1", "2 <nil>"))
+	tk.MustExec("drop table t3")
+	plan.UseNewPlanner = false
+}
+
+func (s *testSuite) TestMergeJoin(c *C) {
+	plan.UseNewPlanner = true
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (id int primary key, a int)")
+	tk.MustExec("create table t2 (id int primary key, a int, index a (a))")
+	tk.MustExec("insert into t1 values (1, 1), (2, 2), (4, 4), (5, 5)")
+	tk.MustExec("insert into t2 values (1, 5), (2, 4), (3, 4), (4, null), (5, 2), (6, 0)")
+
+	sql := "select /*+ TIDB_SMJ(t1, t2) */ t1.id, t2.id from t1 join t2 on t1.id = t2.a"
+	tk.MustQuery("explain " + sql).Check(testkit.RowsWithSep(" | ",
+		"1 | SIMPLE | t2 | index | a | a | <nil> | <nil> | 10000 | <nil>",
+		"1 | SIMPLE | t1 | ALL | <nil> | <nil> | <nil> | <nil> | 10000 | Using merge join",
+	))
+	tk.MustQuery(sql).Check(testkit.Rows("2 5", "4 2", "4 3", "5 1"))
+	sql = "select /*+ TIDB_SMJ(t1) */ t1.id, t2.id from t1 join t2 on t1.id = t2.a and t1.a = t2.a and t2.id > 1"
+	tk.MustQuery(sql).Check(testkit.Rows("2 5", "4 2", "4 3"))
+
+	// The scans stopped early by the limit or the merge join are closed with their results.
+	tk.MustQuery("select id from t2 use index (a) where a > 0 limit 1").Check(testkit.Rows("5"))
+	sql = "select /*+ TIDB_SMJ(t1, t2) */ t1.id, t2.id from t1 join t2 on t1.id = t2.a where t2.a < 3"
+	tk.MustQuery(sql).Check(testkit.Rows("2 5"))
+	plan.UseNewPlanner = false
+}
+
+func (s *testSuite) TestOptimizerHints(c *C) {
+	plan.UseNewPlanner = true
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2, t3")
+	tk.MustExec("create table t1 (id int primary key, a int, index a (a))")
+	tk.MustExec("create table t2 (id int primary key, a int, index a (a))")
+	tk.MustExec("create table t3 (id int primary key, a int)")
+	tk.MustExec("insert into t1 values (1, 1), (2, 2), (3, 3), (4, 4)")
+	tk.MustExec("insert into t2 values (1, 4), (2, 3), (3, 2), (5, 1)")
+	tk.MustExec("insert into t3 values (1, 1), (2, 2)")
+	tk.MustExec("analyze table t1, t2, t3")
+
+	// The hash join is used though t1 can be looked up by the handle.
+	sql := "select /*+ TIDB_HJ(t1) */ t1.id, t2.id from t1 join t2 on t1.id = t2.id"
+	tk.MustQuery("explain " + sql).Check(testkit.RowsWithSep(" | ",
+		"1 | SIMPLE | t2 | ALL | <nil> | <nil> | <nil> | <nil> | 4 | <nil>",
+		"1 | SIMPLE | t1 | ALL | <nil> | <nil> | <nil> | <nil> | 4 | Using join buffer (hash join)",
+	))
+	tk.MustQuery(sql).Check(testkit.Rows("1 1", "2 2", "3 3"))
+	tk.MustQuery("show warnings").Check(testkit.Rows())
+
+	// The tables are joined in the order of the hints.
+	sql = "select /*+ JOIN_ORDER(t1, t2) */ t1.id, t2.id, t3.id from t1, t2, t3 where t1.a = t2.a and t2.id = t3.id"
+	tk.MustQuery("explain " + sql).Check(testkit.RowsWithSep(" | ",
+		"1 | SIMPLE | t1 | ALL | <nil> | <nil> | <nil> | <nil> | 4 | <nil>",
+		"1 | SIMPLE | t2 | ALL | <nil> | <nil> | <nil> | <nil> | 4 | Using join buffer (hash join)",
+		"1 | SIMPLE | t3 | ALL | <nil> | <nil> | <nil> | <nil> | 2 | Using join buffer (hash join)",
+	))
+	tk.MustQuery(sql).Check(testkit.Rows("3 2 2", "4 1 1"))
+	sql = "select /*+ JOIN_FIXED_ORDER() */ t1.id, t2.id, t3.id from t3, t1, t2 where t1.a = t2.a and t2.id = t3.id"
+	tk.MustQuery("explain " + sql).Check(testkit.RowsWithSep(" | ",
+		"1 | SIMPLE | t3 | ALL | <nil> | <nil> | <nil> | <nil> | 2 | <nil>",
+		"1 | SIMPLE | t1 | ALL | <nil> | <nil> | <nil> | <nil> | 4 | Using join buffer (hash join)",
+		"1 | SIMPLE | t2 | ALL | <nil> | <nil> | <nil> | <nil> | 4 | Using join buffer (hash join)",
+	))
+	tk.MustQuery(sql).Check(testkit.Rows("4 1 1", "3 2 2"))
+
+	// The index hints restrict the indices to read the tables.
+	sql = "select /*+ TIDB_INLJ(t2) */ t1.id, t2.id from t1 join t2 ignore index (a) on t1.a = t2.a order by t1.id"
+	tk.MustQuery(sql).Check(testkit.Rows("1 5", "2 3", "3 2", "4 1"))
+	tk.MustQuery("show warnings").Check(testkit.RowsWithSep(" | ",
+		"Warning | 1105 | Optimizer hint TIDB_INLJ(t2) is inapplicable"))
+	sql = "select id, a from t2 force index (a) where a > 1"
+	tk.MustQuery("explain " + sql).Check(testkit.RowsWithSep(" | ",
+		"1 | SIMPLE | t2 | range | a | a | <nil> | <nil> | 4 | Using where",
+	))
+	tk.MustQuery(sql).Check(testkit.Rows("3 2", "2 3", "1 4"))
+
+	// The unusable hints are ignored with warnings.
+	tk.MustQuery("select /*+ NO_SUCH_HINT(t1), TIDB_INLJ(t4) */ id from t1 use index (b) where id = 1").Check(testkit.Rows("1"))
+	warnings := testkit.RowsWithSep(" | ",
+		"Warning | 1105 | Optimizer hint NO_SUCH_HINT(t1) is not supported",
+		"Warning | 3128 | Unresolved name 't4' for TIDB_INLJ hint",
+		"Warning | 1176 | Key 'b' doesn't exist in table 't1'",
+	)
+	tk.MustQuery("show warnings").Check(warnings)
+	// SHOW WARNINGS doesn't clear the warnings, the next statement does.
+	tk.MustQuery("show warnings").Check(warnings)
+	tk.MustQuery("select id from t1 where id = 1").Check(testkit.Rows("1"))
+	tk.MustQuery("show warnings").Check(testkit.Rows())
+
+	// The aggregation hints.
+	tk.MustQuery("select /*+ NO_AGG_TO_COP() */ count(*), sum(a) from t1").Check(testkit.Rows("4 10"))
+	tk.MustQuery("show warnings").Check(testkit.Rows())
+	tk.MustQuery("select /*+ AGG_TO_COP(), NO_AGG_TO_COP() */ count(distinct a) from t1").Check(testkit.Rows("4"))
+	tk.MustQuery("show warnings").Check(testkit.RowsWithSep(" | ",
+		"Warning | 3126 | Hint NO_AGG_TO_COP() is ignored as conflicting/duplicated",
+		"Warning | 1105 | Optimizer hint AGG_TO_COP() is inapplicable",
+	))
+	plan.UseNewPlanner = false
+
+	// The old planner doesn't choose the join algorithms.
+	tk.MustQuery("select /*+ TIDB_INLJ(t2), AGG_TO_COP() */ count(distinct t1.a) from t1 join t2 on t1.id = t2.id").Check(testkit.Rows("3"))
+	tk.MustQuery("show warnings").Check(testkit.RowsWithSep(" | ",
+		"Warning | 1105 | Optimizer hint TIDB_INLJ(t2) is inapplicable",
+		"Warning | 1105 | Optimizer hint AGG_TO_COP() is inapplicable",
+	))
+	tk.MustExec("update /*+ AGG_TO_COP() */ t1 force index (b) set a = 5 where a = 4")
+	tk.MustQuery("show warnings").Check(testkit.RowsWithSep(" | ",
+		"Warning | 1105 | Optimizer hint AGG_TO_COP() is inapplicable",
+		"Warning | 1176 | Key 'b' doesn't exist in table 't1'",
+	))
+	tk.MustExec("delete /*+ MAX_EXECUTION_TIME(1000) */ from t1 where a = 5")
+	tk.MustQuery("show warnings").Check(testkit.RowsWithSep(" | ",
+		"Warning | 1105 | Optimizer hint MAX_EXECUTION_TIME(1000) is inapplicable",
+	))
+	tk.MustQuery("select id, a from t1 use index (a) where a > 1").Check(testkit.Rows("2 2", "3 3"))
+	tk.MustExec("update t1 force index (a) set a = 4 where a = 3")
+	tk.MustQuery("show warnings").Check(testkit.Rows())
+	tk.MustExec("delete from t1 use index (a) where a = 4")
+	tk.MustQuery("show warnings").Check(testkit.Rows())
+	tk.MustQuery("select id, a from t1 ignore index (a) where a > 1").Check(testkit.Rows("2 2"))
+}
+
 func (s *testSuite) TestIndexScan(c *C) {
 	defer testleak.AfterTest(c)()
 	tk := testkit.NewTestKit(c, s.store)
@@ -1178,6 +1378,94 @@ func (s *testSuite) TestSubquerySameTable(c *C) {
 	plan.UseNewPlanner = false
 }
 
+func (s *testSuite) TestSubqueryDecorrelate(c *C) {
+	plan.UseNewPlanner = true
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, s")
+	tk.MustExec("create table t (a int, b int)")
+	tk.MustExec("create table s (a int, b int)")
+	tk.MustExec("insert t values (1, 1), (2, 2), (3, null), (null, 4)")
+	tk.MustExec("insert s values (1, 10), (2, null), (null, 30), (1, 40)")
+	cases := []struct {
+		sql    string
+		result [][]interface{}
+	}{
+		{
+			"select a from t where exists (select 1 from s where s.a = t.a)",
+			testkit.Rows("1", "2"),
+		},
+		{
+			"select a from t where not exists (select 1 from s where s.a = t.a)",
+			testkit.Rows("3", "<nil>"),
+		},
+		{
+			"select a from t where a in (select a from s)",
+			testkit.Rows("1", "2"),
+		},
+		{
+			// NOT IN is never true if the subquery returns NULL.
+			"select a from t where a not in (select a from s)",
+			testkit.Rows(),
+		},
+		{
+			"select a from t where a not in (select a from s where a is not null)",
+			testkit.Rows("3"),
+		},
+		{
+			"select a from t where a in (select a from s where s.b > t.b)",
+			testkit.Rows("1"),
+		},
+		{
+			// The subquery of t.a = 3 is empty, NOT IN is true even if the value is NULL.
+			"select a from t where a not in (select a from s where s.b > t.b)",
+			testkit.Rows("3"),
+		},
+		{
+			"select a, a in (select a from s), a not in (select a from s where a is not null) from t",
+			testkit.Rows("1 1 0", "2 1 0", "3 <nil> 1", "<nil> <nil> <nil>"),
+		},
+		{
+			"select a, exists (select 1 from s where s.a = t.a) from t",
+			testkit.Rows("1 1", "2 1", "3 0", "<nil> 0"),
+		},
+		{
+			"select a from t where a = 3 or exists (select 1 from s where s.a = t.a)",
+			testkit.Rows("1", "2", "3"),
+		},
+		{
+			"select a, (select count(*) from s where s.a = t.a) from t",
+			testkit.Rows("1 2", "2 1", "3 0", "<nil> 0"),
+		},
+		{
+			"select a, (select count(b) + 1 from s where s.a = t.a), (select sum(b) from s where s.a = t.a) from t",
+			testkit.Rows("1 3 50", "2 1 <nil>", "3 1 <nil>", "<nil> 1 <nil>"),
+		},
+		{
+			// The correlated conditions which aren't equal conditions are evaluated by apply.
+			"select a, (select count(*) from s where s.a < t.a) from t",
+			testkit.Rows("1 0", "2 2", "3 3", "<nil> 0"),
+		},
+		{
+			"select a from t where a not in (select s.a + t.b from s where s.a is not null)",
+			testkit.Rows("1", "2"),
+		},
+		{
+			"select a from t where a in (1, 3)",
+			testkit.Rows("1", "3"),
+		},
+		{
+			"select a from t where a not in (1, 3)",
+			testkit.Rows("2"),
+		},
+	}
+	for _, ca := range cases {
+		tk.MustQuery(ca.sql).Check(ca.result)
+	}
+	plan.UseNewPlanner = false
+}
+
 func (s *testSuite) TestIndexReverseOrder(c *C) {
 	defer testleak.AfterTest(c)()
 	tk := testkit.NewTestKit(c, s.store)
@@ -1427,6 +1715,57 @@ func (s *testSuite) TestAggregation(c *C) {
 	plan.UseNewPlanner = false
 }
 
+func (s *testSuite) TestAggregationPushDown(c *C) {
+	plan.UseNewPlanner = true
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, g varchar(10), i int, d decimal(10, 2), f double, dt datetime)")
+	tk.MustQuery("select count(*), sum(i), avg(d), max(dt) from t").Check(testkit.Rows("0 <nil> <nil> <nil>"))
+	tk.MustQuery("select count(*) from t group by g").Check(testkit.Rows())
+	tk.MustExec(`insert t values (1, 'a', 1, 1.25, 0.5, '2016-10-01 10:00:00'), (2, 'b', 2, 2.50, 1.5, '2016-10-02 10:00:00'),
+		(3, 'a', null, 3.75, null, null), (4, 'b', 4, null, 2.5, '2016-09-30 10:00:00'), (5, null, 5, 5.00, 3.5, null)`)
+	tk.MustQuery("select count(*), count(i), sum(i), sum(d), avg(i), avg(d), sum(f), avg(f) from t").Check(
+		testkit.Rows("5 4 12 12.50 3.0000 3.125000 8 2"))
+	tk.MustQuery("select count(*), sum(i), avg(d), max(i), min(d), max(dt) from t group by g").Check(testkit.Rows(
+		"2 1 2.500000 1 1.25 2016-10-01 10:00:00",
+		"2 6 2.500000 4 2.50 2016-10-02 10:00:00",
+		"1 5 5.000000 5 5.00 <nil>",
+	))
+	tk.MustQuery("select sum(i + 1), count(g) from t where id > 1 group by g").Check(testkit.Rows("8 2", "<nil> 1", "6 0"))
+	// The distinct aggregate functions are not pushed down.
+	tk.MustQuery("select count(distinct g), sum(distinct i) from t").Check(testkit.Rows("2 12"))
+	plan.UseNewPlanner = false
+}
+
+func (s *testSuite) TestLimitPushDown(c *C) {
+	plan.UseNewPlanner = true
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, a int, b int)")
+	tk.MustQuery("select * from t order by a limit 2").Check(testkit.Rows())
+	tk.MustExec("insert t values (1, 5, 1), (2, 3, 2), (3, null, 3), (4, 8, 4), (5, 3, 5), (6, 1, 6)")
+	tk.MustQuery("select id from t limit 2").Check(testkit.Rows("1", "2"))
+	tk.MustQuery("select id from t where b > 2 limit 1, 2").Check(testkit.Rows("4", "5"))
+	tk.MustQuery("select id from t order by id desc limit 2").Check(testkit.Rows("6", "5"))
+	tk.MustQuery("select * from t order by a limit 3").Check(testkit.Rows("3 <nil> 3", "6 1 6", "2 3 2"))
+	tk.MustQuery("select id, a from t order by a desc, id desc limit 2, 3").Check(testkit.Rows("5 3", "2 3", "6 1"))
+	tk.MustQuery("select id, a, b from t where id > 1 order by a + b limit 2").Check(testkit.Rows("3 <nil> 3", "2 3 2"))
+	tk.MustQuery("select id, a from t order by a limit 0").Check(testkit.Rows())
+	tk.MustQuery("select id, b from t order by b limit 10, 1").Check(testkit.Rows())
+	// The huge limits are sorted without a top n, and aren't pushed down.
+	tk.MustQuery("select id, b from t order by b limit 18446744073709551615").Check(testkit.Rows("1 1", "2 2", "3 3", "4 4", "5 5", "6 6"))
+	tk.MustQuery("select id, b from t order by b limit 4, 1000000000000").Check(testkit.Rows("5 5", "6 6"))
+	tk.MustQuery("select id, b from t order by b limit 1, 18446744073709551615").Check(testkit.Rows("2 2", "3 3", "4 4", "5 5", "6 6"))
+	tk.MustQuery("select id, b from t order by b limit 18446744073709551615, 1").Check(testkit.Rows())
+	tk.MustQuery("select id from t limit 4, 18446744073709551615").Check(testkit.Rows("5", "6"))
+	tk.MustQuery("select id from t limit 9223372036854775808").Check(testkit.Rows("1", "2", "3", "4", "5", "6"))
+	plan.UseNewPlanner = false
+}
+
 func (s *testSuite) TestAdapterStatement(c *C) {
 	defer testleak.AfterTest(c)()
 	se, err := tidb.CreateSession(s.store)
@@ -1447,3 +1786,94 @@ func (s *testSuite) TestAdapterStatement(c *C) {
 	c.Check(err, IsNil)
 	c.Check(stmt.OriginText(), Equals, "create table t (a int)")
 }
+
+func (s *testSuite) TestPartitionTable(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec(`create table t (a int primary key, b int, index idx_b (b)) partition by range (a) (
+		partition p0 values less than (10),
+		partition p1 values less than (20),
+		partition p2 values less than maxvalue)`)
+	tk.MustExec("insert t values (1, 1), (11, 2), (21, 3), (22, 4)")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 1", "11 2", "21 3", "22 4"))
+	tk.MustQuery("select * from t where a >= 10 and a < 21").Check(testkit.Rows("11 2"))
+	tk.MustQuery("select a from t where b > 1 order by b desc").Check(testkit.Rows("22", "21", "11"))
+	tk.MustQuery("select count(*) from t use index (idx_b) where b < 4").Check(testkit.Rows("3"))
+	_, err := tk.Exec("insert t values (1, 5)")
+	c.Assert(err, NotNil)
+
+	// The updated row is moved to the partition of its new partition column value.
+	tk.MustExec("update t set a = 12 where a = 1")
+	tk.MustQuery("select * from t where a < 10").Check(testkit.Rows())
+	tk.MustQuery("select * from t where a < 20").Check(testkit.Rows("11 2", "12 1"))
+	tk.MustExec("update t set b = b + 10 where a > 20")
+	tk.MustQuery("select b from t where a > 20").Check(testkit.Rows("13", "14"))
+	tk.MustExec("delete from t where b > 13")
+	tk.MustQuery("select a from t").Check(testkit.Rows("11", "12", "21"))
+	tk.MustExec("admin check table t")
+
+	// The uncommitted rows are read from their partitions.
+	tk.MustExec("begin")
+	tk.MustExec("insert t values (2, 5), (15, 6), (30, 7)")
+	tk.MustQuery("select a from t").Check(testkit.Rows("2", "11", "12", "15", "21", "30"))
+	tk.MustQuery("select a from t where a > 13").Check(testkit.Rows("15", "21", "30"))
+	tk.MustQuery("select a from t where b >= 5").Check(testkit.Rows("2", "15", "30", "21"))
+	tk.MustExec("delete from t where a = 11")
+	tk.MustQuery("select a from t where a < 20").Check(testkit.Rows("2", "12", "15"))
+	tk.MustExec("commit")
+	tk.MustQuery("select a from t").Check(testkit.Rows("2", "12", "15", "21", "30"))
+
+	tk.MustQuery("show create table t").Check(testkit.Rows("t CREATE TABLE `t` (\n" +
+		"  `a` int(11) NOT NULL DEFAULT NULL,\n" +
+		"  `b` int(11) DEFAULT NULL,\n" +
+		" PRIMARY KEY (`a`) ,\n" +
+		"  KEY `idx_b` (`b`)\n" +
+		") ENGINE=InnoDB\n" +
+		"PARTITION BY RANGE (`a`) (\n" +
+		"  PARTITION `p0` VALUES LESS THAN (10),\n" +
+		"  PARTITION `p1` VALUES LESS THAN (20),\n" +
+		"  PARTITION `p2` VALUES LESS THAN MAXVALUE\n" +
+		")"))
+
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int auto_increment, key (b)) partition by hash (a) partitions 3")
+	tk.MustExec("insert t (a) values (1), (2), (3), (4), (null)")
+	tk.MustQuery("select a, b from t where a = 2").Check(testkit.Rows("2 2"))
+	tk.MustQuery("select b from t where a is null").Check(testkit.Rows("5"))
+	tk.MustQuery("select count(*) from t where a in (1, 4)").Check(testkit.Rows("2"))
+	tk.MustExec("update t set a = a + 1 where b <= 2")
+	tk.MustQuery("select a from t where a <= 3 order by a").Check(testkit.Rows("2", "3", "3"))
+	tk.MustExec("truncate table t")
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("0"))
+
+	tk.MustExec("drop table if exists t")
+	tk.MustExec(`create table t (id int, d datetime) partition by range (to_days(d)) (
+		partition p0 values less than (to_days('2017-01-01')),
+		partition p1 values less than (to_days('2017-02-01')))`)
+	tk.MustExec("insert t values (1, '2016-12-31 23:59:59'), (2, '2017-01-01'), (3, '2017-01-31 12:00:00'), (4, '0000-00-00')")
+	_, err = tk.Exec("insert t values (5, '2017-02-01')")
+	c.Assert(err, NotNil)
+	tk.MustQuery("select id from t where d >= '2017-01-01' and d < '2017-02-01'").Check(testkit.Rows("2", "3"))
+	tk.MustQuery("select id from t where d < '2017-01-01'").Check(testkit.Rows("1", "4"))
+	tk.MustQuery("select id from t where d = '2017-01-31 12:00:00'").Check(testkit.Rows("3"))
+	tk.MustQuery("show create table t").Check(testkit.Rows("t CREATE TABLE `t` (\n" +
+		"  `id` int(11) DEFAULT NULL,\n" +
+		"  `d` datetime DEFAULT NULL\n" +
+		") ENGINE=InnoDB\n" +
+		"PARTITION BY RANGE (TO_DAYS(`d`)) (\n" +
+		"  PARTITION `p0` VALUES LESS THAN (736695),\n" +
+		"  PARTITION `p1` VALUES LESS THAN (736726)\n" +
+		")"))
+
+	tk.MustExec("drop table if exists t")
+	tk.MustExec(`create table t (id int, d date) partition by range (year(d)) (
+		partition p0 values less than (2017),
+		partition p1 values less than maxvalue)`)
+	tk.MustExec("insert t values (1, '2016-06-01'), (2, '2017-06-01'), (3, '2018-06-01')")
+	tk.MustQuery("select id from t where d > '2016-12-31'").Check(testkit.Rows("2", "3"))
+	tk.MustExec("update t set d = '2015-01-01' where id = 3")
+	tk.MustQuery("select id from t where d < '2017-01-01'").Check(testkit.Rows("1", "3"))
+	tk.MustExec("drop table t")
+}
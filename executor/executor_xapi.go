@@ -32,6 +32,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser/opcode"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/types"
@@ -40,7 +41,14 @@ import (
 	"github.com/pingcap/tipb/go-tipb"
 )
 
-const defaultConcurrency int = 10
+// defaultConcurrency is the concurrency of the coprocessor requests.
+var defaultConcurrency = 10
+
+// SetCopConcurrency changes the concurrency of the coprocessor requests, it should be called before
+// any statement runs.
+func SetCopConcurrency(concurrency int) {
+	defaultConcurrency = concurrency
+}
 
 var (
 	_ Executor  = &XSelectTableExec{}
@@ -73,6 +81,8 @@ type XSelectTableExec struct {
 	aggFields        []*types.FieldType
 	// Indicate if the exec is handling aggregate result.
 	aggregate bool
+	// copStats is set if the exec is analyzed by EXPLAIN ANALYZE.
+	copStats *kv.CopStats
 }
 
 // AddAggregate implements XExecutor interface.
@@ -90,6 +100,9 @@ func (e *XSelectTableExec) GetTableName() *ast.TableName {
 
 // Next implements Executor Next interface.
 func (e *XSelectTableExec) Next() (*Row, error) {
+	if err := checkKilled(e.ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
 	if e.result == nil {
 		err := e.doRequest()
 		if err != nil {
@@ -117,6 +130,7 @@ func (e *XSelectTableExec) Next() (*Row, error) {
 			e.subResult = nil
 			continue
 		}
+		addRowsExamined(e.ctx)
 		if e.aggregate {
 			// compose aggreagte row
 			return &Row{Data: rowData}, nil
@@ -194,7 +208,7 @@ func (e *XSelectTableExec) doRequest() error {
 	// Aggregate Info
 	selReq.Aggregates = e.aggFuncs
 	selReq.GroupBy = e.byItems
-	e.result, err = xapi.Select(txn.GetClient(), selReq, defaultConcurrency)
+	e.result, err = xapi.Select(txn.GetClient(), selReq, defaultConcurrency, variable.GetSessionVars(e.ctx).StmtDeadline, copStatsOf(e.ctx, e.copStats))
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -221,6 +235,8 @@ type XSelectIndexExec struct {
 	aggFields []*types.FieldType
 	// Indicate if the exec is handling aggregate result.
 	aggregate bool
+	// copStats is set if the exec is analyzed by EXPLAIN ANALYZE.
+	copStats *kv.CopStats
 
 	tasks      []*lookupTableTask
 	taskCursor int
@@ -275,6 +291,9 @@ func (e *XSelectIndexExec) Fields() []*ast.ResultField {
 
 // Next implements Executor Next interface.
 func (e *XSelectIndexExec) Next() (*Row, error) {
+	if err := checkKilled(e.ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
 	if e.tasks == nil {
 		startTs := time.Now()
 		handles, err := e.fetchHandles()
@@ -318,6 +337,7 @@ func (e *XSelectIndexExec) Next() (*Row, error) {
 				}
 			}
 			task.cursor++
+			addRowsExamined(e.ctx)
 			return row, nil
 		}
 		e.taskCursor++
@@ -491,9 +511,9 @@ func (e *XSelectIndexExec) doIndexRequest() (*xapi.SelectResult, error) {
 	}
 	concurrency := 1
 	if e.indexPlan.OutOfOrder {
-		concurrency = 10
+		concurrency = defaultConcurrency
 	}
-	return xapi.Select(txn.GetClient(), selIdxReq, concurrency)
+	return xapi.Select(txn.GetClient(), selIdxReq, concurrency, variable.GetSessionVars(e.ctx).StmtDeadline, copStatsOf(e.ctx, e.copStats))
 }
 
 func (e *XSelectIndexExec) doTableRequest(handles []int64) (*xapi.SelectResult, error) {
@@ -530,7 +550,7 @@ func (e *XSelectIndexExec) doTableRequest(handles []int64) (*xapi.SelectResult,
 	// Aggregate Info
 	selTableReq.Aggregates = e.aggFuncs
 	selTableReq.GroupBy = e.byItems
-	resp, err := xapi.Select(txn.GetClient(), selTableReq, defaultConcurrency)
+	resp, err := xapi.Select(txn.GetClient(), selTableReq, defaultConcurrency, variable.GetSessionVars(e.ctx).StmtDeadline, copStatsOf(e.ctx, e.copStats))
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -17,7 +17,9 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/parser/opcode"
 	"github.com/pingcap/tidb/plan"
@@ -81,6 +83,23 @@ func (e *explainEntry) setJoinTypeForIndexScan(p *plan.IndexScan) {
 	e.joinType = "range"
 }
 
+// setJoinTypeForIndexJoin sets the join type of the inner table of index join, keyCount is the number of
+// the join keys used to look up the table.
+func (e *explainEntry) setJoinTypeForIndexJoin(p *plan.NewTableScan, keyCount int) {
+	if p.Index == nil {
+		e.joinType = "eq_ref"
+		e.key = "PRIMARY"
+		e.keyLen = "8"
+		return
+	}
+	e.key = p.Index.Name.O
+	if p.Index.Unique && keyCount == len(p.Index.Columns) {
+		e.joinType = "eq_ref"
+	} else {
+		e.joinType = "ref"
+	}
+}
+
 // ExplainExec represents an explain executor.
 // See: https://dev.mysql.com/doc/refman/5.7/en/explain-output.html
 type ExplainExec struct {
@@ -88,6 +107,14 @@ type ExplainExec struct {
 	fields   []*ast.ResultField
 	rows     []*Row
 	cursor   int
+
+	// The fields of EXPLAIN ANALYZE, stmtExec is the executor of the statement, which collects the runtime
+	// statistics to stmtStats.
+	ctx       context.Context
+	stmtExec  Executor
+	stmtStats *runtimeStats
+	format    string
+	analyzed  bool
 }
 
 // Schema implements Executor Schema interface.
@@ -103,7 +130,11 @@ func (e *ExplainExec) Fields() []*ast.ResultField {
 // Next implements Execution Next interface.
 func (e *ExplainExec) Next() (*Row, error) {
 	if e.rows == nil {
-		e.fetchRows()
+		if e.stmtExec == nil {
+			e.fetchRows()
+		} else if err := e.fetchAnalyzeRows(); err != nil {
+			return nil, errors.Trace(err)
+		}
 	}
 	if e.cursor >= len(e.rows) {
 		return nil, nil
@@ -158,7 +189,9 @@ func (v *explainVisitor) explain(p plan.Plan) {
 		v.entries = append(v.entries, v.newEntryForTableScan(x))
 	case *plan.IndexScan:
 		v.entries = append(v.entries, v.newEntryForIndexScan(x))
-	case *plan.Sort:
+	case *plan.NewTableScan:
+		v.entries = append(v.entries, v.newEntryForNewTableScan(x))
+	case *plan.Sort, *plan.NewSort:
 		v.sort = true
 	}
 
@@ -172,6 +205,7 @@ func (v *explainVisitor) newEntryForTableScan(p *plan.TableScan) *explainEntry {
 		ID:         v.id,
 		selectType: "SIMPLE",
 		table:      p.Table.Name.O,
+		rows:       int64(p.RowCount()),
 	}
 	entry.setJoinTypeForTableScan(p)
 	if entry.joinType != "ALL" {
@@ -192,6 +226,7 @@ func (v *explainVisitor) newEntryForIndexScan(p *plan.IndexScan) *explainEntry {
 		selectType: "SIMPLE",
 		table:      p.Table.Name.O,
 		key:        p.Index.Name.O,
+		rows:       int64(p.RowCount()),
 	}
 	if len(p.AccessConditions) != 0 {
 		keyLen := 0
@@ -214,6 +249,69 @@ func (v *explainVisitor) newEntryForIndexScan(p *plan.IndexScan) *explainEntry {
 	return entry
 }
 
+func (v *explainVisitor) newEntryForNewTableScan(p *plan.NewTableScan) *explainEntry {
+	entry := &explainEntry{
+		ID:         v.id,
+		selectType: "SIMPLE",
+		table:      p.Table.Name.O,
+		joinType:   "ALL",
+		rows:       int64(p.RowCount()),
+	}
+	if p.Index != nil {
+		entry.joinType = "index"
+		if p.IndexRanges != nil {
+			entry.joinType = "range"
+		}
+		entry.key = p.Index.Name.O
+	}
+	var child plan.Plan = p
+	parent := p.GetParentByIndex(0)
+	if sel, ok := parent.(*plan.Selection); ok {
+		entry.extra = append(entry.extra, "Using where")
+		child, parent = sel, sel.GetParentByIndex(0)
+	}
+	// The table of the subquery of semi join is under the projection of the subquery.
+	if proj, ok := parent.(*plan.Projection); ok {
+		if join, ok := proj.GetParentByIndex(0).(*plan.Join); ok && isSemiJoin(join) {
+			child, parent = proj, join
+		}
+	}
+	if join, ok := parent.(*plan.Join); ok {
+		switch join.Algorithm {
+		case plan.IndexJoin:
+			if child == join.GetChildByIndex(join.InnerChildIdx) {
+				entry.setJoinTypeForIndexJoin(p, len(join.EqualConditions))
+			}
+		case plan.MergeJoin:
+			if child == join.GetChildByIndex(1) {
+				entry.extra = append(entry.extra, "Using merge join")
+			}
+		default:
+			// The hash join executor builds the hash table with the small child.
+			smallChild := join.GetChildByIndex(1)
+			if join.JoinType == plan.RightOuterJoin {
+				smallChild = join.GetChildByIndex(0)
+			}
+			if child == smallChild {
+				entry.extra = append(entry.extra, "Using join buffer (hash join)")
+				switch join.JoinType {
+				case plan.SemiJoin:
+					entry.extra = append(entry.extra, "FirstMatch")
+				case plan.AntiSemiJoin:
+					entry.extra = append(entry.extra, "Not exists")
+				}
+			}
+		}
+	}
+
+	v.setSortExtra(entry)
+	return entry
+}
+
+func isSemiJoin(join *plan.Join) bool {
+	return join.JoinType == plan.SemiJoin || join.JoinType == plan.AntiSemiJoin
+}
+
 func (v *explainVisitor) setSortExtra(entry *explainEntry) {
 	if v.sort {
 		entry.extra = append(entry.extra, "Using filesort")
@@ -0,0 +1,281 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// runtimeStats is the runtime statistics of an executor of the statement explained by EXPLAIN ANALYZE.
+type runtimeStats struct {
+	name string
+	// plan is the plan the executor is built for, its estimated row count is shown. If the plan is pushed down to
+	// the executor of its child, the executor shows the estimated row count of the pushed plan.
+	plan plan.Plan
+	// rows is the number of the rows returned.
+	rows int64
+	// loops is the number of the times the executor is started, the inner executor of a join may be restarted
+	// for every outer row.
+	loops int64
+	// time is the total time spent in Next, including the time of the children.
+	time time.Duration
+	// copStats is the statistics of the coprocessor requests, it's nil if the executor doesn't send them.
+	copStats *kv.CopStats
+	children []*runtimeStats
+}
+
+// analyzedExec wraps an executor to collect its runtime statistics.
+type analyzedExec struct {
+	Executor
+	stats   *runtimeStats
+	started bool
+}
+
+// Next implements Executor Next interface.
+func (e *analyzedExec) Next() (*Row, error) {
+	if !e.started {
+		e.started = true
+		e.stats.loops++
+	}
+	start := time.Now()
+	row, err := e.Executor.Next()
+	e.stats.time += time.Since(start)
+	if row != nil {
+		e.stats.rows++
+	}
+	return row, errors.Trace(err)
+}
+
+// Close implements Executor Close interface.
+func (e *analyzedExec) Close() error {
+	e.started = false
+	return errors.Trace(e.Executor.Close())
+}
+
+// analyzedNewExec wraps a NewExecutor to collect its runtime statistics.
+type analyzedNewExec struct {
+	analyzedExec
+}
+
+// Init implements NewExecutor Init interface.
+func (e *analyzedNewExec) Init() {
+	e.started = false
+	e.Executor.(NewExecutor).Init()
+}
+
+// unwrapExec returns the executor wrapped to collect the runtime statistics, or e if it isn't wrapped.
+// The builder checks the executor of a child plan by it to push the parent plan down.
+func unwrapExec(e Executor) Executor {
+	switch x := e.(type) {
+	case *analyzedExec:
+		return x.Executor
+	case *analyzedNewExec:
+		return x.Executor
+	}
+	return e
+}
+
+// analyze wraps the executor built for the plan to collect its runtime statistics, the statistics of the executors
+// built since mark are the children of its statistics. If the plan is pushed down to the executor of its child,
+// the child is returned as is and the statistics are shared.
+func (b *executorBuilder) analyze(p plan.Plan, e Executor, mark int) Executor {
+	var stats *runtimeStats
+	switch x := e.(type) {
+	case *analyzedExec:
+		stats = x.stats
+	case *analyzedNewExec:
+		stats = x.stats
+	}
+	wrapped := stats != nil
+	if wrapped {
+		stats.plan = p
+	} else if stats = b.planStats[p]; stats == nil {
+		stats = &runtimeStats{name: executorName(e), plan: p}
+		stats.children = append(stats.children, b.runtimeStats[mark:]...)
+		b.runtimeStats = append(b.runtimeStats[:mark], stats)
+	}
+	// The executor which is rebuilt for the same plan adds to the same statistics.
+	b.planStats[p] = stats
+	if parent := b.lazyParents[p]; parent != nil && len(b.runtimeStats) > mark {
+		parent.children = append(parent.children, b.runtimeStats[mark:]...)
+		b.runtimeStats = b.runtimeStats[:mark]
+	}
+	if wrapped {
+		return e
+	}
+	// The inner plans of the nested loop joins are built when the joins are executed.
+	switch x := e.(type) {
+	case *JoinInnerExec:
+		for _, inner := range x.InnerPlans {
+			b.lazyParents[inner] = stats
+		}
+	case *JoinOuterExec:
+		b.lazyParents[x.InnerPlan] = stats
+	}
+	if copStats := copStatsFieldOf(e); copStats != nil {
+		if stats.copStats == nil {
+			stats.copStats = &kv.CopStats{Parent: &variable.GetSessionVars(b.ctx).StmtStats.CopStats}
+		}
+		*copStats = stats.copStats
+	}
+	if _, ok := e.(NewExecutor); ok {
+		return &analyzedNewExec{analyzedExec{Executor: e, stats: stats}}
+	}
+	return &analyzedExec{Executor: e, stats: stats}
+}
+
+// copStatsFieldOf returns the field of the coprocessor statistics of the executor which reads the table by
+// coprocessor requests, it returns nil if the executor doesn't send them.
+func copStatsFieldOf(e Executor) **kv.CopStats {
+	switch x := e.(type) {
+	case *NewTableScanExec:
+		return &x.copStats
+	case *NewIndexScanExec:
+		return &x.copStats
+	case *XSelectTableExec:
+		return &x.copStats
+	case *XSelectIndexExec:
+		return &x.copStats
+	case *UnionScanExec:
+		return copStatsFieldOf(x.Src)
+	case *FilterExec:
+		return copStatsFieldOf(x.Src)
+	}
+	return nil
+}
+
+// copStatsOf returns the statistics which the coprocessor requests of a scan are counted in, they're the
+// statistics of the scan if it's analyzed by EXPLAIN ANALYZE, or the statistics of the statement.
+func copStatsOf(ctx context.Context, stats *kv.CopStats) *kv.CopStats {
+	if stats != nil {
+		return stats
+	}
+	return &variable.GetSessionVars(ctx).StmtStats.CopStats
+}
+
+// executorName returns the name of the executor shown by EXPLAIN ANALYZE, e.g. HashJoin for *HashJoinExec.
+func executorName(e Executor) string {
+	t := reflect.TypeOf(e)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return strings.TrimPrefix(strings.TrimSuffix(t.Name(), "Exec"), "New")
+}
+
+// analyze executes the statement explained by EXPLAIN ANALYZE to collect the runtime statistics, the result rows
+// are discarded. It runs before the statement is committed, so a DML statement modifies the table as it isn't
+// explained.
+func (e *ExplainExec) analyze() error {
+	if e.analyzed {
+		return nil
+	}
+	e.analyzed = true
+	defer e.stmtExec.Close()
+	for {
+		if err := checkKilled(e.ctx); err != nil {
+			return errors.Trace(err)
+		}
+		row, err := e.stmtExec.Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			return nil
+		}
+	}
+}
+
+// explainAnalyzeNode is an executor in the JSON format of EXPLAIN ANALYZE.
+type explainAnalyzeNode struct {
+	ID            string                `json:"id"`
+	EstimatedRows float64               `json:"estimated_rows"`
+	ActualRows    int64                 `json:"actual_rows"`
+	Loops         int64                 `json:"loops"`
+	Time          string                `json:"time"`
+	CopTasks      *int64                `json:"cop_tasks,omitempty"`
+	CopRetries    *int64                `json:"cop_retries,omitempty"`
+	Children      []*explainAnalyzeNode `json:"children,omitempty"`
+}
+
+// explainAnalyzeNodes converts the runtime statistics to the nodes of EXPLAIN ANALYZE in preorder, the IDs of
+// the executors are numbered in preorder. The depths of the nodes are returned too.
+func explainAnalyzeNodes(stats *runtimeStats) ([]*explainAnalyzeNode, []int) {
+	var nodes []*explainAnalyzeNode
+	var depths []int
+	var visit func(s *runtimeStats, depth int) *explainAnalyzeNode
+	visit = func(s *runtimeStats, depth int) *explainAnalyzeNode {
+		node := &explainAnalyzeNode{
+			ID:            fmt.Sprintf("%s_%d", s.name, len(nodes)+1),
+			EstimatedRows: s.plan.RowCount(),
+			ActualRows:    s.rows,
+			Loops:         s.loops,
+			Time:          s.time.String(),
+		}
+		if s.copStats != nil {
+			tasks, retries := atomic.LoadInt64(&s.copStats.Tasks), atomic.LoadInt64(&s.copStats.Retries)
+			node.CopTasks, node.CopRetries = &tasks, &retries
+		}
+		nodes = append(nodes, node)
+		depths = append(depths, depth)
+		for _, child := range s.children {
+			node.Children = append(node.Children, visit(child, depth+1))
+		}
+		return node
+	}
+	visit(stats, 0)
+	return nodes, depths
+}
+
+// fetchAnalyzeRows builds the rows of EXPLAIN ANALYZE, every row is an executor in the row format, and there is
+// a single row of the JSON document in the JSON format.
+func (e *ExplainExec) fetchAnalyzeRows() error {
+	if err := e.analyze(); err != nil {
+		return errors.Trace(err)
+	}
+	nodes, depths := explainAnalyzeNodes(e.stmtStats)
+	if e.format == ast.ExplainFormatJSON {
+		doc, err := json.MarshalIndent(nodes[0], "", "  ")
+		if err != nil {
+			return errors.Trace(err)
+		}
+		e.rows = append(e.rows, &Row{Data: types.MakeDatums(string(doc))})
+		return nil
+	}
+	for i, node := range nodes {
+		id := node.ID
+		if depths[i] > 0 {
+			id = strings.Repeat("  ", depths[i]-1) + "└─" + id
+		}
+		row := &Row{Data: types.MakeDatums(id, node.EstimatedRows, node.ActualRows, node.Loops, node.Time, nil, nil)}
+		if node.CopTasks != nil {
+			row.Data[5].SetInt64(*node.CopTasks)
+			row.Data[6].SetInt64(*node.CopRetries)
+		}
+		e.rows = append(e.rows, row)
+	}
+	return nil
+}
@@ -14,7 +14,12 @@
 package executor_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 )
@@ -34,56 +39,133 @@ func (s *testSuite) TestExplain(c *C) {
 		{
 			"select * from t1",
 			[]string{
-				"1 | SIMPLE | t1 | ALL | <nil> | <nil> | <nil> | <nil> | 0 | <nil>",
+				"1 | SIMPLE | t1 | ALL | <nil> | <nil> | <nil> | <nil> | 10000 | <nil>",
 			},
 		},
 		{
 			"select * from t1 order by c2",
 			[]string{
-				"1 | SIMPLE | t1 | index | c2 | c2 | <nil> | <nil> | 0 | <nil>",
+				"1 | SIMPLE | t1 | index | c2 | c2 | <nil> | <nil> | 10000 | <nil>",
 			},
 		},
 		{
 			"select * from t2 order by c2",
 			[]string{
-				"1 | SIMPLE | t2 | ALL | <nil> | <nil> | <nil> | <nil> | 0 | Using filesort",
+				"1 | SIMPLE | t2 | ALL | <nil> | <nil> | <nil> | <nil> | 10000 | Using filesort",
 			},
 		},
 		{
 			"select * from t1 where t1.c1 > 0",
 			[]string{
-				"1 | SIMPLE | t1 | range | PRIMARY | PRIMARY | 8 | <nil> | 0 | Using where",
+				"1 | SIMPLE | t1 | range | PRIMARY | PRIMARY | 8 | <nil> | 3300 | Using where",
 			},
 		},
 		{
 			"select * from t1 where t1.c1 = 1",
 			[]string{
-				"1 | SIMPLE | t1 | const | PRIMARY | PRIMARY | 8 | <nil> | 0 | Using where",
+				"1 | SIMPLE | t1 | const | PRIMARY | PRIMARY | 8 | <nil> | 100 | Using where",
 			},
 		},
 		{
 			"select * from t1 where t1.c2 = 1",
 			[]string{
-				"1 | SIMPLE | t1 | range | c2 | c2 | -1 | <nil> | 0 | Using where",
+				"1 | SIMPLE | t1 | range | c2 | c2 | -1 | <nil> | 100 | Using where",
 			},
 		},
 		{
 			"select * from t1 left join t2 on t1.c2 = t2.c1 where t1.c1 > 1",
 			[]string{
-				"1 | SIMPLE | t1 | range | PRIMARY | PRIMARY | 8 | <nil> | 0 | Using where",
-				"1 | SIMPLE | t2 | eq_ref | c1 | c1 | -1 | <nil> | 0 | Using where",
+				"1 | SIMPLE | t1 | range | PRIMARY | PRIMARY | 8 | <nil> | 3300 | Using where",
+				"1 | SIMPLE | t2 | eq_ref | c1 | c1 | -1 | <nil> | 100 | Using where",
 			},
 		},
 		{
 			"update t1 set t1.c2 = 2 where t1.c1 = 1",
 			[]string{
-				"1 | SIMPLE | t1 | const | PRIMARY | PRIMARY | 8 | <nil> | 0 | Using where",
+				"1 | SIMPLE | t1 | const | PRIMARY | PRIMARY | 8 | <nil> | 100 | Using where",
 			},
 		},
 		{
 			"delete from t1 where t1.c2 = 1",
 			[]string{
-				"1 | SIMPLE | t1 | range | c2 | c2 | -1 | <nil> | 0 | Using where",
+				"1 | SIMPLE | t1 | range | c2 | c2 | -1 | <nil> | 100 | Using where",
+			},
+		},
+	}
+	for _, ca := range cases {
+		result := tk.MustQuery("explain " + ca.sql)
+		result.Check(testkit.RowsWithSep(" | ", ca.result...))
+	}
+}
+
+func (s *testSuite) TestExplainWithStatistics(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t1")
+	tk.MustExec("create table t (a int primary key, b int, c int, index b (b), index c (c), index bc (b, c))")
+	tk.MustExec("create table t1 (a int, b int, index b (b), index a (a))")
+	for i := 1; i <= 100; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t values (%d, %d, %d)", i, i%10, i))
+		tk.MustExec(fmt.Sprintf("insert into t1 values (1, %d)", i))
+	}
+
+	// Without statistics, the row count is guesstimated from the condition type.
+	tk.MustQuery("explain select * from t where b = 1").Check(testkit.RowsWithSep(" | ",
+		"1 | SIMPLE | t | range | bc | bc | -1 | <nil> | 100 | Using where",
+	))
+	tk.MustQuery("explain select * from t1 where a = 1 and b = 5").Check(testkit.RowsWithSep(" | ",
+		"1 | SIMPLE | t1 | range | a | a | -1 | <nil> | 100 | Using where",
+	))
+
+	tk.MustExec("analyze table t, t1")
+	cases := []struct {
+		sql    string
+		result []string
+	}{
+		{
+			"select * from t",
+			[]string{
+				"1 | SIMPLE | t | ALL | <nil> | <nil> | <nil> | <nil> | 100 | <nil>",
+			},
+		},
+		{
+			"select * from t where a > 90",
+			[]string{
+				"1 | SIMPLE | t | range | PRIMARY | PRIMARY | 8 | <nil> | 10 | Using where",
+			},
+		},
+		{
+			// Out of range, the histogram may be outdated.
+			"select * from t where a > 1000",
+			[]string{
+				"1 | SIMPLE | t | range | PRIMARY | PRIMARY | 8 | <nil> | 1 | Using where",
+			},
+		},
+		{
+			"select * from t where c = 5",
+			[]string{
+				"1 | SIMPLE | t | range | c | c | -1 | <nil> | 1 | Using where",
+			},
+		},
+		{
+			"select * from t where b = 1",
+			[]string{
+				"1 | SIMPLE | t | range | bc | bc | -1 | <nil> | 10 | Using where",
+			},
+		},
+		{
+			// The range of a multi-column index prefix.
+			"select * from t where b = 1 and c > 50",
+			[]string{
+				"1 | SIMPLE | t | range | bc | bc | -2 | <nil> | 5 | Using where",
+			},
+		},
+		{
+			// All the values of t1.a are 1, the index b is chosen.
+			"select * from t1 where a = 1 and b = 5",
+			[]string{
+				"1 | SIMPLE | t1 | range | b | b | -1 | <nil> | 1 | Using where",
 			},
 		},
 	}
@@ -92,3 +174,108 @@ func (s *testSuite) TestExplain(c *C) {
 		result.Check(testkit.RowsWithSep(" | ", ca.result...))
 	}
 }
+
+func (s *testSuite) TestExplainJoinReorder(c *C) {
+	plan.UseNewPlanner = true
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists f, d1, d2")
+	tk.MustExec("create table f (id int primary key, d1 int, d2 int)")
+	tk.MustExec("create table d1 (id int primary key, v int)")
+	tk.MustExec("create table d2 (id int primary key, v int)")
+	for i := 1; i <= 60; i++ {
+		tk.MustExec(fmt.Sprintf("insert into f values (%d, %d, %d)", i, i%5, i%3))
+	}
+	for i := 0; i < 5; i++ {
+		tk.MustExec(fmt.Sprintf("insert into d1 values (%d, %d)", i, i))
+	}
+	for i := 0; i < 3; i++ {
+		tk.MustExec(fmt.Sprintf("insert into d2 values (%d, %d)", i, i))
+	}
+	tk.MustExec("analyze table f, d1, d2")
+
+	// The fact table is the left child of the top join, the small dimension tables are joined first.
+	sql := "select f.id, d1.v, d2.v from d1 join f on d1.id = f.d1 join d2 on f.d2 = d2.id where d1.v < 10"
+	tk.MustQuery("explain " + sql).Check(testkit.RowsWithSep(" | ",
+		"1 | SIMPLE | f | ALL | <nil> | <nil> | <nil> | <nil> | 60 | <nil>",
+		"1 | SIMPLE | d2 | ALL | <nil> | <nil> | <nil> | <nil> | 3 | <nil>",
+		"1 | SIMPLE | d1 | ALL | <nil> | <nil> | <nil> | <nil> | 5 | Using where; Using join buffer (hash join)",
+	))
+	tk.MustQuery("select count(*), sum(x.v1), sum(x.v2) from (select f.id, d1.v as v1, d2.v as v2 from d1 join f on d1.id = f.d1 join d2 on f.d2 = d2.id where d1.v < 10) x").Check(testkit.Rows("60 120 60"))
+
+	// The outer join is not reordered.
+	sql = "select count(*) from f left join (d1 join d2 on d1.id = d2.id) on f.d1 = d1.id"
+	tk.MustQuery("explain " + sql).Check(testkit.RowsWithSep(" | ",
+		"1 | SIMPLE | f | ALL | <nil> | <nil> | <nil> | <nil> | 60 | <nil>",
+		"1 | SIMPLE | d1 | ALL | <nil> | <nil> | <nil> | <nil> | 5 | <nil>",
+		"1 | SIMPLE | d2 | ALL | <nil> | <nil> | <nil> | <nil> | 3 | Using merge join",
+	))
+	tk.MustQuery(sql).Check(testkit.Rows("60"))
+	plan.UseNewPlanner = false
+}
+
+// explainAnalyzeRows returns the rows of EXPLAIN ANALYZE without the time column, which isn't stable.
+func explainAnalyzeRows(tk *testkit.TestKit, sql string) []string {
+	var rows []string
+	for _, row := range tk.MustQuery("explain analyze " + sql).Rows() {
+		row = append(row[:4:4], row[5:]...)
+		rows = append(rows, strings.TrimSuffix(fmt.Sprintln(row...), "\n"))
+	}
+	return rows
+}
+
+func (s *testSuite) TestExplainAnalyze(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t1")
+	tk.MustExec("create table t (a int primary key, b int, index b (b))")
+	tk.MustExec("create table t1 (a int, b int)")
+	for i := 1; i <= 20; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t values (%d, %d)", i, i))
+		tk.MustExec(fmt.Sprintf("insert into t1 values (%d, %d)", i, i))
+	}
+	tk.MustExec("analyze table t, t1")
+
+	c.Assert(explainAnalyzeRows(tk, "select * from t where a > 5"), DeepEquals, []string{
+		"SelectFields_1 15 15 1 <nil> <nil>",
+		"└─XSelectTable_2 15 15 1 1 0",
+	})
+	// The inner table of the nested loop join is read for every outer row.
+	c.Assert(explainAnalyzeRows(tk, "select * from t1 join t on t.a = t1.a where t1.b > 5"), DeepEquals, []string{
+		"SelectFields_1 0 15 1 <nil> <nil>",
+		"└─JoinInner_2 0 15 1 <nil> <nil>",
+		"  └─XSelectTable_3 20 15 1 1 0",
+		"  └─XSelectTable_4 0.2 15 15 15 0",
+	})
+
+	rows := tk.MustQuery("explain analyze format = json select count(*) from t").Rows()
+	c.Assert(rows, HasLen, 1)
+	var node map[string]interface{}
+	c.Assert(json.Unmarshal([]byte(rows[0][0].(string)), &node), IsNil)
+	c.Assert(node["actual_rows"], Equals, float64(1))
+
+	// The explained DML statement is executed.
+	explainAnalyzeRows(tk, "update t set b = b + 1 where a = 1")
+	explainAnalyzeRows(tk, "delete from t1 where a > 10")
+	tk.MustQuery("select b from t where a = 1").Check(testkit.Rows("2"))
+	tk.MustQuery("select count(*) from t1").Check(testkit.Rows("10"))
+
+	_, err := tk.Exec("explain analyze format = xml select * from t")
+	c.Assert(err, NotNil)
+
+	plan.UseNewPlanner = true
+	c.Assert(explainAnalyzeRows(tk, "select * from t where a > 5"), DeepEquals, []string{
+		"Projection_1 20 15 1 <nil> <nil>",
+		"└─TableScan_2 20 15 1 1 0",
+	})
+	c.Assert(explainAnalyzeRows(tk, "select * from t join t1 on t.b = t1.b"), DeepEquals, []string{
+		"Projection_1 20 10 1 <nil> <nil>",
+		"└─Projection_2 20 10 1 <nil> <nil>",
+		"  └─HashJoin_3 20 10 1 <nil> <nil>",
+		"    └─TableScan_4 20 20 1 1 0",
+		"    └─TableScan_5 20 10 1 1 0",
+	})
+	plan.UseNewPlanner = false
+}
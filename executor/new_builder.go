@@ -14,12 +14,18 @@
 package executor
 
 import (
+	"math"
+
+	"github.com/golang/protobuf/proto"
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
 )
 
@@ -37,8 +43,20 @@ func composeCondition(conditions []expression.Expression) expression.Expression
 			composeCondition(conditions[length/2:])})
 }
 
-//TODO: select join algorithm during cbo phase.
 func (b *executorBuilder) buildJoin(v *plan.Join) Executor {
+	if v.JoinType == plan.SemiJoin || v.JoinType == plan.AntiSemiJoin {
+		return b.buildHashSemiJoin(v)
+	}
+	switch v.Algorithm {
+	case plan.IndexJoin:
+		return b.buildIndexJoin(v)
+	case plan.MergeJoin:
+		return b.buildMergeJoin(v)
+	}
+	return b.buildHashJoin(v)
+}
+
+func (b *executorBuilder) buildHashJoin(v *plan.Join) Executor {
 	e := &HashJoinExec{
 		schema:      v.GetSchema(),
 		otherFilter: composeCondition(v.OtherConditions),
@@ -89,9 +107,102 @@ func (b *executorBuilder) buildJoin(v *plan.Join) Executor {
 	return e
 }
 
+func (b *executorBuilder) buildHashSemiJoin(v *plan.Join) Executor {
+	e := &HashSemiJoinExec{
+		schema:      v.GetSchema(),
+		leftFilter:  composeCondition(v.LeftConditions),
+		rightFilter: composeCondition(v.RightConditions),
+		otherFilter: composeCondition(v.OtherConditions),
+		anti:        v.JoinType == plan.AntiSemiJoin,
+		withAux:     v.WithAux,
+		ctx:         b.ctx,
+	}
+	for _, eqCond := range v.EqualConditions {
+		e.leftKeys = append(e.leftKeys, eqCond.Args[0])
+		e.rightKeys = append(e.rightKeys, eqCond.Args[1])
+	}
+	for _, naCond := range v.NullAwareConditions {
+		e.leftNullAwareKeys = append(e.leftNullAwareKeys, naCond.Args[0])
+		e.rightNullAwareKeys = append(e.rightNullAwareKeys, naCond.Args[1])
+	}
+	e.leftExec, _ = b.build(v.GetChildByIndex(0)).(NewExecutor)
+	e.rightExec, _ = b.build(v.GetChildByIndex(1)).(NewExecutor)
+	return e
+}
+
+func (b *executorBuilder) buildIndexJoin(v *plan.Join) Executor {
+	e := &IndexJoinExec{
+		schema:      v.GetSchema(),
+		otherFilter: composeCondition(v.OtherConditions),
+		innerIdx:    v.InnerChildIdx,
+		outer:       v.JoinType != plan.InnerJoin,
+		ctx:         b.ctx,
+	}
+	for _, eqCond := range v.EqualConditions {
+		outerKey, _ := eqCond.Args[1-v.InnerChildIdx].(*expression.Column)
+		innerKey, _ := eqCond.Args[v.InnerChildIdx].(*expression.Column)
+		e.outerKeys = append(e.outerKeys, outerKey)
+		e.innerKeys = append(e.innerKeys, innerKey)
+	}
+	if v.InnerChildIdx == 0 {
+		e.outerFilter = composeCondition(v.RightConditions)
+		e.innerFilter = composeCondition(v.LeftConditions)
+	} else {
+		e.outerFilter = composeCondition(v.LeftConditions)
+		e.innerFilter = composeCondition(v.RightConditions)
+	}
+	e.outerExec, _ = b.build(v.GetChildByIndex(1 - v.InnerChildIdx)).(NewExecutor)
+	e.innerExec, _ = b.build(v.GetChildByIndex(v.InnerChildIdx)).(NewExecutor)
+	if b.err != nil {
+		return nil
+	}
+	e.innerScan = lookupScannerOf(e.innerExec)
+	if e.innerScan == nil {
+		b.err = ErrUnknownPlan.Gen("Unknown inner executor of index join")
+		return nil
+	}
+	return e
+}
+
+// lookupScannerOf returns the scan executor under the selections.
+func lookupScannerOf(e Executor) lookupScanner {
+	for {
+		switch x := unwrapExec(e).(type) {
+		case *SelectionExec:
+			e = x.Src
+		case lookupScanner:
+			return x
+		default:
+			return nil
+		}
+	}
+}
+
+func (b *executorBuilder) buildMergeJoin(v *plan.Join) Executor {
+	e := &MergeJoinExec{
+		schema:      v.GetSchema(),
+		otherFilter: composeCondition(v.OtherConditions),
+		ctx:         b.ctx,
+	}
+	e.leftKey, _ = v.EqualConditions[0].Args[0].(*expression.Column)
+	e.rightKey, _ = v.EqualConditions[0].Args[1].(*expression.Column)
+	e.leftExec, _ = b.build(v.GetChildByIndex(0)).(NewExecutor)
+	e.rightExec, _ = b.build(v.GetChildByIndex(1)).(NewExecutor)
+	return e
+}
+
 func (b *executorBuilder) buildAggregation(v *plan.Aggregation) Executor {
+	src := b.build(v.GetChildByIndex(0))
+	if tableScan, ok := unwrapExec(src).(*NewTableScanExec); ok && v.PushDownHint != plan.NoAggToCop {
+		if e := b.pushDownAggregation(v, src, tableScan); e != nil {
+			return e
+		}
+	}
+	if v.PushDownHint == plan.AggToCop {
+		b.appendInapplicableHintWarning(v.PushDownHint)
+	}
 	return &AggregationExec{
-		Src:          b.build(v.GetChildByIndex(0)).(NewExecutor),
+		Src:          src.(NewExecutor),
 		schema:       v.GetSchema(),
 		ctx:          b.ctx,
 		AggFuncs:     v.AggFuncs,
@@ -99,6 +210,144 @@ func (b *executorBuilder) buildAggregation(v *plan.Aggregation) Executor {
 	}
 }
 
+// pushDownAggregation pushes the aggregation down to the table scan, every region returns the partial results
+// of its groups, and the returned executor merges them. It returns nil if the aggregation can't be pushed down.
+// The src is the table scan, or the one wrapped by EXPLAIN ANALYZE.
+func (b *executorBuilder) pushDownAggregation(v *plan.Aggregation, src Executor, tableScan *NewTableScanExec) Executor {
+	txn, err := b.ctx.GetTxn(false)
+	if err != nil {
+		b.err = err
+		return nil
+	}
+	client := txn.GetClient()
+	if len(v.GroupByItems) > 0 && !client.SupportRequestType(kv.ReqTypeSelect, kv.ReqSubTypeGroupBy) {
+		return nil
+	}
+	pbAggFuncs := make([]*tipb.Expr, 0, len(v.AggFuncs))
+	for _, af := range v.AggFuncs {
+		if af.IsDistinct() {
+			return nil
+		}
+		pbAggFunc := b.aggFuncToNewPBExpr(client, af, tableScan.tableInfo)
+		if pbAggFunc == nil {
+			return nil
+		}
+		pbAggFuncs = append(pbAggFuncs, pbAggFunc)
+	}
+	pbByItems := make([]*tipb.ByItem, 0, len(v.GroupByItems))
+	for _, item := range v.GroupByItems {
+		pbExpr := b.newExprToPBExpr(client, item, tableScan.tableInfo)
+		if pbExpr == nil {
+			return nil
+		}
+		pbByItems = append(pbByItems, &tipb.ByItem{Expr: pbExpr})
+	}
+	// The first field of a partial result row is the group key, and every aggregate function has one or two fields.
+	gk := types.NewFieldType(mysql.TypeBlob)
+	gk.Charset = charset.CharsetBin
+	gk.Collate = charset.CollationBin
+	fields := []*types.FieldType{gk}
+	finalAggFuncs := make([]expression.AggregationFunction, 0, len(v.AggFuncs))
+	for _, af := range v.AggFuncs {
+		var args []expression.Expression
+		if needCount(af.GetName()) {
+			ft := types.NewFieldType(mysql.TypeLonglong)
+			ft.Flen = 21
+			ft.Charset = charset.CharsetBin
+			ft.Collate = charset.CollationBin
+			args = append(args, &expression.Column{Index: len(fields), RetType: ft})
+			fields = append(fields, ft)
+		}
+		if needValue(af.GetName()) {
+			ft := partialValueType(af)
+			if ft == nil {
+				return nil
+			}
+			args = append(args, &expression.Column{Index: len(fields), RetType: ft})
+			fields = append(fields, ft)
+		}
+		finalAggFunc := expression.NewAggFunction(af.GetName(), args, false)
+		finalAggFunc.SetMode(expression.FinalMode)
+		finalAggFuncs = append(finalAggFuncs, finalAggFunc)
+	}
+	tableScan.aggFuncs = pbAggFuncs
+	tableScan.byItems = pbByItems
+	tableScan.aggFields = fields
+	e := &AggregationExec{
+		Src:      src.(NewExecutor),
+		schema:   v.GetSchema(),
+		ctx:      b.ctx,
+		AggFuncs: finalAggFuncs,
+	}
+	if len(v.GroupByItems) > 0 {
+		// The partial results are grouped by the group key.
+		e.GroupByItems = []expression.Expression{&expression.Column{Index: 0, RetType: gk}}
+	}
+	return e
+}
+
+// partialValueType returns the field type of the partial value of the aggregate function, it returns nil if it's unknown.
+func partialValueType(af expression.AggregationFunction) *types.FieldType {
+	argType := af.GetArgs()[0].GetType()
+	if argType == nil {
+		return nil
+	}
+	switch af.GetName() {
+	case ast.AggFuncSum, ast.AggFuncAvg:
+		return partialSumType(argType)
+	}
+	return argType
+}
+
+// partialSumType returns the field type of the partial sum of the argument type.
+// The sum of integers and decimals is a decimal of the same scale, the sum of the others is a float.
+func partialSumType(argType *types.FieldType) *types.FieldType {
+	switch argType.Tp {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
+		ft := types.NewFieldType(mysql.TypeNewDecimal)
+		ft.Decimal = 0
+		return ft
+	case mysql.TypeNewDecimal:
+		ft := types.NewFieldType(mysql.TypeNewDecimal)
+		ft.Decimal = argType.Decimal
+		return ft
+	}
+	return types.NewFieldType(mysql.TypeDouble)
+}
+
+func (b *executorBuilder) aggFuncToNewPBExpr(client kv.Client, af expression.AggregationFunction,
+	tbl *model.TableInfo) *tipb.Expr {
+	var tp tipb.ExprType
+	switch af.GetName() {
+	case ast.AggFuncCount:
+		tp = tipb.ExprType_Count
+	case ast.AggFuncFirstRow:
+		tp = tipb.ExprType_First
+	case ast.AggFuncMax:
+		tp = tipb.ExprType_Max
+	case ast.AggFuncMin:
+		tp = tipb.ExprType_Min
+	case ast.AggFuncSum:
+		tp = tipb.ExprType_Sum
+	case ast.AggFuncAvg:
+		tp = tipb.ExprType_Avg
+	default:
+		return nil
+	}
+	if !client.SupportRequestType(kv.ReqTypeSelect, int64(tp)) {
+		return nil
+	}
+	children := make([]*tipb.Expr, 0, len(af.GetArgs()))
+	for _, arg := range af.GetArgs() {
+		pbArg := b.newExprToPBExpr(client, arg, tbl)
+		if pbArg == nil {
+			return nil
+		}
+		children = append(children, pbArg)
+	}
+	return &tipb.Expr{Tp: tp.Enum(), Children: children}
+}
+
 func (b *executorBuilder) toPBExpr(conditions []expression.Expression, tbl *model.TableInfo) (
 	*tipb.Expr, []expression.Expression) {
 	txn, err := b.ctx.GetTxn(false)
@@ -112,11 +361,11 @@ func (b *executorBuilder) toPBExpr(conditions []expression.Expression, tbl *mode
 
 func (b *executorBuilder) buildSelection(v *plan.Selection) Executor {
 	exec := b.build(v.GetChildByIndex(0))
-	switch exec.(type) {
+	switch x := unwrapExec(exec).(type) {
 	case *NewTableScanExec:
-		tableScan := exec.(*NewTableScanExec)
-		tableScan.where, v.Conditions = b.toPBExpr(v.Conditions, tableScan.tableInfo)
-		// TODO: Implement NewIndexScan
+		x.where, v.Conditions = b.toPBExpr(v.Conditions, x.tableInfo)
+	case *NewIndexScanExec:
+		x.where, v.Conditions = b.toPBExpr(v.Conditions, x.tableInfo)
 	}
 
 	if len(v.Conditions) == 0 {
@@ -156,6 +405,22 @@ func (b *executorBuilder) buildNewTableScan(v *plan.NewTableScan) Executor {
 	supportDesc := client.SupportRequestType(kv.ReqTypeSelect, kv.ReqSubTypeDesc)
 	if !memDB && client.SupportRequestType(kv.ReqTypeSelect, 0) {
 		// TODO: support union scan exec.
+		if v.Index != nil {
+			ranges := v.IndexRanges
+			if ranges == nil {
+				ranges = fullIndexRanges()
+			}
+			return &NewIndexScanExec{
+				tableInfo: v.Table,
+				index:     v.Index,
+				ctx:       b.ctx,
+				asName:    v.TableAsName,
+				table:     table,
+				schema:    v.GetSchema(),
+				Columns:   v.Columns,
+				ranges:    ranges,
+			}
+		}
 		return &NewTableScanExec{
 			tableInfo:   v.Table,
 			ctx:         b.ctx,
@@ -171,6 +436,14 @@ func (b *executorBuilder) buildNewTableScan(v *plan.NewTableScan) Executor {
 	return nil
 }
 
+// fullIndexRanges returns the index ranges which contain all the index entries.
+func fullIndexRanges() []*plan.IndexRange {
+	return []*plan.IndexRange{{
+		LowVal:  []types.Datum{{}},
+		HighVal: []types.Datum{types.MaxValueDatum()},
+	}}
+}
+
 func (b *executorBuilder) buildNewSort(v *plan.NewSort) Executor {
 	src := b.build(v.GetChildByIndex(0))
 	return &NewSortExec{
@@ -181,14 +454,89 @@ func (b *executorBuilder) buildNewSort(v *plan.NewSort) Executor {
 	}
 }
 
+// maxTopNCount is the max offset+count of a limit which is executed by a top n, the rows of a larger limit are
+// sorted by the sort executor and then skipped and limited.
+const maxTopNCount = 1 << 20
+
+func (b *executorBuilder) buildTopN(v *plan.Limit, sort *plan.NewSort, totalCount int) Executor {
+	src := b.build(sort.GetChildByIndex(0))
+	if tableScan := limitScanOf(src); tableScan != nil {
+		b.pushDownLimit(tableScan, sort.ByItems, uint64(totalCount))
+	}
+	return &TopnExec{
+		NewSortExec: NewSortExec{
+			Src:     src.(NewExecutor),
+			ByItems: sort.ByItems,
+			ctx:     b.ctx,
+			schema:  v.GetSchema(),
+		},
+		limit:      v,
+		totalCount: totalCount,
+	}
+}
+
+// limitScanOf returns the table scan which returns the rows of the executor one by one, so a limit of the executor
+// can be pushed down to it. The projected columns keep the names of the table columns, the order by items of the
+// columns are still converted to the table columns.
+func limitScanOf(e Executor) *NewTableScanExec {
+	switch x := unwrapExec(e).(type) {
+	case *NewTableScanExec:
+		return x
+	case *ProjectionExec:
+		return limitScanOf(x.Src)
+	}
+	return nil
+}
+
+// pushDownLimit pushes the limit down to the table scan if the store supports it, every region returns
+// at most count rows. If byItems isn't nil, the rows are the first ones in the order of the items.
+func (b *executorBuilder) pushDownLimit(tableScan *NewTableScanExec, byItems []plan.ByItems, count uint64) {
+	if tableScan.aggFuncs != nil || count > math.MaxInt64 {
+		return
+	}
+	txn, err := b.ctx.GetTxn(false)
+	if err != nil {
+		b.err = err
+		return
+	}
+	client := txn.GetClient()
+	var orderBy []*tipb.ByItem
+	if byItems != nil {
+		if !client.SupportRequestType(kv.ReqTypeSelect, kv.ReqSubTypeTopN) {
+			return
+		}
+		orderBy = make([]*tipb.ByItem, 0, len(byItems))
+		for _, item := range byItems {
+			pbExpr := b.newExprToPBExpr(client, item.Expr, tableScan.tableInfo)
+			if pbExpr == nil {
+				return
+			}
+			orderBy = append(orderBy, &tipb.ByItem{Expr: pbExpr, Desc: proto.Bool(item.Desc)})
+		}
+	}
+	tableScan.orderBy = orderBy
+	tableScan.limitCount = proto.Int64(int64(count))
+}
+
 func (b *executorBuilder) buildApply(v *plan.Apply) Executor {
 	src := b.build(v.GetChildByIndex(0))
-	return &ApplyExec{
+	e := &ApplyExec{
 		schema:      v.GetSchema(),
 		innerExec:   b.build(v.InnerPlan).(NewExecutor),
 		outerSchema: v.OuterSchema,
 		Src:         src.(NewExecutor),
 	}
+	if v.Checker != nil {
+		e.checker = &conditionChecker{
+			anti:    v.Checker.Anti,
+			withAux: v.Checker.WithAux,
+			ctx:     b.ctx,
+		}
+		if v.Checker.Condition != nil {
+			e.checker.cond = v.Checker.Condition
+		}
+	}
+	return e
 }
 
 func (b *executorBuilder) buildExists(v *plan.Exists) Executor {
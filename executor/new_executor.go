@@ -14,6 +14,8 @@
 package executor
 
 import (
+	"container/heap"
+	"math"
 	"sort"
 	"time"
 
@@ -27,6 +29,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/types"
@@ -77,20 +80,24 @@ func joinTwoRow(a *Row, b *Row) *Row {
 	return ret
 }
 
-func (e *HashJoinExec) getHashKey(exprs []*expression.Column, row *Row) ([]byte, error) {
+// getHashKey returns the hash key of the row, hasNull is true if any key is NULL, the row doesn't match any row then.
+func (e *HashJoinExec) getHashKey(exprs []*expression.Column, row *Row) (hasNull bool, key []byte, err error) {
 	vals := make([]types.Datum, 0, len(exprs))
 	for _, expr := range exprs {
 		v, err := expr.Eval(row.Data, e.ctx)
 		if err != nil {
-			return nil, errors.Trace(err)
+			return false, nil, errors.Trace(err)
+		}
+		if v.IsNull() {
+			return true, nil, nil
 		}
 		vals = append(vals, v)
 	}
 	if len(vals) == 0 {
-		return []byte{}, nil
+		return false, []byte{}, nil
 	}
-
-	return codec.EncodeValue([]byte{}, vals...)
+	key, err = codec.EncodeValue([]byte{}, vals...)
+	return false, key, errors.Trace(err)
 }
 
 // Schema implements Executor Schema interface.
@@ -133,10 +140,13 @@ func (e *HashJoinExec) prepare() error {
 				continue
 			}
 		}
-		hashcode, err := e.getHashKey(e.smallHashKey, row)
+		hasNull, hashcode, err := e.getHashKey(e.smallHashKey, row)
 		if err != nil {
 			return errors.Trace(err)
 		}
+		if hasNull {
+			continue
+		}
 		if rows, ok := e.hashTable[string(hashcode)]; !ok {
 			e.hashTable[string(hashcode)] = []*Row{row}
 		} else {
@@ -149,10 +159,13 @@ func (e *HashJoinExec) prepare() error {
 }
 
 func (e *HashJoinExec) constructMatchedRows(bigRow *Row) (matchedRows []*Row, err error) {
-	hashcode, err := e.getHashKey(e.bigHashKey, bigRow)
+	hasNull, hashcode, err := e.getHashKey(e.bigHashKey, bigRow)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if hasNull {
+		return
+	}
 
 	rows, ok := e.hashTable[string(hashcode)]
 	if !ok {
@@ -519,6 +532,21 @@ type NewTableScanExec struct {
 	Columns     []*model.ColumnInfo
 	schema      expression.Schema
 	ranges      []plan.TableRange
+	// fullRanges are the ranges before the scan is restricted by setLookupKeys.
+	fullRanges []plan.TableRange
+
+	// The aggregation pushed down, the scan returns the partial results of the aggregate functions.
+	aggFuncs  []*tipb.Expr
+	byItems   []*tipb.ByItem
+	aggFields []*types.FieldType
+
+	// The limit pushed down, every region returns at most limitCount rows.
+	// If orderBy is set, the rows are the first ones in the order of it.
+	limitCount *int64
+	orderBy    []*tipb.ByItem
+
+	// copStats is set if the scan is analyzed by EXPLAIN ANALYZE.
+	copStats *kv.CopStats
 }
 
 // Schema implements Executor Schema interface.
@@ -541,22 +569,40 @@ func (e *NewTableScanExec) doRequest() error {
 		TableId: proto.Int64(e.tableInfo.ID),
 	}
 	selReq.TableInfo.Columns = tablecodec.ColumnsToProto(columns, e.tableInfo.PKIsHandle)
-	e.result, err = xapi.Select(txn.GetClient(), selReq, defaultConcurrency)
+	selReq.Aggregates = e.aggFuncs
+	selReq.GroupBy = e.byItems
+	selReq.OrderBy = e.orderBy
+	selReq.Limit = e.limitCount
+	e.result, err = xapi.Select(txn.GetClient(), selReq, defaultConcurrency, variable.GetSessionVars(e.ctx).StmtDeadline, copStatsOf(e.ctx, e.copStats))
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if e.aggFields != nil {
+		// The returned rows are the partial results of the aggregation.
+		e.result.SetFields(e.aggFields)
+	}
 	return nil
 }
 
 // Init implements NewExecutor Init interface.
 func (e *NewTableScanExec) Init() {
+	// The executor may be initialized again without being closed, e.g. the inner executor of IndexJoinExec.
+	if e.result != nil {
+		e.result.Close()
+	}
 	e.result = nil
 	e.subResult = nil
 }
 
 // Next implements Executor interface.
 func (e *NewTableScanExec) Next() (*Row, error) {
+	if err := checkKilled(e.ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
 	if e.result == nil {
+		if len(e.ranges) == 0 {
+			return nil, nil
+		}
 		err := e.doRequest()
 		if err != nil {
 			return nil, errors.Trace(err)
@@ -583,6 +629,7 @@ func (e *NewTableScanExec) Next() (*Row, error) {
 			e.subResult = nil
 			continue
 		}
+		addRowsExamined(e.ctx)
 		return resultRowToRow(e.table, h, rowData, e.asName), nil
 	}
 }
@@ -594,9 +641,234 @@ func (e *NewTableScanExec) Fields() []*ast.ResultField {
 
 // Close implements Executor interface.
 func (e *NewTableScanExec) Close() error {
+	e.subResult = nil
+	if e.result == nil {
+		return nil
+	}
+	err := e.result.Close()
+	e.result = nil
+	return errors.Trace(err)
+}
+
+// lookupScanner is a scan executor which can be restricted to the rows of some keys,
+// it's used as the inner executor of IndexJoinExec.
+type lookupScanner interface {
+	// setLookupKeys restricts the scan to the rows of the keys, it takes effect after Init.
+	setLookupKeys(keys [][]types.Datum)
+}
+
+// setLookupKeys implements lookupScanner interface, the keys are handles.
+func (e *NewTableScanExec) setLookupKeys(keys [][]types.Datum) {
+	if e.fullRanges == nil {
+		e.fullRanges = e.ranges
+	}
+	handles := make([]int64, 0, len(keys))
+	for _, key := range keys {
+		handles = append(handles, key[0].GetInt64())
+	}
+	sort.Sort(int64Slice(handles))
+	e.ranges = e.ranges[:0:0]
+	for i, h := range handles {
+		if i > 0 && h == handles[i-1] {
+			continue
+		}
+		for _, ran := range e.fullRanges {
+			if h >= ran.LowVal && h <= ran.HighVal {
+				e.ranges = append(e.ranges, plan.TableRange{LowVal: h, HighVal: h})
+				break
+			}
+		}
+	}
+}
+
+// NewIndexScanExec is an executor which reads the table by an index, the rows are returned in the order of the index.
+type NewIndexScanExec struct {
+	tableInfo *model.TableInfo
+	table     table.Table
+	index     *model.IndexInfo
+	asName    *model.CIStr
+	ctx       context.Context
+	where     *tipb.Expr
+	Columns   []*model.ColumnInfo
+	schema    expression.Schema
+	ranges    []*plan.IndexRange
+	idxResult *xapi.SelectResult
+	rows      []*Row
+	cursor    int
+	// copStats is set if the scan is analyzed by EXPLAIN ANALYZE.
+	copStats *kv.CopStats
+}
+
+// Schema implements Executor Schema interface.
+func (e *NewIndexScanExec) Schema() expression.Schema {
+	return e.schema
+}
+
+// setLookupKeys implements lookupScanner interface, the keys are the values of the index prefix.
+func (e *NewIndexScanExec) setLookupKeys(keys [][]types.Datum) {
+	e.ranges = make([]*plan.IndexRange, 0, len(keys))
+	for _, key := range keys {
+		e.ranges = append(e.ranges, &plan.IndexRange{
+			LowVal:  append([]types.Datum(nil), key...),
+			HighVal: append([]types.Datum(nil), key...),
+		})
+	}
+}
+
+func (e *NewIndexScanExec) doIndexRequest() error {
+	txn, err := e.ctx.GetTxn(false)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	selIdxReq := new(tipb.SelectRequest)
+	startTs := txn.StartTS()
+	selIdxReq.StartTs = &startTs
+	selIdxReq.IndexInfo = tablecodec.IndexToProto(e.tableInfo, e.index)
+	fieldTypes := make([]*types.FieldType, len(e.index.Columns))
+	for i, v := range e.index.Columns {
+		fieldTypes[i] = &(e.tableInfo.Columns[v.Offset].FieldType)
+	}
+	selIdxReq.Ranges, err = indexRangesToPBRanges(e.ranges, fieldTypes)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// The handles are read in the order of the index.
+	e.idxResult, err = xapi.Select(txn.GetClient(), selIdxReq, 1, variable.GetSessionVars(e.ctx).StmtDeadline, copStatsOf(e.ctx, e.copStats))
+	return errors.Trace(err)
+}
+
+func (e *NewIndexScanExec) doTableRequest(handles []int64) (*xapi.SelectResult, error) {
+	txn, err := e.ctx.GetTxn(false)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	selTableReq := new(tipb.SelectRequest)
+	startTs := txn.StartTS()
+	selTableReq.StartTs = &startTs
+	selTableReq.TableInfo = &tipb.TableInfo{
+		TableId: proto.Int64(e.tableInfo.ID),
+	}
+	selTableReq.TableInfo.Columns = tablecodec.ColumnsToProto(e.Columns, e.tableInfo.PKIsHandle)
+	for _, h := range handles {
+		if h == math.MaxInt64 {
+			// We can't convert MaxInt64 into an left closed, right open range.
+			continue
+		}
+		pbRange := new(tipb.KeyRange)
+		pbRange.Low = codec.EncodeInt(nil, h)
+		pbRange.High = kv.Key(pbRange.Low).PrefixNext()
+		selTableReq.Ranges = append(selTableReq.Ranges, pbRange)
+	}
+	selTableReq.Where = e.where
+	return xapi.Select(txn.GetClient(), selTableReq, defaultConcurrency, variable.GetSessionVars(e.ctx).StmtDeadline, copStatsOf(e.ctx, e.copStats))
+}
+
+// fetchRows reads the rows of the handles in the next index sub result, the rows are sorted in the order of the index.
+// It returns false if the index result is exhausted.
+func (e *NewIndexScanExec) fetchRows() (bool, error) {
+	subResult, err := e.idxResult.Next()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if subResult == nil {
+		return false, nil
+	}
+	handles, err := extractHandlesFromIndexSubResult(subResult)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	e.rows, e.cursor = e.rows[:0], 0
+	if len(handles) == 0 {
+		return true, nil
+	}
+	tblResult, err := e.doTableRequest(handles)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	// The table result is drained or abandoned by an error here, it's closed to stop the coprocessor workers.
+	defer tblResult.Close()
+	for {
+		tblSubResult, err := tblResult.Next()
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if tblSubResult == nil {
+			break
+		}
+		for {
+			h, rowData, err := tblSubResult.Next()
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			if rowData == nil {
+				break
+			}
+			addRowsExamined(e.ctx)
+			e.rows = append(e.rows, resultRowToRow(e.table, h, rowData, e.asName))
+		}
+	}
+	order := make(map[int64]int, len(handles))
+	for i, h := range handles {
+		order[h] = i
+	}
+	sort.Sort(&rowsSorter{order: order, rows: e.rows})
+	return true, nil
+}
+
+// Init implements NewExecutor Init interface.
+func (e *NewIndexScanExec) Init() {
+	// The executor may be initialized again without being closed, e.g. the inner executor of IndexJoinExec.
+	if e.idxResult != nil {
+		e.idxResult.Close()
+	}
+	e.idxResult = nil
+	e.rows = nil
+	e.cursor = 0
+}
+
+// Next implements Executor interface.
+func (e *NewIndexScanExec) Next() (*Row, error) {
+	if err := checkKilled(e.ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if e.idxResult == nil {
+		if len(e.ranges) == 0 {
+			return nil, nil
+		}
+		if err := e.doIndexRequest(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	for e.cursor >= len(e.rows) {
+		ok, err := e.fetchRows()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !ok {
+			return nil, nil
+		}
+	}
+	e.cursor++
+	return e.rows[e.cursor-1], nil
+}
+
+// Fields implements Executor interface.
+func (e *NewIndexScanExec) Fields() []*ast.ResultField {
 	return nil
 }
 
+// Close implements Executor interface.
+// The index result is closed to stop the coprocessor workers, which are blocked if the result isn't drained.
+func (e *NewIndexScanExec) Close() error {
+	e.rows = nil
+	if e.idxResult == nil {
+		return nil
+	}
+	err := e.idxResult.Close()
+	e.idxResult = nil
+	return errors.Trace(err)
+}
+
 // NewSortExec represents sorting executor.
 type NewSortExec struct {
 	Src     NewExecutor
@@ -639,9 +911,14 @@ func (e *NewSortExec) Swap(i, j int) {
 
 // Less implements sort.Interface Less interface.
 func (e *NewSortExec) Less(i, j int) bool {
+	return e.lessRow(e.Rows[i], e.Rows[j])
+}
+
+// lessRow returns true if rowI is in front of rowJ in the order.
+func (e *NewSortExec) lessRow(rowI, rowJ *orderByRow) bool {
 	for index, by := range e.ByItems {
-		v1 := e.Rows[i].key[index]
-		v2 := e.Rows[j].key[index]
+		v1 := rowI.key[index]
+		v2 := rowJ.key[index]
 
 		ret, err := v1.CompareDatum(v2)
 		if err != nil {
@@ -719,6 +996,96 @@ func (e *NewSortExec) Next() (*Row, error) {
 	return row, nil
 }
 
+// TopnExec implements a Top-N algorithm, it keeps the first offset+count rows of the source in a heap,
+// so the rows of all the regions are merged without sorting all of them.
+type TopnExec struct {
+	NewSortExec
+	limit *plan.Limit
+	// totalCount is offset+count of the limit, it's not more than maxTopNCount.
+	totalCount int
+	heapSize   int
+}
+
+// Init implements NewExecutor Init interface.
+func (e *TopnExec) Init() {
+	e.NewSortExec.Init()
+	e.Idx = 0
+	e.heapSize = 0
+}
+
+// Less implements heap.Interface Less interface, the root of the heap is the last row in the order.
+func (e *TopnExec) Less(i, j int) bool {
+	return e.NewSortExec.Less(j, i)
+}
+
+// Len implements heap.Interface Len interface.
+func (e *TopnExec) Len() int {
+	return e.heapSize
+}
+
+// Push implements heap.Interface Push interface.
+func (e *TopnExec) Push(x interface{}) {
+	e.Rows = append(e.Rows, x.(*orderByRow))
+	e.heapSize++
+}
+
+// Pop implements heap.Interface Pop interface.
+func (e *TopnExec) Pop() interface{} {
+	e.heapSize--
+	return nil
+}
+
+// Next implements Executor Next interface.
+func (e *TopnExec) Next() (*Row, error) {
+	if !e.fetched {
+		e.Idx = int(e.limit.Offset)
+		e.Rows = nil
+		for e.totalCount > 0 {
+			srcRow, err := e.Src.Next()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if srcRow == nil {
+				break
+			}
+			orderRow := &orderByRow{
+				row: srcRow,
+				key: make([]types.Datum, len(e.ByItems)),
+			}
+			for i, byItem := range e.ByItems {
+				orderRow.key[i], err = byItem.Expr.Eval(srcRow.Data, e.ctx)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+			}
+			if e.heapSize < e.totalCount {
+				heap.Push(e, orderRow)
+			} else if e.lessRow(orderRow, e.Rows[0]) {
+				// Replace the last row in the heap as the new row is in front of it.
+				e.Rows[0] = orderRow
+				heap.Fix(e, 0)
+			}
+			if e.err != nil {
+				return nil, errors.Trace(e.err)
+			}
+		}
+		// Pop all the rows, the rows are put in order from the back of the slice.
+		for e.heapSize > 0 {
+			heap.Pop(e)
+		}
+		e.fetched = true
+	}
+	if e.err != nil {
+		return nil, errors.Trace(e.err)
+	}
+	if e.Idx >= len(e.Rows) {
+		return nil, nil
+	}
+	row := e.Rows[e.Idx].row
+	e.Idx++
+	return row, nil
+}
+
 // Close implements Executor Close interface.
 func (e *NewSortExec) Close() error {
 	return e.Src.Close()
@@ -851,6 +1218,48 @@ type ApplyExec struct {
 	Src         NewExecutor
 	outerSchema expression.Schema
 	innerExec   NewExecutor
+	// checker is set if the apply is a semi apply.
+	checker *conditionChecker
+}
+
+// conditionChecker checks whether the outer row of semi apply matches the inner rows.
+type conditionChecker struct {
+	cond    expression.Expression
+	anti    bool
+	withAux bool
+	ctx     context.Context
+}
+
+// check reads the inner rows until one of them matches the outer row, unknown is true if the condition is NULL
+// for some inner rows but isn't true for any of them. Any inner row matches if the condition is nil.
+func (c *conditionChecker) check(outerRow *Row, innerExec NewExecutor) (matched, unknown bool, err error) {
+	for {
+		innerRow, err := innerExec.Next()
+		if err != nil {
+			return false, false, errors.Trace(err)
+		}
+		if innerRow == nil {
+			return false, unknown, nil
+		}
+		if c.cond == nil {
+			return true, false, nil
+		}
+		v, err := c.cond.Eval(joinTwoRow(outerRow, innerRow).Data, c.ctx)
+		if err != nil {
+			return false, false, errors.Trace(err)
+		}
+		if v.IsNull() {
+			unknown = true
+			continue
+		}
+		b, err := v.ToBool()
+		if err != nil {
+			return false, false, errors.Trace(err)
+		}
+		if b != 0 {
+			return true, false, nil
+		}
+	}
 }
 
 // Init implements NewExecutor Init interface.
@@ -875,24 +1284,35 @@ func (e *ApplyExec) Close() error {
 
 // Next implements Executor Next interface.
 func (e *ApplyExec) Next() (*Row, error) {
-	srcRow, err := e.Src.Next()
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-	if srcRow == nil {
-		return nil, nil
-	}
-	e.innerExec.Init()
-	for _, col := range e.outerSchema {
-		idx := col.Index
-		col.SetValue(&srcRow.Data[idx])
-	}
-	outerRow, err := e.innerExec.Next()
-	if err != nil {
-		return nil, errors.Trace(err)
+	for {
+		srcRow, err := e.Src.Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if srcRow == nil {
+			return nil, nil
+		}
+		e.innerExec.Init()
+		for _, col := range e.outerSchema {
+			idx := col.Index
+			col.SetValue(&srcRow.Data[idx])
+		}
+		if e.checker == nil {
+			outerRow, err := e.innerExec.Next()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			srcRow.Data = append(srcRow.Data, outerRow.Data...)
+			return srcRow, nil
+		}
+		matched, unknown, err := e.checker.check(srcRow, e.innerExec)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row := semiJoinOutput(srcRow, matched, unknown, e.checker.anti, e.checker.withAux); row != nil {
+			return row, nil
+		}
 	}
-	srcRow.Data = append(srcRow.Data, outerRow.Data...)
-	return srcRow, nil
 }
 
 // ExistsExec represents exists executor.
@@ -0,0 +1,668 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// indexJoinBatchSize is the number of outer rows whose keys are looked up in the inner table at a time.
+const indexJoinBatchSize = 256
+
+// IndexJoinExec implements the index nested loop join algorithm. It reads a batch of outer rows,
+// then looks up the rows of their keys in the inner table by the handle or an index.
+type IndexJoinExec struct {
+	outerExec NewExecutor
+	innerExec NewExecutor
+	// innerScan is the table scan of innerExec, the lookup keys are set to it.
+	innerScan lookupScanner
+	outerKeys []*expression.Column
+	innerKeys []*expression.Column
+	// innerIdx is the index of the inner child in the join.
+	innerIdx    int
+	outer       bool
+	outerFilter expression.Expression
+	innerFilter expression.Expression
+	otherFilter expression.Expression
+	ctx         context.Context
+	schema      expression.Schema
+
+	outerExhausted bool
+	resultRows     []*Row
+	cursor         int
+}
+
+// Schema implements Executor Schema interface.
+func (e *IndexJoinExec) Schema() expression.Schema {
+	return e.schema
+}
+
+// Fields implements Executor Fields interface.
+func (e *IndexJoinExec) Fields() []*ast.ResultField {
+	return nil
+}
+
+// Init implements NewExecutor Init interface.
+func (e *IndexJoinExec) Init() {
+	e.outerExec.Init()
+	e.outerExhausted = false
+	e.resultRows = nil
+	e.cursor = 0
+}
+
+// Close implements Executor Close interface.
+func (e *IndexJoinExec) Close() error {
+	e.resultRows = nil
+	if err := e.outerExec.Close(); err != nil {
+		return errors.Trace(err)
+	}
+	return e.innerExec.Close()
+}
+
+// Next implements Executor Next interface.
+func (e *IndexJoinExec) Next() (*Row, error) {
+	for e.cursor >= len(e.resultRows) {
+		if e.outerExhausted {
+			return nil, nil
+		}
+		if err := e.joinNextBatch(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	e.cursor++
+	return e.resultRows[e.cursor-1], nil
+}
+
+// outerKey returns the lookup key of the outer row, it returns nil if the row can't match any inner row.
+func (e *IndexJoinExec) outerKey(row *Row) ([]types.Datum, error) {
+	if e.outerFilter != nil {
+		matched, err := expression.EvalBool(e.outerFilter, row.Data, e.ctx)
+		if err != nil || !matched {
+			return nil, errors.Trace(err)
+		}
+	}
+	key := make([]types.Datum, 0, len(e.outerKeys))
+	for i, col := range e.outerKeys {
+		v, err := col.Eval(row.Data, e.ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if v.IsNull() {
+			return nil, nil
+		}
+		// The key is converted to the type of the inner column to look up the inner table. The value which can't be
+		// converted, like the one out of the range of the inner integer type, can't match any inner row.
+		converted, err := v.ConvertTo(e.innerKeys[i].RetType)
+		if err != nil {
+			return nil, nil
+		}
+		cmp, err := converted.CompareDatum(v)
+		if err != nil || cmp != 0 {
+			return nil, errors.Trace(err)
+		}
+		key = append(key, converted)
+	}
+	return key, nil
+}
+
+func (e *IndexJoinExec) joinNextBatch() error {
+	e.resultRows, e.cursor = e.resultRows[:0], 0
+	var outerRows []*Row
+	var lookupKeys [][]types.Datum
+	outerKeys := make([][]byte, 0, indexJoinBatchSize)
+	keySet := make(map[string]struct{})
+	for len(outerRows) < indexJoinBatchSize {
+		row, err := e.outerExec.Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			e.outerExhausted = true
+			break
+		}
+		key, err := e.outerKey(row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		var encodedKey []byte
+		if key != nil {
+			encodedKey, err = codec.EncodeValue([]byte{}, key...)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if _, ok := keySet[string(encodedKey)]; !ok {
+				keySet[string(encodedKey)] = struct{}{}
+				lookupKeys = append(lookupKeys, key)
+			}
+		}
+		outerRows = append(outerRows, row)
+		outerKeys = append(outerKeys, encodedKey)
+	}
+	innerRows, err := e.lookupInnerRows(lookupKeys)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for i, outerRow := range outerRows {
+		matched := false
+		if outerKeys[i] != nil {
+			for _, innerRow := range innerRows[string(outerKeys[i])] {
+				joinedRow := e.joinRows(outerRow, innerRow)
+				if e.otherFilter != nil {
+					ok, err := expression.EvalBool(e.otherFilter, joinedRow.Data, e.ctx)
+					if err != nil {
+						return errors.Trace(err)
+					}
+					if !ok {
+						continue
+					}
+				}
+				matched = true
+				e.resultRows = append(e.resultRows, joinedRow)
+			}
+		}
+		if !matched && e.outer {
+			e.resultRows = append(e.resultRows, e.fillNullRow(outerRow))
+		}
+	}
+	return nil
+}
+
+// lookupInnerRows reads the inner rows of the keys, the rows are grouped by the encoded keys.
+func (e *IndexJoinExec) lookupInnerRows(keys [][]types.Datum) (map[string][]*Row, error) {
+	rows := make(map[string][]*Row)
+	if len(keys) == 0 {
+		return rows, nil
+	}
+	e.innerScan.setLookupKeys(keys)
+	e.innerExec.Init()
+	for {
+		row, err := e.innerExec.Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		if e.innerFilter != nil {
+			matched, err := expression.EvalBool(e.innerFilter, row.Data, e.ctx)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if !matched {
+				continue
+			}
+		}
+		vals := make([]types.Datum, 0, len(e.innerKeys))
+		for _, col := range e.innerKeys {
+			v, err := col.Eval(row.Data, e.ctx)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			vals = append(vals, v)
+		}
+		key, err := codec.EncodeValue([]byte{}, vals...)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		rows[string(key)] = append(rows[string(key)], row)
+	}
+	return rows, nil
+}
+
+func (e *IndexJoinExec) joinRows(outerRow, innerRow *Row) *Row {
+	if e.innerIdx == 0 {
+		return joinTwoRow(innerRow, outerRow)
+	}
+	return joinTwoRow(outerRow, innerRow)
+}
+
+func (e *IndexJoinExec) fillNullRow(outerRow *Row) *Row {
+	innerRow := &Row{
+		RowKeys: make([]*RowKeyEntry, len(e.innerExec.Schema())),
+		Data:    make([]types.Datum, len(e.innerExec.Schema())),
+	}
+	return e.joinRows(outerRow, innerRow)
+}
+
+// MergeJoinExec implements the sort merge inner join algorithm, both children are read in the order of the join key.
+type MergeJoinExec struct {
+	leftExec    NewExecutor
+	rightExec   NewExecutor
+	leftKey     *expression.Column
+	rightKey    *expression.Column
+	otherFilter expression.Expression
+	ctx         context.Context
+	schema      expression.Schema
+
+	// rightGroup are the right rows whose keys are rightGroupKey.
+	rightGroup    []*Row
+	rightGroupKey types.Datum
+	// rightRow is the first right row after the group.
+	rightRow       *Row
+	rightKeyOfRow  types.Datum
+	rightExhausted bool
+	resultRows     []*Row
+	cursor         int
+}
+
+// Schema implements Executor Schema interface.
+func (e *MergeJoinExec) Schema() expression.Schema {
+	return e.schema
+}
+
+// Fields implements Executor Fields interface.
+func (e *MergeJoinExec) Fields() []*ast.ResultField {
+	return nil
+}
+
+// Init implements NewExecutor Init interface.
+func (e *MergeJoinExec) Init() {
+	e.leftExec.Init()
+	e.rightExec.Init()
+	e.rightGroup = nil
+	e.rightRow = nil
+	e.rightExhausted = false
+	e.resultRows = nil
+	e.cursor = 0
+}
+
+// Close implements Executor Close interface.
+func (e *MergeJoinExec) Close() error {
+	e.rightGroup = nil
+	e.resultRows = nil
+	if err := e.leftExec.Close(); err != nil {
+		return errors.Trace(err)
+	}
+	return e.rightExec.Close()
+}
+
+// Next implements Executor Next interface.
+func (e *MergeJoinExec) Next() (*Row, error) {
+	for e.cursor >= len(e.resultRows) {
+		leftRow, err := e.leftExec.Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if leftRow == nil {
+			return nil, nil
+		}
+		leftKey, err := e.leftKey.Eval(leftRow.Data, e.ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if leftKey.IsNull() {
+			continue
+		}
+		cmp, err := e.seekRightGroup(leftKey)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if cmp != 0 {
+			if e.rightExhausted && len(e.rightGroup) == 0 {
+				return nil, nil
+			}
+			continue
+		}
+		e.resultRows, e.cursor = e.resultRows[:0], 0
+		for _, rightRow := range e.rightGroup {
+			joinedRow := joinTwoRow(leftRow, rightRow)
+			if e.otherFilter != nil {
+				matched, err := expression.EvalBool(e.otherFilter, joinedRow.Data, e.ctx)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+				if !matched {
+					continue
+				}
+			}
+			e.resultRows = append(e.resultRows, joinedRow)
+		}
+	}
+	e.cursor++
+	return e.resultRows[e.cursor-1], nil
+}
+
+// seekRightGroup moves the right group forward until its key isn't less than the left key,
+// it returns the result of comparing the group key with the left key.
+func (e *MergeJoinExec) seekRightGroup(leftKey types.Datum) (int, error) {
+	for {
+		if len(e.rightGroup) > 0 {
+			cmp, err := e.rightGroupKey.CompareDatum(leftKey)
+			if err != nil {
+				return 0, errors.Trace(err)
+			}
+			if cmp >= 0 {
+				return cmp, nil
+			}
+		}
+		if err := e.nextRightGroup(); err != nil {
+			return 0, errors.Trace(err)
+		}
+		if len(e.rightGroup) == 0 {
+			return -1, nil
+		}
+	}
+}
+
+// nextRightGroup reads the next group of the right rows which have the same key, the rows of null keys are skipped.
+func (e *MergeJoinExec) nextRightGroup() error {
+	e.rightGroup = e.rightGroup[:0]
+	for {
+		if e.rightRow == nil {
+			if e.rightExhausted {
+				return nil
+			}
+			row, err := e.rightExec.Next()
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if row == nil {
+				e.rightExhausted = true
+				return nil
+			}
+			key, err := e.rightKey.Eval(row.Data, e.ctx)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if key.IsNull() {
+				continue
+			}
+			e.rightRow, e.rightKeyOfRow = row, key
+		}
+		if len(e.rightGroup) > 0 {
+			cmp, err := e.rightKeyOfRow.CompareDatum(e.rightGroupKey)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if cmp != 0 {
+				return nil
+			}
+		} else {
+			e.rightGroupKey = e.rightKeyOfRow
+		}
+		e.rightGroup = append(e.rightGroup, e.rightRow)
+		e.rightRow = nil
+	}
+}
+
+// HashSemiJoinExec implements the hash semi join algorithm, it returns the left rows which match any right row, or
+// the ones which don't match any right row for anti semi join. The hash table is built with the right rows.
+// The null aware keys of IN subquery are compared in three-valued logic, the match is unknown if they're NULL,
+// the left row isn't returned by anti semi join then, and its aux column is NULL.
+type HashSemiJoinExec struct {
+	leftExec           NewExecutor
+	rightExec          NewExecutor
+	leftKeys           []expression.Expression
+	rightKeys          []expression.Expression
+	leftNullAwareKeys  []expression.Expression
+	rightNullAwareKeys []expression.Expression
+	leftFilter         expression.Expression
+	rightFilter        expression.Expression
+	otherFilter        expression.Expression
+	anti               bool
+	withAux            bool
+	ctx                context.Context
+	schema             expression.Schema
+
+	prepared bool
+	// hashTable groups the right rows by the equal keys.
+	hashTable map[string][]*Row
+	// nullAwareTable groups the right rows whose null aware keys aren't NULL by the equal and null aware keys.
+	nullAwareTable map[string][]*Row
+	// nullKeyRows groups the right rows which have NULL null aware keys by the equal keys.
+	nullKeyRows map[string][]*Row
+}
+
+// Schema implements Executor Schema interface.
+func (e *HashSemiJoinExec) Schema() expression.Schema {
+	return e.schema
+}
+
+// Fields implements Executor Fields interface.
+func (e *HashSemiJoinExec) Fields() []*ast.ResultField {
+	return nil
+}
+
+// Init implements NewExecutor Init interface.
+func (e *HashSemiJoinExec) Init() {
+	e.leftExec.Init()
+	e.rightExec.Init()
+	e.prepared = false
+}
+
+// Close implements Executor Close interface.
+func (e *HashSemiJoinExec) Close() error {
+	e.hashTable = nil
+	e.nullAwareTable = nil
+	e.nullKeyRows = nil
+	return nil
+}
+
+// evalJoinKeys evaluates the keys with the row, hasNull is true if any key is NULL.
+func evalJoinKeys(keys []expression.Expression, row *Row, ctx context.Context) (vals []types.Datum, hasNull bool, err error) {
+	vals = make([]types.Datum, 0, len(keys))
+	for _, key := range keys {
+		v, err := key.Eval(row.Data, ctx)
+		if err != nil {
+			return nil, false, errors.Trace(err)
+		}
+		if v.IsNull() {
+			hasNull = true
+		}
+		vals = append(vals, v)
+	}
+	return vals, hasNull, nil
+}
+
+func (e *HashSemiJoinExec) prepare() error {
+	e.hashTable = make(map[string][]*Row)
+	e.nullAwareTable = make(map[string][]*Row)
+	e.nullKeyRows = make(map[string][]*Row)
+	for {
+		row, err := e.rightExec.Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			e.rightExec.Close()
+			break
+		}
+		if e.rightFilter != nil {
+			matched, err := expression.EvalBool(e.rightFilter, row.Data, e.ctx)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if !matched {
+				continue
+			}
+		}
+		vals, hasNull, err := evalJoinKeys(e.rightKeys, row, e.ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		// The row whose equal key is NULL doesn't match any left row.
+		if hasNull {
+			continue
+		}
+		key, err := codec.EncodeValue([]byte{}, vals...)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		e.hashTable[string(key)] = append(e.hashTable[string(key)], row)
+		if len(e.rightNullAwareKeys) == 0 {
+			continue
+		}
+		naVals, naHasNull, err := evalJoinKeys(e.rightNullAwareKeys, row, e.ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if naHasNull {
+			e.nullKeyRows[string(key)] = append(e.nullKeyRows[string(key)], row)
+			continue
+		}
+		naKey, err := codec.EncodeValue(key, naVals...)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		e.nullAwareTable[string(naKey)] = append(e.nullAwareTable[string(naKey)], row)
+	}
+	e.prepared = true
+	return nil
+}
+
+// match checks whether the left row matches any right row, unknown is true if it doesn't match any right row,
+// but the null aware keys of some right rows may be equal to its keys.
+func (e *HashSemiJoinExec) match(leftRow *Row) (matched, unknown bool, err error) {
+	if e.leftFilter != nil {
+		matched, err = expression.EvalBool(e.leftFilter, leftRow.Data, e.ctx)
+		if err != nil || !matched {
+			return false, false, errors.Trace(err)
+		}
+	}
+	vals, hasNull, err := evalJoinKeys(e.leftKeys, leftRow, e.ctx)
+	if err != nil || hasNull {
+		return false, false, errors.Trace(err)
+	}
+	key, err := codec.EncodeValue([]byte{}, vals...)
+	if err != nil {
+		return false, false, errors.Trace(err)
+	}
+	if len(e.leftNullAwareKeys) == 0 {
+		for _, rightRow := range e.hashTable[string(key)] {
+			matched, err = e.otherMatched(leftRow, rightRow)
+			if err != nil || matched {
+				return matched, false, errors.Trace(err)
+			}
+		}
+		return false, false, nil
+	}
+	naVals, naHasNull, err := evalJoinKeys(e.leftNullAwareKeys, leftRow, e.ctx)
+	if err != nil {
+		return false, false, errors.Trace(err)
+	}
+	if naHasNull {
+		// Any right row may be equal to the NULL keys.
+		return e.matchNullAware(leftRow, naVals, e.hashTable[string(key)])
+	}
+	naKey, err := codec.EncodeValue(key, naVals...)
+	if err != nil {
+		return false, false, errors.Trace(err)
+	}
+	for _, rightRow := range e.nullAwareTable[string(naKey)] {
+		matched, err = e.otherMatched(leftRow, rightRow)
+		if err != nil || matched {
+			return matched, false, errors.Trace(err)
+		}
+	}
+	return e.matchNullAware(leftRow, naVals, e.nullKeyRows[string(key)])
+}
+
+// matchNullAware compares the null aware keys of the left row with the right rows in three-valued logic.
+func (e *HashSemiJoinExec) matchNullAware(leftRow *Row, leftVals []types.Datum, rightRows []*Row) (matched, unknown bool, err error) {
+	for _, rightRow := range rightRows {
+		rightVals, _, err := evalJoinKeys(e.rightNullAwareKeys, rightRow, e.ctx)
+		if err != nil {
+			return false, false, errors.Trace(err)
+		}
+		equal, null := true, false
+		for i, lv := range leftVals {
+			if lv.IsNull() || rightVals[i].IsNull() {
+				null = true
+				continue
+			}
+			cmp, err := lv.CompareDatum(rightVals[i])
+			if err != nil {
+				return false, false, errors.Trace(err)
+			}
+			if cmp != 0 {
+				equal = false
+				break
+			}
+		}
+		if !equal {
+			continue
+		}
+		matched, err = e.otherMatched(leftRow, rightRow)
+		if err != nil {
+			return false, false, errors.Trace(err)
+		}
+		if !matched {
+			continue
+		}
+		if !null {
+			return true, false, nil
+		}
+		unknown = true
+	}
+	return false, unknown, nil
+}
+
+// otherMatched evaluates the other conditions with the joined row.
+func (e *HashSemiJoinExec) otherMatched(leftRow, rightRow *Row) (bool, error) {
+	if e.otherFilter == nil {
+		return true, nil
+	}
+	matched, err := expression.EvalBool(e.otherFilter, joinTwoRow(leftRow, rightRow).Data, e.ctx)
+	return matched, errors.Trace(err)
+}
+
+// Next implements Executor Next interface.
+func (e *HashSemiJoinExec) Next() (*Row, error) {
+	if !e.prepared {
+		if err := e.prepare(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	for {
+		leftRow, err := e.leftExec.Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if leftRow == nil {
+			e.leftExec.Close()
+			return nil, nil
+		}
+		matched, unknown, err := e.match(leftRow)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row := semiJoinOutput(leftRow, matched, unknown, e.anti, e.withAux); row != nil {
+			return row, nil
+		}
+	}
+}
+
+// semiJoinOutput returns the output row of semi join or semi apply for the left row, it's nil if the row is
+// filtered out. The aux column is 1 if the row matches, NULL if the match is unknown, or 0.
+func semiJoinOutput(row *Row, matched, unknown, anti, withAux bool) *Row {
+	if withAux {
+		aux := types.NewIntDatum(0)
+		if matched {
+			aux = types.NewIntDatum(1)
+		} else if unknown {
+			aux.SetNull()
+		}
+		row.Data = append(row.Data, aux)
+		return row
+	}
+	if (!anti && matched) || (anti && !matched && !unknown) {
+		return row
+	}
+	return nil
+}
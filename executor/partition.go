@@ -0,0 +1,96 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/table"
+)
+
+// buildPartitionUnion builds the scans of the partitions of the partitioned table by build, ids are the IDs of
+// the partitions to read and nil means all the partitions.
+func (b *executorBuilder) buildPartitionUnion(pt table.PartitionedTable, ids []int64, fields []*ast.ResultField,
+	build func(p table.Table) Executor) Executor {
+	e := &PartitionUnionExec{table: pt, fields: fields}
+	partitions := pt.Partitions()
+	if ids != nil {
+		partitions = make([]table.Table, 0, len(ids))
+		for _, id := range ids {
+			if p := pt.GetPartition(id); p != nil {
+				partitions = append(partitions, p)
+			}
+		}
+	}
+	for _, p := range partitions {
+		src := build(p)
+		if b.err != nil {
+			return nil
+		}
+		e.Srcs = append(e.Srcs, src)
+	}
+	return e
+}
+
+// PartitionUnionExec reads the rows of the partitions of a partitioned table one partition after another.
+// The rows are returned as the rows of the partitioned table, so they're updated and deleted by the partitioned
+// table.
+type PartitionUnionExec struct {
+	table  table.Table
+	Srcs   []Executor
+	fields []*ast.ResultField
+	cursor int
+}
+
+// Schema implements Executor Schema interface.
+func (e *PartitionUnionExec) Schema() expression.Schema {
+	return nil
+}
+
+// Fields implements Executor Fields interface.
+func (e *PartitionUnionExec) Fields() []*ast.ResultField {
+	return e.fields
+}
+
+// Next implements Executor Next interface.
+func (e *PartitionUnionExec) Next() (*Row, error) {
+	for e.cursor < len(e.Srcs) {
+		row, err := e.Srcs[e.cursor].Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			e.cursor++
+			continue
+		}
+		for _, key := range row.RowKeys {
+			key.Tbl = e.table
+		}
+		return row, nil
+	}
+	return nil, nil
+}
+
+// Close implements Executor Close interface.
+func (e *PartitionUnionExec) Close() error {
+	e.cursor = 0
+	var err error
+	for _, src := range e.Srcs {
+		if err1 := src.Close(); err1 != nil && err == nil {
+			err = err1
+		}
+	}
+	return errors.Trace(err)
+}
@@ -174,6 +174,7 @@ type ExecuteExec struct {
 	ID        uint32
 	StmtExec  Executor
 	Stmt      ast.StmtNode
+	Plan      plan.Plan
 }
 
 // Schema implements Executor Schema interface.
@@ -246,6 +247,7 @@ func (e *ExecuteExec) Build() error {
 	}
 	e.StmtExec = stmtExec
 	e.Stmt = prepared.Stmt
+	e.Plan = p
 	return nil
 }
 
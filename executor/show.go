@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
@@ -26,9 +27,12 @@ import (
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/plan/statistics"
 	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -104,6 +108,10 @@ func (e *ShowExec) fetchAll() error {
 		return e.fetchShowIndex()
 	case ast.ShowProcedureStatus:
 		return e.fetchShowProcedureStatus()
+	case ast.ShowProcessList:
+		return e.fetchShowProcessList()
+	case ast.ShowStatsMeta:
+		return e.fetchShowStatsMeta()
 	case ast.ShowStatus:
 		return e.fetchShowStatus()
 	case ast.ShowTables:
@@ -115,7 +123,7 @@ func (e *ShowExec) fetchAll() error {
 	case ast.ShowVariables:
 		return e.fetchShowVariables()
 	case ast.ShowWarnings:
-		// empty result
+		return e.fetchShowWarnings()
 	}
 	return nil
 }
@@ -135,6 +143,54 @@ func (e *ShowExec) fetchShowEngines() error {
 	return nil
 }
 
+func (e *ShowExec) fetchShowProcessList() error {
+	sm := util.GetSessionManager(e.ctx)
+	if sm == nil {
+		return nil
+	}
+	pl := sm.ShowProcessList()
+	sort.Sort(byProcessID(pl))
+	for _, pi := range pl {
+		row := &Row{Data: types.MakeDatums(pi.ToRow(e.Full)...)}
+		e.rows = append(e.rows, row)
+	}
+	return nil
+}
+
+type byProcessID []util.ProcessInfo
+
+func (s byProcessID) Len() int           { return len(s) }
+func (s byProcessID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byProcessID) Less(i, j int) bool { return s[i].ID < s[j].ID }
+
+// tsoPhysicalShiftBits is the number of the bits of the logical part of a timestamp,
+// the physical part is the unix time in milliseconds.
+const tsoPhysicalShiftBits = 18
+
+func (e *ShowExec) fetchShowStatsMeta() error {
+	metas, err := statistics.LoadMetas(e.ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, db := range e.is.AllSchemas() {
+		for _, tbl := range db.Tables {
+			for _, meta := range metas {
+				if meta.TableID != tbl.ID {
+					continue
+				}
+				ms := int64(meta.Version >> tsoPhysicalShiftBits)
+				updateTime := mysql.Time{
+					Time: time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond)),
+					Type: mysql.TypeDatetime,
+				}
+				row := &Row{Data: types.MakeDatums(db.Name.O, tbl.Name.O, updateTime, meta.ModifyCount, meta.Count)}
+				e.rows = append(e.rows, row)
+			}
+		}
+	}
+	return nil
+}
+
 func (e *ShowExec) fetchShowDatabases() error {
 	dbs := e.is.AllSchemaNames()
 	// TODO: let information_schema be the first database
@@ -284,6 +340,23 @@ func (e *ShowExec) fetchShowCharset() error {
 	return nil
 }
 
+func (e *ShowExec) fetchShowWarnings() error {
+	for _, warn := range variable.GetSessionVars(e.ctx).StmtWarnings {
+		var sqlErr *mysql.SQLError
+		switch x := errors.Cause(warn).(type) {
+		case *terror.Error:
+			sqlErr = x.ToSQLError()
+		case *mysql.SQLError:
+			sqlErr = x
+		default:
+			sqlErr = mysql.NewErrf(mysql.ErrUnknown, "%s", warn.Error())
+		}
+		row := &Row{Data: types.MakeDatums("Warning", int64(sqlErr.Code), sqlErr.Message)}
+		e.rows = append(e.rows, row)
+	}
+	return nil
+}
+
 func (e *ShowExec) fetchShowVariables() error {
 	sessionVars := variable.GetSessionVars(e.ctx)
 	globalVars := variable.GetGlobalVarAccessor(e.ctx)
@@ -318,7 +391,7 @@ func (e *ShowExec) fetchShowVariables() error {
 }
 
 func (e *ShowExec) fetchShowStatus() error {
-	statusVars, err := variable.GetStatusVars()
+	statusVars, err := variable.GetStatusVars(variable.GetSessionVars(e.ctx))
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -454,6 +527,30 @@ func (e *ShowExec) fetchShowCreateTable() error {
 		buf.WriteString(fmt.Sprintf(" COMMENT='%s'", tb.Meta().Comment))
 	}
 
+	if pi := tb.Meta().Partition; pi != nil {
+		if pi.Func != "" {
+			buf.WriteString(fmt.Sprintf("\nPARTITION BY %s (%s(`%s`))", pi.Type, strings.ToUpper(pi.Func), pi.Column.O))
+		} else {
+			buf.WriteString(fmt.Sprintf("\nPARTITION BY %s (`%s`)", pi.Type, pi.Column.O))
+		}
+		if pi.Type == model.PartitionTypeHash {
+			buf.WriteString(fmt.Sprintf(" PARTITIONS %d", len(pi.Definitions)))
+		} else {
+			buf.WriteString(" (\n")
+			for i, def := range pi.Definitions {
+				if def.LessThan != nil {
+					buf.WriteString(fmt.Sprintf("  PARTITION `%s` VALUES LESS THAN (%d)", def.Name.O, *def.LessThan))
+				} else {
+					buf.WriteString(fmt.Sprintf("  PARTITION `%s` VALUES LESS THAN MAXVALUE", def.Name.O))
+				}
+				if i != len(pi.Definitions)-1 {
+					buf.WriteString(",\n")
+				}
+			}
+			buf.WriteString("\n)")
+		}
+	}
+
 	data := types.MakeDatums(tb.Meta().Name.O, buf.String())
 	e.rows = append(e.rows, &Row{Data: data})
 	return nil
@@ -104,6 +104,9 @@ type UnionScanExec struct {
 	usedIndex []int
 	desc      bool
 	condition ast.ExprNode
+	// partitioned is the partitioned table if Src reads a partition of it, only the added rows of the partition
+	// are merged.
+	partitioned table.PartitionedTable
 
 	addedRows   []*Row
 	cursor      int
@@ -249,9 +252,27 @@ func (us *UnionScanExec) compare(a, b *Row) (int, error) {
 	return cmp, nil
 }
 
+// dirtyTableID returns the ID of the dirty table of the table t read by Src, the rows of the partitions are in the
+// dirty table of the partitioned table.
+func (us *UnionScanExec) dirtyTableID(t table.Table) int64 {
+	if us.partitioned != nil {
+		return us.partitioned.Meta().ID
+	}
+	return t.Meta().ID
+}
+
 func (us *UnionScanExec) buildAndSortAddedRows(t table.Table, asName *model.CIStr) error {
 	us.addedRows = make([]*Row, 0, len(us.dirty.addedRows))
 	for h, data := range us.dirty.addedRows {
+		if us.partitioned != nil {
+			p, err := us.partitioned.LocatePartition(data)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if p.Meta().ID != t.Meta().ID {
+				continue
+			}
+		}
 		for i, field := range us.Src.Fields() {
 			field.Expr.SetDatum(data[i])
 		}
@@ -42,25 +42,50 @@ type AggregationFunction interface {
 
 	// Clear collects the mapper's memory.
 	Clear()
+
+	// GetName gets the lower case name of the aggregate function.
+	GetName() string
+
+	// IsDistinct checks whether the aggregate function only aggregates the distinct values.
+	IsDistinct() bool
+
+	// SetMode sets the mode of the aggregate function.
+	SetMode(mode AggFunctionMode)
+
+	// GetMode gets the mode of the aggregate function.
+	GetMode() AggFunctionMode
 }
 
+// AggFunctionMode stands for the mode of an aggregate function.
+type AggFunctionMode int
+
+const (
+	// CompleteMode function accepts the origin data and computes the final result.
+	CompleteMode AggFunctionMode = iota
+	// FinalMode function accepts the partial results computed by the coprocessor and merges them.
+	// The partial result layout of each function is: count for COUNT, count and sum for AVG,
+	// and the value for SUM, MAX, MIN and FIRSTROW. Args are the columns of the partial results.
+	FinalMode
+)
+
 // NewAggFunction creates a new AggregationFunction.
 func NewAggFunction(funcType string, funcArgs []Expression, distinct bool) AggregationFunction {
-	switch strings.ToLower(funcType) {
+	name := strings.ToLower(funcType)
+	switch name {
 	case ast.AggFuncSum:
-		return &sumFunction{aggFunction: newAggFunc(funcArgs, distinct)}
+		return &sumFunction{aggFunction: newAggFunc(name, funcArgs, distinct)}
 	case ast.AggFuncCount:
-		return &countFunction{aggFunction: newAggFunc(funcArgs, distinct)}
+		return &countFunction{aggFunction: newAggFunc(name, funcArgs, distinct)}
 	case ast.AggFuncAvg:
-		return &avgFunction{aggFunction: newAggFunc(funcArgs, distinct)}
+		return &avgFunction{aggFunction: newAggFunc(name, funcArgs, distinct)}
 	case ast.AggFuncGroupConcat:
-		return &concatFunction{aggFunction: newAggFunc(funcArgs, distinct)}
+		return &concatFunction{aggFunction: newAggFunc(name, funcArgs, distinct)}
 	case ast.AggFuncMax:
-		return &maxMinFunction{aggFunction: newAggFunc(funcArgs, distinct), isMax: true}
+		return &maxMinFunction{aggFunction: newAggFunc(name, funcArgs, distinct), isMax: true}
 	case ast.AggFuncMin:
-		return &maxMinFunction{aggFunction: newAggFunc(funcArgs, distinct), isMax: false}
+		return &maxMinFunction{aggFunction: newAggFunc(name, funcArgs, distinct), isMax: false}
 	case ast.AggFuncFirstRow:
-		return &firstRowFunction{aggFunction: newAggFunc(funcArgs, distinct)}
+		return &firstRowFunction{aggFunction: newAggFunc(name, funcArgs, distinct)}
 	}
 	return nil
 }
@@ -68,18 +93,41 @@ func NewAggFunction(funcType string, funcArgs []Expression, distinct bool) Aggre
 type aggCtxMapper map[string]*ast.AggEvaluateContext
 
 type aggFunction struct {
+	name         string
+	mode         AggFunctionMode
 	Args         []Expression
 	Distinct     bool
 	resultMapper aggCtxMapper
 }
 
-func newAggFunc(args []Expression, dist bool) aggFunction {
+func newAggFunc(name string, args []Expression, dist bool) aggFunction {
 	return aggFunction{
+		name:         name,
 		Args:         args,
 		resultMapper: make(aggCtxMapper, 0),
 		Distinct:     dist}
 }
 
+// GetName implements AggregationFunction interface.
+func (af *aggFunction) GetName() string {
+	return af.name
+}
+
+// IsDistinct implements AggregationFunction interface.
+func (af *aggFunction) IsDistinct() bool {
+	return af.Distinct
+}
+
+// SetMode implements AggregationFunction interface.
+func (af *aggFunction) SetMode(mode AggFunctionMode) {
+	af.mode = mode
+}
+
+// GetMode implements AggregationFunction interface.
+func (af *aggFunction) GetMode() AggFunctionMode {
+	return af.mode
+}
+
 func (af *aggFunction) Clear() {
 	af.resultMapper = make(aggCtxMapper, 0)
 }
@@ -155,6 +203,14 @@ type countFunction struct {
 // Update implements AggregationFunction interface.
 func (cf *countFunction) Update(row []types.Datum, groupKey []byte, ectx context.Context) error {
 	ctx := cf.getContext(groupKey)
+	if cf.mode == FinalMode {
+		count, err := cf.Args[0].Eval(row, ectx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		ctx.Count += count.GetInt64()
+		return nil
+	}
 	var vals []interface{}
 	if cf.Distinct {
 		vals = make([]interface{}, 0, len(cf.Args))
@@ -196,9 +252,34 @@ type avgFunction struct {
 
 // Update implements AggregationFunction interface.
 func (af *avgFunction) Update(row []types.Datum, groupKey []byte, ctx context.Context) error {
+	if af.mode == FinalMode {
+		return af.updateAvgPartial(row, groupKey, ctx)
+	}
 	return af.updateSum(row, groupKey, ctx)
 }
 
+// updateAvgPartial merges the partial count and sum.
+func (af *avgFunction) updateAvgPartial(row []types.Datum, groupKey []byte, ectx context.Context) error {
+	ctx := af.getContext(groupKey)
+	count, err := af.Args[0].Eval(row, ectx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	sum, err := af.Args[1].Eval(row, ectx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if count.GetInt64() == 0 {
+		return nil
+	}
+	ctx.Value, err = types.CalculateSum(ctx.Value, sum.GetValue())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ctx.Count += count.GetInt64()
+	return nil
+}
+
 // GetGroupResult implements AggregationFunction interface.
 func (af *avgFunction) GetGroupResult(groupKey []byte) (d types.Datum) {
 	ctx := af.getContext(groupKey)
@@ -17,11 +17,14 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/meta/autoid"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -37,6 +40,7 @@ const (
 	catalogVal         = "def"
 	tableProfiling     = "PROFILING"
 	tablePartitions    = "PARTITIONS"
+	tableProcesslist   = "PROCESSLIST"
 )
 
 type columnInfo struct {
@@ -197,6 +201,18 @@ var collationsCols = []columnInfo{
 	{"SORTLEN", mysql.TypeLonglong, 3, 0, nil, nil},
 }
 
+// See: https://dev.mysql.com/doc/refman/5.7/en/processlist-table.html
+var processlistCols = []columnInfo{
+	{"ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"USER", mysql.TypeVarchar, 32, 0, nil, nil},
+	{"HOST", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"DB", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"COMMAND", mysql.TypeVarchar, 16, 0, nil, nil},
+	{"TIME", mysql.TypeLonglong, 7, 0, nil, nil},
+	{"STATE", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"INFO", mysql.TypeBlob, 196606, 0, nil, nil},
+}
+
 // See: https://dev.mysql.com/doc/refman/5.7/en/partitions-table.html
 var partitionsCols = []columnInfo{
 	{"TABLE_CATALOG", mysql.TypeVarchar, 512, 0, nil, nil},
@@ -481,9 +497,68 @@ var tableNameToColumns = map[string]([]columnInfo){
 	tableFiles:         filesCols,
 	tableProfiling:     profilingCols,
 	tablePartitions:    partitionsCols,
+	tableProcesslist:   processlistCols,
 }
 
 func createMemoryTable(meta *model.TableInfo, alloc autoid.Allocator) (table.Table, error) {
 	tbl, _ := tables.MemoryTableFromMeta(alloc, meta)
+	if meta.Name.O == tableProcesslist {
+		return &processlistTable{Table: tbl}, nil
+	}
 	return tbl, nil
 }
+
+// processlistTable is the PROCESSLIST table, its rows are not stored but read from
+// the session manager of the context when it is scanned. The handle of a row is the connection ID.
+type processlistTable struct {
+	table.Table
+}
+
+func processList(ctx context.Context) []util.ProcessInfo {
+	sm := util.GetSessionManager(ctx)
+	if sm == nil {
+		return nil
+	}
+	return sm.ShowProcessList()
+}
+
+// Seek implements table.Table Seek interface.
+func (t *processlistTable) Seek(ctx context.Context, handle int64) (int64, bool, error) {
+	var result int64
+	found := false
+	for _, pi := range processList(ctx) {
+		h := int64(pi.ID)
+		if h >= handle && (!found || h < result) {
+			result, found = h, true
+		}
+	}
+	return result, found, nil
+}
+
+// RowWithCols implements table.Table RowWithCols interface.
+func (t *processlistTable) RowWithCols(ctx context.Context, h int64, cols []*table.Column) ([]types.Datum, error) {
+	for _, pi := range processList(ctx) {
+		if int64(pi.ID) != h {
+			continue
+		}
+		row := types.MakeDatums(pi.ToRow(true)...)
+		v := make([]types.Datum, len(cols))
+		for i, col := range cols {
+			if col == nil {
+				continue
+			}
+			v[i] = row[col.Offset]
+		}
+		return v, nil
+	}
+	return nil, table.ErrRowNotFound
+}
+
+// Row implements table.Table Row interface.
+func (t *processlistTable) Row(ctx context.Context, h int64) ([]types.Datum, error) {
+	r, err := t.RowWithCols(ctx, h, t.Cols())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return r, nil
+}
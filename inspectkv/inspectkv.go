@@ -36,6 +36,8 @@ type DDLInfo struct {
 	ReorgHandle int64 // it's only used for DDL information.
 	Owner       *model.Owner
 	Job         *model.Job
+	// ReorgChunks are the checkpoints of the chunks of the job reorganized concurrently.
+	ReorgChunks []*model.ReorgChunk
 }
 
 // GetDDLInfo returns DDL information.
@@ -64,6 +66,10 @@ func GetDDLInfo(txn kv.Transaction) (*DDLInfo, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	info.ReorgChunks, err = t.GetDDLReorgChunks(info.Job)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 
 	return info, nil
 }
@@ -90,6 +96,62 @@ func GetBgDDLInfo(txn kv.Transaction) (*DDLInfo, error) {
 	return info, nil
 }
 
+// GetDDLJobs returns the DDL jobs in the queue.
+func GetDDLJobs(txn kv.Transaction) ([]*model.Job, error) {
+	jobs, err := meta.NewMeta(txn).GetAllDDLJobs()
+	return jobs, errors.Trace(err)
+}
+
+// defaultHistoryDDLJobNumber is the number of the history DDL jobs returned by default.
+const defaultHistoryDDLJobNumber = 10
+
+// GetHistoryDDLJobs returns the last n history DDL jobs, the latest job is the first.
+// If n <= 0, the last 10 jobs are returned.
+func GetHistoryDDLJobs(txn kv.Transaction, n int64) ([]*model.Job, error) {
+	if n <= 0 {
+		n = defaultHistoryDDLJobNumber
+	}
+	jobs, err := meta.NewMeta(txn).GetLastNHistoryDDLJobs(int(n))
+	return jobs, errors.Trace(err)
+}
+
+// CancelJobs marks the DDL jobs in the queue as cancelling, the DDL worker rolls them back and cancels them.
+// Only the add index jobs and the jobs which haven't changed the schema can be cancelled.
+// It returns the error of every job, the error is nil if the job is cancelled.
+func CancelJobs(txn kv.Transaction, ids []int64) ([]error, error) {
+	t := meta.NewMeta(txn)
+	jobs, err := t.GetAllDDLJobs()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	errs := make([]error, len(ids))
+	for i, id := range ids {
+		errs[i] = errDDLJobNotFound.Gen("DDL job %d not found", id)
+		for j, job := range jobs {
+			if job.ID != id {
+				continue
+			}
+
+			switch {
+			case job.State == model.JobCancelling:
+				errs[i] = errCancelledDDLJob.Gen("DDL job %d is already cancelled", id)
+			case job.Type != model.ActionAddIndex && job.SchemaState != model.StateNone:
+				errs[i] = errCannotCancelDDLJob.Gen("DDL job %d can't be cancelled in state %s", id, job.SchemaState)
+			default:
+				job.State = model.JobCancelling
+				if err = t.UpdateDDLJob(int64(j), job); err != nil {
+					return nil, errors.Trace(err)
+				}
+				errs[i] = nil
+			}
+			break
+		}
+	}
+
+	return errs, nil
+}
+
 func nextIndexVals(data []types.Datum) []types.Datum {
 	// Add 0x0 to the end of data.
 	return append(data, types.Datum{})
@@ -458,10 +520,16 @@ const (
 	codeDataNotEqual       terror.ErrCode = 1
 	codeRepeatHandle                      = 2
 	codeInvalidColumnState                = 3
+	codeDDLJobNotFound                    = 4
+	codeCancelledDDLJob                   = 5
+	codeCannotCancelDDLJob                = 6
 )
 
 var (
 	errDateNotEqual       = terror.ClassInspectkv.New(codeDataNotEqual, "data isn't equal")
 	errRepeatHandle       = terror.ClassInspectkv.New(codeRepeatHandle, "handle is repeated")
 	errInvalidColumnState = terror.ClassInspectkv.New(codeInvalidColumnState, "invalid column state")
+	errDDLJobNotFound     = terror.ClassInspectkv.New(codeDDLJobNotFound, "DDL job not found")
+	errCancelledDDLJob    = terror.ClassInspectkv.New(codeCancelledDDLJob, "DDL job is already cancelled")
+	errCannotCancelDDLJob = terror.ClassInspectkv.New(codeCannotCancelDDLJob, "DDL job can't be cancelled")
 )
@@ -28,6 +28,7 @@ import (
 	"github.com/pingcap/tidb/store/localstore/goleveldb"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
@@ -149,6 +150,14 @@ func (s *testSuite) TestGetDDLInfo(c *C) {
 	c.Assert(info.Owner, DeepEquals, owner)
 	c.Assert(info.Job, DeepEquals, job)
 	c.Assert(info.ReorgHandle, Equals, int64(0))
+	c.Assert(info.ReorgChunks, HasLen, 0)
+
+	chunk := &model.ReorgChunk{StartHandle: 1, EndHandle: 10, NextHandle: 1}
+	err = t.UpdateDDLReorgChunk(job, 0, chunk)
+	c.Assert(err, IsNil)
+	info, err = GetDDLInfo(txn)
+	c.Assert(err, IsNil)
+	c.Assert(info.ReorgChunks, DeepEquals, []*model.ReorgChunk{chunk})
 	err = txn.Commit()
 	c.Assert(err, IsNil)
 }
@@ -177,6 +186,67 @@ func (s *testSuite) TestGetBgDDLInfo(c *C) {
 	c.Assert(err, IsNil)
 }
 
+func (s *testSuite) TestGetHistoryDDLJobs(c *C) {
+	defer testleak.AfterTest(c)()
+	txn, err := s.store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	t := meta.NewMeta(txn)
+
+	for i := 1; i <= 12; i++ {
+		err = t.AddHistoryDDLJob(&model.Job{ID: int64(i), State: model.JobDone})
+		c.Assert(err, IsNil)
+	}
+	jobs, err := GetHistoryDDLJobs(txn, 0)
+	c.Assert(err, IsNil)
+	c.Assert(jobs, HasLen, 10)
+	c.Assert(jobs[0].ID, Equals, int64(12))
+	c.Assert(jobs[9].ID, Equals, int64(3))
+	jobs, err = GetHistoryDDLJobs(txn, 20)
+	c.Assert(err, IsNil)
+	c.Assert(jobs, HasLen, 12)
+	c.Assert(jobs[11].ID, Equals, int64(1))
+}
+
+func (s *testSuite) TestCancelJobs(c *C) {
+	defer testleak.AfterTest(c)()
+	txn, err := s.store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	t := meta.NewMeta(txn)
+
+	jobs := []*model.Job{
+		{ID: 101, Type: model.ActionAddIndex, State: model.JobRunning, SchemaState: model.StateWriteReorganization},
+		{ID: 102, Type: model.ActionDropTable, State: model.JobRunning, SchemaState: model.StateWriteOnly},
+		{ID: 103, Type: model.ActionAddColumn},
+	}
+	for _, job := range jobs {
+		err = t.EnQueueDDLJob(job)
+		c.Assert(err, IsNil)
+	}
+	errs, err := CancelJobs(txn, []int64{101, 102, 103, 104})
+	c.Assert(err, IsNil)
+	c.Assert(errs, HasLen, 4)
+	c.Assert(errs[0], IsNil)
+	c.Assert(terror.ErrorEqual(errs[1], errCannotCancelDDLJob), IsTrue)
+	c.Assert(errs[2], IsNil)
+	c.Assert(terror.ErrorEqual(errs[3], errDDLJobNotFound), IsTrue)
+
+	queued, err := GetDDLJobs(txn)
+	c.Assert(err, IsNil)
+	states := make(map[int64]model.JobState)
+	for _, job := range queued {
+		states[job.ID] = job.State
+	}
+	c.Assert(states[101], Equals, model.JobCancelling)
+	c.Assert(states[102], Equals, model.JobRunning)
+	c.Assert(states[103], Equals, model.JobCancelling)
+
+	errs, err = CancelJobs(txn, []int64{101})
+	c.Assert(err, IsNil)
+	c.Assert(terror.ErrorEqual(errs[0], errCancelledDDLJob), IsTrue)
+}
+
 func (s *testSuite) TestScan(c *C) {
 	defer testleak.AfterTest(c)()
 	alloc := autoid.NewAllocator(s.store, s.dbInfo.ID)
@@ -33,7 +33,8 @@ const (
 	codeNotCommitted                              = 9
 	codeNotImplemented                            = 10
 
-	codeKeyExists = 1062
+	codeKeyExists    = 1062
+	codeQueryTimeout = 3024
 )
 
 var (
@@ -63,11 +64,14 @@ var (
 	ErrKeyExists = terror.ClassKV.New(codeKeyExists, "key already exist")
 	// ErrNotImplemented returns when a function is not implemented yet.
 	ErrNotImplemented = terror.ClassKV.New(codeNotImplemented, "not implemented")
+	// ErrQueryTimeout returns when a statement runs longer than its max execution time.
+	ErrQueryTimeout = terror.ClassKV.New(codeQueryTimeout, "Query execution was interrupted, maximum statement execution time exceeded")
 )
 
 func init() {
 	kvMySQLErrCodes := map[terror.ErrCode]uint16{
-		codeKeyExists: mysql.ErrDupEntry,
+		codeKeyExists:    mysql.ErrDupEntry,
+		codeQueryTimeout: mysql.ErrQueryTimeout,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassKV] = kvMySQLErrCodes
 }
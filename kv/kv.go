@@ -16,6 +16,8 @@ package kv
 import (
 	"bytes"
 	"io"
+	"sync/atomic"
+	"time"
 )
 
 // Transaction options
@@ -112,6 +114,7 @@ const (
 	ReqSubTypeBasic   = 0
 	ReqSubTypeDesc    = 10000
 	ReqSubTypeGroupBy = 10001
+	ReqSubTypeTopN    = 10002
 )
 
 // KeyRange represents a range where StartKey <= key < EndKey.
@@ -140,6 +143,39 @@ type Request struct {
 	// ResponseIterator.Next is called. If concurrency is greater than 1, the request will be
 	// sent to multiple storage units concurrently.
 	Concurrency int
+	// If Deadline is not zero, the request stops sending to storage units and Response.Next
+	// returns ErrQueryTimeout after the deadline.
+	Deadline time.Time
+	// Stats collects the execution statistics of the request if it's not nil.
+	Stats *CopStats
+}
+
+// CopStats is the execution statistics of coprocessor requests, the fields must be accessed atomically.
+type CopStats struct {
+	// Tasks is the number of the tasks sent to the storage units, a task is retried
+	// as more tasks if its storage unit has been split.
+	Tasks int64
+	// Retries is the number of the retried tasks.
+	Retries int64
+	// Parent is the statistics the counters are also added to, for example, the statistics of a statement
+	// are the parent of the ones of its executors.
+	Parent *CopStats
+}
+
+// AddTasks adds n to the number of the tasks if s is not nil.
+func (s *CopStats) AddTasks(n int) {
+	if s != nil {
+		atomic.AddInt64(&s.Tasks, int64(n))
+		s.Parent.AddTasks(n)
+	}
+}
+
+// AddRetries adds n to the number of the retries if s is not nil.
+func (s *CopStats) AddRetries(n int) {
+	if s != nil {
+		atomic.AddInt64(&s.Retries, int64(n))
+		s.Parent.AddRetries(n)
+	}
 }
 
 // Response represents the response returned from KV layer.
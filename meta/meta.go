@@ -416,6 +416,7 @@ func (m *Meta) GetTable(dbID int64, tableID int64) (*model.TableInfo, error) {
 //	DDLJobList: list jobs
 //	DDLJobHistory: hash
 //	DDLJobReorg: hash
+//	DDLJobReorgChunk:jobID: hash
 //
 // for multi DDL workers, only one can become the owner
 // to operate DDL jobs, and dispatch them to MR Jobs.
@@ -425,6 +426,8 @@ var (
 	mDDLJobListKey    = []byte("DDLJobList")
 	mDDLJobHistoryKey = []byte("DDLJobHistory")
 	mDDLJobReorgKey   = []byte("DDLJobReorg")
+
+	mDDLJobReorgChunkPrefix = "DDLJobReorgChunk"
 )
 
 func (m *Meta) getJobOwner(key []byte) (*model.Owner, error) {
@@ -522,6 +525,23 @@ func (m *Meta) DDLJobQueueLen() (int64, error) {
 	return m.txn.LLen(mDDLJobListKey)
 }
 
+// GetAllDDLJobs gets all the DDL jobs in the queue.
+func (m *Meta) GetAllDDLJobs() ([]*model.Job, error) {
+	n, err := m.DDLJobQueueLen()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	jobs := make([]*model.Job, 0, n)
+	for i := int64(0); i < n; i++ {
+		job, err := m.GetDDLJob(i)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
 func (m *Meta) jobIDKey(id int64) []byte {
 	b := make([]byte, 8)
 	binary.BigEndian.PutUint64(b, uint64(id))
@@ -558,19 +578,50 @@ func (m *Meta) GetHistoryDDLJob(id int64) (*model.Job, error) {
 	return m.getHistoryDDLJob(mDDLJobHistoryKey, id)
 }
 
-// IsBootstrapped returns whether we have already run bootstrap or not.
-// return true means we don't need doing any other bootstrap.
-func (m *Meta) IsBootstrapped() (bool, error) {
-	value, err := m.txn.GetInt64(mBootstrapKey)
+// GetAllHistoryDDLJobs gets all the history DDL jobs, the jobs are sorted by job ID.
+func (m *Meta) GetAllHistoryDDLJobs() ([]*model.Job, error) {
+	pairs, err := m.txn.HGetAll(mDDLJobHistoryKey)
 	if err != nil {
-		return false, errors.Trace(err)
+		return nil, errors.Trace(err)
 	}
-	return value == 1, nil
+	jobs := make([]*model.Job, 0, len(pairs))
+	for _, pair := range pairs {
+		job := &model.Job{}
+		if err = job.Decode(pair.Value); err != nil {
+			return nil, errors.Trace(err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// GetLastNHistoryDDLJobs gets the latest num history DDL jobs, the latest job is the first.
+func (m *Meta) GetLastNHistoryDDLJobs(num int) ([]*model.Job, error) {
+	pairs, err := m.txn.HGetLastN(mDDLJobHistoryKey, num)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	jobs := make([]*model.Job, 0, len(pairs))
+	for _, pair := range pairs {
+		job := &model.Job{}
+		if err = job.Decode(pair.Value); err != nil {
+			return nil, errors.Trace(err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// GetBootstrapVersion returns the version of the server which bootstrapped the store,
+// 0 means the store isn't bootstrapped.
+func (m *Meta) GetBootstrapVersion() (int64, error) {
+	value, err := m.txn.GetInt64(mBootstrapKey)
+	return value, errors.Trace(err)
 }
 
-// FinishBootstrap finishes bootstrap.
-func (m *Meta) FinishBootstrap() error {
-	err := m.txn.Set(mBootstrapKey, []byte("1"))
+// FinishBootstrap finishes bootstrap with the version of the server.
+func (m *Meta) FinishBootstrap(version int64) error {
+	err := m.txn.Set(mBootstrapKey, []byte(strconv.FormatInt(version, 10)))
 	return errors.Trace(err)
 }
 
@@ -592,6 +643,51 @@ func (m *Meta) GetDDLReorgHandle(job *model.Job) (int64, error) {
 	return value, errors.Trace(err)
 }
 
+func (m *Meta) reorgChunkKey(jobID int64) []byte {
+	return []byte(fmt.Sprintf("%s:%d", mDDLJobReorgChunkPrefix, jobID))
+}
+
+func (m *Meta) reorgChunkField(idx int) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(idx))
+	return b
+}
+
+// UpdateDDLReorgChunk saves the checkpoint of the chunk with the index of the job reorganization.
+// All the chunks should be added in a transaction before they're updated concurrently, because adding
+// a chunk updates the count of the chunks of the job.
+func (m *Meta) UpdateDDLReorgChunk(job *model.Job, idx int, chunk *model.ReorgChunk) error {
+	b, err := json.Marshal(chunk)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = m.txn.HSet(m.reorgChunkKey(job.ID), m.reorgChunkField(idx), b)
+	return errors.Trace(err)
+}
+
+// GetDDLReorgChunks gets the checkpoints of the chunks of the job reorganization, the chunks are sorted by index.
+func (m *Meta) GetDDLReorgChunks(job *model.Job) ([]*model.ReorgChunk, error) {
+	pairs, err := m.txn.HGetAll(m.reorgChunkKey(job.ID))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	chunks := make([]*model.ReorgChunk, 0, len(pairs))
+	for _, pair := range pairs {
+		chunk := &model.ReorgChunk{}
+		if err = json.Unmarshal(pair.Value, chunk); err != nil {
+			return nil, errors.Trace(err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+// RemoveDDLReorgChunks removes the checkpoints of the chunks of the job reorganization.
+func (m *Meta) RemoveDDLReorgChunks(job *model.Job) error {
+	err := m.txn.HClear(m.reorgChunkKey(job.ID))
+	return errors.Trace(err)
+}
+
 // DDL background job structure
 //	BgJobOnwer: []byte
 //	BgJobList: list jobs
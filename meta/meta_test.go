@@ -14,6 +14,7 @@
 package meta_test
 
 import (
+	"math"
 	"testing"
 
 	. "github.com/pingcap/check"
@@ -148,16 +149,16 @@ func (s *testSuite) TestMeta(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(dbs, HasLen, 0)
 
-	bootstrapped, err := t.IsBootstrapped()
+	ver, err := t.GetBootstrapVersion()
 	c.Assert(err, IsNil)
-	c.Assert(bootstrapped, IsFalse)
+	c.Assert(ver, Equals, int64(0))
 
-	err = t.FinishBootstrap()
+	err = t.FinishBootstrap(2)
 	c.Assert(err, IsNil)
 
-	bootstrapped, err = t.IsBootstrapped()
+	ver, err = t.GetBootstrapVersion()
 	c.Assert(err, IsNil)
-	c.Assert(bootstrapped, IsTrue)
+	c.Assert(ver, Equals, int64(2))
 
 	err = txn.Commit()
 	c.Assert(err, IsNil)
@@ -200,6 +201,9 @@ func (s *testSuite) TestDDL(c *C) {
 	job.ID = 2
 	err = t.UpdateDDLJob(0, job)
 	c.Assert(err, IsNil)
+	jobs, err := t.GetAllDDLJobs()
+	c.Assert(err, IsNil)
+	c.Assert(jobs, DeepEquals, []*model.Job{job})
 
 	err = t.UpdateDDLReorgHandle(job, 1)
 	c.Assert(err, IsNil)
@@ -211,6 +215,26 @@ func (s *testSuite) TestDDL(c *C) {
 	err = t.RemoveDDLReorgHandle(job)
 	c.Assert(err, IsNil)
 
+	chunks := []*model.ReorgChunk{
+		{StartHandle: math.MinInt64, EndHandle: 10, NextHandle: 5, RowCount: 3},
+		{StartHandle: 11, EndHandle: 20, NextHandle: 11},
+	}
+	for i := len(chunks) - 1; i >= 0; i-- {
+		err = t.UpdateDDLReorgChunk(job, i, chunks[i])
+		c.Assert(err, IsNil)
+	}
+	chunks[1].Done = true
+	err = t.UpdateDDLReorgChunk(job, 1, chunks[1])
+	c.Assert(err, IsNil)
+	cs, err := t.GetDDLReorgChunks(job)
+	c.Assert(err, IsNil)
+	c.Assert(cs, DeepEquals, chunks)
+	err = t.RemoveDDLReorgChunks(job)
+	c.Assert(err, IsNil)
+	cs, err = t.GetDDLReorgChunks(job)
+	c.Assert(err, IsNil)
+	c.Assert(cs, HasLen, 0)
+
 	v, err = t.DeQueueDDLJob()
 	c.Assert(err, IsNil)
 	c.Assert(v, DeepEquals, job)
@@ -220,6 +244,12 @@ func (s *testSuite) TestDDL(c *C) {
 	v, err = t.GetHistoryDDLJob(2)
 	c.Assert(err, IsNil)
 	c.Assert(v, DeepEquals, job)
+	jobs, err = t.GetAllHistoryDDLJobs()
+	c.Assert(err, IsNil)
+	c.Assert(jobs, DeepEquals, []*model.Job{job})
+	jobs, err = t.GetLastNHistoryDDLJobs(1)
+	c.Assert(err, IsNil)
+	c.Assert(jobs, DeepEquals, []*model.Job{job})
 
 	// DDL background job test
 	err = t.SetBgJobOwner(owner)
@@ -0,0 +1,122 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidb
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	queryHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "server",
+			Name:      "query_duration_seconds",
+			Help:      "Bucketed histogram of processing time (s) of handled queries.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 20),
+		}, []string{"sql_type"})
+	txnCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "session",
+			Name:      "transaction_total",
+			Help:      "Counter of transactions.",
+		}, []string{"type", "result"})
+	retryCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "session",
+			Name:      "retry_total",
+			Help:      "Counter of transaction retries.",
+		}, []string{"result"})
+)
+
+func init() {
+	prometheus.MustRegister(queryHistogram)
+	prometheus.MustRegister(txnCounter)
+	prometheus.MustRegister(retryCounter)
+}
+
+const (
+	metricOK    = "ok"
+	metricError = "error"
+)
+
+func resultLabel(err error) string {
+	if err != nil {
+		return metricError
+	}
+	return metricOK
+}
+
+// stmtLabel returns the label of the statement type used in the metrics.
+func stmtLabel(node ast.StmtNode) string {
+	switch x := node.(type) {
+	case *ast.SelectStmt, *ast.UnionStmt:
+		return "Select"
+	case *ast.InsertStmt:
+		if x.IsReplace {
+			return "Replace"
+		}
+		return "Insert"
+	case *ast.UpdateStmt:
+		return "Update"
+	case *ast.DeleteStmt:
+		return "Delete"
+	case *ast.ShowStmt:
+		return "Show"
+	case *ast.SetStmt:
+		return "Set"
+	case *ast.UseStmt:
+		return "Use"
+	case *ast.BeginStmt:
+		return "Begin"
+	case *ast.CommitStmt:
+		return "Commit"
+	case *ast.RollbackStmt:
+		return "Rollback"
+	case *ast.ExplainStmt:
+		return "Explain"
+	case ast.DDLNode:
+		return "DDL"
+	}
+	return "other"
+}
+
+// observeQuery observes the query duration when the statement is done, the statement with a record set
+// is done when the record set is closed.
+func observeQuery(rs ast.RecordSet, label string, startTime time.Time) ast.RecordSet {
+	if rs == nil {
+		queryHistogram.WithLabelValues(label).Observe(time.Since(startTime).Seconds())
+		return nil
+	}
+	return &metricsRecordSet{RecordSet: rs, label: label, startTime: startTime}
+}
+
+// metricsRecordSet observes the query duration on Close.
+type metricsRecordSet struct {
+	ast.RecordSet
+	label     string
+	startTime time.Time
+}
+
+func (rs *metricsRecordSet) Close() error {
+	err := rs.RecordSet.Close()
+	queryHistogram.WithLabelValues(rs.label).Observe(time.Since(rs.startTime).Seconds())
+	return errors.Trace(err)
+}
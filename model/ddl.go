@@ -16,6 +16,7 @@ package model
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 
 	"github.com/juju/errors"
 )
@@ -36,6 +37,12 @@ const (
 	ActionDropIndex
 	ActionAddForeignKey
 	ActionDropForeignKey
+	ActionModifyColumn
+	ActionSetDefaultValue
+	ActionRenameTable
+	ActionAddTablePartition
+	ActionDropTablePartition
+	ActionTruncateTablePartition
 )
 
 func (action ActionType) String() string {
@@ -60,6 +67,18 @@ func (action ActionType) String() string {
 		return "add foreign key"
 	case ActionDropForeignKey:
 		return "drop foreign key"
+	case ActionModifyColumn:
+		return "modify column"
+	case ActionSetDefaultValue:
+		return "set default value"
+	case ActionRenameTable:
+		return "rename table"
+	case ActionAddTablePartition:
+		return "add partition"
+	case ActionDropTablePartition:
+		return "drop partition"
+	case ActionTruncateTablePartition:
+		return "truncate partition"
 	default:
 		return "none"
 	}
@@ -87,11 +106,14 @@ type Job struct {
 }
 
 // Encode encodes job with json format.
+// The raw args of the job decoded without DecodeArgs are kept.
 func (job *Job) Encode() ([]byte, error) {
 	var err error
-	job.RawArgs, err = json.Marshal(job.Args)
-	if err != nil {
-		return nil, errors.Trace(err)
+	if job.Args != nil || job.RawArgs == nil {
+		job.RawArgs, err = json.Marshal(job.Args)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
 	}
 
 	var b []byte
@@ -126,8 +148,9 @@ func (job *Job) IsFinished() bool {
 }
 
 // IsRunning returns whether job is still running or not.
+// A cancelling job is still running to roll back.
 func (job *Job) IsRunning() bool {
-	return job.State == JobRunning
+	return job.State == JobRunning || job.State == JobCancelling
 }
 
 // JobState is for job state.
@@ -139,6 +162,9 @@ const (
 	JobRunning
 	JobDone
 	JobCancelled
+	// JobCancelling means the job is cancelled by ADMIN CANCEL DDL JOBS,
+	// and it's rolled back before it's cancelled.
+	JobCancelling
 )
 
 // String implements fmt.Stringer interface.
@@ -150,11 +176,57 @@ func (s JobState) String() string {
 		return "done"
 	case JobCancelled:
 		return "cancelled"
+	case JobCancelling:
+		return "cancelling"
 	default:
 		return "none"
 	}
 }
 
+// ReorgChunk is a range of the handles reorganized by a worker, the chunks of a job are reorganized concurrently.
+// It's saved as the checkpoint of the range, so the reorganization is resumed after the owner changes.
+type ReorgChunk struct {
+	// StartHandle and EndHandle are the first and the last handles of the range.
+	StartHandle int64 `json:"start_handle"`
+	EndHandle   int64 `json:"end_handle"`
+	// NextHandle is the handle to resume the reorganization of the range from.
+	NextHandle int64 `json:"next_handle"`
+	// Done is true if all the rows in the range are reorganized.
+	Done bool `json:"done"`
+	// RowCount is the number of the rows reorganized in the range.
+	RowCount int64 `json:"row_count"`
+}
+
+// String implements fmt.Stringer interface.
+func (c *ReorgChunk) String() string {
+	return fmt.Sprintf("[%d, %d], NextHandle:%d, Done:%v, RowCount:%d",
+		c.StartHandle, c.EndHandle, c.NextHandle, c.Done, c.RowCount)
+}
+
+// ReorgProgress returns the number of the reorganized rows of the chunks and the estimated total number of their
+// rows. The rows of the ranges which aren't reorganized are estimated by the density of the reorganized ranges, or
+// every handle of them is counted as a row before any range is reorganized.
+func ReorgProgress(chunks []*ReorgChunk) (done int64, total int64) {
+	var reorganized, remaining float64
+	for _, c := range chunks {
+		done += c.RowCount
+		if c.Done {
+			reorganized += float64(c.EndHandle) - float64(c.StartHandle) + 1
+			continue
+		}
+		reorganized += float64(c.NextHandle) - float64(c.StartHandle)
+		remaining += float64(c.EndHandle) - float64(c.NextHandle) + 1
+	}
+	if reorganized > 0 {
+		remaining *= float64(done) / reorganized
+	}
+	estimated := float64(done) + remaining
+	if estimated >= math.MaxInt64 {
+		return done, math.MaxInt64
+	}
+	return done, int64(estimated)
+}
+
 // Owner is for DDL Owner.
 type Owner struct {
 	OwnerID string `json:"owner_id"`
@@ -16,6 +16,8 @@ package model
 import (
 	"strings"
 
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -65,6 +67,10 @@ type ColumnInfo struct {
 	types.FieldType `json:"type"`
 	State           SchemaState `json:"state"`
 	Comment         string      `json:"comment"`
+	// ChangeFromID is set while MODIFY/CHANGE COLUMN changes the column. It's the ID of the public column whose
+	// values are converted and written to this non-public column, which is either the new column before it replaces
+	// the old one, or the old column after it's replaced.
+	ChangeFromID int64 `json:"change_from_id,omitempty"`
 }
 
 // Clone clones ColumnInfo.
@@ -87,6 +93,8 @@ type TableInfo struct {
 	PKIsHandle  bool          `json:"pk_is_handle"`
 	Comment     string        `json:"comment"`
 	AutoIncID   int64         `json:"auto_inc_id"`
+	// Partition is the partitioning of the table, it's nil if the table isn't partitioned.
+	Partition *PartitionInfo `json:"partition"`
 }
 
 // Clone clones TableInfo.
@@ -108,6 +116,10 @@ func (t *TableInfo) Clone() *TableInfo {
 		nt.ForeignKeys[i] = t.ForeignKeys[i].Clone()
 	}
 
+	if t.Partition != nil {
+		nt.Partition = t.Partition.Clone()
+	}
+
 	return &nt
 }
 
@@ -193,6 +205,148 @@ func (fk *FKInfo) Clone() *FKInfo {
 	return &nfk
 }
 
+// PartitionType is the type of the partitioning of a table.
+type PartitionType int
+
+// String implements Stringer interface.
+func (t PartitionType) String() string {
+	switch t {
+	case PartitionTypeRange:
+		return "RANGE"
+	case PartitionTypeHash:
+		return "HASH"
+	}
+	return ""
+}
+
+// PartitionTypes
+const (
+	PartitionTypeRange PartitionType = iota + 1
+	PartitionTypeHash
+)
+
+// PartitionDefinition provides meta data describing a partition of a table.
+// The rows of the partition are stored as the rows of a physical table whose ID is the ID of the partition.
+type PartitionDefinition struct {
+	ID   int64 `json:"id"`
+	Name CIStr `json:"name"`
+	// LessThan is the exclusive upper bound of the partition column of the RANGE partition, it's nil for MAXVALUE.
+	LessThan *int64 `json:"less_than"`
+}
+
+// Clone clones PartitionDefinition.
+func (def *PartitionDefinition) Clone() *PartitionDefinition {
+	ndef := *def
+	if def.LessThan != nil {
+		lessThan := *def.LessThan
+		ndef.LessThan = &lessThan
+	}
+	return &ndef
+}
+
+// PartitionInfo provides meta data describing the partitioning of a table.
+// It corresponds to the PARTITION BY clause of `CREATE TABLE`, the partition expression can only be a column of
+// integer type, or YEAR or TO_DAYS of a column of DATE or DATETIME type now.
+// See: https://dev.mysql.com/doc/refman/5.7/en/partitioning-types.html
+type PartitionInfo struct {
+	Type   PartitionType `json:"type"`
+	Column CIStr         `json:"column"`
+	// Func is the lower case name of the function applied to the partition column, it's empty if the partition
+	// expression is the column itself.
+	Func string `json:"func"`
+	// Definitions are the partitions of the table, the RANGE partitions are ordered by their upper bounds.
+	Definitions []*PartitionDefinition `json:"definitions"`
+}
+
+// Clone clones PartitionInfo.
+func (pi *PartitionInfo) Clone() *PartitionInfo {
+	npi := *pi
+	npi.Definitions = make([]*PartitionDefinition, len(pi.Definitions))
+	for i := range pi.Definitions {
+		npi.Definitions[i] = pi.Definitions[i].Clone()
+	}
+	return &npi
+}
+
+// FindPartition returns the offset of the partition with the name, it returns -1 if the partition doesn't exist.
+func (pi *PartitionInfo) FindPartition(name string) int {
+	name = strings.ToLower(name)
+	for i, def := range pi.Definitions {
+		if def.Name.L == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Partition functions.
+const (
+	PartitionFuncYear   = "year"
+	PartitionFuncToDays = "to_days"
+)
+
+// Eval evaluates the partition expression with the value of the partition column. The functions are monotonic, so the
+// partitions can be pruned by the values of the column. A zero date is NULL for TO_DAYS, so it's in the first partition.
+func (pi *PartitionInfo) Eval(d types.Datum) (types.Datum, error) {
+	if pi.Func == "" || d.IsNull() {
+		return d, nil
+	}
+	v, err := d.ConvertTo(types.NewFieldType(mysql.TypeDatetime))
+	if err != nil {
+		return v, errors.Trace(err)
+	}
+	t := v.GetMysqlTime()
+	switch pi.Func {
+	case PartitionFuncYear:
+		if t.IsZero() {
+			return types.NewIntDatum(0), nil
+		}
+		return types.NewIntDatum(int64(t.Year())), nil
+	case PartitionFuncToDays:
+		if t.IsZero() {
+			return types.Datum{}, nil
+		}
+		return types.NewIntDatum(t.ToDays()), nil
+	}
+	return types.Datum{}, errors.Errorf("unknown partition function %s", pi.Func)
+}
+
+// LocatePartition returns the offset of the partition which the value of the partition expression belongs to.
+// The NULL value belongs to the first partition, and a HASH partition is chosen by the absolute value modulo the
+// number of the partitions. It returns -1 if the value is not less than the upper bound of the last RANGE partition.
+func (pi *PartitionInfo) LocatePartition(d types.Datum) (int, error) {
+	if d.IsNull() {
+		return 0, nil
+	}
+	if pi.Type == PartitionTypeHash {
+		n := uint64(len(pi.Definitions))
+		if d.Kind() == types.KindUint64 {
+			return int(d.GetUint64() % n), nil
+		}
+		v, err := d.ToInt64()
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		if v < 0 {
+			return int(uint64(-v) % n), nil
+		}
+		return int(uint64(v) % n), nil
+	}
+	for i, def := range pi.Definitions {
+		if def.LessThan == nil {
+			return i, nil
+		}
+		cmp, err := d.CompareDatum(types.NewIntDatum(*def.LessThan))
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		if cmp < 0 {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
 // DBInfo provides meta data describing a DB.
 type DBInfo struct {
 	ID      int64        `json:"id"`      // Database ID
@@ -14,6 +14,7 @@
 package model
 
 import (
+	"math"
 	"testing"
 
 	. "github.com/pingcap/check"
@@ -58,6 +59,7 @@ func (*testSuite) TestClone(c *C) {
 		Primary: true,
 	}
 
+	lessThan := int64(10)
 	table := &TableInfo{
 		ID:          1,
 		Name:        NewCIStr("t"),
@@ -66,6 +68,14 @@ func (*testSuite) TestClone(c *C) {
 		Columns:     []*ColumnInfo{column},
 		Indices:     []*IndexInfo{index},
 		ForeignKeys: []*FKInfo{},
+		Partition: &PartitionInfo{
+			Type:   PartitionTypeRange,
+			Column: NewCIStr("c"),
+			Definitions: []*PartitionDefinition{
+				{ID: 2, Name: NewCIStr("p0"), LessThan: &lessThan},
+				{ID: 3, Name: NewCIStr("p1")},
+			},
+		},
 	}
 
 	dbInfo := &DBInfo{
@@ -78,6 +88,78 @@ func (*testSuite) TestClone(c *C) {
 
 	n := dbInfo.Clone()
 	c.Assert(n, DeepEquals, dbInfo)
+	c.Assert(n.Tables[0].Partition.Definitions[0].LessThan, Not(Equals), &lessThan)
+}
+
+func (*testSuite) TestPartition(c *C) {
+	lessThan := []int64{-10, 10}
+	pi := &PartitionInfo{
+		Type:   PartitionTypeRange,
+		Column: NewCIStr("c"),
+		Definitions: []*PartitionDefinition{
+			{ID: 1, Name: NewCIStr("p0"), LessThan: &lessThan[0]},
+			{ID: 2, Name: NewCIStr("p1"), LessThan: &lessThan[1]},
+		},
+	}
+	c.Assert(pi.FindPartition("P1"), Equals, 1)
+	c.Assert(pi.FindPartition("p2"), Equals, -1)
+	cases := []struct {
+		d types.Datum
+		i int
+	}{
+		{types.Datum{}, 0},
+		{types.NewIntDatum(-11), 0},
+		{types.NewIntDatum(-10), 1},
+		{types.NewUintDatum(9), 1},
+		{types.NewIntDatum(10), -1},
+		{types.NewUintDatum(math.MaxUint64), -1},
+	}
+	for _, ca := range cases {
+		i, err := pi.LocatePartition(ca.d)
+		c.Assert(err, IsNil)
+		c.Assert(i, Equals, ca.i, Commentf("%v", ca.d))
+	}
+	pi.Definitions = append(pi.Definitions, &PartitionDefinition{ID: 3, Name: NewCIStr("p2")})
+	i, err := pi.LocatePartition(types.NewIntDatum(math.MaxInt64))
+	c.Assert(err, IsNil)
+	c.Assert(i, Equals, 2)
+
+	pi.Type = PartitionTypeHash
+	cases = []struct {
+		d types.Datum
+		i int
+	}{
+		{types.Datum{}, 0},
+		{types.NewIntDatum(4), 1},
+		{types.NewIntDatum(-4), 1},
+		{types.NewUintDatum(math.MaxUint64), 0},
+	}
+	for _, ca := range cases {
+		i, err = pi.LocatePartition(ca.d)
+		c.Assert(err, IsNil)
+		c.Assert(i, Equals, ca.i, Commentf("%v", ca.d))
+	}
+	c.Assert(pi.Type.String(), Equals, "HASH")
+
+	fcases := []struct {
+		fn string
+		d  types.Datum
+		v  types.Datum
+	}{
+		{PartitionFuncToDays, types.Datum{}, types.Datum{}},
+		{PartitionFuncToDays, types.NewStringDatum("2007-10-07 10:00:00"), types.NewIntDatum(733321)},
+		{PartitionFuncToDays, types.NewStringDatum("0000-00-00"), types.Datum{}},
+		{PartitionFuncYear, types.NewStringDatum("2007-10-07"), types.NewIntDatum(2007)},
+		{PartitionFuncYear, types.NewStringDatum("0000-00-00"), types.NewIntDatum(0)},
+	}
+	for _, ca := range fcases {
+		pi.Func = ca.fn
+		v, err := pi.Eval(ca.d)
+		c.Assert(err, IsNil)
+		c.Assert(v, DeepEquals, ca.v, Commentf("%s %v", ca.fn, ca.d))
+	}
+	_, err = pi.Eval(types.NewStringDatum("abc"))
+	c.Assert(err, NotNil)
 }
 
 func (*testSuite) TestJobCodec(c *C) {
@@ -105,9 +187,47 @@ func (*testSuite) TestJobCodec(c *C) {
 
 	c.Assert(len(newJob.String()), Greater, 0)
 
+	// the args are kept if the job is encoded again before they're decoded.
+	newJob = &Job{}
+	err = newJob.Decode(b)
+	c.Assert(err, IsNil)
+	b, err = newJob.Encode()
+	c.Assert(err, IsNil)
+	err = newJob.Decode(b)
+	c.Assert(err, IsNil)
+	err = newJob.DecodeArgs(&name, &a)
+	c.Assert(err, IsNil)
+	c.Assert(name, DeepEquals, NewCIStr("a"))
+
 	job.State = JobDone
 	c.Assert(job.IsFinished(), IsTrue)
 	c.Assert(job.IsRunning(), IsFalse)
+
+	job.State = JobCancelling
+	c.Assert(job.IsFinished(), IsFalse)
+	c.Assert(job.IsRunning(), IsTrue)
+}
+
+func (*testSuite) TestReorgProgress(c *C) {
+	chunks := []*ReorgChunk{
+		{StartHandle: 1, EndHandle: 100, NextHandle: 1},
+		{StartHandle: 101, EndHandle: 200, NextHandle: 101},
+	}
+	done, total := ReorgProgress(chunks)
+	c.Assert(done, Equals, int64(0))
+	c.Assert(total, Equals, int64(200))
+
+	// Half of the handles in the reorganized ranges are rows.
+	chunks[0].NextHandle, chunks[0].RowCount = 51, 25
+	chunks[1].Done, chunks[1].RowCount = true, 50
+	done, total = ReorgProgress(chunks)
+	c.Assert(done, Equals, int64(75))
+	c.Assert(total, Equals, int64(100))
+	c.Assert(len(chunks[0].String()), Greater, 0)
+
+	done, total = ReorgProgress([]*ReorgChunk{{StartHandle: math.MinInt64, EndHandle: math.MaxInt64, NextHandle: math.MinInt64}})
+	c.Assert(done, Equals, int64(0))
+	c.Assert(total, Equals, int64(math.MaxInt64))
 }
 
 func (testSuite) TestState(c *C) {
@@ -127,6 +247,7 @@ func (testSuite) TestState(c *C) {
 		JobRunning,
 		JobDone,
 		JobCancelled,
+		JobCancelling,
 	}
 
 	for _, state := range jobTbl {
@@ -142,6 +263,12 @@ func (testSuite) TestState(c *C) {
 		ActionDropColumn,
 		ActionAddIndex,
 		ActionDropIndex,
+		ActionModifyColumn,
+		ActionSetDefaultValue,
+		ActionRenameTable,
+		ActionAddTablePartition,
+		ActionDropTablePartition,
+		ActionTruncateTablePartition,
 	}
 
 	for _, action := range actionTbl {
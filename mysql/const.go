@@ -80,6 +80,42 @@ const (
 	ComResetConnection
 )
 
+// Command2Str is the command information to command name.
+var Command2Str = map[byte]string{
+	ComSleep:            "Sleep",
+	ComQuit:             "Quit",
+	ComInitDB:           "Init DB",
+	ComQuery:            "Query",
+	ComFieldList:        "Field List",
+	ComCreateDB:         "Create DB",
+	ComDropDB:           "Drop DB",
+	ComRefresh:          "Refresh",
+	ComShutdown:         "Shutdown",
+	ComStatistics:       "Statistics",
+	ComProcessInfo:      "Processlist",
+	ComConnect:          "Connect",
+	ComProcessKill:      "Kill",
+	ComDebug:            "Debug",
+	ComPing:             "Ping",
+	ComTime:             "Time",
+	ComDelayedInsert:    "Delayed Insert",
+	ComChangeUser:       "Change User",
+	ComBinlogDump:       "Binlog Dump",
+	ComTableDump:        "Table Dump",
+	ComConnectOut:       "Connect out",
+	ComRegisterSlave:    "Register Slave",
+	ComStmtPrepare:      "Prepare",
+	ComStmtExecute:      "Execute",
+	ComStmtSendLongData: "Long Data",
+	ComStmtClose:        "Close stmt",
+	ComStmtReset:        "Reset stmt",
+	ComSetOption:        "Set option",
+	ComStmtFetch:        "Fetch",
+	ComDaemon:           "Daemon",
+	ComBinlogDumpGtid:   "Binlog Dump",
+	ComResetConnection:  "Reset connect",
+}
+
 // Client informations.
 const (
 	ClientLongPassword uint32 = 1 << iota
@@ -134,6 +170,10 @@ const (
 	GlobalStatusTable = "GLOBAL_STATUS"
 	// TiDBTable is the table contains tidb info.
 	TiDBTable = "tidb"
+	// StatsMetaTable is the table contains the row count and version of the table statistics.
+	StatsMetaTable = "stats_meta"
+	// StatsHistogramsTable is the table contains the histograms of the table statistics.
+	StatsHistogramsTable = "stats_histograms"
 )
 
 // PrivilegeType  privilege
@@ -882,4 +882,9 @@ const (
 	ErrMustChangePasswordLogin                                      = 1862
 	ErrRowInWrongPartition                                          = 1863
 	ErrErrorLast                                                    = 1863
+
+	// MySQL 5.7 errors.
+	ErrQueryTimeout        = 3024
+	ErrWarnConflictingHint = 3126
+	ErrUnresolvedHintName  = 3128
 )
@@ -879,4 +879,9 @@ var MySQLErrName = map[uint16]string{
 	ErrAlterOperationNotSupportedReasonNotNull:               "cannot silently convert NULL values, as required in this SQLMODE",
 	ErrMustChangePasswordLogin:                               "Your password has expired. To log in you must change it using a client that supports expired passwords.",
 	ErrRowInWrongPartition:                                   "Found a row in wrong partition %s",
+
+	// MySQL 5.7 errors.
+	ErrQueryTimeout:        "Query execution was interrupted, maximum statement execution time exceeded",
+	ErrWarnConflictingHint: "Hint %s is ignored as conflicting/duplicated",
+	ErrUnresolvedHintName:  "Unresolved name '%s' for %s hint",
 }
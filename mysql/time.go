@@ -256,6 +256,19 @@ func (t Time) ToNumber() Decimal {
 	return d
 }
 
+// ToDays returns the number of days since year 0, it's the same as the TO_DAYS function of MySQL.
+// e.g, 2007-10-07 -> 733321
+func (t Time) ToDays() int64 {
+	year, month, day := int64(t.Year()), int64(t.Month()), int64(t.Day())
+	days := 365*year + 31*(month-1) + day
+	if month <= 2 {
+		year--
+	} else {
+		days -= (month*4 + 23) / 10
+	}
+	return days + year/4 - ((year/100+1)*3)/4
+}
+
 // Convert converts t with type tp.
 func (t Time) Convert(tp uint8) (Time, error) {
 	if t.Type == tp || t.IsZero() {
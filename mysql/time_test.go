@@ -533,6 +533,27 @@ func (s *testTimeSuite) TestToNumber(c *C) {
 	}
 }
 
+func (s *testTimeSuite) TestToDays(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		Input  string
+		Expect int64
+	}{
+		{"0000-01-01", 1},
+		{"0001-01-01", 366},
+		{"2007-10-07", 733321},
+		{"2008-10-07", 733687},
+		{"2008-02-29 12:00:00", 733466},
+		{"2017-01-01", 736695},
+	}
+
+	for _, t := range tbl {
+		v, err := ParseTime(t.Input, TypeDatetime, 0)
+		c.Assert(err, IsNil)
+		c.Assert(v.ToDays(), Equals, t.Expect, Commentf("%s", t.Input))
+	}
+}
+
 func (s *testTimeSuite) TestParseFrac(c *C) {
 	defer testleak.AfterTest(c)()
 	tbl := []struct {
@@ -54,6 +54,7 @@ import (
 	/*yy:token "\"%c\"" */	stringLit       "string literal"
 	/*yy:token "%x"     */	hexLit          "hexadecimal literal"
 	/*yy:token "%b"     */	bitLit          "bit literal"
+	hintComment	"optimizer hint comment"
 
 
 	abs		"ABS"
@@ -82,8 +83,10 @@ import (
 	btree		"BTREE"
 	by		"BY"
 	byteType	"BYTE"
+	cancel		"CANCEL"
 	caseKwd		"CASE"
 	cast		"CAST"
+	change		"CHANGE"
 	character	"CHARACTER"
 	charsetKwd	"CHARSET"
 	check 		"CHECK"
@@ -159,6 +162,7 @@ import (
 	foreign		"FOREIGN"
 	forKwd		"FOR"
 	force		"FORCE"
+	format		"FORMAT"
 	foundRows	"FOUND_ROWS"
 	from		"FROM"
 	full		"FULL"
@@ -186,15 +190,18 @@ import (
 	is		"IS"
 	isNull		"ISNULL"
 	isolation	"ISOLATION"
+	jobs		"JOBS"
 	join		"JOIN"
 	key		"KEY"
 	keyBlockSize	"KEY_BLOCK_SIZE"
 	keys		"KEYS"
+	kill		"KILL"
 	lastInsertID	"LAST_INSERT_ID"
 	le		"<="
 	leading		"LEADING"
 	left		"LEFT"
 	length		"LENGTH"
+	less		"LESS"
 	level		"LEVEL"
 	like		"LIKE"
 	limit		"LIMIT"
@@ -208,12 +215,14 @@ import (
 	ltrim		"LTRIM"
 	max		"MAX"
 	maxRows		"MAX_ROWS"
+	maxValue	"MAXVALUE"
 	microsecond	"MICROSECOND"
 	min		"MIN"
 	minute		"MINUTE"
 	minRows		"MIN_ROWS"
 	mod 		"MOD"
 	mode		"MODE"
+	modify		"MODIFY"
 	month		"MONTH"
 	monthname	"MONTHNAME"
 	names		"NAMES"
@@ -232,6 +241,8 @@ import (
 	order		"ORDER"
 	oror		"||"
 	outer		"OUTER"
+	partition	"PARTITION"
+	partitions	"PARTITIONS"
 	password	"PASSWORD"
 	placeholder	"PLACEHOLDER"
 	pow 		"POW"
@@ -239,13 +250,17 @@ import (
 	prepare		"PREPARE"
 	primary		"PRIMARY"
 	procedure	"PROCEDURE"
+	processlist	"PROCESSLIST"
 	quarter		"QUARTER"
+	query		"QUERY"
 	quick		"QUICK"
 	rand		"RAND"
+	rangeKwd	"RANGE"
 	read		"READ"
 	redundant	"REDUNDANT"
 	references	"REFERENCES"
 	regexpKwd	"REGEXP"
+	rename		"RENAME"
 	repeat		"REPEAT"
 	repeatable	"REPEATABLE"
 	replace		"REPLACE"
@@ -270,6 +285,7 @@ import (
 	signed		"SIGNED"
 	some 		"SOME"
 	start		"START"
+	statsMeta	"STATS_META"
 	status		"STATUS"
 	stringType	"string"
 	subDate		"SUBDATE"
@@ -281,8 +297,10 @@ import (
 	sysDate		"SYSDATE"
 	tableKwd	"TABLE"
 	tables		"TABLES"
+	than		"THAN"
 	then		"THEN"
 	to		"TO"
+	toDays		"TO_DAYS"
 	trailing	"TRAILING"
 	transaction	"TRANSACTION"
 	triggers	"TRIGGERS"
@@ -504,6 +522,7 @@ import (
 	JoinTable 		"join table"
 	JoinType		"join type"
 	KeyOrIndex		"{KEY|INDEX}"
+	KillStmt		"Kill statement"
 	LikeEscapeOpt 		"like escape option"
 	LimitClause		"LIMIT clause"
 	Literal			"literal value"
@@ -516,6 +535,7 @@ import (
 	NationalOpt		"National option"
 	NotOpt			"optional NOT"
 	NowSym			"CURRENT_TIMESTAMP/LOCALTIME/LOCALTIMESTAMP/NOW"
+	NumList			"Some numbers"
 	NumLiteral		"Num/Int/Float/Decimal Literal"
 	ObjectType		"Grant statement object type"
 	OnDuplicateKeyUpdate	"ON DUPLICATE KEY UPDATE value list"
@@ -529,6 +549,11 @@ import (
 	OrderByOptional		"Optional ORDER BY clause optional"
 	ByList			"BY list"
 	OuterOpt		"optional OUTER clause"
+	PartitionDefinition	"partition definition"
+	PartitionDefinitionList	"partition definition list"
+	PartitionLessThan	"VALUES LESS THAN value of partition"
+	PartitionNumOpt		"optional PARTITIONS number"
+	PartitionOpt		"optional PARTITION BY clause"
 	QuickOptional		"QUICK or empty"
 	PasswordOpt		"Password option"
 	ColumnPosition		"Column position [First|After ColumnName]"
@@ -547,6 +572,8 @@ import (
 	OnUpdateOpt		"optional ON UPDATE clause"
 	ReferOpt		"reference option"
 	RegexpSym		"REGEXP or RLIKE"
+	RenameTableStmt		"rename table statement"
+	RenameTo		"optional TO or AS of rename"
 	ReplaceIntoStmt		"REPLACE INTO statement"
 	ReplacePriority		"replace statement priority"
 	RollbackStmt		"ROLLBACK statement"
@@ -572,6 +599,7 @@ import (
 	StringName		"string literal or identifier"
 	StringList 		"string list"
 	ExplainableStmt		"explainable statement"
+	ExplainFormatOpt	"explain format option"
 	SubSelect		"Sub Select"
 	Symbol			"Constraint Symbol"
 	SystemVariable		"System defined variable name"
@@ -584,11 +612,14 @@ import (
 	TableLockList		"Table lock list"
 	TableName		"Table name"
 	TableNameList		"Table name list"
+	TableOptimizerHintsOpt	"table optimizer hints opt"
 	TableOption		"create table option"
 	TableOptionList		"create table option list"
 	TableOptionListOpt	"create table option list opt"
 	TableRef 		"table reference"
 	TableRefs 		"table references"
+	TableToTable	 	"rename table to table"
+	TableToTableList 	"rename table to table by list"
 	TimeUnit		"Time unit"
 	TransactionChar		"Transaction characteristic"
 	TransactionChars	"Transaction characteristic list"
@@ -769,6 +800,67 @@ AlterTableSpec:
 			Name: $4.(string),
 		}
 	}
+|	"MODIFY" ColumnKeywordOpt ColumnDef ColumnPosition
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp:		ast.AlterTableModifyColumn,
+			Column:		$3.(*ast.ColumnDef),
+			Position:	$4.(*ast.ColumnPosition),
+		}
+	}
+|	"CHANGE" ColumnKeywordOpt ColumnName ColumnDef ColumnPosition
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp:		ast.AlterTableChangeColumn,
+			OldColumnName:	$3.(*ast.ColumnName),
+			Column:		$4.(*ast.ColumnDef),
+			Position:	$5.(*ast.ColumnPosition),
+		}
+	}
+|	"ALTER" ColumnKeywordOpt ColumnName "SET" "DEFAULT" SignedLiteral
+	{
+		option := &ast.ColumnOption{Tp: ast.ColumnOptionDefaultValue, Expr: $6.(ast.ExprNode)}
+		$$ = &ast.AlterTableSpec{
+			Tp:	ast.AlterTableAlterColumn,
+			Column:	&ast.ColumnDef{Name: $3.(*ast.ColumnName), Options: []*ast.ColumnOption{option}},
+		}
+	}
+|	"ALTER" ColumnKeywordOpt ColumnName "DROP" "DEFAULT"
+	{
+		// The column without the default value option drops the default value.
+		$$ = &ast.AlterTableSpec{
+			Tp:	ast.AlterTableAlterColumn,
+			Column:	&ast.ColumnDef{Name: $3.(*ast.ColumnName)},
+		}
+	}
+|	"RENAME" RenameTo TableName
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp:		ast.AlterTableRenameTable,
+			NewTable:	$3.(*ast.TableName),
+		}
+	}
+|	"ADD" "PARTITION" '(' PartitionDefinitionList ')'
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp:			ast.AlterTableAddPartition,
+			PartDefinitions:	$4.([]*ast.PartitionDefinition),
+		}
+	}
+|	"DROP" "PARTITION" Identifier
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp:	ast.AlterTableDropPartition,
+			Name:	$3.(string),
+		}
+	}
+|	"TRUNCATE" "PARTITION" Identifier
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp:	ast.AlterTableTruncatePartition,
+			Name:	$3.(string),
+		}
+	}
 |	"DISABLE" "KEYS"
 	{
 		$$ = &ast.AlterTableSpec{}
@@ -781,6 +873,11 @@ AlterTableSpec:
 KeyOrIndex:
 	"KEY"|"INDEX"
 
+RenameTo:
+	{}
+|	"TO"
+|	"AS"
+
 ColumnKeywordOpt:
 	{}
 |	"COLUMN"
@@ -1333,7 +1430,7 @@ DatabaseOptionList:
  *      )
  *******************************************************************/
 CreateTableStmt:
-	"CREATE" "TABLE" IfNotExists TableName '(' TableElementList ')' TableOptionListOpt
+	"CREATE" "TABLE" IfNotExists TableName '(' TableElementList ')' TableOptionListOpt PartitionOpt
 	{
 		tes := $6.([]interface {})
 		var columnDefs []*ast.ColumnDef
@@ -1357,6 +1454,73 @@ CreateTableStmt:
 			Constraints:    constraints,
 			Options:        $8.([]*ast.TableOption),
 		}
+		if $9 != nil {
+			$$.(*ast.CreateTableStmt).Partition = $9.(*ast.PartitionOptions)
+		}
+	}
+
+PartitionOpt:
+	{
+		$$ = nil
+	}
+|	"PARTITION" "BY" "RANGE" '(' Expression ')' '(' PartitionDefinitionList ')'
+	{
+		$$ = &ast.PartitionOptions{
+			Tp:		model.PartitionTypeRange,
+			Expr:		$5.(ast.ExprNode),
+			Definitions:	$8.([]*ast.PartitionDefinition),
+		}
+	}
+|	"PARTITION" "BY" "HASH" '(' Expression ')' PartitionNumOpt
+	{
+		$$ = &ast.PartitionOptions{
+			Tp:	model.PartitionTypeHash,
+			Expr:	$5.(ast.ExprNode),
+			Num:	$7.(uint64),
+		}
+	}
+
+PartitionNumOpt:
+	{
+		$$ = uint64(1)
+	}
+|	"PARTITIONS" LengthNum
+	{
+		$$ = $2
+	}
+
+PartitionDefinitionList:
+	PartitionDefinition
+	{
+		$$ = []*ast.PartitionDefinition{$1.(*ast.PartitionDefinition)}
+	}
+|	PartitionDefinitionList ',' PartitionDefinition
+	{
+		$$ = append($1.([]*ast.PartitionDefinition), $3.(*ast.PartitionDefinition))
+	}
+
+PartitionDefinition:
+	"PARTITION" Identifier "VALUES" "LESS" "THAN" PartitionLessThan
+	{
+		def := &ast.PartitionDefinition{Name: $2.(string)}
+		if $6 != nil {
+			def.LessThan = $6.(ast.ExprNode)
+		}
+		$$ = def
+	}
+
+PartitionLessThan:
+	'(' Expression ')'
+	{
+		$$ = $2
+	}
+|	"MAXVALUE"
+	{
+		$$ = nil
+	}
+|	'(' "MAXVALUE" ')'
+	{
+		$$ = nil
 	}
 
 Default:
@@ -1393,24 +1557,27 @@ DoStmt:
  *
  *******************************************************************/
 DeleteFromStmt:
-	"DELETE" LowPriorityOptional QuickOptional IgnoreOptional "FROM" TableName WhereClauseOptional OrderByOptional LimitClause
+	"DELETE" TableOptimizerHintsOpt LowPriorityOptional QuickOptional IgnoreOptional "FROM" TableName IndexHintListOpt WhereClauseOptional OrderByOptional LimitClause
 	{
 		// Single Table
-		join := &ast.Join{Left: &ast.TableSource{Source: $6.(ast.ResultSetNode)}, Right: nil}
+		tn := $7.(*ast.TableName)
+		tn.IndexHints = $8.([]*ast.IndexHint)
+		join := &ast.Join{Left: &ast.TableSource{Source: tn}, Right: nil}
 		x := &ast.DeleteStmt{
+			TableHints:	$2.([]*ast.TableOptimizerHint),
 			TableRefs:	&ast.TableRefsClause{TableRefs: join},
-			LowPriority:	$2.(bool),
-			Quick:		$3.(bool),
-			Ignore:		$4.(bool),
+			LowPriority:	$3.(bool),
+			Quick:		$4.(bool),
+			Ignore:		$5.(bool),
 		}
-		if $7 != nil {
-			x.Where = $7.(ast.ExprNode)
+		if $9 != nil {
+			x.Where = $9.(ast.ExprNode)
 		}
-		if $8 != nil {
-			x.Order = $8.(*ast.OrderByClause)
+		if $10 != nil {
+			x.Order = $10.(*ast.OrderByClause)
 		}
-		if $9 != nil {
-			x.Limit = $9.(*ast.Limit)
+		if $11 != nil {
+			x.Limit = $11.(*ast.Limit)
 		}
 
 		$$ = x
@@ -1418,39 +1585,41 @@ DeleteFromStmt:
 			break
 		}
 	}
-|	"DELETE" LowPriorityOptional QuickOptional IgnoreOptional TableNameList "FROM" TableRefs WhereClauseOptional
+|	"DELETE" TableOptimizerHintsOpt LowPriorityOptional QuickOptional IgnoreOptional TableNameList "FROM" TableRefs WhereClauseOptional
 	{
 		// Multiple Table
 		x := &ast.DeleteStmt{
-			LowPriority:	$2.(bool),
-			Quick:		$3.(bool),
-			Ignore:		$4.(bool),
+			TableHints:	$2.([]*ast.TableOptimizerHint),
+			LowPriority:	$3.(bool),
+			Quick:		$4.(bool),
+			Ignore:		$5.(bool),
 			IsMultiTable:	true,
 			BeforeFrom:	true,
-			Tables:		&ast.DeleteTableList{Tables: $5.([]*ast.TableName)},
-			TableRefs:	&ast.TableRefsClause{TableRefs: $7.(*ast.Join)},
+			Tables:		&ast.DeleteTableList{Tables: $6.([]*ast.TableName)},
+			TableRefs:	&ast.TableRefsClause{TableRefs: $8.(*ast.Join)},
 		}
-		if $8 != nil {
-			x.Where = $8.(ast.ExprNode)
+		if $9 != nil {
+			x.Where = $9.(ast.ExprNode)
 		}
 		$$ = x
 		if yylex.(*lexer).root {
 			break
 		}
 	}
-|	"DELETE" LowPriorityOptional QuickOptional IgnoreOptional "FROM" TableNameList "USING" TableRefs WhereClauseOptional
+|	"DELETE" TableOptimizerHintsOpt LowPriorityOptional QuickOptional IgnoreOptional "FROM" TableNameList "USING" TableRefs WhereClauseOptional
 	{
 		// Multiple Table
 		x := &ast.DeleteStmt{
-			LowPriority:	$2.(bool),
-			Quick:		$3.(bool),
-			Ignore:		$4.(bool),
+			TableHints:	$2.([]*ast.TableOptimizerHint),
+			LowPriority:	$3.(bool),
+			Quick:		$4.(bool),
+			Ignore:		$5.(bool),
 			IsMultiTable:	true,
-			Tables:		&ast.DeleteTableList{Tables: $6.([]*ast.TableName)},
-			TableRefs:	&ast.TableRefsClause{TableRefs: $8.(*ast.Join)},
+			Tables:		&ast.DeleteTableList{Tables: $7.([]*ast.TableName)},
+			TableRefs:	&ast.TableRefsClause{TableRefs: $9.(*ast.Join)},
 		}
-		if $9 != nil {
-			x.Where = $9.(ast.ExprNode)
+		if $10 != nil {
+			x.Where = $10.(ast.ExprNode)
 		}
 		$$ = x
 		if yylex.(*lexer).root {
@@ -1538,6 +1707,27 @@ ExplainStmt:
 	{
 		$$ = &ast.ExplainStmt{Stmt: $2.(ast.StmtNode)}
 	}
+|	ExplainSym "ANALYZE" ExplainFormatOpt ExplainableStmt
+	{
+		$$ = &ast.ExplainStmt{
+			Stmt:		$4.(ast.StmtNode),
+			Analyze:	true,
+			Format:		$3.(string),
+		}
+	}
+
+ExplainFormatOpt:
+	{
+		$$ = ast.ExplainFormatRow
+	}
+|	"FORMAT" eq Identifier
+	{
+		$$ = strings.ToLower($3.(string))
+	}
+|	"FORMAT" eq stringLit
+	{
+		$$ = strings.ToLower($3.(string))
+	}
 
 LengthNum:
 	NUM
@@ -1954,7 +2144,8 @@ UnReservedKeyword:
 |	"COMMENT" | "AVG_ROW_LENGTH" | "CONNECTION" | "CHECKSUM" | "COMPRESSION" | "KEY_BLOCK_SIZE" | "MAX_ROWS" | "MIN_ROWS"
 |	"NATIONAL" | "ROW" | "ROW_FORMAT" | "QUARTER" | "ESCAPE" | "GRANTS" | "FIELDS" | "TRIGGERS" | "DELAY_KEY_WRITE"
 |	"ISOLATION" |	"REPEATABLE" | "COMMITTED" | "UNCOMMITTED" | "ONLY" | "SERIALIZABLE" | "LEVEL" | "VARIABLES"
-|	"SQL_CACHE" | "SQL_NO_CACHE" | "ACTION" | "DISABLE" | "ENABLE" | "REVERSE"
+|	"SQL_CACHE" | "SQL_NO_CACHE" | "ACTION" | "DISABLE" | "ENABLE" | "REVERSE" | "PROCESSLIST" | "QUERY"
+|	"STATS_META" | "FORMAT" | "MODIFY" | "CANCEL" | "JOBS" | "PARTITIONS" | "LESS" | "THAN"
 
 NotKeywordToken:
 	"ABS" | "ADDDATE" | "ADMIN" | "COALESCE" | "CONCAT" | "CONCAT_WS" | "CONNECTION_ID" | "CUR_TIME"| "COUNT" | "DAY"
@@ -1962,7 +2153,7 @@ NotKeywordToken:
 |	"IFNULL" | "ISNULL" | "LAST_INSERT_ID" | "LCASE" | "LENGTH" | "LOCATE" | "LOWER" | "LTRIM" | "MAX" | "MICROSECOND" | "MIN"
 |	"MINUTE" | "NULLIF" | "MONTH" | "MONTHNAME" | "NOW" | "POW" | "POWER" | "RAND" | "SECOND" | "SQL_CALC_FOUND_ROWS" | "SUBDATE"
 |	"SUBSTRING" %prec lowerThanLeftParen | "SUBSTRING_INDEX" | "SUM" | "TRIM" | "RTRIM" | "UCASE" | "UPPER" | "VERSION"
-|	"WEEKDAY" | "WEEKOFYEAR" | "YEARWEEK" | "ROUND"
+|	"WEEKDAY" | "WEEKOFYEAR" | "YEARWEEK" | "ROUND" | "TO_DAYS"
 
 /************************************************************************************
  *
@@ -2745,6 +2936,10 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1.(string)), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
 	}
+|	"TO_DAYS" '(' Expression ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1.(string)), Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
 |	"YEARWEEK" '(' ExpressionList ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1.(string)), Args: $3.([]ast.ExprNode)}
@@ -3143,21 +3338,50 @@ RollbackStmt:
 		$$ = &ast.RollbackStmt{}
 	}
 
+/*******************************************************************************************
+ * See: https://dev.mysql.com/doc/refman/5.7/en/rename-table.html
+ *******************************************************************************************/
+RenameTableStmt:
+	"RENAME" "TABLE" TableToTableList
+	{
+		$$ = &ast.RenameTableStmt{TableToTables: $3.([]*ast.TableToTable)}
+	}
+
+TableToTableList:
+	TableToTable
+	{
+		$$ = []*ast.TableToTable{$1.(*ast.TableToTable)}
+	}
+|	TableToTableList ',' TableToTable
+	{
+		$$ = append($1.([]*ast.TableToTable), $3.(*ast.TableToTable))
+	}
+
+TableToTable:
+	TableName "TO" TableName
+	{
+		$$ = &ast.TableToTable{
+			OldTable:	$1.(*ast.TableName),
+			NewTable:	$3.(*ast.TableName),
+		}
+	}
+
 SelectStmt:
-	"SELECT" SelectStmtOpts SelectStmtFieldList SelectStmtLimit SelectLockOpt
+	"SELECT" TableOptimizerHintsOpt SelectStmtOpts SelectStmtFieldList SelectStmtLimit SelectLockOpt
 	{
 		st := &ast.SelectStmt {
-			Distinct:      $2.(bool),
-			Fields:        $3.(*ast.FieldList),
-			LockTp:	       $5.(ast.SelectLockType),
+			TableHints:    $2.([]*ast.TableOptimizerHint),
+			Distinct:      $3.(bool),
+			Fields:        $4.(*ast.FieldList),
+			LockTp:	       $6.(ast.SelectLockType),
 		}
 		lastField := st.Fields.Fields[len(st.Fields.Fields)-1]
 		if lastField.Expr != nil && lastField.AsName.O == "" {
 			src := yylex.(*lexer).src
 			var lastEnd int
-			if $4 != nil {
+			if $5 != nil {
 				lastEnd = yyS[yypt-1].offset-1
-			} else if $5 != ast.SelectLockNone {
+			} else if $6 != ast.SelectLockNone {
 				lastEnd = yyS[yypt].offset-1
 			} else {
 				lastEnd = len(src)
@@ -3167,40 +3391,42 @@ SelectStmt:
 			}
 			lastField.SetText(src[lastField.Offset:lastEnd])
 		}
-		if $4 != nil {
-			st.Limit = $4.(*ast.Limit)
+		if $5 != nil {
+			st.Limit = $5.(*ast.Limit)
 		}
 		$$ = st
 	}
-|	"SELECT" SelectStmtOpts SelectStmtFieldList FromDual WhereClauseOptional SelectStmtLimit SelectLockOpt
+|	"SELECT" TableOptimizerHintsOpt SelectStmtOpts SelectStmtFieldList FromDual WhereClauseOptional SelectStmtLimit SelectLockOpt
 	{
 		st := &ast.SelectStmt {
-			Distinct:      $2.(bool),
-			Fields:        $3.(*ast.FieldList),
-			LockTp:	       $7.(ast.SelectLockType),
+			TableHints:    $2.([]*ast.TableOptimizerHint),
+			Distinct:      $3.(bool),
+			Fields:        $4.(*ast.FieldList),
+			LockTp:	       $8.(ast.SelectLockType),
 		}
 		lastField := st.Fields.Fields[len(st.Fields.Fields)-1]
 		if lastField.Expr != nil && lastField.AsName.O == "" {
 			lastEnd := yyS[yypt-3].offset-1
 			lastField.SetText(yylex.(*lexer).src[lastField.Offset:lastEnd])
 		}
-		if $5 != nil {
-			st.Where = $5.(ast.ExprNode)
-		}
 		if $6 != nil {
-			st.Limit = $6.(*ast.Limit)
+			st.Where = $6.(ast.ExprNode)
+		}
+		if $7 != nil {
+			st.Limit = $7.(*ast.Limit)
 		}
 		$$ = st
 	}
-|	"SELECT" SelectStmtOpts SelectStmtFieldList "FROM"
+|	"SELECT" TableOptimizerHintsOpt SelectStmtOpts SelectStmtFieldList "FROM"
 	TableRefsClause WhereClauseOptional SelectStmtGroup HavingClause OrderByOptional
 	SelectStmtLimit SelectLockOpt
 	{
 		st := &ast.SelectStmt{
-			Distinct:	$2.(bool),
-			Fields:		$3.(*ast.FieldList),
-			From:		$5.(*ast.TableRefsClause),
-			LockTp:		$11.(ast.SelectLockType),
+			TableHints:	$2.([]*ast.TableOptimizerHint),
+			Distinct:	$3.(bool),
+			Fields:		$4.(*ast.FieldList),
+			From:		$6.(*ast.TableRefsClause),
+			LockTp:		$12.(ast.SelectLockType),
 		}
 
 		lastField := st.Fields.Fields[len(st.Fields.Fields)-1]
@@ -3209,24 +3435,24 @@ SelectStmt:
 			lastField.SetText(yylex.(*lexer).src[lastField.Offset:lastEnd])
 		}
 
-		if $6 != nil {
-			st.Where = $6.(ast.ExprNode)
-		}
-
 		if $7 != nil {
-			st.GroupBy = $7.(*ast.GroupByClause)
+			st.Where = $7.(ast.ExprNode)
 		}
 
 		if $8 != nil {
-			st.Having = $8.(*ast.HavingClause)
+			st.GroupBy = $8.(*ast.GroupByClause)
 		}
 
 		if $9 != nil {
-			st.OrderBy = $9.(*ast.OrderByClause)
+			st.Having = $9.(*ast.HavingClause)
 		}
 
 		if $10 != nil {
-			st.Limit = $10.(*ast.Limit)
+			st.OrderBy = $10.(*ast.OrderByClause)
+		}
+
+		if $11 != nil {
+			st.Limit = $11.(*ast.Limit)
 		}
 
 		$$ = st
@@ -3483,6 +3709,16 @@ SelectStmtDistinct:
 		$$ = true
 	}
 
+TableOptimizerHintsOpt:
+	/* EMPTY */
+	{
+		$$ = []*ast.TableOptimizerHint(nil)
+	}
+|	hintComment
+	{
+		$$ = parseOptimizerHints($1.(string))
+	}
+
 SelectStmtOpts:
 	SelectStmtDistinct SelectStmtSQLCache SelectStmtCalcFoundRows
 	{
@@ -3811,6 +4047,24 @@ AdminStmt:
 	{
 		$$ = &ast.AdminStmt{Tp: ast.AdminShowDDL}
 	}
+|	"ADMIN" "SHOW" "DDL" "JOBS"
+	{
+		$$ = &ast.AdminStmt{Tp: ast.AdminShowDDLJobs}
+	}
+|	"ADMIN" "SHOW" "DDL" "JOBS" LengthNum
+	{
+		$$ = &ast.AdminStmt{
+			Tp:		ast.AdminShowDDLJobs,
+			JobNumber:	int64($5.(uint64)),
+		}
+	}
+|	"ADMIN" "CANCEL" "DDL" "JOBS" NumList
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminCancelDDLJobs,
+			JobIDs:	$5.([]int64),
+		}
+	}
 |	"ADMIN" "CHECK" "TABLE" TableNameList
 	{
 		$$ = &ast.AdminStmt{
@@ -3819,6 +4073,39 @@ AdminStmt:
 		}
 	}
 
+NumList:
+	LengthNum
+	{
+		$$ = []int64{int64($1.(uint64))}
+	}
+|	NumList ',' LengthNum
+	{
+		$$ = append($1.([]int64), int64($3.(uint64)))
+	}
+
+/****************************Kill Statement*******************************/
+KillStmt:
+	"KILL" LengthNum
+	{
+		// See: https://dev.mysql.com/doc/refman/5.7/en/kill.html
+		$$ = &ast.KillStmt{
+			ConnectionID:	$2.(uint64),
+		}
+	}
+|	"KILL" "CONNECTION" LengthNum
+	{
+		$$ = &ast.KillStmt{
+			ConnectionID:	$3.(uint64),
+		}
+	}
+|	"KILL" "QUERY" LengthNum
+	{
+		$$ = &ast.KillStmt{
+			ConnectionID:	$3.(uint64),
+			Query:		true,
+		}
+	}
+
 /****************************Show Statement*******************************/
 ShowStmt:
 	"SHOW" ShowTargetFilterable ShowLikeOrWhereOpt
@@ -3860,6 +4147,14 @@ ShowStmt:
 			Table: $4.(*ast.TableName),
 		}
 	}
+|	"SHOW" OptFull "PROCESSLIST"
+	{
+		// See: https://dev.mysql.com/doc/refman/5.7/en/show-processlist.html
+		$$ = &ast.ShowStmt{
+			Tp:	ast.ShowProcessList,
+			Full:	$2.(bool),
+		}
+	}
 
 ShowTargetFilterable:
 	"ENGINES"
@@ -3949,6 +4244,12 @@ ShowTargetFilterable:
 			Tp: ast.ShowProcedureStatus,
 		}
 	}
+|	"STATS_META"
+	{
+		$$ = &ast.ShowStmt {
+			Tp: ast.ShowStatsMeta,
+		}
+	}
 
 ShowLikeOrWhereOpt:
 	{
@@ -4032,7 +4333,9 @@ Statement:
 |	DropTableStmt
 |	GrantStmt
 |	InsertIntoStmt
+|	KillStmt
 |	PreparedStmt
+|	RenameTableStmt
 |	RollbackStmt
 |	ReplaceIntoStmt
 |	SelectStmt
@@ -4709,42 +5012,44 @@ StringName:
  * See: https://dev.mysql.com/doc/refman/5.7/en/update.html
  ***********************************************************************************/
 UpdateStmt:
-	"UPDATE" LowPriorityOptional IgnoreOptional TableRef "SET" AssignmentList WhereClauseOptional OrderByOptional LimitClause
+	"UPDATE" TableOptimizerHintsOpt LowPriorityOptional IgnoreOptional TableRef "SET" AssignmentList WhereClauseOptional OrderByOptional LimitClause
 	{
 		var refs *ast.Join
-		if x, ok := $4.(*ast.Join); ok {
+		if x, ok := $5.(*ast.Join); ok {
 			refs = x
 		} else {
-			refs = &ast.Join{Left: $4.(ast.ResultSetNode)}
+			refs = &ast.Join{Left: $5.(ast.ResultSetNode)}
 		}
 		st := &ast.UpdateStmt{
-			LowPriority:	$2.(bool),
+			TableHints:	$2.([]*ast.TableOptimizerHint),
+			LowPriority:	$3.(bool),
 			TableRefs:	&ast.TableRefsClause{TableRefs: refs},
-			List:		$6.([]*ast.Assignment),
-		}
-		if $7 != nil {
-			st.Where = $7.(ast.ExprNode)
+			List:		$7.([]*ast.Assignment),
 		}
 		if $8 != nil {
-			st.Order = $8.(*ast.OrderByClause)
+			st.Where = $8.(ast.ExprNode)
 		}
 		if $9 != nil {
-			st.Limit = $9.(*ast.Limit)
+			st.Order = $9.(*ast.OrderByClause)
+		}
+		if $10 != nil {
+			st.Limit = $10.(*ast.Limit)
 		}
 		$$ = st
 		if yylex.(*lexer).root {
 			break
 		}
 	}
-|	"UPDATE" LowPriorityOptional IgnoreOptional TableRefs "SET" AssignmentList WhereClauseOptional
+|	"UPDATE" TableOptimizerHintsOpt LowPriorityOptional IgnoreOptional TableRefs "SET" AssignmentList WhereClauseOptional
 	{
 		st := &ast.UpdateStmt{
-			LowPriority:	$2.(bool),
-			TableRefs:	&ast.TableRefsClause{TableRefs: $4.(*ast.Join)},
-			List:		$6.([]*ast.Assignment),
+			TableHints:	$2.([]*ast.TableOptimizerHint),
+			LowPriority:	$3.(bool),
+			TableRefs:	&ast.TableRefsClause{TableRefs: $5.(*ast.Join)},
+			List:		$7.([]*ast.Assignment),
 		}
-		if $7 != nil {
-			st.Where = $7.(ast.ExprNode)
+		if $8 != nil {
+			st.Where = $8.(ast.ExprNode)
 		}
 		$$ = st
 		if yylex.(*lexer).root {
@@ -21,6 +21,7 @@ import (
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/util/testleak"
 )
 
@@ -47,7 +48,8 @@ func (s *testParserSuite) TestSimple(c *C) {
 		"delay_key_write", "isolation", "repeatable", "committed", "uncommitted", "only", "serializable", "level",
 		"curtime", "variables", "dayname", "version", "btree", "hash", "row_format", "dynamic", "fixed", "compressed",
 		"compact", "redundant", "sql_no_cache sql_no_cache", "sql_cache sql_cache", "action", "round",
-		"enable", "disable", "reverse",
+		"enable", "disable", "reverse", "processlist", "query", "stats_meta", "format", "modify",
+		"cancel", "jobs", "partitions", "less", "than",
 	}
 	for _, kw := range unreservedKws {
 		src := fmt.Sprintf("SELECT %s FROM tbl;", kw)
@@ -247,6 +249,42 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		{"ALTER TABLE t ADD COLUMN a SMALLINT UNSIGNED AFTER b", true},
 		{"ALTER TABLE t DISABLE KEYS", true},
 		{"ALTER TABLE t ENABLE KEYS", true},
+		{"ALTER TABLE t MODIFY COLUMN a varchar(255)", true},
+		{"ALTER TABLE t MODIFY a bigint NOT NULL DEFAULT 1 FIRST", true},
+		{"ALTER TABLE t MODIFY COLUMN a int AFTER b", true},
+		{"ALTER TABLE t CHANGE COLUMN a b varchar(255)", true},
+		{"ALTER TABLE t CHANGE a b int COMMENT 'b' AFTER c", true},
+		{"ALTER TABLE t CHANGE a varchar(255)", false},
+		{"ALTER TABLE t ALTER COLUMN a SET DEFAULT 1", true},
+		{"ALTER TABLE t ALTER a SET DEFAULT -1.5", true},
+		{"ALTER TABLE t ALTER COLUMN a SET DEFAULT 'abc'", true},
+		{"ALTER TABLE t ALTER COLUMN a DROP DEFAULT", true},
+		{"ALTER TABLE t ALTER a DROP DEFAULT", true},
+		{"ALTER TABLE t ALTER COLUMN a SET DEFAULT", false},
+		{"ALTER TABLE t RENAME t1", true},
+		{"ALTER TABLE t RENAME TO db1.t1", true},
+		{"ALTER TABLE t RENAME AS t1", true},
+		{"ALTER TABLE t ADD COLUMN a int, RENAME TO t1", true},
+		{"ALTER TABLE t RENAME TO", false},
+		{"ALTER TABLE t ADD PARTITION (PARTITION p2 VALUES LESS THAN (200))", true},
+		{"ALTER TABLE t ADD PARTITION (PARTITION p2 VALUES LESS THAN (200), PARTITION p3 VALUES LESS THAN MAXVALUE)", true},
+		{"ALTER TABLE t ADD PARTITION PARTITION p2 VALUES LESS THAN (200)", false},
+		{"ALTER TABLE t DROP PARTITION p1", true},
+		{"ALTER TABLE t DROP PARTITION", false},
+		{"ALTER TABLE t TRUNCATE PARTITION p1", true},
+
+		// For partition
+		{"CREATE TABLE t (a int, b int) PARTITION BY RANGE (a) (PARTITION p0 VALUES LESS THAN (10), PARTITION p1 VALUES LESS THAN (20))", true},
+		{"CREATE TABLE t (a int) ENGINE=InnoDB PARTITION BY RANGE (a) (PARTITION p0 VALUES LESS THAN (-10), PARTITION p1 VALUES LESS THAN MAXVALUE)", true},
+		{"CREATE TABLE t (a int) PARTITION BY RANGE (a) (PARTITION p0 VALUES LESS THAN (MAXVALUE))", true},
+		{"CREATE TABLE t (a int) PARTITION BY RANGE (a) (PARTITION p0 VALUES LESS THAN 10)", false},
+		{"CREATE TABLE t (a int) PARTITION BY RANGE (a)", false},
+		{"CREATE TABLE t (a int) PARTITION BY HASH (a) PARTITIONS 4", true},
+		{"CREATE TABLE t (a int) PARTITION BY HASH (a)", true},
+		{"CREATE TABLE t (a int) PARTITION BY HASH (a) PARTITIONS", false},
+		{"CREATE TABLE t (d date) PARTITION BY RANGE (TO_DAYS(d)) (PARTITION p0 VALUES LESS THAN (TO_DAYS('2017-01-01')), PARTITION p1 VALUES LESS THAN MAXVALUE)", true},
+		{"CREATE TABLE t (d datetime) PARTITION BY RANGE (YEAR(d)) (PARTITION p0 VALUES LESS THAN (2017))", true},
+		{"CREATE TABLE t (partition int)", false},
 
 		// from join
 		{"SELECT * from t1, t2, t3", true},
@@ -261,6 +299,12 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		// For admin
 		{"admin show ddl;", true},
 		{"admin check table t1, t2;", true},
+		{"admin show ddl jobs;", true},
+		{"admin show ddl jobs 20;", true},
+		{"admin show ddl jobs -1;", false},
+		{"admin cancel ddl jobs 1", true},
+		{"admin cancel ddl jobs 1, 2;", true},
+		{"admin cancel ddl jobs;", false},
 
 		// For set names
 		{"set names utf8", true},
@@ -294,6 +338,19 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		{`SHOW DATABASES LIKE 'test2'`, true},
 		{`SHOW PROCEDURE STATUS WHERE Db='test'`, true},
 		{`SHOW INDEX FROM t;`, true},
+		{`SHOW PROCESSLIST;`, true},
+		{`SHOW FULL PROCESSLIST;`, true},
+		{`SHOW PROCESSLIST LIKE 'a'`, false},
+		{`SHOW STATS_META`, true},
+		{`SHOW STATS_META WHERE Table_name = 't'`, true},
+
+		// For kill statement
+		{"kill 1", true},
+		{"kill connection 1", true},
+		{"kill query 1", true},
+		{"kill", false},
+		{"kill query", false},
+		{"kill 'a'", false},
 
 		// For default value
 		{"CREATE TABLE sbtest (id INTEGER UNSIGNED NOT NULL AUTO_INCREMENT, k integer UNSIGNED DEFAULT '0' NOT NULL, c char(120) DEFAULT '' NOT NULL, pad char(60) DEFAULT '' NOT NULL, PRIMARY KEY  (id) )", true},
@@ -303,6 +360,14 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		{"TRUNCATE TABLE t1", true},
 		{"TRUNCATE t1", true},
 
+		// For rename table statement
+		{"RENAME TABLE t TO t1", true},
+		{"RENAME TABLE t t1", false},
+		{"RENAME TABLE d.t TO d1.t1", true},
+		{"RENAME TABLE t1 TO t2, t2 TO t1", true},
+		{"RENAME TABLE t1 TO t2,", false},
+		{"RENAME t1 TO t2", false},
+
 		// For delete statement
 		{"DELETE t1, t2 FROM t1 INNER JOIN t2 INNER JOIN t3 WHERE t1.id=t2.id AND t2.id=t3.id;", true},
 		{"DELETE FROM t1, t2 USING t1 INNER JOIN t2 INNER JOIN t3 WHERE t1.id=t2.id AND t2.id=t3.id;", true},
@@ -339,6 +404,13 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		{`SELECT /*!40001 SQL_NO_CACHE */ * FROM test WHERE 1 limit 0, 2000;`, true},
 
 		{`ANALYZE TABLE t`, true},
+
+		// For explain analyze
+		{`EXPLAIN ANALYZE SELECT * FROM t`, true},
+		{`EXPLAIN ANALYZE FORMAT = JSON SELECT * FROM t`, true},
+		{`DESC ANALYZE FORMAT = 'row' DELETE FROM t WHERE a = 1`, true},
+		{`EXPLAIN ANALYZE t`, false},
+		{`EXPLAIN ANALYZE FORMAT SELECT * FROM t`, false},
 	}
 	s.RunTest(c, table)
 }
@@ -476,6 +548,8 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{"SELECT WEEK('2007-02-03');", true},
 		{"SELECT WEEK('2007-02-03', 0);", true},
 		{"SELECT WEEKOFYEAR('2007-02-03');", true},
+		{"SELECT TO_DAYS('2007-02-03');", true},
+		{"SELECT TO_DAYS('2007-02-03', 1);", false},
 		{"SELECT MONTH('2007-02-03');", true},
 		{"SELECT MONTHNAME('2007-02-03');", true},
 		{"SELECT YEAR('2007-02-03');", true},
@@ -948,8 +1022,56 @@ func (s *testParserSuite) TestIndexHint(c *C) {
 		{`select * from t use index for order by (idx1)`, true},
 		{`select * from t force index for group by (idx1)`, true},
 		{`select * from t use index for group by (idx1) use index for order by (idx2), t2`, true},
+		{`update t force index (idx) set c = 1 where c = 2`, true},
+		{`delete from t use index (idx1, idx2) where c = 1 order by c limit 1`, true},
+		{`delete from t ignore index (idx) where c = 1`, true},
+	}
+	s.RunTest(c, table)
+}
+
+func (s *testParserSuite) TestOptimizerHints(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{`select /*+ MAX_EXECUTION_TIME(1000) */ * from t`, true},
+		{`select /*+ MAX_EXECUTION_TIME(1000) */ 1`, true},
+		{`select /*+ MAX_EXECUTION_TIME(1000) */ 1 from dual`, true},
+		{`select /*+ unknown_hint */ distinct c from t`, true},
+		// A hint comment in other places is an ordinary comment.
+		{`select c /*+ MAX_EXECUTION_TIME(1000) */ from t`, true},
+		{`/*+ MAX_EXECUTION_TIME(1000) */ select c from t`, true},
+		{`update /*+ TIDB_INLJ(t1) */ t1, t2 set t1.c = t2.c where t1.id = t2.id`, true},
+		{`update /*+ NO_AGG_TO_COP() */ low_priority t set c = 1`, true},
+		{`delete /*+ JOIN_ORDER(t1, t2) */ t1 from t1, t2 where t1.id = t2.id`, true},
+		{`delete /*+ NO_AGG_TO_COP() */ quick from t where c = 1`, true},
+		{`update t /*+ TIDB_INLJ(t) */ set c = 1`, true},
 	}
 	s.RunTest(c, table)
+
+	st, err := ParseOneStmt("select /*+ max_execution_time(1000), TIDB_INLJ(t1, `t2`) bad_hint(1 */ c from t", "", "")
+	c.Assert(err, IsNil)
+	hints := st.(*ast.SelectStmt).TableHints
+	c.Assert(hints, HasLen, 2)
+	c.Assert(hints[0].HintName.L, Equals, "max_execution_time")
+	c.Assert(hints[0].MaxExecutionTime, Equals, uint64(1000))
+	c.Assert(hints[1].HintName.L, Equals, "tidb_inlj")
+	c.Assert(hints[1].Tables, DeepEquals, []model.CIStr{model.NewCIStr("t1"), model.NewCIStr("t2")})
+
+	st, err = ParseOneStmt("select /*+ MAX_EXECUTION_TIME(abc) */ c from t", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(st.(*ast.SelectStmt).TableHints, HasLen, 0)
+
+	st, err = ParseOneStmt("update /*+ JOIN_FIXED_ORDER() */ t set c = 1", "", "")
+	c.Assert(err, IsNil)
+	hints = st.(*ast.UpdateStmt).TableHints
+	c.Assert(hints, HasLen, 1)
+	c.Assert(hints[0].HintName.L, Equals, "join_fixed_order")
+	c.Assert(hints[0].Tables, HasLen, 0)
+
+	st, err = ParseOneStmt("delete /*+ TIDB_SMJ(t1, t2) */ from t1 using t1, t2 where t1.id = t2.id", "", "")
+	c.Assert(err, IsNil)
+	hints = st.(*ast.DeleteStmt).TableHints
+	c.Assert(hints, HasLen, 1)
+	c.Assert(hints[0].Tables, DeepEquals, []model.CIStr{model.NewCIStr("t1"), model.NewCIStr("t2")})
 }
 
 func (s *testParserSuite) TestEscape(c *C) {
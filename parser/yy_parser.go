@@ -15,10 +15,13 @@ package parser
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
 )
@@ -58,6 +61,43 @@ func handleMySQLSpecificCode(sql string) string {
 	return specCodePattern.ReplaceAllStringFunc(sql, trimComment)
 }
 
+var hintPattern = regexp.MustCompile(`^[ \t\r\n,]*([a-zA-Z_][a-zA-Z0-9_]*)[ \t\r\n]*\(([^()]*)\)`)
+
+// parseOptimizerHints parses the hints in an optimizer hint comment like
+// "/*+ MAX_EXECUTION_TIME(1000) */". The same as MySQL, malformed hints are ignored.
+func parseOptimizerHints(comment string) []*ast.TableOptimizerHint {
+	txt := strings.TrimSuffix(strings.TrimPrefix(comment, "/*+"), "*/")
+	var hints []*ast.TableOptimizerHint
+	for {
+		m := hintPattern.FindStringSubmatch(txt)
+		if m == nil {
+			break
+		}
+		txt = txt[len(m[0]):]
+		args := strings.FieldsFunc(m[2], func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+		hint := &ast.TableOptimizerHint{HintName: model.NewCIStr(m[1])}
+		switch hint.HintName.L {
+		case "max_execution_time":
+			if len(args) != 1 {
+				continue
+			}
+			n, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				continue
+			}
+			hint.MaxExecutionTime = n
+		default:
+			for _, arg := range args {
+				hint.Tables = append(hint.Tables, model.NewCIStr(strings.Trim(arg, "`")))
+			}
+		}
+		hints = append(hints, hint)
+	}
+	return hints
+}
+
 // Parse parses a query string to raw ast.StmtNode.
 // If charset or collation is "", default charset and collation will be used.
 func Parse(sql, charset, collation string) ([]ast.StmtNode, error) {
@@ -304,7 +304,9 @@ func (ps *perfSchema) registerStatements() {
 	ps.RegisterStatement("sql", "explain", (*ast.ExplainStmt)(nil))
 	ps.RegisterStatement("sql", "grant", (*ast.GrantStmt)(nil))
 	ps.RegisterStatement("sql", "insert", (*ast.InsertStmt)(nil))
+	ps.RegisterStatement("sql", "kill", (*ast.KillStmt)(nil))
 	ps.RegisterStatement("sql", "prepare", (*ast.PrepareStmt)(nil))
+	ps.RegisterStatement("sql", "rename_table", (*ast.RenameTableStmt)(nil))
 	ps.RegisterStatement("sql", "rollback", (*ast.RollbackStmt)(nil))
 	ps.RegisterStatement("sql", "select", (*ast.SelectStmt)(nil))
 	ps.RegisterStatement("sql", "set", (*ast.SetStmt)(nil))
@@ -135,6 +135,12 @@ func pruneColumnsAndResolveIndices(p Plan, parentUsedCols []*expression.Column)
 				aggrFunc.SetArgs(i, newArg)
 			}
 		}
+		for i, expr := range v.GroupByItems {
+			v.GroupByItems[i], err = retrieveColumnsInExpression(expr, p.GetChildByIndex(0).GetSchema())
+			if err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+		}
 		v.schema.InitIndices()
 		return used, append(outer, outerCols...), nil
 	case *NewSort:
@@ -207,11 +213,14 @@ func pruneColumnsAndResolveIndices(p Plan, parentUsedCols []*expression.Column)
 		for _, otherCond := range v.OtherConditions {
 			cols, outerCols = extractColumn(otherCond, cols, outerCols)
 		}
+		for _, naCond := range v.NullAwareConditions {
+			cols, outerCols = extractColumn(naCond, cols, outerCols)
+		}
 		var leftCols, rightCols []*expression.Column
 		for _, col := range cols {
 			if p.GetChildByIndex(0).GetSchema().GetIndex(col) != -1 {
 				leftCols = append(leftCols, col)
-			} else {
+			} else if p.GetChildByIndex(1).GetSchema().GetIndex(col) != -1 {
 				rightCols = append(rightCols, col)
 			}
 		}
@@ -237,14 +246,28 @@ func pruneColumnsAndResolveIndices(p Plan, parentUsedCols []*expression.Column)
 				return nil, nil, errors.Trace(err)
 			}
 		}
-		used := append(usedLeft, usedRight...)
+		var used []bool
+		// The other conditions of semi join are evaluated with the joined rows, which aren't the output rows.
+		joinedSchema := p.GetSchema()
+		if v.JoinType == SemiJoin || v.JoinType == AntiSemiJoin {
+			used = usedLeft
+			if v.WithAux {
+				used = append(used, true)
+			}
+			joinedSchema = append(p.GetChildByIndex(0).GetSchema().DeepCopy(), p.GetChildByIndex(1).GetSchema().DeepCopy()...)
+		} else {
+			used = append(usedLeft, usedRight...)
+		}
 		for i := len(used) - 1; i >= 0; i-- {
 			if !used[i] {
 				v.schema = append(v.schema[:i], v.schema[i+1:]...)
 			}
 		}
+		if v.JoinType == SemiJoin || v.JoinType == AntiSemiJoin {
+			joinedSchema.InitIndices()
+		}
 		for i, otherCond := range v.OtherConditions {
-			v.OtherConditions[i], err = retrieveColumnsInExpression(otherCond, p.GetSchema())
+			v.OtherConditions[i], err = retrieveColumnsInExpression(otherCond, joinedSchema)
 			if err != nil {
 				return nil, nil, errors.Trace(err)
 			}
@@ -259,6 +282,16 @@ func pruneColumnsAndResolveIndices(p Plan, parentUsedCols []*expression.Column)
 				return nil, nil, errors.Trace(err)
 			}
 		}
+		for _, naCond := range v.NullAwareConditions {
+			naCond.Args[0], err = retrieveColumnsInExpression(naCond.Args[0], p.GetChildByIndex(0).GetSchema())
+			if err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+			naCond.Args[1], err = retrieveColumnsInExpression(naCond.Args[1], p.GetChildByIndex(1).GetSchema())
+			if err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+		}
 		v.schema.InitIndices()
 		return used, outerCols, nil
 	default:
@@ -283,6 +316,14 @@ func pruneApply(v *Apply, parentUsedCols []*expression.Column) ([]bool, []*expre
 		}
 	}
 	newUsedCols := v.OuterSchema
+	if v.Checker != nil && v.Checker.Condition != nil {
+		cols, _ := extractColumn(v.Checker.Condition, nil, nil)
+		for _, col := range cols {
+			if v.GetChildByIndex(0).GetSchema().GetIndex(col) != -1 {
+				newUsedCols = append(newUsedCols, col)
+			}
+		}
+	}
 	for _, used := range parentUsedCols {
 		if v.GetChildByIndex(0).GetSchema().GetIndex(used) != -1 {
 			newUsedCols = append(newUsedCols, used)
@@ -300,6 +341,15 @@ func pruneApply(v *Apply, parentUsedCols []*expression.Column) ([]bool, []*expre
 			v.schema = append(v.schema[:i], v.schema[i+1:]...)
 		}
 	}
+	if v.Checker != nil && v.Checker.Condition != nil {
+		// The condition is evaluated with the outer row and the inner row joined.
+		joinedSchema := append(v.GetChildByIndex(0).GetSchema().DeepCopy(), v.InnerPlan.GetSchema().DeepCopy()...)
+		joinedSchema.InitIndices()
+		_, err = retrieveColumnsInExpression(v.Checker.Condition, joinedSchema)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
 	v.schema.InitIndices()
 	return used, outer, nil
 }
@@ -15,6 +15,14 @@ package plan
 
 import (
 	"math"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/plan/statistics"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
 )
 
 // Pre-defined cost factors.
@@ -69,9 +77,9 @@ func estimate(p Plan) {
 }
 
 func tableScan(v *TableScan) {
-	var rowCount float64 = FullRangeCount
-	for _, con := range v.AccessConditions {
-		rowCount *= guesstimateFilterRate(con)
+	rowCount, ok := tableScanRowCount(v)
+	if !ok {
+		rowCount = pseudoRowCount(v.stats, v.AccessConditions)
 	}
 	v.startupCost = 0
 	if v.limit == 0 {
@@ -84,9 +92,9 @@ func tableScan(v *TableScan) {
 }
 
 func indexScan(v *IndexScan) {
-	var rowCount float64 = FullRangeCount
-	for _, con := range v.AccessConditions {
-		rowCount *= guesstimateFilterRate(con)
+	rowCount, ok := indexScanRowCount(v)
+	if !ok {
+		rowCount = pseudoRowCount(v.stats, v.AccessConditions)
 	}
 	v.startupCost = 0
 	if v.limit == 0 {
@@ -98,6 +106,183 @@ func indexScan(v *IndexScan) {
 	v.totalCost = v.rowCount * IndexCost
 }
 
+// pseudoRowCount guesstimates the row count with the filter rates of the access conditions,
+// it's used when the statistics can't estimate the row count. The table is assumed to have
+// FullRangeCount rows if it isn't analyzed.
+func pseudoRowCount(stats *statistics.Table, conditions []ast.ExprNode) float64 {
+	var rowCount float64 = FullRangeCount
+	if stats != nil {
+		rowCount = float64(stats.Count)
+	}
+	for _, con := range conditions {
+		rowCount *= guesstimateFilterRate(con)
+	}
+	return rowCount
+}
+
+// tableScanRowCount estimates the row count of the table ranges with the histogram of the primary key,
+// it returns false if the histogram can't be used.
+func tableScanRowCount(v *TableScan) (float64, bool) {
+	if v.stats == nil || v.RefAccess {
+		// The access conditions of RefAccess have no value until the plan is executed.
+		return 0, false
+	}
+	if len(v.AccessConditions) == 0 {
+		return float64(v.stats.Count), true
+	}
+	if !v.Table.PKIsHandle {
+		// The ranges are built on the hidden row ID, which has no histogram.
+		return 0, false
+	}
+	var pkCol *statistics.Column
+	for _, col := range v.Table.Columns {
+		if mysql.HasPriKeyFlag(col.Flag) && !mysql.HasUnsignedFlag(col.Flag) {
+			pkCol = v.stats.ColumnByID(col.ID)
+		}
+	}
+	if pkCol == nil {
+		return 0, false
+	}
+	if err := buildTableRange(v); err != nil {
+		return 0, false
+	}
+	var rowCount int64
+	for _, ran := range v.Ranges {
+		low, high := types.NewIntDatum(ran.LowVal), types.NewIntDatum(ran.HighVal)
+		if ran.LowVal == math.MinInt64 {
+			low = types.MinNotNullDatum()
+		}
+		if ran.HighVal == math.MaxInt64 {
+			high = types.MaxValueDatum()
+		}
+		// The high value is included in the table range.
+		count, err := pkCol.BetweenRowCount(low, high)
+		if err != nil {
+			return 0, false
+		}
+		rowCount += count
+		if high.Kind() != types.KindMaxValue {
+			count, err = pkCol.EqualRowCount(high)
+			if err != nil {
+				return 0, false
+			}
+			rowCount += count
+		}
+	}
+	return math.Min(float64(rowCount), float64(v.stats.Count)), true
+}
+
+// indexScanRowCount estimates the row count of the index ranges with the histogram of the index,
+// it returns false if the histogram can't be used. The index histogram is built on the encoded
+// index values, so a range of a column prefix is the range of the encoded prefix.
+func indexScanRowCount(v *IndexScan) (float64, bool) {
+	if v.stats == nil || v.RefAccess {
+		return 0, false
+	}
+	if len(v.AccessConditions) == 0 {
+		return float64(v.stats.Count), true
+	}
+	idx := v.stats.IndexByID(v.Index.ID)
+	if idx == nil {
+		return 0, false
+	}
+	if err := buildIndexRange(v); err != nil {
+		return 0, false
+	}
+	var rowCount int64
+	for _, ran := range v.Ranges {
+		count, err := indexRangeRowCount(v, idx, ran)
+		if err != nil {
+			return 0, false
+		}
+		rowCount += count
+	}
+	return math.Min(float64(rowCount), float64(v.stats.Count)), true
+}
+
+func indexRangeRowCount(v *IndexScan, idx *statistics.Column, ran *IndexRange) (int64, error) {
+	lowVal, err := convertIndexValues(v, ran.LowVal)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	highVal, err := convertIndexValues(v, ran.HighVal)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	low, err := codec.EncodeKey(nil, lowVal...)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if ran.IsPoint() && len(ran.LowVal) == len(v.Index.Columns) {
+		count, err := idx.EqualRowCount(types.NewBytesDatum(low))
+		return count, errors.Trace(err)
+	}
+	if ran.LowExclude {
+		low = kv.Key(low).PrefixNext()
+	}
+	high, err := codec.EncodeKey(nil, highVal...)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if !ran.HighExclude {
+		high = kv.Key(high).PrefixNext()
+	}
+	count, err := idx.BetweenRowCount(types.NewBytesDatum(low), types.NewBytesDatum(high))
+	return count, errors.Trace(err)
+}
+
+// convertIndexValues converts the range values to the types of the index columns,
+// so they are encoded as the values in the index.
+func convertIndexValues(v *IndexScan, vals []types.Datum) ([]types.Datum, error) {
+	converted := make([]types.Datum, len(vals))
+	for i, val := range vals {
+		switch val.Kind() {
+		case types.KindNull, types.KindMinNotNull, types.KindMaxValue:
+			converted[i] = val
+			continue
+		}
+		col := v.Table.Columns[v.Index.Columns[i].Offset]
+		d, err := val.ConvertTo(&col.FieldType)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		converted[i] = d
+	}
+	return converted, nil
+}
+
+// rowCountSetter is implemented by the plans which embed basePlan.
+type rowCountSetter interface {
+	setRowCount(count float64)
+}
+
+// estimateRowCounts estimates the row counts of the plan and its descendants, EXPLAIN ANALYZE shows them alongside
+// the actual row counts. The plans which the cost model doesn't estimate are estimated like the leaves of the join
+// group in the new planner, or take the row counts of their children in the old one.
+func (b *planBuilder) estimateRowCounts(p Plan) {
+	estimate(p)
+	b.fillRowCounts(p)
+}
+
+func (b *planBuilder) fillRowCounts(p Plan) {
+	children := p.GetChildren()
+	for _, child := range children {
+		b.fillRowCounts(child)
+	}
+	if apply, ok := p.(*Apply); ok {
+		b.fillRowCounts(apply.InnerPlan)
+	}
+	setter, ok := p.(rowCountSetter)
+	if !ok || p.RowCount() > 0 {
+		return
+	}
+	if UseNewPlanner {
+		setter.setRowCount(b.estimateRowCount(p))
+	} else if len(children) == 1 {
+		setter.setRowCount(children[0].RowCount())
+	}
+}
+
 // EstimateCost estimates the cost of the plan.
 func EstimateCost(p Plan) float64 {
 	estimate(p)
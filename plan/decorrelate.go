@@ -0,0 +1,329 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// decorrelate rewrites the applies in p to joins, so their inner plans are executed once instead of for every
+// outer row, and returns the new plan of p. The correlated conditions of the inner plan become the join conditions.
+// The semi apply of [NOT] EXISTS and [NOT] IN subquery becomes semi join or anti semi join, the apply of scalar
+// subquery with aggregation becomes left outer join with the aggregation grouped by the correlated columns.
+// The applies which can't be rewritten are kept.
+func (b *planBuilder) decorrelate(p Plan) (Plan, error) {
+	for _, child := range p.GetChildren() {
+		newChild, err := b.decorrelate(child)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if newChild != child {
+			if err = p.ReplaceChild(child, newChild); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+	}
+	ap, ok := p.(*Apply)
+	if !ok {
+		return p, nil
+	}
+	innerPlan, err := b.decorrelate(ap.InnerPlan)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ap.InnerPlan = innerPlan
+	var root Plan
+	if ap.Checker != nil {
+		root, err = b.decorrelateSemiApply(ap)
+	} else {
+		root, err = b.decorrelateScalarApply(ap)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if root == nil {
+		return ap, nil
+	}
+	for _, parent := range ap.GetParents() {
+		root.AddParent(parent)
+	}
+	return root, nil
+}
+
+// decorrelateSemiApply rewrites the semi apply to semi join, it returns nil if the inner plan can't be decorrelated.
+func (b *planBuilder) decorrelateSemiApply(ap *Apply) (Plan, error) {
+	outer, inner := ap.GetChildByIndex(0), ap.InnerPlan
+	if _, ok := correlatedConditions(inner); !ok {
+		return nil, nil
+	}
+	cond := ap.Checker.Condition
+	if cond != nil && !hashKeyCompatible(cond.Args[0], cond.Args[1]) {
+		return nil, nil
+	}
+	join := &Join{JoinType: SemiJoin, WithAux: ap.Checker.WithAux, hints: b.tableHints}
+	if ap.Checker.Anti {
+		join.JoinType = AntiSemiJoin
+	}
+	if cond != nil {
+		join.NullAwareConditions = []*expression.ScalarFunction{cond}
+	}
+	join.EqualConditions, join.LeftConditions, join.RightConditions, join.OtherConditions =
+		extractOnCondition(pullUpCorrelatedConditions(inner), outer, inner)
+	// The equal conditions of the values which aren't hashed in the same way are evaluated with the joined rows.
+	eqConds := join.EqualConditions[:0]
+	for _, eqCond := range join.EqualConditions {
+		if hashKeyCompatible(eqCond.Args[0], eqCond.Args[1]) {
+			eqConds = append(eqConds, eqCond)
+		} else {
+			join.OtherConditions = append(join.OtherConditions, eqCond)
+		}
+	}
+	join.EqualConditions = eqConds
+	join.SetSchema(ap.GetSchema())
+	join.correlated = outer.IsCorrelated()
+	if err := outer.ReplaceParent(ap, join); err != nil {
+		return nil, errors.Trace(err)
+	}
+	join.AddChild(outer)
+	addChild(join, inner)
+	return join, nil
+}
+
+// decorrelateScalarApply rewrites the apply of scalar subquery with aggregation to left outer join, it returns nil
+// if the subquery isn't in the form of
+// MaxOneRow->Projection->Aggregation->(Selection or Projection)*->an uncorrelated plan,
+// or the correlated conditions aren't the equal conditions of the inner columns and the outer values.
+// e.g. select t.a, (select count(*) from s where s.a = t.a) from t is rewritten to
+// select t.a, ifnull(cnt, 0) from t left join (select s.a, count(*) cnt from s group by s.a) s on s.a = t.a.
+func (b *planBuilder) decorrelateScalarApply(ap *Apply) (Plan, error) {
+	maxOneRow, ok := ap.InnerPlan.(*MaxOneRow)
+	if !ok {
+		return nil, nil
+	}
+	proj, ok := maxOneRow.GetChildByIndex(0).(*Projection)
+	if !ok {
+		return nil, nil
+	}
+	agg, ok := proj.GetChildByIndex(0).(*Aggregation)
+	if !ok || len(agg.GroupByItems) > 0 {
+		return nil, nil
+	}
+	for _, expr := range proj.Exprs {
+		if _, outerCols := extractColumn(expr, nil, nil); len(outerCols) > 0 {
+			return nil, nil
+		}
+	}
+	for _, aggFunc := range agg.AggFuncs {
+		for _, arg := range aggFunc.GetArgs() {
+			if _, outerCols := extractColumn(arg, nil, nil); len(outerCols) > 0 {
+				return nil, nil
+			}
+		}
+	}
+	conds, ok := correlatedConditions(agg.GetChildByIndex(0))
+	if !ok || len(conds) == 0 {
+		return nil, nil
+	}
+	innerCols := make([]*expression.Column, 0, len(conds))
+	outerValues := make([]expression.Expression, 0, len(conds))
+	for _, cond := range conds {
+		innerCol, outerValue := splitCorrelatedEqual(cond)
+		if innerCol == nil || !hashKeyCompatible(innerCol, outerValue) {
+			return nil, nil
+		}
+		innerCols = append(innerCols, innerCol)
+		outerValues = append(outerValues, decorrelateExpr(outerValue))
+	}
+	pullUpCorrelatedConditions(agg.GetChildByIndex(0))
+
+	// The aggregation is grouped by the inner columns of the correlated conditions, whose first rows are joined.
+	outer := ap.GetChildByIndex(0)
+	joinConds := make([]expression.Expression, 0, len(conds))
+	for i, innerCol := range innerCols {
+		agg.GroupByItems = append(agg.GroupByItems, innerCol.DeepCopy())
+		agg.AggFuncs = append(agg.AggFuncs, expression.NewAggFunction(ast.AggFuncFirstRow,
+			[]expression.Expression{innerCol.DeepCopy()}, false))
+		col := &expression.Column{
+			FromID:  agg.id,
+			ColName: model.NewCIStr(fmt.Sprintf("%s_col_%d", agg.id, len(agg.schema))),
+			RetType: innerCol.GetType(),
+		}
+		agg.schema = append(agg.schema, col)
+		eq := expression.NewFunction(model.NewCIStr(ast.EQ), []expression.Expression{outerValues[i], col.DeepCopy()})
+		eq.RetType = types.NewFieldType(mysql.TypeLonglong)
+		joinConds = append(joinConds, eq)
+	}
+	agg.correlated = false
+	join := &Join{JoinType: LeftOuterJoin, hints: b.tableHints}
+	join.SetSchema(append(outer.GetSchema().DeepCopy(), agg.GetSchema().DeepCopy()...))
+	join.EqualConditions, join.LeftConditions, join.RightConditions, join.OtherConditions =
+		extractOnCondition(joinConds, outer, agg)
+	join.correlated = outer.IsCorrelated()
+	if err := outer.ReplaceParent(ap, join); err != nil {
+		return nil, errors.Trace(err)
+	}
+	join.AddChild(outer)
+	if err := agg.ReplaceParent(proj, join); err != nil {
+		return nil, errors.Trace(err)
+	}
+	join.AddChild(agg)
+
+	// The projection outputs the columns of the apply. COUNT is 0 instead of NULL for the outer rows which
+	// don't match any group, the other aggregate functions are NULL for empty input.
+	exprs := expression.Schema2Exprs(outer.GetSchema().DeepCopy())
+	for _, expr := range proj.Exprs {
+		exprs = append(exprs, replaceCountColumns(expr, agg))
+	}
+	proj.Exprs = exprs
+	proj.SetSchema(ap.GetSchema())
+	proj.correlated = join.IsCorrelated()
+	proj.parents = nil
+	proj.children = nil
+	addChild(proj, join)
+	return proj, nil
+}
+
+// replaceCountColumns replaces the columns of the COUNT functions of the aggregation in expr with IFNULL(col, 0).
+func replaceCountColumns(expr expression.Expression, agg *Aggregation) expression.Expression {
+	switch x := expr.(type) {
+	case *expression.Column:
+		idx := agg.GetSchema().GetIndex(x)
+		if idx == -1 || agg.AggFuncs[idx].GetName() != ast.AggFuncCount {
+			return x
+		}
+		zero := &expression.Constant{Value: types.NewIntDatum(0), RetType: types.NewFieldType(mysql.TypeLonglong)}
+		ifNull := expression.NewFunction(model.NewCIStr("ifnull"), []expression.Expression{x, zero})
+		ifNull.RetType = x.GetType()
+		if ifNull.RetType == nil {
+			ifNull.RetType = types.NewFieldType(mysql.TypeLonglong)
+		}
+		return ifNull
+	case *expression.ScalarFunction:
+		for i, arg := range x.Args {
+			x.Args[i] = replaceCountColumns(arg, agg)
+		}
+	}
+	return expr
+}
+
+// correlatedConditions returns the correlated conditions of the selections on the top of p. It returns false if
+// there are correlated expressions elsewhere, which means p can't be decorrelated.
+func correlatedConditions(p Plan) ([]expression.Expression, bool) {
+	switch x := p.(type) {
+	case *Selection:
+		conds, ok := correlatedConditions(x.GetChildByIndex(0))
+		for _, cond := range x.Conditions {
+			if _, outerCols := extractColumn(cond, nil, nil); len(outerCols) > 0 {
+				conds = append(conds, cond)
+			}
+		}
+		return conds, ok
+	case *Projection:
+		for _, expr := range x.Exprs {
+			if _, outerCols := extractColumn(expr, nil, nil); len(outerCols) > 0 {
+				return nil, false
+			}
+		}
+		return correlatedConditions(x.GetChildByIndex(0))
+	}
+	return nil, !p.IsCorrelated()
+}
+
+// pullUpCorrelatedConditions removes the correlated conditions from the selections on the top of p, and returns
+// them with the correlated columns turned into the columns of the outer plan. The projections on the way output
+// the inner columns which the conditions use.
+func pullUpCorrelatedConditions(p Plan) []expression.Expression {
+	var conds []expression.Expression
+	switch x := p.(type) {
+	case *Selection:
+		conds = pullUpCorrelatedConditions(x.GetChildByIndex(0))
+		rest := x.Conditions[:0]
+		for _, cond := range x.Conditions {
+			if _, outerCols := extractColumn(cond, nil, nil); len(outerCols) > 0 {
+				conds = append(conds, decorrelateExpr(cond))
+			} else {
+				rest = append(rest, cond)
+			}
+		}
+		x.Conditions = rest
+		x.schema = x.GetChildByIndex(0).GetSchema().DeepCopy()
+		x.correlated = false
+	case *Projection:
+		child := x.GetChildByIndex(0)
+		conds = pullUpCorrelatedConditions(child)
+		for _, cond := range conds {
+			cols, _ := extractColumn(cond, nil, nil)
+			for _, col := range cols {
+				if child.GetSchema().GetIndex(col) != -1 && x.schema.GetIndex(col) == -1 {
+					x.Exprs = append(x.Exprs, col.DeepCopy())
+					x.schema = append(x.schema, col.DeepCopy().(*expression.Column))
+				}
+			}
+		}
+		x.correlated = false
+	}
+	return conds
+}
+
+// decorrelateExpr returns a copy of expr whose correlated columns are turned into the normal columns.
+func decorrelateExpr(expr expression.Expression) expression.Expression {
+	switch x := expr.(type) {
+	case *expression.Column:
+		col := x.DeepCopy().(*expression.Column)
+		col.Correlated = false
+		return col
+	case *expression.ScalarFunction:
+		newFunc := &expression.ScalarFunction{FuncName: x.FuncName, Function: x.Function, RetType: x.RetType}
+		for _, arg := range x.Args {
+			newFunc.Args = append(newFunc.Args, decorrelateExpr(arg))
+		}
+		return newFunc
+	}
+	return expr.DeepCopy()
+}
+
+// splitCorrelatedEqual splits the correlated condition in the form of inner column = outer value, the returned
+// inner column is nil if the condition isn't in the form.
+func splitCorrelatedEqual(cond expression.Expression) (*expression.Column, expression.Expression) {
+	eq, ok := cond.(*expression.ScalarFunction)
+	if !ok || eq.FuncName.L != ast.EQ {
+		return nil, nil
+	}
+	for i, arg := range eq.Args {
+		col, ok := arg.(*expression.Column)
+		if !ok || col.Correlated {
+			continue
+		}
+		other := eq.Args[1-i]
+		cols, outerCols := extractColumn(other, nil, nil)
+		if len(cols) == 0 && len(outerCols) > 0 {
+			return col, other
+		}
+	}
+	return nil, nil
+}
+
+// hashKeyCompatible checks whether the equal values of the two expressions are encoded to the same hash key, so
+// they can be the keys of hash join.
+func hashKeyCompatible(expr1, expr2 expression.Expression) bool {
+	tp1, tp2 := expr1.GetType(), expr2.GetType()
+	return tp1 != nil && tp2 != nil && joinKeyCompatible(tp1, tp2)
+}
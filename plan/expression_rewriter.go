@@ -8,6 +8,7 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser/opcode"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -16,11 +17,24 @@ import (
 var EvalSubquery func(p Plan, is infoschema.InfoSchema, ctx context.Context) ([]types.Datum, error)
 
 func (b *planBuilder) rewrite(expr ast.ExprNode, p Plan, aggMapper map[*ast.AggregateFuncExpr]int) (newExpr expression.Expression, newPlan Plan, correlated bool, err error) {
+	return b.rewriteExpr(expr, p, aggMapper, true)
+}
+
+// rewriteExpr rewrites the expression on the plan. If it isn't asScalar, the expression is a condition of WHERE,
+// the [NOT] EXISTS or [NOT] IN subquery condition filters the rows of the plan by a semi apply, and the returned
+// expression is nil.
+func (b *planBuilder) rewriteExpr(expr ast.ExprNode, p Plan, aggMapper map[*ast.AggregateFuncExpr]int, asScalar bool) (
+	newExpr expression.Expression, newPlan Plan, correlated bool, err error) {
 	er := &expressionRewriter{p: p, aggrMap: aggMapper, schema: p.GetSchema(), b: b}
-	expr.Accept(er)
+	if asScalar || !er.filterBySubquery(expr) {
+		expr.Accept(er)
+	}
 	if er.err != nil {
 		return nil, nil, false, errors.Trace(er.err)
 	}
+	if !asScalar && len(er.ctxStack) == 0 {
+		return nil, er.p, er.correlated, nil
+	}
 	if len(er.ctxStack) != 1 {
 		return nil, nil, false, errors.Errorf("context len %v is invalid", len(er.ctxStack))
 	}
@@ -53,10 +67,125 @@ func (er *expressionRewriter) buildSubquery(subq *ast.SubqueryExpr) (Plan, expre
 		er.err = errors.Trace(er.b.err)
 		return nil, nil
 	}
-	er.b.err = Refine(np)
 	return np, outerSchema
 }
 
+// evalSubquery evaluates the incorrelated subquery, the plan is refined first because it isn't optimized.
+func (er *expressionRewriter) evalSubquery(np Plan) expression.Expression {
+	if err := Refine(np); err != nil {
+		er.err = errors.Trace(err)
+		return nil
+	}
+	d, err := EvalSubquery(np, er.b.is, er.b.ctx)
+	if err != nil {
+		er.err = errors.Trace(err)
+		return nil
+	}
+	return &expression.Constant{Value: d[0], RetType: np.GetSchema()[0].GetType()}
+}
+
+// filterBySubquery filters the rows of the plan if the condition is a [NOT] EXISTS or [NOT] IN subquery,
+// it returns false if the condition isn't one of them.
+func (er *expressionRewriter) filterBySubquery(cond ast.ExprNode) bool {
+	not := false
+	for {
+		switch v := cond.(type) {
+		case *ast.ParenthesesExpr:
+			cond = v.Expr
+			continue
+		case *ast.UnaryOperationExpr:
+			if v.Op == opcode.Not {
+				not = !not
+				cond = v.V
+				continue
+			}
+		case *ast.ExistsSubqueryExpr:
+			er.handleExistsSubquery(v, not, false)
+			return true
+		case *ast.PatternInExpr:
+			if v.Sel != nil {
+				er.handleInSubquery(v, not != v.Not, false)
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// handleExistsSubquery rewrites the [NOT] EXISTS subquery. The correlated subquery is rewritten to a semi apply,
+// which filters the rows if it isn't asScalar, or appends an aux column of the result.
+func (er *expressionRewriter) handleExistsSubquery(v *ast.ExistsSubqueryExpr, not, asScalar bool) {
+	subq, ok := v.Sel.(*ast.SubqueryExpr)
+	if !ok {
+		er.err = errors.Errorf("Unknown exists type %T.", v.Sel)
+		return
+	}
+	np, outerSchema := er.buildSubquery(subq)
+	if er.err != nil {
+		return
+	}
+	if np.IsCorrelated() {
+		er.p = er.b.buildSemiApply(er.p, np, outerSchema, nil, !asScalar && not, asScalar)
+		if asScalar {
+			er.pushAuxColumn(not)
+		}
+		return
+	}
+	result := er.evalSubquery(er.b.buildExists(np))
+	if er.err != nil {
+		return
+	}
+	if not {
+		result = er.newFunction(ast.UnaryNot, types.NewFieldType(mysql.TypeLonglong), result)
+	}
+	er.ctxStack = append(er.ctxStack, result)
+}
+
+// handleInSubquery rewrites the [NOT] IN subquery to a semi apply, whose condition is the equal condition of the
+// value and the column of the subquery.
+func (er *expressionRewriter) handleInSubquery(v *ast.PatternInExpr, not, asScalar bool) {
+	v.Expr.Accept(er)
+	if er.err != nil {
+		return
+	}
+	lexpr := er.ctxStack[len(er.ctxStack)-1]
+	er.ctxStack = er.ctxStack[:len(er.ctxStack)-1]
+	subq, ok := v.Sel.(*ast.SubqueryExpr)
+	if !ok {
+		er.err = errors.Errorf("Unknown compare type %T.", v.Sel)
+		return
+	}
+	np, outerSchema := er.buildSubquery(subq)
+	if er.err != nil {
+		return
+	}
+	if len(np.GetSchema()) != 1 {
+		er.err = ErrOneColumn.Gen("Operand should contain 1 column(s)")
+		return
+	}
+	cond := er.newFunction(ast.EQ, types.NewFieldType(mysql.TypeLonglong), lexpr, np.GetSchema()[0].DeepCopy())
+	er.p = er.b.buildSemiApply(er.p, np, outerSchema, cond, !asScalar && not, asScalar)
+	if asScalar {
+		er.pushAuxColumn(not)
+	}
+}
+
+// pushAuxColumn pushes the aux column of the semi apply, which is negated for NOT EXISTS and NOT IN.
+func (er *expressionRewriter) pushAuxColumn(not bool) {
+	schema := er.p.GetSchema()
+	var aux expression.Expression = schema[len(schema)-1]
+	if not {
+		aux = er.newFunction(ast.UnaryNot, types.NewFieldType(mysql.TypeLonglong), aux)
+	}
+	er.ctxStack = append(er.ctxStack, aux)
+}
+
+func (er *expressionRewriter) newFunction(funcName string, retType *types.FieldType, args ...expression.Expression) *expression.ScalarFunction {
+	function := expression.NewFunction(model.NewCIStr(funcName), args)
+	function.RetType = retType
+	return function
+}
+
 // Enter implements Visitor interface.
 func (er *expressionRewriter) Enter(inNode ast.Node) (retNode ast.Node, skipChildren bool) {
 	switch v := inNode.(type) {
@@ -72,28 +201,13 @@ func (er *expressionRewriter) Enter(inNode ast.Node) (retNode ast.Node, skipChil
 		er.ctxStack = append(er.ctxStack, er.schema[index])
 		return inNode, true
 	case *ast.ExistsSubqueryExpr:
-		if subq, ok := v.Sel.(*ast.SubqueryExpr); ok {
-			np, outerSchema := er.buildSubquery(subq)
-			if er.err != nil {
-				return retNode, true
-			}
-			np = er.b.buildExists(np)
-			if np.IsCorrelated() {
-				ap := er.b.buildApply(er.p, np, outerSchema)
-				er.p = ap
-				er.ctxStack = append(er.ctxStack, ap.GetSchema()[len(ap.GetSchema())-1])
-			} else {
-				d, err := EvalSubquery(np, er.b.is, er.b.ctx)
-				if err != nil {
-					er.err = errors.Trace(err)
-					return retNode, true
-				}
-				er.ctxStack = append(er.ctxStack, &expression.Constant{Value: d[0], RetType: np.GetSchema()[0].GetType()})
-			}
+		er.handleExistsSubquery(v, false, true)
+		return inNode, true
+	case *ast.PatternInExpr:
+		if v.Sel != nil {
+			er.handleInSubquery(v, v.Not, true)
 			return inNode, true
 		}
-		er.err = errors.Errorf("Unknown exists type %T.", v.Sel)
-		return inNode, true
 	case *ast.SubqueryExpr:
 		np, outerSchema := er.buildSubquery(v)
 		if er.err != nil {
@@ -105,12 +219,11 @@ func (er *expressionRewriter) Enter(inNode ast.Node) (retNode ast.Node, skipChil
 			er.p = ap
 			er.ctxStack = append(er.ctxStack, ap.GetSchema()[len(ap.GetSchema())-1])
 		} else {
-			d, err := EvalSubquery(np, er.b.is, er.b.ctx)
-			if err != nil {
-				er.err = errors.Trace(err)
+			result := er.evalSubquery(np)
+			if er.err != nil {
 				return retNode, true
 			}
-			er.ctxStack = append(er.ctxStack, &expression.Constant{Value: d[0], RetType: np.GetSchema()[0].GetType()})
+			er.ctxStack = append(er.ctxStack, result)
 		}
 		return inNode, true
 	}
@@ -181,7 +294,11 @@ func (er *expressionRewriter) Leave(inNode ast.Node) (retNode ast.Node, ok bool)
 		}
 		function.Function = f.F
 		er.ctxStack = er.ctxStack[:length-1]
-		er.ctxStack = append(er.ctxStack, function)
+		if v.Not {
+			er.ctxStack = append(er.ctxStack, er.newFunction(ast.UnaryNot, v.Type, function))
+		} else {
+			er.ctxStack = append(er.ctxStack, function)
+		}
 	case *ast.BinaryOperationExpr:
 		function := &expression.ScalarFunction{Args: []expression.Expression{er.ctxStack[length-2], er.ctxStack[length-1]}, RetType: v.Type}
 		funcName, ok := opcode.Ops[v.Op]
@@ -218,7 +335,26 @@ func (er *expressionRewriter) Leave(inNode ast.Node) (retNode ast.Node, ok bool)
 		function.Function = f.F
 		er.ctxStack = er.ctxStack[:length-1]
 		er.ctxStack = append(er.ctxStack, function)
-
+	case *ast.PatternInExpr:
+		if v.Sel != nil {
+			break
+		}
+		// The IN list is rewritten to the OR of the equal conditions.
+		lexpr := er.ctxStack[length-len(v.List)-1]
+		var function expression.Expression
+		for i := length - len(v.List); i < length; i++ {
+			eq := er.newFunction(ast.EQ, v.Type, lexpr, er.ctxStack[i])
+			if function == nil {
+				function = eq
+			} else {
+				function = er.newFunction(ast.OrOr, v.Type, function, eq)
+			}
+		}
+		if v.Not {
+			function = er.newFunction(ast.UnaryNot, v.Type, function)
+		}
+		er.ctxStack = er.ctxStack[:length-len(v.List)-1]
+		er.ctxStack = append(er.ctxStack, function)
 	default:
 		er.err = errors.Errorf("UnknownType: %T", v)
 	}
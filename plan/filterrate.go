@@ -15,6 +15,7 @@ package plan
 
 import (
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/parser/opcode"
 )
 
@@ -113,3 +114,30 @@ func guesstimatePatternLike(expr *ast.PatternLikeExpr) float64 {
 	}
 	return rateLike
 }
+
+// guesstimateExprFilterRate guesstimates the filter rate for an expression of the new planner.
+func guesstimateExprFilterRate(expr expression.Expression) float64 {
+	f, ok := expr.(*expression.ScalarFunction)
+	if !ok {
+		return rateFull
+	}
+	switch f.FuncName.L {
+	case ast.AndAnd:
+		return guesstimateExprFilterRate(f.Args[0]) * guesstimateExprFilterRate(f.Args[1])
+	case ast.OrOr:
+		rateL := guesstimateExprFilterRate(f.Args[0])
+		rateR := guesstimateExprFilterRate(f.Args[1])
+		return rateL + rateR - rateL*rateR
+	case ast.EQ, ast.NullEQ:
+		return rateEqual
+	case ast.GT, ast.GE, ast.LT, ast.LE:
+		return rateGreaterOrLess
+	case ast.NE:
+		return rateNotEqual
+	case ast.IsNull:
+		return rateIsNull
+	case ast.UnaryNot:
+		return rateFull - guesstimateExprFilterRate(f.Args[0])
+	}
+	return rateFull
+}
@@ -0,0 +1,274 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx/variable"
+)
+
+// The optimizer hints other than the join algorithm hints.
+const (
+	// JoinFixedOrder is the hint to join the tables in the order of the FROM clause.
+	JoinFixedOrder = "join_fixed_order"
+	// JoinOrder is the hint to join the tables in the hint first, in the order of the hint.
+	JoinOrder = "join_order"
+	// AggToCop is the hint to push the aggregation down to the coprocessor.
+	AggToCop = "agg_to_cop"
+	// NoAggToCop is the hint not to push the aggregation down to the coprocessor.
+	NoAggToCop = "no_agg_to_cop"
+
+	// maxExecutionTime is the hint to set the timeout of the SELECT statement, it's handled by the session.
+	maxExecutionTime = "max_execution_time"
+)
+
+// hintTable is a table of a join hint, the table is empty for the JOIN_ORDER hint which is applied as a whole.
+type hintTable struct {
+	hint  *ast.TableOptimizerHint
+	table string
+}
+
+func (h hintTable) String() string {
+	if h.table == "" {
+		return hintString(h.hint)
+	}
+	return fmt.Sprintf("%s(%s)", strings.ToUpper(h.hint.HintName.L), h.table)
+}
+
+func hintString(hint *ast.TableOptimizerHint) string {
+	if hint.HintName.L == maxExecutionTime {
+		return fmt.Sprintf("%s(%d)", strings.ToUpper(hint.HintName.L), hint.MaxExecutionTime)
+	}
+	tables := make([]string, 0, len(hint.Tables))
+	for _, tbl := range hint.Tables {
+		tables = append(tables, tbl.O)
+	}
+	return fmt.Sprintf("%s(%s)", strings.ToUpper(hint.HintName.L), strings.Join(tables, ", "))
+}
+
+// appendWarning appends a warning to the statement being built, the warnings are shown by SHOW WARNINGS.
+func (b *planBuilder) appendWarning(err error) {
+	if b.ctx == nil {
+		return
+	}
+	if vars := variable.GetSessionVars(b.ctx); vars != nil {
+		vars.AppendWarning(err)
+	}
+}
+
+// checkHints checks the optimizer hints of the SELECT, UPDATE or DELETE statement, and returns the usable ones.
+// The hints which are unknown, unresolved, inapplicable or conflicting are ignored with warnings.
+// The join hints are only usable by the new planner, UPDATE and DELETE are always built by the old one.
+func (b *planBuilder) checkHints(stmt ast.Node, hasAgg bool) []*ast.TableOptimizerHint {
+	var hints []*ast.TableOptimizerHint
+	var from *ast.TableRefsClause
+	isSelect := false
+	switch x := stmt.(type) {
+	case *ast.SelectStmt:
+		hints, from, isSelect = x.TableHints, x.From, true
+	case *ast.UpdateStmt:
+		hints, from = x.TableHints, x.TableRefs
+	case *ast.DeleteStmt:
+		hints, from = x.TableHints, x.TableRefs
+	}
+	if len(hints) == 0 {
+		return nil
+	}
+	tables := make(map[string]bool)
+	if from != nil {
+		collectTableNames(from.TableRefs, tables)
+	}
+	var usable []*ast.TableOptimizerHint
+	var hasJoinOrder, hasAggHint bool
+	// algorithmTables are the tables in the join algorithm hints, a table can only be in one of them.
+	algorithmTables := make(map[string]bool)
+	for _, hint := range hints {
+		switch hint.HintName.L {
+		case maxExecutionTime:
+			if !isSelect {
+				b.appendWarning(ErrInapplicableHint.Gen("Optimizer hint %s is inapplicable", hintString(hint)))
+				continue
+			}
+		case TiDBIndexJoin, TiDBMergeJoin, TiDBHashJoin:
+			if !isSelect || !UseNewPlanner {
+				b.appendWarning(ErrInapplicableHint.Gen("Optimizer hint %s is inapplicable", hintString(hint)))
+				continue
+			}
+			hint = b.resolveHintTables(hint, tables)
+			resolved := hint.Tables[:0]
+			for _, tbl := range hint.Tables {
+				if algorithmTables[tbl.L] {
+					b.appendWarning(ErrConflictingHint.Gen("Hint %s is ignored as conflicting/duplicated",
+						hintTable{hint: hint, table: tbl.O}))
+					continue
+				}
+				algorithmTables[tbl.L] = true
+				resolved = append(resolved, tbl)
+			}
+			hint.Tables = resolved
+			if len(hint.Tables) == 0 {
+				continue
+			}
+			for _, tbl := range hint.Tables {
+				b.joinHints = append(b.joinHints, hintTable{hint: hint, table: tbl.L})
+			}
+		case JoinOrder, JoinFixedOrder:
+			if !isSelect || !UseNewPlanner {
+				b.appendWarning(ErrInapplicableHint.Gen("Optimizer hint %s is inapplicable", hintString(hint)))
+				continue
+			}
+			if hasJoinOrder {
+				b.appendWarning(ErrConflictingHint.Gen("Hint %s is ignored as conflicting/duplicated", hintString(hint)))
+				continue
+			}
+			if hint.HintName.L == JoinOrder {
+				hint = b.resolveHintTables(hint, tables)
+				if len(hint.Tables) == 0 {
+					continue
+				}
+				b.joinHints = append(b.joinHints, hintTable{hint: hint})
+			}
+			hasJoinOrder = true
+		case AggToCop, NoAggToCop:
+			if !hasAgg {
+				b.appendWarning(ErrInapplicableHint.Gen("Optimizer hint %s is inapplicable", hintString(hint)))
+				continue
+			}
+			if hasAggHint {
+				b.appendWarning(ErrConflictingHint.Gen("Hint %s is ignored as conflicting/duplicated", hintString(hint)))
+				continue
+			}
+			hasAggHint = true
+		default:
+			b.appendWarning(ErrUnknownHint.Gen("Optimizer hint %s is not supported", hintString(hint)))
+			continue
+		}
+		usable = append(usable, hint)
+	}
+	return usable
+}
+
+// resolveHintTables returns a copy of the hint whose tables are in the statement, the unresolved tables are
+// ignored with warnings. The hint in the statement isn't changed because a prepared statement is planned repeatedly.
+func (b *planBuilder) resolveHintTables(hint *ast.TableOptimizerHint, tables map[string]bool) *ast.TableOptimizerHint {
+	resolved := *hint
+	resolved.Tables = make([]model.CIStr, 0, len(hint.Tables))
+	seen := make(map[string]bool)
+	for _, tbl := range hint.Tables {
+		if !tables[tbl.L] {
+			b.appendWarning(ErrUnresolvedHintName.Gen("Unresolved name '%s' for %s hint", tbl.O,
+				strings.ToUpper(hint.HintName.L)))
+			continue
+		}
+		if !seen[tbl.L] {
+			seen[tbl.L] = true
+			resolved.Tables = append(resolved.Tables, tbl)
+		}
+	}
+	return &resolved
+}
+
+// collectTableNames collects the names of the tables in the FROM clause, the name of a table is its alias if it has one.
+func collectTableNames(node ast.ResultSetNode, names map[string]bool) {
+	switch x := node.(type) {
+	case *ast.Join:
+		collectTableNames(x.Left, names)
+		if x.Right != nil {
+			collectTableNames(x.Right, names)
+		}
+	case *ast.TableSource:
+		if x.AsName.L != "" {
+			names[x.AsName.L] = true
+		} else if tn, ok := x.Source.(*ast.TableName); ok {
+			names[tn.Name.L] = true
+		}
+	}
+}
+
+// aggPushDownHint returns the aggregation hint in the usable hints, it's empty if there isn't one.
+func aggPushDownHint(hints []*ast.TableOptimizerHint) string {
+	for _, hint := range hints {
+		if hint.HintName.L == AggToCop || hint.HintName.L == NoAggToCop {
+			return hint.HintName.L
+		}
+	}
+	return ""
+}
+
+// hasHint checks whether there is the hint in the usable hints.
+func hasHint(hints []*ast.TableOptimizerHint, name string) bool {
+	for _, hint := range hints {
+		if hint.HintName.L == name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyHint records that the join hint of the table is applied.
+func (b *planBuilder) applyHint(hint *ast.TableOptimizerHint, table string) {
+	if b.appliedJoinHints == nil {
+		b.appliedJoinHints = make(map[hintTable]bool)
+	}
+	b.appliedJoinHints[hintTable{hint: hint, table: table}] = true
+}
+
+// warnUnappliedHints reports the join hints which aren't applied after the joins are optimized, for example,
+// the table of an index join hint can't be looked up by the join keys.
+func (b *planBuilder) warnUnappliedHints() {
+	for _, ht := range b.joinHints {
+		if !b.appliedJoinHints[ht] {
+			b.appendWarning(ErrInapplicableHint.Gen("Optimizer hint %s is inapplicable", ht))
+		}
+	}
+}
+
+// chooseHintedIndices chooses the indices to read the tables whose index hints exclude the table scan, the
+// index whose first column is compared with constants is preferred. It runs before the join algorithms are
+// chosen, index join and merge join choose the indices of their tables by themselves.
+func chooseHintedIndices(p Plan) {
+	var scan *NewTableScan
+	var conditions []expression.Expression
+	switch x := p.(type) {
+	case *Apply:
+		chooseHintedIndices(x.InnerPlan)
+	case *Selection:
+		scan, _ = x.GetChildByIndex(0).(*NewTableScan)
+		conditions = x.Conditions
+	case *NewTableScan:
+		scan = x
+	}
+	if scan != nil && scan.indexForced && scan.Index == nil {
+		for _, idx := range scan.indices {
+			if idx.State != model.StatePublic {
+				continue
+			}
+			if scan.Index == nil {
+				scan.Index = idx
+			}
+			if len(indexAccessConditions(scan, idx, conditions)) > 0 {
+				scan.Index = idx
+				break
+			}
+		}
+	}
+	for _, child := range p.GetChildren() {
+		chooseHintedIndices(child)
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"math"
+
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// The join algorithm hints.
+const (
+	// TiDBIndexJoin is the hint to use index join, the tables in the hint are used as the inner tables.
+	TiDBIndexJoin = "tidb_inlj"
+	// TiDBMergeJoin is the hint to use merge join for the tables in the hint.
+	TiDBMergeJoin = "tidb_smj"
+	// TiDBHashJoin is the hint to use hash join for the tables in the hint.
+	TiDBHashJoin = "tidb_hj"
+)
+
+// The cost factors of the join algorithms, the cost of reading a row sequentially is 1.
+const (
+	// hashBuildFactor is the cost of putting a row into the hash table.
+	hashBuildFactor = 1.0
+	// handleLookupFactor is the cost of looking up a row by the handle.
+	handleLookupFactor = 2.0
+	// indexLookupFactor is the cost of looking up a row by an index, both the index and the row are read.
+	indexLookupFactor = 4.0
+	// indexOrderFactor is the cost of reading a row in the order of an index.
+	indexOrderFactor = 3.0
+)
+
+// joinAlgorithmCandidate is a possible algorithm of a join.
+type joinAlgorithmCandidate struct {
+	algorithm JoinAlgorithm
+	cost      float64
+	// keys are the equal conditions used as the keys of index join or merge join.
+	keys []*expression.ScalarFunction
+	// innerChildIdx is the index of the inner child of index join.
+	innerChildIdx int
+	// scans are the tables which the hints of the algorithm are applied to, indices are the indices to read them.
+	scans   []*NewTableScan
+	indices []*model.IndexInfo
+}
+
+// chooseJoinAlgorithms chooses the algorithm of every join in p by cost, the join hints are preferred.
+// It runs after the columns are resolved.
+func (b *planBuilder) chooseJoinAlgorithms(p Plan) {
+	for _, child := range p.GetChildren() {
+		b.chooseJoinAlgorithms(child)
+	}
+	switch x := p.(type) {
+	case *Apply:
+		b.chooseJoinAlgorithms(x.InnerPlan)
+	case *Join:
+		b.chooseJoinAlgorithm(x)
+	}
+}
+
+func (b *planBuilder) chooseJoinAlgorithm(join *Join) {
+	left, right := join.GetChildByIndex(0), join.GetChildByIndex(1)
+	smallRows := b.estimateRowCount(right)
+	if join.JoinType == RightOuterJoin {
+		smallRows = b.estimateRowCount(left)
+	}
+	best := &joinAlgorithmCandidate{
+		algorithm: HashJoin,
+		cost:      b.readCost(left) + b.readCost(right) + smallRows*hashBuildFactor,
+	}
+	for _, child := range []Plan{left, right} {
+		if scan := tableScanOf(child); scan != nil {
+			best.scans = append(best.scans, scan)
+		}
+	}
+	var hinted *joinAlgorithmCandidate
+	if len(join.preferredBy(best)) > 0 {
+		hinted = best
+	}
+	for _, c := range b.joinAlgorithmCandidates(join) {
+		if c.cost < best.cost {
+			best = c
+		}
+		if len(join.preferredBy(c)) > 0 && (hinted == nil || c.cost < hinted.cost) {
+			hinted = c
+		}
+	}
+	if hinted != nil {
+		best = hinted
+		for _, ht := range join.preferredBy(hinted) {
+			b.applyHint(ht.hint, ht.table)
+		}
+	}
+	if best.algorithm == HashJoin {
+		return
+	}
+	join.Algorithm = best.algorithm
+	join.InnerChildIdx = best.innerChildIdx
+	for i, scan := range best.scans {
+		scan.Index = best.indices[i]
+	}
+	// The equal conditions which are not the keys are evaluated with the joined rows.
+	for _, cond := range join.EqualConditions {
+		isKey := false
+		for _, key := range best.keys {
+			if key == cond {
+				isKey = true
+				break
+			}
+		}
+		if !isKey {
+			expr, _ := retrieveColumnsInExpression(cond, join.GetSchema())
+			join.OtherConditions = append(join.OtherConditions, expr)
+		}
+	}
+	join.EqualConditions = best.keys
+}
+
+// preferredBy returns the hint tables which prefer the candidate.
+func (p *Join) preferredBy(c *joinAlgorithmCandidate) []hintTable {
+	var preferred []hintTable
+	for _, hint := range p.hints {
+		switch {
+		case hint.HintName.L == TiDBIndexJoin && c.algorithm == IndexJoin:
+		case hint.HintName.L == TiDBMergeJoin && c.algorithm == MergeJoin:
+		case hint.HintName.L == TiDBHashJoin && c.algorithm == HashJoin:
+		default:
+			continue
+		}
+		for _, tbl := range hint.Tables {
+			for _, scan := range c.scans {
+				if scan.tableName().L == tbl.L {
+					preferred = append(preferred, hintTable{hint: hint, table: tbl.L})
+				}
+			}
+		}
+	}
+	return preferred
+}
+
+// tableName returns the name of the table used in the statement, it's the alias if the table has one.
+func (p *NewTableScan) tableName() model.CIStr {
+	if p.TableAsName != nil && p.TableAsName.L != "" {
+		return *p.TableAsName
+	}
+	return p.Table.Name
+}
+
+func (b *planBuilder) joinAlgorithmCandidates(join *Join) []*joinAlgorithmCandidate {
+	var candidates []*joinAlgorithmCandidate
+	switch join.JoinType {
+	case InnerJoin:
+		candidates = append(candidates, b.indexJoinCandidate(join, 1), b.indexJoinCandidate(join, 0),
+			b.mergeJoinCandidate(join))
+	case LeftOuterJoin:
+		candidates = append(candidates, b.indexJoinCandidate(join, 1))
+	case RightOuterJoin:
+		candidates = append(candidates, b.indexJoinCandidate(join, 0))
+	}
+	ret := candidates[:0]
+	for _, c := range candidates {
+		if c != nil {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// indexJoinCandidate returns the index join candidate whose inner child is the child of innerIdx,
+// the inner child must be a table which can be looked up by the join keys.
+func (b *planBuilder) indexJoinCandidate(join *Join, innerIdx int) *joinAlgorithmCandidate {
+	inner, outer := join.GetChildByIndex(innerIdx), join.GetChildByIndex(1-innerIdx)
+	scan := tableScanOf(inner)
+	if scan == nil || len(join.EqualConditions) == 0 {
+		return nil
+	}
+	outerRows := b.estimateRowCount(outer)
+	// innerKey finds the equal condition whose inner column is col.
+	innerKey := func(col *model.ColumnInfo) *expression.ScalarFunction {
+		for _, cond := range join.EqualConditions {
+			innerCol, outerCol := cond.Args[innerIdx].(*expression.Column), cond.Args[1-innerIdx].(*expression.Column)
+			if columnInfo(scan, innerCol) == col && joinKeyCompatible(innerCol.RetType, outerCol.RetType) {
+				return cond
+			}
+		}
+		return nil
+	}
+
+	var best *joinAlgorithmCandidate
+	if pk := handleColumn(scan.Table); pk != nil {
+		if key := innerKey(pk); key != nil {
+			best = &joinAlgorithmCandidate{
+				algorithm:     IndexJoin,
+				cost:          b.readCost(outer) + outerRows*handleLookupFactor,
+				keys:          []*expression.ScalarFunction{key},
+				innerChildIdx: innerIdx,
+				scans:         []*NewTableScan{scan},
+				indices:       []*model.IndexInfo{nil},
+			}
+		}
+	}
+	for _, idx := range scan.indices {
+		if idx.State != model.StatePublic {
+			continue
+		}
+		var keys []*expression.ScalarFunction
+		for _, idxCol := range idx.Columns {
+			if idxCol.Length != types.UnspecifiedLength {
+				break
+			}
+			key := innerKey(scan.Table.Columns[idxCol.Offset])
+			if key == nil {
+				break
+			}
+			keys = append(keys, key)
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		innerCol := keys[0].Args[innerIdx].(*expression.Column)
+		tableRows := b.estimateRowCount(scan)
+		rowsPerKey := tableRows / b.columnNDV(scan, innerCol, tableRows)
+		for range keys[1:] {
+			rowsPerKey *= rateEqual
+		}
+		cost := b.readCost(outer) + outerRows*math.Max(rowsPerKey, 1)*indexLookupFactor
+		if best == nil || cost < best.cost {
+			best = &joinAlgorithmCandidate{
+				algorithm:     IndexJoin,
+				cost:          cost,
+				keys:          keys,
+				innerChildIdx: innerIdx,
+				scans:         []*NewTableScan{scan},
+				indices:       []*model.IndexInfo{idx},
+			}
+		}
+	}
+	return best
+}
+
+// mergeJoinCandidate returns the merge join candidate, both children must be tables which can be read
+// in the order of a join key.
+func (b *planBuilder) mergeJoinCandidate(join *Join) *joinAlgorithmCandidate {
+	leftScan, rightScan := tableScanOf(join.GetChildByIndex(0)), tableScanOf(join.GetChildByIndex(1))
+	if leftScan == nil || rightScan == nil {
+		return nil
+	}
+	var best *joinAlgorithmCandidate
+	for _, cond := range join.EqualConditions {
+		leftCol, rightCol := cond.Args[0].(*expression.Column), cond.Args[1].(*expression.Column)
+		if !joinKeyCompatible(leftCol.RetType, rightCol.RetType) {
+			continue
+		}
+		leftIdx, leftFactor, ok := orderedBy(leftScan, leftCol)
+		if !ok {
+			continue
+		}
+		rightIdx, rightFactor, ok := orderedBy(rightScan, rightCol)
+		if !ok {
+			continue
+		}
+		cost := b.estimateRowCount(leftScan)*leftFactor + b.estimateRowCount(rightScan)*rightFactor
+		if best == nil || cost < best.cost {
+			best = &joinAlgorithmCandidate{
+				algorithm: MergeJoin,
+				cost:      cost,
+				keys:      []*expression.ScalarFunction{cond},
+				scans:     []*NewTableScan{leftScan, rightScan},
+				indices:   []*model.IndexInfo{leftIdx, rightIdx},
+			}
+		}
+	}
+	return best
+}
+
+// orderedBy finds the way to read the table in the order of the column, it returns the index to read
+// the table and the cost factor. A nil index means the table is read by the handle.
+func orderedBy(scan *NewTableScan, col *expression.Column) (*model.IndexInfo, float64, bool) {
+	colInfo := columnInfo(scan, col)
+	if colInfo == nil {
+		return nil, 0, false
+	}
+	if handleColumn(scan.Table) == colInfo {
+		return nil, 1, true
+	}
+	for _, idx := range scan.indices {
+		if idx.State == model.StatePublic && idx.Columns[0].Offset == colInfo.Offset &&
+			idx.Columns[0].Length == types.UnspecifiedLength {
+			return idx, indexOrderFactor, true
+		}
+	}
+	return nil, 0, false
+}
+
+// readCost returns the cost of reading all the rows of the plan.
+func (b *planBuilder) readCost(p Plan) float64 {
+	if scan := tableScanOf(p); scan != nil {
+		return b.estimateRowCount(scan)
+	}
+	return b.estimateRowCount(p)
+}
+
+// tableScanOf returns the table scan of the plan which is a table scan with filters, or nil.
+func tableScanOf(p Plan) *NewTableScan {
+	for {
+		switch x := p.(type) {
+		case *Selection:
+			p = x.GetChildByIndex(0)
+		case *NewTableScan:
+			return x
+		default:
+			return nil
+		}
+	}
+}
+
+// columnInfo returns the column info of the column of the table scan, or nil.
+func columnInfo(scan *NewTableScan, col *expression.Column) *model.ColumnInfo {
+	idx := scan.GetSchema().GetIndex(col)
+	if idx == -1 {
+		return nil
+	}
+	return scan.Columns[idx]
+}
+
+// handleColumn returns the integer primary key column which is the handle of the table, or nil.
+// The unsigned handles are not in the order of the values, they are not used.
+func handleColumn(tbl *model.TableInfo) *model.ColumnInfo {
+	if !tbl.PKIsHandle {
+		return nil
+	}
+	for _, col := range tbl.Columns {
+		if mysql.HasPriKeyFlag(col.Flag) && !mysql.HasUnsignedFlag(col.Flag) {
+			return col
+		}
+	}
+	return nil
+}
+
+// joinKeyCompatible checks whether the values of the two types are compared in the same way as they are
+// ordered in the indices, so they can be used as the keys of index join and merge join.
+func joinKeyCompatible(tp1, tp2 *types.FieldType) bool {
+	if isIntegerType(tp1.Tp) && isIntegerType(tp2.Tp) {
+		return mysql.HasUnsignedFlag(tp1.Flag) == mysql.HasUnsignedFlag(tp2.Flag)
+	}
+	return isStringType(tp1.Tp) && isStringType(tp2.Tp)
+}
+
+func isIntegerType(tp byte) bool {
+	switch tp {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
+		return true
+	}
+	return false
+}
+
+func isStringType(tp byte) bool {
+	switch tp {
+	case mysql.TypeString, mysql.TypeVarchar, mysql.TypeVarString:
+		return true
+	}
+	return false
+}
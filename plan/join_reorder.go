@@ -0,0 +1,482 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"math"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+)
+
+const (
+	// joinReorderDPThreshold is the max number of the tables in a join group which is reordered by
+	// dynamic programming, the larger groups are reordered greedily.
+	joinReorderDPThreshold = 10
+	// joinReorderMaxTables is the max number of the tables in a join group which can be reordered.
+	joinReorderMaxTables = 64
+)
+
+// joinGroup is a tree of inner joins, the leaves are the plans which are not inner joins.
+type joinGroup struct {
+	leaves []Plan
+	// parents are the joins which the leaves belong to before reordering.
+	parents []Plan
+	conds   []expression.Expression
+	hints   []*ast.TableOptimizerHint
+}
+
+func (g *joinGroup) extract(p Plan, parent Plan) {
+	if join, ok := p.(*Join); ok && join.JoinType == InnerJoin {
+		g.conds = append(g.conds, expression.ScalarFuncs2Exprs(join.EqualConditions)...)
+		g.conds = append(g.conds, join.LeftConditions...)
+		g.conds = append(g.conds, join.RightConditions...)
+		g.conds = append(g.conds, join.OtherConditions...)
+		for _, child := range join.GetChildren() {
+			g.extract(child, join)
+		}
+		return
+	}
+	g.leaves = append(g.leaves, p)
+	g.parents = append(g.parents, parent)
+}
+
+// reorderJoin reorders the inner joins in p by the estimated row counts, and returns the new plan of p.
+// The outer joins are the boundaries of the join groups, their children are reordered separately.
+func (b *planBuilder) reorderJoin(p Plan) (Plan, error) {
+	if scan, ok := p.(*NewTableScan); ok {
+		// The row count of the table which isn't joined is estimated too, it's shown by EXPLAIN.
+		b.estimateRowCount(scan)
+		return scan, nil
+	}
+	join, ok := p.(*Join)
+	if !ok || join.JoinType != InnerJoin {
+		for _, child := range p.GetChildren() {
+			newChild, err := b.reorderJoin(child)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if newChild != child {
+				if err = p.ReplaceChild(child, newChild); err != nil {
+					return nil, errors.Trace(err)
+				}
+			}
+		}
+		if ap, ok := p.(*Apply); ok {
+			innerPlan, err := b.reorderJoin(ap.InnerPlan)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			ap.InnerPlan = innerPlan
+		}
+		return p, nil
+	}
+
+	g := &joinGroup{hints: join.hints}
+	g.extract(join, nil)
+	for i, leaf := range g.leaves {
+		newLeaf, err := b.reorderJoin(leaf)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if newLeaf != leaf {
+			if err = g.parents[i].ReplaceChild(leaf, newLeaf); err != nil {
+				return nil, errors.Trace(err)
+			}
+			g.leaves[i] = newLeaf
+		}
+	}
+	if len(g.leaves) > joinReorderMaxTables || hasHint(g.hints, JoinFixedOrder) {
+		return join, nil
+	}
+
+	s := b.newJoinReorderSolver(g)
+	var tree *joinNode
+	if order, hint := g.hintedOrder(); order != nil {
+		b.applyHint(hint, "")
+		tree = s.solveOrdered(order)
+	} else if len(g.leaves) <= joinReorderDPThreshold {
+		tree = s.solveDP()
+	} else {
+		tree = s.solveGreedy()
+	}
+	newJoin, err := b.buildReorderedJoin(g, s, tree, make([]bool, len(s.conds)))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	root := newJoin
+	// The parents find the columns by the schema, the order of the columns must be kept.
+	if !sameColumnOrder(join.GetSchema(), newJoin.GetSchema()) {
+		proj := &Projection{Exprs: expression.Schema2Exprs(join.GetSchema().DeepCopy())}
+		proj.SetSchema(join.GetSchema())
+		proj.id = b.allocID(proj)
+		proj.correlated = newJoin.IsCorrelated()
+		addChild(proj, newJoin)
+		root = proj
+	}
+	for _, parent := range join.GetParents() {
+		root.AddParent(parent)
+	}
+	return root, nil
+}
+
+// hintedOrder returns the leaves of the tables in the JOIN_ORDER hint and the hint, the order is nil
+// if there isn't the hint or some tables in the hint aren't the leaves of the group.
+func (g *joinGroup) hintedOrder() ([]int, *ast.TableOptimizerHint) {
+	for _, hint := range g.hints {
+		if hint.HintName.L != JoinOrder {
+			continue
+		}
+		order := make([]int, 0, len(hint.Tables))
+		for _, tbl := range hint.Tables {
+			leaf := -1
+			for i, p := range g.leaves {
+				if scan := tableScanOf(p); scan != nil && scan.tableName().L == tbl.L {
+					leaf = i
+					break
+				}
+			}
+			if leaf == -1 {
+				return nil, nil
+			}
+			order = append(order, leaf)
+		}
+		return order, hint
+	}
+	return nil, nil
+}
+
+func sameColumnOrder(s1, s2 expression.Schema) bool {
+	if len(s1) != len(s2) {
+		return false
+	}
+	for i, col := range s1 {
+		if s2.GetIndex(col) != i {
+			return false
+		}
+	}
+	return true
+}
+
+// buildReorderedJoin builds the joins of the tree, the conditions are placed in the lowest joins which
+// contain all the columns of them. The placed records the conditions which are placed.
+func (b *planBuilder) buildReorderedJoin(g *joinGroup, s *joinReorderSolver, node *joinNode, placed []bool) (Plan, error) {
+	if node.left == nil {
+		return g.leaves[node.leaf], nil
+	}
+	leftNode, rightNode := node.left, node.right
+	// The hash join executor builds the hash table with the right child, so the smaller one is put on the right.
+	if s.rowCount(leftNode.mask) < s.rowCount(rightNode.mask) {
+		leftNode, rightNode = rightNode, leftNode
+	}
+	left, err := b.buildReorderedJoin(g, s, leftNode, placed)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	right, err := b.buildReorderedJoin(g, s, rightNode, placed)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var conds []expression.Expression
+	for i, cond := range s.conds {
+		if !placed[i] && cond.mask&node.mask == cond.mask {
+			placed[i] = true
+			conds = append(conds, cond.expr)
+		}
+	}
+	join := &Join{JoinType: InnerJoin, hints: g.hints}
+	join.EqualConditions, join.LeftConditions, join.RightConditions, join.OtherConditions = extractOnCondition(conds, left, right)
+	join.SetSchema(append(left.GetSchema().DeepCopy(), right.GetSchema().DeepCopy()...))
+	join.correlated = left.IsCorrelated() || right.IsCorrelated()
+	join.rowCount = s.rowCount(node.mask)
+	for _, child := range []*joinNode{leftNode, rightNode} {
+		var childPlan Plan
+		if child.left == nil {
+			childPlan = g.leaves[child.leaf]
+			if err = childPlan.ReplaceParent(g.parents[child.leaf], join); err != nil {
+				return nil, errors.Trace(err)
+			}
+		} else if child == leftNode {
+			childPlan = left
+			childPlan.AddParent(join)
+		} else {
+			childPlan = right
+			childPlan.AddParent(join)
+		}
+		join.AddChild(childPlan)
+	}
+	return join, nil
+}
+
+// joinNode is a node of the join tree, the leaf node is a table of the join group.
+type joinNode struct {
+	// mask is the set of the tables in the node, the ith bit means the ith table.
+	mask        uint64
+	leaf        int
+	left, right *joinNode
+}
+
+// joinCond is a condition of the join group.
+type joinCond struct {
+	expr expression.Expression
+	// mask is the set of the tables whose columns are used by the condition.
+	mask uint64
+	// rate is the estimated filter rate of the condition.
+	rate float64
+}
+
+// joinReorderSolver finds the join order with the least cost, the cost of a join tree is the sum
+// of the estimated row counts of all the joins.
+type joinReorderSolver struct {
+	counts []float64
+	conds  []joinCond
+}
+
+func (b *planBuilder) newJoinReorderSolver(g *joinGroup) *joinReorderSolver {
+	s := &joinReorderSolver{counts: make([]float64, len(g.leaves))}
+	for i, leaf := range g.leaves {
+		s.counts[i] = b.estimateRowCount(leaf)
+	}
+	fullMask := uint64(1)<<uint(len(g.leaves)) - 1
+	for _, expr := range g.conds {
+		cond := joinCond{expr: expr}
+		cols, _ := extractColumn(expr, nil, nil)
+		for _, col := range cols {
+			if i := g.leafIndex(col); i != -1 {
+				cond.mask |= 1 << uint(i)
+			} else {
+				cond.mask = fullMask
+			}
+		}
+		cond.rate = b.joinCondFilterRate(g, s.counts, expr)
+		s.conds = append(s.conds, cond)
+	}
+	return s
+}
+
+func (g *joinGroup) leafIndex(col *expression.Column) int {
+	for i, leaf := range g.leaves {
+		if leaf.GetSchema().GetIndex(col) != -1 {
+			return i
+		}
+	}
+	return -1
+}
+
+// joinCondFilterRate estimates the filter rate of a join condition. The rate of an equal condition
+// between two tables is 1/max(NDV1, NDV2), the NDV of a column is assumed to be the row count of
+// its table if it's not analyzed.
+func (b *planBuilder) joinCondFilterRate(g *joinGroup, counts []float64, expr expression.Expression) float64 {
+	if f, ok := expr.(*expression.ScalarFunction); ok && f.FuncName.L == ast.EQ {
+		lCol, lOK := f.Args[0].(*expression.Column)
+		rCol, rOK := f.Args[1].(*expression.Column)
+		if lOK && rOK {
+			li, ri := g.leafIndex(lCol), g.leafIndex(rCol)
+			if li != -1 && ri != -1 && li != ri {
+				lNDV := b.columnNDV(g.leaves[li], lCol, counts[li])
+				rNDV := b.columnNDV(g.leaves[ri], rCol, counts[ri])
+				return 1 / math.Max(math.Max(lNDV, rNDV), 1)
+			}
+		}
+	}
+	return guesstimateExprFilterRate(expr)
+}
+
+// columnNDV returns the number of distinct values of the column in the plan whose row count is count.
+func (b *planBuilder) columnNDV(p Plan, col *expression.Column, count float64) float64 {
+	for {
+		sel, ok := p.(*Selection)
+		if !ok {
+			break
+		}
+		p = sel.GetChildByIndex(0)
+	}
+	scan, ok := p.(*NewTableScan)
+	if !ok {
+		return count
+	}
+	stats := b.tableStats(scan.Table)
+	idx := scan.GetSchema().GetIndex(col)
+	if stats == nil || idx == -1 {
+		return count
+	}
+	colStats := stats.ColumnByID(scan.Columns[idx].ID)
+	if colStats == nil || colStats.NDV <= 0 {
+		return count
+	}
+	return math.Min(float64(colStats.NDV), count)
+}
+
+// estimateRowCount estimates the row count of a leaf of the join group.
+func (b *planBuilder) estimateRowCount(p Plan) float64 {
+	switch x := p.(type) {
+	case *NewTableScan:
+		x.rowCount = FullRangeCount
+		if stats := b.tableStats(x.Table); stats != nil {
+			x.rowCount = float64(stats.Count)
+		}
+		return x.rowCount
+	case *Selection:
+		count := b.estimateRowCount(x.GetChildByIndex(0))
+		for _, cond := range x.Conditions {
+			count *= guesstimateExprFilterRate(cond)
+		}
+		return count
+	case *Join:
+		if x.rowCount > 0 {
+			return x.rowCount
+		}
+		leftCount := b.estimateRowCount(x.GetChildByIndex(0))
+		rightCount := b.estimateRowCount(x.GetChildByIndex(1))
+		switch x.JoinType {
+		case LeftOuterJoin, SemiJoin, AntiSemiJoin:
+			return leftCount
+		case RightOuterJoin:
+			return rightCount
+		}
+		return leftCount * rightCount
+	case *Aggregation:
+		if len(x.GroupByItems) == 0 {
+			return 1
+		}
+	case *MaxOneRow, *Exists:
+		return 1
+	case *Limit:
+		return math.Min(b.estimateRowCount(x.GetChildByIndex(0)), float64(x.Count))
+	}
+	if len(p.GetChildren()) == 0 {
+		return 1
+	}
+	var count float64
+	for _, child := range p.GetChildren() {
+		count += b.estimateRowCount(child)
+	}
+	return count
+}
+
+// rowCount estimates the row count of joining the tables in the mask.
+func (s *joinReorderSolver) rowCount(mask uint64) float64 {
+	count := 1.0
+	for i, c := range s.counts {
+		if mask&(1<<uint(i)) != 0 {
+			count *= c
+		}
+	}
+	for _, cond := range s.conds {
+		if cond.mask != 0 && cond.mask&mask == cond.mask {
+			count *= cond.rate
+		}
+	}
+	return math.Max(count, 1)
+}
+
+func (s *joinReorderSolver) buildTree(mask uint64, split []uint64) *joinNode {
+	if mask&(mask-1) == 0 {
+		leaf := 0
+		for mask>>uint(leaf) != 1 {
+			leaf++
+		}
+		return &joinNode{mask: mask, leaf: leaf}
+	}
+	return &joinNode{
+		mask:  mask,
+		left:  s.buildTree(split[mask], split),
+		right: s.buildTree(mask^split[mask], split),
+	}
+}
+
+// solveDP finds the best bushy join tree by dynamic programming over all the subsets of the tables.
+func (s *joinReorderSolver) solveDP() *joinNode {
+	fullMask := uint64(1)<<uint(len(s.counts)) - 1
+	cost := make([]float64, fullMask+1)
+	split := make([]uint64, fullMask+1)
+	for mask := uint64(1); mask <= fullMask; mask++ {
+		if mask&(mask-1) == 0 {
+			continue
+		}
+		cost[mask] = math.Inf(1)
+		count := s.rowCount(mask)
+		// The subsets are less than the mask, so their costs are known.
+		for sub := (mask - 1) & mask; sub > 0; sub = (sub - 1) & mask {
+			other := mask ^ sub
+			if sub < other {
+				continue
+			}
+			if c := cost[sub] + cost[other] + count; c < cost[mask] {
+				cost[mask] = c
+				split[mask] = sub
+			}
+		}
+	}
+	return s.buildTree(fullMask, split)
+}
+
+// solveGreedy builds a left deep join tree. It starts from the table with the fewest rows, then joins
+// the table which makes the fewest rows each time, the tables connected by conditions are preferred
+// to avoid the cartesian products.
+func (s *joinReorderSolver) solveGreedy() *joinNode {
+	var node *joinNode
+	for i, count := range s.counts {
+		if node == nil || count < s.counts[node.leaf] {
+			node = &joinNode{mask: 1 << uint(i), leaf: i}
+		}
+	}
+	return s.joinGreedily(node)
+}
+
+// solveOrdered builds a left deep join tree which joins the tables in the order first, then joins
+// the other tables greedily.
+func (s *joinReorderSolver) solveOrdered(order []int) *joinNode {
+	node := &joinNode{mask: 1 << uint(order[0]), leaf: order[0]}
+	for _, i := range order[1:] {
+		leaf := &joinNode{mask: 1 << uint(i), leaf: i}
+		node = &joinNode{mask: node.mask | leaf.mask, left: node, right: leaf}
+	}
+	return s.joinGreedily(node)
+}
+
+// joinGreedily joins the tables which aren't in the tree to it one by one, the table which makes the
+// fewest rows is joined each time.
+func (s *joinReorderSolver) joinGreedily(node *joinNode) *joinNode {
+	fullMask := uint64(1)<<uint(len(s.counts)) - 1
+	for node.mask != fullMask {
+		var best *joinNode
+		bestConnected, bestCount := false, 0.0
+		for i := range s.counts {
+			bit := uint64(1) << uint(i)
+			if node.mask&bit != 0 {
+				continue
+			}
+			connected := s.connected(node.mask, bit)
+			count := s.rowCount(node.mask | bit)
+			if best == nil || (connected && !bestConnected) || (connected == bestConnected && count < bestCount) {
+				best = &joinNode{mask: bit, leaf: i}
+				bestConnected, bestCount = connected, count
+			}
+		}
+		node = &joinNode{mask: node.mask | best.mask, left: node, right: best}
+	}
+	return node
+}
+
+// connected checks whether there is a condition between the two sets of tables.
+func (s *joinReorderSolver) connected(mask1, mask2 uint64) bool {
+	for _, cond := range s.conds {
+		if cond.mask&mask1 != 0 && cond.mask&mask2 != 0 && cond.mask&(mask1|mask2) == cond.mask {
+			return true
+		}
+	}
+	return false
+}
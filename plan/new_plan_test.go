@@ -70,6 +70,9 @@ func newMockResolve(node ast.Node) error {
 		Name:  model.NewCIStr("d"),
 	}
 	pkColumn.Flag = mysql.PriKeyFlag
+	for _, col := range []*model.ColumnInfo{pkColumn, col0, col1, col2} {
+		col.FieldType.Tp = mysql.TypeLonglong
+	}
 	table := &model.TableInfo{
 		Columns:    []*model.ColumnInfo{pkColumn, col0, col1, col2},
 		Indices:    indices,
@@ -135,8 +138,8 @@ func (s *testPlanSuite) TestPredicatePushDown(c *C) {
 		},
 		{
 			sql:   "select a from t where exists(select 1 from t as x where x.a < t.a)",
-			first: "DataScan(t)->Apply(DataScan(t)->Selection->Projection->Exists)->Selection->Projection",
-			best:  "DataScan(t)->Apply(DataScan(t)->Selection->Projection->Exists)->Selection->Projection",
+			first: "DataScan(t)->Apply(DataScan(t)->Selection->Projection)->Projection",
+			best:  "DataScan(t)->Apply(DataScan(t)->Selection->Projection)->Projection",
 		},
 	}
 	for _, ca := range cases {
@@ -162,6 +165,127 @@ func (s *testPlanSuite) TestPredicatePushDown(c *C) {
 	UseNewPlanner = false
 }
 
+func (s *testPlanSuite) TestJoinReorder(c *C) {
+	UseNewPlanner = true
+	defer testleak.AfterTest(c)()
+	cases := []struct {
+		sql  string
+		best string
+	}{
+		{
+			sql:  "select * from t t1, t t2, t t3 where t1.a = t2.a and t2.b = t3.b and t2.d > 1 and t3.c = 1",
+			best: "Join{DataScan(t)->Join{DataScan(t)->Selection->DataScan(t)->Selection}}->Projection",
+		},
+		{
+			sql:  "select * from t t1 join t t2 on t1.a = t2.a where t1.c = 1",
+			best: "Join{DataScan(t)->DataScan(t)->Selection}->Projection->Projection",
+		},
+		{
+			sql:  "select t1.a from t t1 join t t2 on t1.a = t2.a join t t3 on t2.b = t3.b where t1.c = 1 and t3.c = 1",
+			best: "Join{Join{DataScan(t)->DataScan(t)->Selection}->DataScan(t)->Selection}->Projection->Projection",
+		},
+		{
+			sql:  "select * from t t1 left join (t t2 join t t3 on t2.a = t3.a and t2.c = 1) on t1.a = t2.a",
+			best: "Join{DataScan(t)->Join{DataScan(t)->DataScan(t)->Selection}->Projection}->Projection",
+		},
+	}
+	for _, ca := range cases {
+		comment := Commentf("for %s", ca.sql)
+		stmt, err := parser.ParseOneStmt(ca.sql, "", "")
+		c.Assert(err, IsNil, comment)
+		ast.SetFlag(stmt)
+
+		err = newMockResolve(stmt)
+		c.Assert(err, IsNil)
+
+		builder := &planBuilder{}
+		p := builder.build(stmt)
+		c.Assert(builder.err, IsNil)
+
+		p, err = builder.logicalOptimize(p)
+		c.Assert(err, IsNil)
+		c.Assert(ToString(p), Equals, ca.best, comment)
+	}
+	UseNewPlanner = false
+
+	// A star schema: the fact table has 10000 rows, every dimension table has 100 rows.
+	solver := &joinReorderSolver{counts: []float64{100, 100, 10000, 100}}
+	for _, i := range []uint{0, 1, 3} {
+		solver.conds = append(solver.conds, joinCond{mask: 1<<i | 1<<2, rate: 0.01})
+	}
+	checkTree := func(node *joinNode) {
+		// Every join has a condition, no cartesian product is made.
+		for node.left != nil {
+			c.Assert(solver.connected(node.left.mask, node.right.mask), IsTrue)
+			node = node.left
+		}
+	}
+	checkTree(solver.solveDP())
+	checkTree(solver.solveGreedy())
+}
+
+func (s *testPlanSuite) TestDecorrelate(c *C) {
+	UseNewPlanner = true
+	defer testleak.AfterTest(c)()
+	cases := []struct {
+		sql  string
+		best string
+	}{
+		{
+			sql:  "select a from t where exists (select 1 from t x where x.b = t.b and x.c > 1)",
+			best: "SemiJoin{DataScan(t)->DataScan(t)->Selection->Projection}->Projection",
+		},
+		{
+			sql:  "select a from t where not exists (select 1 from t x where x.b = t.b and x.c < t.c)",
+			best: "AntiSemiJoin{DataScan(t)->DataScan(t)->Projection}->Projection",
+		},
+		{
+			sql:  "select a from t where b in (select x.b from t x where x.c = t.c)",
+			best: "SemiJoin{DataScan(t)->DataScan(t)->Projection}->Projection",
+		},
+		{
+			sql:  "select a from t where b not in (select x.b from t x)",
+			best: "AntiSemiJoin{DataScan(t)->DataScan(t)->Projection}->Projection",
+		},
+		{
+			sql:  "select a from t where c = 1 or b in (select x.b from t x where x.c = t.c)",
+			best: "SemiJoinWithAux{DataScan(t)->DataScan(t)->Projection}->Selection->Projection",
+		},
+		{
+			sql:  "select a, (select count(*) from t x where x.b = t.b and x.c > 1) from t",
+			best: "Join{DataScan(t)->DataScan(t)->Selection->Aggr}->Projection->Projection",
+		},
+		{
+			// The correlated condition isn't an equal condition, the scalar subquery isn't decorrelated.
+			sql:  "select a, (select count(*) from t x where x.b < t.b) from t",
+			best: "DataScan(t)->Apply(DataScan(t)->Selection->Aggr->Projection->MaxOneRow)->Projection",
+		},
+		{
+			// The subquery has a correlated projection, the semi apply isn't decorrelated.
+			sql:  "select a from t where b in (select x.b + t.c from t x)",
+			best: "DataScan(t)->Apply(DataScan(t)->Projection)->Projection",
+		},
+	}
+	for _, ca := range cases {
+		comment := Commentf("for %s", ca.sql)
+		stmt, err := parser.ParseOneStmt(ca.sql, "", "")
+		c.Assert(err, IsNil, comment)
+		ast.SetFlag(stmt)
+
+		err = newMockResolve(stmt)
+		c.Assert(err, IsNil)
+
+		builder := &planBuilder{}
+		p := builder.build(stmt)
+		c.Assert(builder.err, IsNil)
+
+		p, err = builder.logicalOptimize(p)
+		c.Assert(err, IsNil)
+		c.Assert(ToString(p), Equals, ca.best, comment)
+	}
+	UseNewPlanner = false
+}
+
 func (s *testPlanSuite) TestColumnPruning(c *C) {
 	UseNewPlanner = true
 	defer testleak.AfterTest(c)()
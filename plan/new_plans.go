@@ -15,11 +15,12 @@ package plan
 
 import (
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/model"
 )
 
-// JoinType contains CrossJoin, InnerJoin, LeftOuterJoin, RightOuterJoin, FullOuterJoin, SemiJoin.
+// JoinType contains CrossJoin, InnerJoin, LeftOuterJoin, RightOuterJoin, SemiJoin, AntiSemiJoin.
 type JoinType int
 
 const (
@@ -31,7 +32,22 @@ const (
 	LeftOuterJoin
 	// RightOuterJoin means right join.
 	RightOuterJoin
-	// TODO: support semi join.
+	// SemiJoin means if row a in table A matches some rows in B, just output a.
+	SemiJoin
+	// AntiSemiJoin means if row a in table A does not match any row in B, then output a.
+	AntiSemiJoin
+)
+
+// JoinAlgorithm is the algorithm to execute a join.
+type JoinAlgorithm int
+
+const (
+	// HashJoin builds a hash table with the small child, and probes it with the rows of the big child.
+	HashJoin JoinAlgorithm = iota
+	// IndexJoin looks up the inner child by the handle or an index with the join keys of a batch of outer rows.
+	IndexJoin
+	// MergeJoin merges the children which are read in the order of the join key.
+	MergeJoin
 )
 
 // Join is the logical join plan.
@@ -44,6 +60,21 @@ type Join struct {
 	LeftConditions  []expression.Expression
 	RightConditions []expression.Expression
 	OtherConditions []expression.Expression
+	// NullAwareConditions are the equal conditions of the IN subqueries of semi join, the first argument is
+	// evaluated with the left row and the second one with the right row. A NULL argument makes the match unknown,
+	// so NOT IN rejects the row.
+	NullAwareConditions []*expression.ScalarFunction
+	// WithAux is true if semi join outputs all the left rows with an aux column, the aux column is 1 if the row
+	// matches, NULL if the match is unknown, or 0. It's used when the subquery isn't a filter of the WHERE clause.
+	WithAux bool
+
+	// Algorithm is chosen by cost after the logical optimization.
+	Algorithm JoinAlgorithm
+	// InnerChildIdx is the index of the inner child of index join.
+	InnerChildIdx int
+
+	// hints are the optimizer hints of the SELECT statement which the join belongs to.
+	hints []*ast.TableOptimizerHint
 }
 
 // Projection represents a select fields plan.
@@ -57,6 +88,8 @@ type Aggregation struct {
 	basePlan
 	AggFuncs     []expression.AggregationFunction
 	GroupByItems []expression.Expression
+	// PushDownHint is AggToCop or NoAggToCop if the aggregation has the hint.
+	PushDownHint string
 }
 
 // Selection means a filter.
@@ -75,6 +108,20 @@ type Apply struct {
 
 	InnerPlan   Plan
 	OuterSchema expression.Schema
+	// Checker is set if the apply is a semi apply, which checks the inner rows instead of joining them.
+	Checker *ApplyConditionChecker
+}
+
+// ApplyConditionChecker checks whether an outer row of apply matches the rows of the inner plan. It's used for the
+// [NOT] EXISTS and [NOT] IN subqueries which can't be decorrelated.
+type ApplyConditionChecker struct {
+	// Condition is the equal condition of IN subquery, it's evaluated with the outer row and the inner row joined.
+	// It's nil for EXISTS subquery, any inner row matches then.
+	Condition *expression.ScalarFunction
+	// Anti is true if the outer rows which don't match any inner row are returned.
+	Anti bool
+	// WithAux is true if all the outer rows are returned with an aux column, like Join.WithAux.
+	WithAux bool
 }
 
 // Exists checks if a query returns result.
@@ -103,6 +150,17 @@ type NewTableScan struct {
 	TableAsName *model.CIStr
 
 	LimitCount *int64
+
+	// Index is the index to read the table by, the rows are returned in the order of the index.
+	// It's set if the table is the inner table of index join, a child of merge join, or the index hint forces it.
+	Index *model.IndexInfo
+	// IndexRanges are the ranges of the index to read, all the index entries are read if it's nil.
+	IndexRanges []*IndexRange
+
+	// indices are the indices which can be used to read the table, they're restricted by the index hints.
+	indices []*model.IndexInfo
+	// indexForced is true if the index hints exclude reading the table by the table scan.
+	indexForced bool
 }
 
 // AddChild for parent.
@@ -34,7 +34,7 @@ func (b *planBuilder) allocID(p Plan) string {
 
 func (b *planBuilder) buildAggregation(p Plan, aggFuncList []*ast.AggregateFuncExpr, gby *ast.GroupByClause) Plan {
 	newAggFuncList := make([]expression.AggregationFunction, 0, len(aggFuncList))
-	agg := &Aggregation{}
+	agg := &Aggregation{PushDownHint: aggPushDownHint(b.tableHints)}
 	agg.id = b.allocID(agg)
 	agg.correlated = p.IsCorrelated()
 	addChild(agg, p)
@@ -87,6 +87,12 @@ func (b *planBuilder) buildResultSetNode(node ast.ResultSetNode) Plan {
 		case *ast.UnionStmt:
 			p = b.buildNewUnion(v)
 		case *ast.TableName:
+			// The experimental new planner doesn't prune the partitions yet, the partitioned tables are only
+			// supported by the default planner.
+			if v.TableInfo.Partition != nil {
+				b.err = ErrUnsupportedType.Gen("unsupported partitioned table %s", v.Name)
+				return nil
+			}
 			// TODO: select physical algorithm during cbo phase.
 			p = b.buildNewTableScanPlan(v)
 		default:
@@ -192,16 +198,16 @@ func (b *planBuilder) buildNewJoin(join *ast.Join) Plan {
 	leftPlan := b.buildResultSetNode(join.Left)
 	rightPlan := b.buildResultSetNode(join.Right)
 	newSchema := append(leftPlan.GetSchema().DeepCopy(), rightPlan.GetSchema().DeepCopy()...)
-	joinPlan := &Join{}
+	joinPlan := &Join{hints: b.tableHints}
 	joinPlan.SetSchema(newSchema)
 	joinPlan.correlated = leftPlan.IsCorrelated() || rightPlan.IsCorrelated()
 	if join.On != nil {
-		onExpr, _, correlated, err := b.rewrite(join.On.Expr, joinPlan, nil)
+		onExpr, np, correlated, err := b.rewrite(join.On.Expr, joinPlan, nil)
 		if err != nil {
 			b.err = err
 			return nil
 		}
-		if correlated {
+		if correlated || np != joinPlan {
 			b.err = errors.New("On condition doesn't support subqueries yet.")
 		}
 		onCondition := splitCNFItems(onExpr)
@@ -229,14 +235,20 @@ func (b *planBuilder) buildSelection(p Plan, where ast.ExprNode, mapper map[*ast
 	selection := &Selection{}
 	selection.correlated = p.IsCorrelated()
 	for _, cond := range conditions {
-		expr, np, correlated, err := b.rewrite(cond, p, mapper)
+		expr, np, correlated, err := b.rewriteExpr(cond, p, mapper, false)
 		if err != nil {
 			b.err = err
 			return nil
 		}
 		p = np
 		selection.correlated = selection.correlated || correlated
-		expressions = append(expressions, expr)
+		// The condition of [NOT] EXISTS or [NOT] IN subquery is rewritten to a semi apply.
+		if expr != nil {
+			expressions = append(expressions, expr)
+		}
+	}
+	if len(expressions) == 0 {
+		return p
 	}
 	selection.Conditions = expressions
 	selection.id = b.allocID(selection)
@@ -486,6 +498,9 @@ func (b *planBuilder) buildNewSelect(sel *ast.SelectStmt) Plan {
 	if hasAgg {
 		aggFuncs, havingMap, orderMap, totalMap = b.extractAggFunc(sel)
 	}
+	oldHints := b.tableHints
+	b.tableHints = b.checkHints(sel, hasAgg)
+	defer func() { b.tableHints = oldHints }()
 	// Build subquery
 	// Convert subquery to expr with plan
 	// TODO: add subquery support.
@@ -571,8 +586,11 @@ func (b *planBuilder) buildNewSelect(sel *ast.SelectStmt) Plan {
 }
 
 func (b *planBuilder) buildNewTableScanPlan(tn *ast.TableName) Plan {
+	indices, includeTableScan := b.availableIndices(tn)
 	p := &NewTableScan{
-		Table: tn.TableInfo,
+		Table:       tn.TableInfo,
+		indices:     indices,
+		indexForced: !includeTableScan,
 	}
 	p.id = b.allocID(p)
 	// Equal condition contains a column from previous joined table.
@@ -605,6 +623,26 @@ func (b *planBuilder) buildApply(p, innter Plan, schema expression.Schema) Plan
 	return ap
 }
 
+// buildSemiApply builds an apply which checks the condition with the inner rows for every outer row, it filters
+// the outer rows, or appends an aux column of the result if it's withAux.
+func (b *planBuilder) buildSemiApply(p, inner Plan, outerSchema expression.Schema, cond *expression.ScalarFunction,
+	anti, withAux bool) Plan {
+	ap := &Apply{
+		InnerPlan:   inner,
+		OuterSchema: outerSchema,
+		Checker:     &ApplyConditionChecker{Condition: cond, Anti: anti, WithAux: withAux},
+	}
+	ap.id = b.allocID(ap)
+	addChild(ap, p)
+	schema := p.GetSchema().DeepCopy()
+	if withAux {
+		schema = append(schema, &expression.Column{FromID: ap.id, RetType: types.NewFieldType(mysql.TypeTiny), ColName: model.NewCIStr("aux_col")})
+	}
+	ap.SetSchema(schema)
+	ap.correlated = p.IsCorrelated()
+	return ap
+}
+
 func (b *planBuilder) buildExists(p Plan) Plan {
 	exists := &Exists{}
 	exists.id = b.allocID(exists)
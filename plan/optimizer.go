@@ -39,19 +39,59 @@ func Optimize(ctx context.Context, node ast.Node, sb SubQueryBuilder, is infosch
 		return nil, errors.Trace(builder.err)
 	}
 	if UseNewPlanner {
-		_, err := builder.predicatePushDown(p, []expression.Expression{})
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		_, _, err = pruneColumnsAndResolveIndices(p, p.GetSchema())
-		if err != nil {
-			return nil, errors.Trace(err)
+		var err error
+		// The plan of the explained statement is optimized, so EXPLAIN shows the optimized join order and algorithms.
+		if explain, ok := p.(*Explain); ok {
+			stmtPlan, err := builder.logicalOptimize(explain.StmtPlan)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if stmtPlan != explain.StmtPlan {
+				if err = explain.ReplaceChild(explain.StmtPlan, stmtPlan); err != nil {
+					return nil, errors.Trace(err)
+				}
+				explain.StmtPlan = stmtPlan
+			}
+			chooseHintedIndices(stmtPlan)
+			builder.chooseJoinAlgorithms(stmtPlan)
+		} else {
+			p, err = builder.logicalOptimize(p)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			chooseHintedIndices(p)
+			builder.chooseJoinAlgorithms(p)
 		}
+		builder.warnUnappliedHints()
 	}
 	err := Refine(p)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if explain, ok := p.(*Explain); ok && explain.Analyze {
+		builder.estimateRowCounts(explain.StmtPlan)
+	}
+	return p, nil
+}
+
+// logicalOptimize applies the logical optimization rules of the new planner to the plan, it returns the new plan.
+func (b *planBuilder) logicalOptimize(p Plan) (Plan, error) {
+	p, err := b.decorrelate(p)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	_, err = b.predicatePushDown(p, []expression.Expression{})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	p, err = b.reorderJoin(p)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	_, _, err = pruneColumnsAndResolveIndices(p, p.GetSchema())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	return p, nil
 }
 
@@ -71,31 +111,49 @@ func PrepareStmt(is infoschema.InfoSchema, ctx context.Context, node ast.Node) e
 
 // Optimizer error codes.
 const (
-	CodeOneColumn           terror.ErrCode = 1
-	CodeSameColumns         terror.ErrCode = 2
-	CodeMultiWildCard       terror.ErrCode = 3
-	CodeUnsupported         terror.ErrCode = 4
-	CodeInvalidGroupFuncUse terror.ErrCode = 5
-	CodeIllegalReference    terror.ErrCode = 6
+	CodeOneColumn            terror.ErrCode = 1
+	CodeSameColumns          terror.ErrCode = 2
+	CodeMultiWildCard        terror.ErrCode = 3
+	CodeUnsupported          terror.ErrCode = 4
+	CodeInvalidGroupFuncUse  terror.ErrCode = 5
+	CodeIllegalReference     terror.ErrCode = 6
+	CodeUnknownHint          terror.ErrCode = 7
+	CodeUnresolvedHintName   terror.ErrCode = 8
+	CodeInapplicableHint     terror.ErrCode = 9
+	CodeConflictingHint      terror.ErrCode = 10
+	CodeKeyDoesNotExist      terror.ErrCode = 11
+	CodeUnknownExplainFormat terror.ErrCode = 12
 )
 
 // Optimizer base errors.
 var (
-	ErrOneColumn           = terror.ClassOptimizer.New(CodeOneColumn, "Operand should contain 1 column(s)")
-	ErrSameColumns         = terror.ClassOptimizer.New(CodeSameColumns, "Operands should contain same columns")
-	ErrMultiWildCard       = terror.ClassOptimizer.New(CodeMultiWildCard, "wildcard field exist more than once")
-	ErrUnSupported         = terror.ClassOptimizer.New(CodeUnsupported, "unsupported")
-	ErrInvalidGroupFuncUse = terror.ClassOptimizer.New(CodeInvalidGroupFuncUse, "Invalid use of group function")
-	ErrIllegalReference    = terror.ClassOptimizer.New(CodeIllegalReference, "Illegal reference")
+	ErrOneColumn            = terror.ClassOptimizer.New(CodeOneColumn, "Operand should contain 1 column(s)")
+	ErrSameColumns          = terror.ClassOptimizer.New(CodeSameColumns, "Operands should contain same columns")
+	ErrMultiWildCard        = terror.ClassOptimizer.New(CodeMultiWildCard, "wildcard field exist more than once")
+	ErrUnSupported          = terror.ClassOptimizer.New(CodeUnsupported, "unsupported")
+	ErrInvalidGroupFuncUse  = terror.ClassOptimizer.New(CodeInvalidGroupFuncUse, "Invalid use of group function")
+	ErrIllegalReference     = terror.ClassOptimizer.New(CodeIllegalReference, "Illegal reference")
+	ErrUnknownHint          = terror.ClassOptimizer.New(CodeUnknownHint, "Optimizer hint %s is not supported")
+	ErrUnresolvedHintName   = terror.ClassOptimizer.New(CodeUnresolvedHintName, "Unresolved name '%s' for %s hint")
+	ErrInapplicableHint     = terror.ClassOptimizer.New(CodeInapplicableHint, "Optimizer hint %s is inapplicable")
+	ErrConflictingHint      = terror.ClassOptimizer.New(CodeConflictingHint, "Hint %s is ignored as conflicting/duplicated")
+	ErrKeyDoesNotExist      = terror.ClassOptimizer.New(CodeKeyDoesNotExist, "Key '%s' doesn't exist in table '%s'")
+	ErrUnknownExplainFormat = terror.ClassOptimizer.New(CodeUnknownExplainFormat, "Unknown EXPLAIN format name: '%s'")
 )
 
 func init() {
 	mySQLErrCodes := map[terror.ErrCode]uint16{
-		CodeOneColumn:           mysql.ErrOperandColumns,
-		CodeSameColumns:         mysql.ErrOperandColumns,
-		CodeMultiWildCard:       mysql.ErrParse,
-		CodeInvalidGroupFuncUse: mysql.ErrInvalidGroupFuncUse,
-		CodeIllegalReference:    mysql.ErrIllegalReference,
+		CodeOneColumn:            mysql.ErrOperandColumns,
+		CodeSameColumns:          mysql.ErrOperandColumns,
+		CodeMultiWildCard:        mysql.ErrParse,
+		CodeInvalidGroupFuncUse:  mysql.ErrInvalidGroupFuncUse,
+		CodeIllegalReference:     mysql.ErrIllegalReference,
+		CodeUnknownHint:          mysql.ErrUnknown,
+		CodeUnresolvedHintName:   mysql.ErrUnresolvedHintName,
+		CodeInapplicableHint:     mysql.ErrUnknown,
+		CodeConflictingHint:      mysql.ErrWarnConflictingHint,
+		CodeKeyDoesNotExist:      mysql.ErrKeyDoesNotExits,
+		CodeUnknownExplainFormat: mysql.ErrUnknownExplainFormat,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassOptimizer] = mySQLErrCodes
 }
@@ -117,6 +117,10 @@ func (p *basePlan) RowCount() float64 {
 	return math.Min(p.rowCount, p.limit)
 }
 
+func (p *basePlan) setRowCount(count float64) {
+	p.rowCount = count
+}
+
 // SetLimit implements Plan SetLimit interface.
 func (p *basePlan) SetLimit(limit float64) {
 	p.limit = limit
@@ -374,6 +374,96 @@ func (s *testPlanSuite) TestSplitWhere(c *C) {
 	}
 }
 
+func (s *testPlanSuite) TestPrunePartitions(c *C) {
+	defer testleak.AfterTest(c)()
+	lessThan := func(v int64) *int64 { return &v }
+	rangePartition := &model.PartitionInfo{
+		Type:   model.PartitionTypeRange,
+		Column: model.NewCIStr("a"),
+		Definitions: []*model.PartitionDefinition{
+			{ID: 1, LessThan: lessThan(10)},
+			{ID: 2, LessThan: lessThan(20)},
+			{ID: 3},
+		},
+	}
+	hashPartition := &model.PartitionInfo{
+		Type:        model.PartitionTypeHash,
+		Column:      model.NewCIStr("a"),
+		Definitions: []*model.PartitionDefinition{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}},
+	}
+	// The partitions of 2016, January 2017 and the later dates.
+	toDaysPartition := &model.PartitionInfo{
+		Type:   model.PartitionTypeRange,
+		Column: model.NewCIStr("c"),
+		Func:   model.PartitionFuncToDays,
+		Definitions: []*model.PartitionDefinition{
+			{ID: 1, LessThan: lessThan(736695)},
+			{ID: 2, LessThan: lessThan(736726)},
+			{ID: 3},
+		},
+	}
+	yearPartition := &model.PartitionInfo{
+		Type:   model.PartitionTypeRange,
+		Column: model.NewCIStr("c"),
+		Func:   model.PartitionFuncYear,
+		Definitions: []*model.PartitionDefinition{
+			{ID: 1, LessThan: lessThan(2017)},
+			{ID: 2, LessThan: lessThan(2018)},
+		},
+	}
+	cases := []struct {
+		partition *model.PartitionInfo
+		expr      string
+		ids       []int64
+	}{
+		{rangePartition, "b = 1", nil},
+		{rangePartition, "a = 1", []int64{1}},
+		{rangePartition, "a >= 10 and a < 20", []int64{2}},
+		{rangePartition, "a between 5 and 10", []int64{1, 2}},
+		{rangePartition, "a < 10 or a > 30", []int64{1, 3}},
+		{rangePartition, "a in (15, 25) and b = 1", []int64{2, 3}},
+		{rangePartition, "a > 30 and a < 20", []int64{}},
+		{rangePartition, "a is null", []int64{1}},
+		{hashPartition, "a = 5", []int64{2}},
+		{hashPartition, "a in (1, 6)", []int64{2, 3}},
+		{hashPartition, "a between 6 and 7", []int64{3, 4}},
+		{hashPartition, "a > 5", []int64{1, 2, 3, 4}},
+		{toDaysPartition, "c = '2017-01-15'", []int64{2}},
+		{toDaysPartition, "c >= '2017-01-01' and c < '2017-02-01'", []int64{2}},
+		{toDaysPartition, "c <= '2017-01-31 10:00:00'", []int64{1, 2}},
+		{toDaysPartition, "c < '2017-02-01 10:00:00'", []int64{1, 2, 3}},
+		{toDaysPartition, "c > '2016-12-31'", []int64{1, 2, 3}},
+		{toDaysPartition, "c = '0000-00-00'", []int64{1}},
+		{toDaysPartition, "c > 'abc'", nil},
+		{yearPartition, "c between '2016-06-01' and '2016-12-31'", []int64{1}},
+		{yearPartition, "c >= '2017-01-01' and c < '2018-01-01'", []int64{2}},
+	}
+	for _, ca := range cases {
+		sql := "select * from t where " + ca.expr
+		comment := Commentf("for %s", sql)
+		stmt, err := parser.ParseOneStmt(sql, "", "")
+		c.Assert(err, IsNil, comment)
+		mockResolve(stmt)
+		tn := stmt.(*ast.SelectStmt).From.TableRefs.Left.(*ast.TableSource).Source.(*ast.TableName)
+		tn.TableInfo.Partition = ca.partition
+		ast.SetFlag(stmt)
+		p, err := BuildPlan(stmt, nil)
+		c.Assert(err, IsNil, comment)
+		c.Assert(Refine(p), IsNil, comment)
+		for len(p.GetChildren()) > 0 {
+			p = p.GetChildByIndex(0)
+		}
+		switch x := p.(type) {
+		case *TableScan:
+			c.Assert(x.Partitions, DeepEquals, ca.ids, comment)
+		case *IndexScan:
+			c.Assert(x.Partitions, DeepEquals, ca.ids, comment)
+		default:
+			c.Fatalf("unexpected plan %s", ToString(p))
+		}
+	}
+}
+
 func (s *testPlanSuite) TestNullRejectFinder(c *C) {
 	defer testleak.AfterTest(c)()
 	cases := []struct {
@@ -555,6 +645,8 @@ func (b *mockJoinResolver) Leave(in ast.Node) (ast.Node, bool) {
 		}
 		b.refers[columnInfo] = refer
 		x.Refer = refer
+		// The result fields of the table are used by the assignments of UPDATE.
+		tn.SetResultFields(append(tn.GetResultFields(), refer))
 		if x.Name.Name.L == "id" {
 			columnInfo.Flag = mysql.PriKeyFlag
 		} else if x.Name.Name.L[0] == 'i' {
@@ -729,12 +821,36 @@ func (s *testPlanSuite) TestIndexHint(c *C) {
 			"select * from t1 use index (i1) ignore index (i1) where t1.i1 = 0",
 			"Table(t1)->Fields",
 		},
+		// The index hints of UPDATE and DELETE choose the access paths of their table scans.
+		{
+			"update t1 force index (i1) set t1.c1 = t1.c1 + 1 where t1.i1 > 0 and t1.i2 = 0",
+			"Index(t1.i1)->Update",
+		},
+		{
+			"update t1 ignore index (i1, i2) set t1.c1 = t1.c1 + 1 where t1.i1 = 0 and t1.i2 = 0",
+			"Table(t1)->Update",
+		},
+		{
+			"delete from t1 use index (i1) where t1.i1 > 0 and t1.i2 = 0",
+			"Index(t1.i1)->Delete",
+		},
+		{
+			"delete from t1 ignore index (i2) where t1.i1 > 0 and t1.i2 = 0",
+			"Index(t1.i1)->Delete",
+		},
+		{
+			"delete from t1 use index () where t1.i1 = 0",
+			"Table(t1)->Delete",
+		},
+		{
+			"delete t1 from t1 force index (i2), t2 where t1.i1 = 0 and t1.i2 > 0 and t2.c1 = t1.c1",
+			"InnerJoin{Index(t1.i2)->Table(t2)}->Delete",
+		},
 	}
 	for _, ca := range cases {
 		comment := Commentf("for %s", ca.sql)
-		s, err := parser.ParseOneStmt(ca.sql, "", "")
+		stmt, err := parser.ParseOneStmt(ca.sql, "", "")
 		c.Assert(err, IsNil, comment)
-		stmt := s.(*ast.SelectStmt)
 		mockJoinResolve(c, stmt)
 		ast.SetFlag(stmt)
 		p, err := BuildPlan(stmt, nil)
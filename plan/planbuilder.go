@@ -26,6 +26,8 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/plan/statistics"
+	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
@@ -62,6 +64,11 @@ type planBuilder struct {
 	ctx          context.Context
 	is           infoschema.InfoSchema
 	outerSchemas []expression.Schema
+	// tableHints are the usable optimizer hints of the statement being built.
+	tableHints []*ast.TableOptimizerHint
+	// joinHints are the tables of the join hints, the hints which aren't applied are reported by warnings.
+	joinHints        []hintTable
+	appliedJoinHints map[hintTable]bool
 }
 
 func (b *planBuilder) build(node ast.Node) Plan {
@@ -88,12 +95,16 @@ func (b *planBuilder) build(node ast.Node) Plan {
 		return b.buildDDL(x)
 	case *ast.DropTableStmt:
 		return b.buildDDL(x)
+	case *ast.RenameTableStmt:
+		return b.buildDDL(x)
 	case *ast.ExecuteStmt:
 		return &Execute{Name: x.Name, UsingVars: x.UsingVars}
 	case *ast.ExplainStmt:
 		return b.buildExplain(x)
 	case *ast.InsertStmt:
 		return b.buildInsert(x)
+	case *ast.KillStmt:
+		return b.buildSimple(x)
 	case *ast.PrepareStmt:
 		return b.buildPrepare(x)
 	case *ast.SelectStmt:
@@ -222,6 +233,9 @@ func (b *planBuilder) buildSubquery(n ast.Node) {
 func (b *planBuilder) buildSelect(sel *ast.SelectStmt) Plan {
 	var aggFuncs []*ast.AggregateFuncExpr
 	hasAgg := b.detectSelectAgg(sel)
+	oldHints := b.tableHints
+	b.tableHints = b.checkHints(sel, hasAgg)
+	defer func() { b.tableHints = oldHints }()
 	canPushLimit := !hasAgg
 	if hasAgg {
 		aggFuncs = b.extractSelectAgg(sel)
@@ -374,7 +388,7 @@ func (b *planBuilder) availableIndices(table *ast.TableName) (indices []*model.I
 			// Currently we don't distinguish between Force and Use because our cost estimation is not reliable.
 			hasUse = true
 			for _, idxName := range hint.IndexNames {
-				idx := findIndexByName(table.TableInfo.Indices, idxName)
+				idx := b.findHintIndex(table, idxName)
 				if idx != nil {
 					indices = append(indices, idx)
 				}
@@ -382,7 +396,7 @@ func (b *planBuilder) availableIndices(table *ast.TableName) (indices []*model.I
 		case ast.HintIgnore:
 			// Collect all the ignore index hints.
 			for _, idxName := range hint.IndexNames {
-				idx := findIndexByName(table.TableInfo.Indices, idxName)
+				idx := b.findHintIndex(table, idxName)
 				if idx != nil {
 					ignores = append(ignores, idx)
 				}
@@ -410,6 +424,16 @@ func (b *planBuilder) availableIndices(table *ast.TableName) (indices []*model.I
 	return indices, true
 }
 
+// findHintIndex finds the index in the index hint of the table, a warning is appended if it doesn't exist.
+// The integer primary key is the handle of the table, it's read by the table scan.
+func (b *planBuilder) findHintIndex(table *ast.TableName, name model.CIStr) *model.IndexInfo {
+	idx := findIndexByName(table.TableInfo.Indices, name)
+	if idx == nil && !(name.L == "primary" && table.TableInfo.PKIsHandle) {
+		b.appendWarning(ErrKeyDoesNotExist.Gen("Key '%s' doesn't exist in table '%s'", name.O, table.Name.O))
+	}
+	return idx
+}
+
 func removeIgnores(indices, ignores []*model.IndexInfo) []*model.IndexInfo {
 	if len(ignores) == 0 {
 		return indices
@@ -446,6 +470,18 @@ func getTableAsName(fields []*ast.ResultField) *model.CIStr {
 	return nil
 }
 
+// tableStats returns the statistics of the table, it returns nil if the table isn't analyzed.
+func (b *planBuilder) tableStats(tblInfo *model.TableInfo) *statistics.Table {
+	if b.ctx == nil {
+		return nil
+	}
+	do := sessionctx.GetDomain(b.ctx)
+	if do == nil || do.StatsHandle() == nil {
+		return nil
+	}
+	return do.StatsHandle().GetTableStats(tblInfo.ID)
+}
+
 func (b *planBuilder) buildTableScanPlan(path *joinPath) Plan {
 	tn := path.table
 	p := &TableScan{
@@ -454,6 +490,7 @@ func (b *planBuilder) buildTableScanPlan(path *joinPath) Plan {
 	}
 	// Equal condition contains a column from previous joined table.
 	p.RefAccess = len(path.eqConds) > 0
+	p.stats = b.tableStats(tn.TableInfo)
 	p.SetFields(tn.GetResultFields())
 	p.TableAsName = getTableAsName(p.Fields())
 	var pkName model.CIStr
@@ -483,6 +520,7 @@ func (b *planBuilder) buildIndexScanPlan(index *model.IndexInfo, path *joinPath)
 	tn := path.table
 	ip := &IndexScan{Table: tn.TableInfo, Index: index, TableName: tn}
 	ip.RefAccess = len(path.eqConds) > 0
+	ip.stats = b.tableStats(tn.TableInfo)
 	ip.SetFields(tn.GetResultFields())
 	ip.TableAsName = getTableAsName(ip.Fields())
 
@@ -586,7 +624,8 @@ func (b *planBuilder) buildSelectFields(src Plan, fields []*ast.ResultField) Pla
 func (b *planBuilder) buildAggregate(src Plan, aggFuncs []*ast.AggregateFuncExpr, groupby *ast.GroupByClause) Plan {
 	// Add aggregate plan.
 	aggPlan := &Aggregate{
-		AggFuncs: aggFuncs,
+		AggFuncs:     aggFuncs,
+		PushDownHint: aggPushDownHint(b.tableHints),
 	}
 	addChild(aggPlan, src)
 	if src != nil {
@@ -652,6 +691,12 @@ func (b *planBuilder) buildAdmin(as *ast.AdminStmt) Plan {
 	case ast.AdminShowDDL:
 		p = &ShowDDL{}
 		p.SetFields(buildShowDDLFields())
+	case ast.AdminShowDDLJobs:
+		p = &ShowDDLJobs{JobNumber: as.JobNumber}
+		p.SetFields(buildShowDDLJobsFields())
+	case ast.AdminCancelDDLJobs:
+		p = &CancelDDLJobs{JobIDs: as.JobIDs}
+		p.SetFields(buildCancelDDLJobsFields())
 	default:
 		b.err = ErrUnsupportedType.Gen("Unsupported type %T", as)
 	}
@@ -660,13 +705,30 @@ func (b *planBuilder) buildAdmin(as *ast.AdminStmt) Plan {
 }
 
 func buildShowDDLFields() []*ast.ResultField {
-	rfs := make([]*ast.ResultField, 0, 6)
+	rfs := make([]*ast.ResultField, 0, 7)
 	rfs = append(rfs, buildResultField("", "SCHEMA_VER", mysql.TypeLonglong, 4))
 	rfs = append(rfs, buildResultField("", "OWNER", mysql.TypeVarchar, 64))
 	rfs = append(rfs, buildResultField("", "JOB", mysql.TypeVarchar, 128))
 	rfs = append(rfs, buildResultField("", "BG_SCHEMA_VER", mysql.TypeLonglong, 4))
 	rfs = append(rfs, buildResultField("", "BG_OWNER", mysql.TypeVarchar, 64))
 	rfs = append(rfs, buildResultField("", "BG_JOB", mysql.TypeVarchar, 128))
+	rfs = append(rfs, buildResultField("", "REORG_PROGRESS", mysql.TypeVarchar, 64))
+
+	return rfs
+}
+
+func buildShowDDLJobsFields() []*ast.ResultField {
+	rfs := make([]*ast.ResultField, 0, 2)
+	rfs = append(rfs, buildResultField("", "JOBS", mysql.TypeVarchar, 128))
+	rfs = append(rfs, buildResultField("", "STATE", mysql.TypeVarchar, 64))
+
+	return rfs
+}
+
+func buildCancelDDLJobsFields() []*ast.ResultField {
+	rfs := make([]*ast.ResultField, 0, 2)
+	rfs = append(rfs, buildResultField("", "JOB_ID", mysql.TypeLonglong, 4))
+	rfs = append(rfs, buildResultField("", "RESULT", mysql.TypeVarchar, 128))
 
 	return rfs
 }
@@ -731,7 +793,8 @@ func pushOrder(p Plan, items []*ast.ByItem) bool {
 	case *Aggregate:
 		return false
 	case *IndexScan:
-		if len(items) > len(x.Index.Columns) {
+		// The rows of the partitions are read one partition after another.
+		if len(items) > len(x.Index.Columns) || x.Table.Partition != nil {
 			return false
 		}
 		var hasDesc bool
@@ -764,7 +827,7 @@ func pushOrder(p Plan, items []*ast.ByItem) bool {
 		x.Desc = hasDesc
 		return true
 	case *TableScan:
-		if len(items) != 1 || !x.Table.PKIsHandle {
+		if len(items) != 1 || !x.Table.PKIsHandle || x.Table.Partition != nil {
 			return false
 		}
 		var refer *ast.ResultField
@@ -910,6 +973,7 @@ func (b *planBuilder) buildDistinct(src Plan) Plan {
 }
 
 func (b *planBuilder) buildUpdate(update *ast.UpdateStmt) Plan {
+	b.tableHints = b.checkHints(update, false)
 	sel := &ast.SelectStmt{From: update.TableRefs, Where: update.Where, OrderBy: update.Order, Limit: update.Limit}
 	p := b.buildFrom(sel)
 	for _, v := range p.Fields() {
@@ -949,6 +1013,7 @@ func (b *planBuilder) buildUpdateLists(list []*ast.Assignment, fields []*ast.Res
 }
 
 func (b *planBuilder) buildDelete(del *ast.DeleteStmt) Plan {
+	b.tableHints = b.checkHints(del, false)
 	sel := &ast.SelectStmt{From: del.TableRefs, Where: del.Where, OrderBy: del.Order, Limit: del.Limit}
 	p := b.buildFrom(sel)
 	for _, v := range p.Fields() {
@@ -1085,13 +1150,24 @@ func (b *planBuilder) buildExplain(explain *ast.ExplainStmt) Plan {
 	if show, ok := explain.Stmt.(*ast.ShowStmt); ok {
 		return b.buildShow(show)
 	}
+	if explain.Analyze && explain.Format != ast.ExplainFormatRow && explain.Format != ast.ExplainFormatJSON {
+		b.err = ErrUnknownExplainFormat.Gen("Unknown EXPLAIN format name: '%s'", explain.Format)
+		return nil
+	}
 	targetPlan := b.build(explain.Stmt)
 	if b.err != nil {
 		return nil
 	}
-	p := &Explain{StmtPlan: targetPlan}
+	p := &Explain{StmtPlan: targetPlan, Analyze: explain.Analyze, Format: explain.Format}
 	addChild(p, targetPlan)
-	p.SetFields(buildExplainFields())
+	switch {
+	case !explain.Analyze:
+		p.SetFields(buildExplainFields())
+	case explain.Format == ast.ExplainFormatJSON:
+		p.SetFields([]*ast.ResultField{buildResultField("", "EXPLAIN", mysql.TypeBlob, 65535)})
+	default:
+		p.SetFields(buildExplainAnalyzeFields())
+	}
 	return p
 }
 
@@ -1110,3 +1186,16 @@ func buildExplainFields() []*ast.ResultField {
 	rfs = append(rfs, buildResultField("", "Extra", mysql.TypeVarchar, 128))
 	return rfs
 }
+
+// buildExplainAnalyzeFields builds the fields of EXPLAIN ANALYZE in the row format, every row is an executor.
+func buildExplainAnalyzeFields() []*ast.ResultField {
+	rfs := make([]*ast.ResultField, 0, 7)
+	rfs = append(rfs, buildResultField("", "id", mysql.TypeVarchar, 128))
+	rfs = append(rfs, buildResultField("", "estimated_rows", mysql.TypeDouble, 22))
+	rfs = append(rfs, buildResultField("", "actual_rows", mysql.TypeLonglong, 21))
+	rfs = append(rfs, buildResultField("", "loops", mysql.TypeLonglong, 21))
+	rfs = append(rfs, buildResultField("", "time", mysql.TypeVarchar, 64))
+	rfs = append(rfs, buildResultField("", "cop_tasks", mysql.TypeLonglong, 21))
+	rfs = append(rfs, buildResultField("", "cop_retries", mysql.TypeLonglong, 21))
+	return rfs
+}
@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/plan/statistics"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -59,6 +60,12 @@ type TableScan struct {
 	TableAsName *model.CIStr
 
 	LimitCount *int64
+
+	// Partitions are the IDs of the partitions to read if the table is partitioned, nil means all the partitions.
+	Partitions []int64
+
+	// stats is the statistics of the table used to estimate the row count, it's nil if the table isn't analyzed.
+	stats *statistics.Table
 }
 
 // ShowDDL is for showing DDL information.
@@ -66,6 +73,20 @@ type ShowDDL struct {
 	basePlan
 }
 
+// ShowDDLJobs is for showing the DDL jobs in the queue and the recent history DDL jobs.
+type ShowDDLJobs struct {
+	basePlan
+
+	JobNumber int64
+}
+
+// CancelDDLJobs is for cancelling DDL jobs.
+type CancelDDLJobs struct {
+	basePlan
+
+	JobIDs []int64
+}
+
 // CheckTable is for checking table data.
 type CheckTable struct {
 	basePlan
@@ -147,6 +168,12 @@ type IndexScan struct {
 	TableAsName *model.CIStr
 
 	LimitCount *int64
+
+	// Partitions are the IDs of the partitions to read if the table is partitioned, nil means all the partitions.
+	Partitions []int64
+
+	// stats is the statistics of the table used to estimate the row count, it's nil if the table isn't analyzed.
+	stats *statistics.Table
 }
 
 // JoinOuter represents outer join plan.
@@ -276,6 +303,8 @@ type Aggregate struct {
 	basePlan
 	AggFuncs     []*ast.AggregateFuncExpr
 	GroupByItems []*ast.ByItem
+	// PushDownHint is AggToCop or NoAggToCop if the aggregation has the hint.
+	PushDownHint string
 }
 
 // SetLimit implements Plan SetLimit interface.
@@ -388,4 +417,8 @@ type Explain struct {
 	basePlan
 
 	StmtPlan Plan
+	// Analyze is true for EXPLAIN ANALYZE, the statement is executed to collect the runtime statistics.
+	Analyze bool
+	// Format is the output format of EXPLAIN ANALYZE.
+	Format string
 }
@@ -41,6 +41,17 @@ func columnSubstitute(expr expression.Expression, schema expression.Schema, newE
 	return expr
 }
 
+// columnsInSchema checks whether all the columns of expr are in the schema.
+func columnsInSchema(expr expression.Expression, schema expression.Schema) bool {
+	cols, _ := extractColumn(expr, nil, nil)
+	for _, col := range cols {
+		if schema.GetIndex(col) == -1 {
+			return false
+		}
+	}
+	return true
+}
+
 // predicatePushDown applies predicate push down to all kinds of plans, except aggregation and union.
 func (b *planBuilder) predicatePushDown(p Plan, predicates []expression.Expression) (ret []expression.Expression, err error) {
 	switch v := p.(type) {
@@ -80,6 +91,21 @@ func (b *planBuilder) predicatePushDown(p Plan, predicates []expression.Expressi
 			rightCond = rightPushCond
 			ret = append(expression.ScalarFuncs2Exprs(equalCond), otherCond...)
 			ret = append(ret, leftPushCond...)
+		} else if v.JoinType == SemiJoin || v.JoinType == AntiSemiJoin {
+			// The schema of semi join is the schema of the left child plus the aux column, the predicates on the
+			// aux column stay above the join. The left conditions of anti semi join or semi join with aux decide
+			// whether the rows match, so they can't filter the left child.
+			for _, cond := range predicates {
+				if columnsInSchema(cond, leftPlan.GetSchema()) {
+					leftCond = append(leftCond, cond)
+				} else {
+					ret = append(ret, cond)
+				}
+			}
+			if v.JoinType == SemiJoin && !v.WithAux {
+				leftCond = append(v.LeftConditions, leftCond...)
+			}
+			rightCond = v.RightConditions
 		} else {
 			leftCond = append(v.LeftConditions, leftPushCond...)
 			rightCond = append(v.RightConditions, rightPushCond...)
@@ -107,6 +133,14 @@ func (b *planBuilder) predicatePushDown(p Plan, predicates []expression.Expressi
 		if v.JoinType == InnerJoin {
 			v.EqualConditions = append(v.EqualConditions, equalCond...)
 			v.OtherConditions = append(v.OtherConditions, otherCond...)
+			// The left and right conditions have been pushed down to the children.
+			v.LeftConditions = nil
+			v.RightConditions = nil
+		} else if v.JoinType == SemiJoin || v.JoinType == AntiSemiJoin {
+			if v.JoinType == SemiJoin && !v.WithAux {
+				v.LeftConditions = nil
+			}
+			v.RightConditions = nil
 		}
 		return
 	case *Projection:
@@ -40,12 +40,16 @@ func refine(in Plan) error {
 
 	var err error
 	switch x := in.(type) {
+	case *Apply:
+		err = refine(x.InnerPlan)
 	case *IndexScan:
 		err = buildIndexRange(x)
+		x.Partitions = prunePartitions(x.Table, x.AccessConditions, x.FilterConditions)
 	case *Limit:
 		x.SetLimit(0)
 	case *TableScan:
 		err = buildTableRange(x)
+		x.Partitions = prunePartitions(x.Table, x.AccessConditions, x.FilterConditions)
 	case *NewTableScan:
 		x.Ranges = []TableRange{{math.MinInt64, math.MaxInt64}}
 	case *Selection:
@@ -97,12 +101,158 @@ func buildTableRange(p *TableScan) error {
 	return errors.Trace(rb.err)
 }
 
+// prunePartitions returns the IDs of the partitions which may have the rows satisfying the conditions, it returns
+// nil if the table isn't partitioned or the partitions can't be pruned by the conditions on the partition column.
+func prunePartitions(table *model.TableInfo, accessConditions, filterConditions []ast.ExprNode) []int64 {
+	pi := table.Partition
+	if pi == nil {
+		return nil
+	}
+	for _, col := range table.Columns {
+		if col.Name.L == pi.Column.L && mysql.HasUnsignedFlag(col.Flag) {
+			// The values of the unsigned column may be out of the range of the table ranges.
+			return nil
+		}
+	}
+	checker := conditionChecker{tableName: table.Name, pkName: pi.Column}
+	rb := rangeBuilder{}
+	rangePoints := fullRange
+	var pruned bool
+	for _, conditions := range [][]ast.ExprNode{accessConditions, filterConditions} {
+		for _, cond := range conditions {
+			if checker.check(cond) {
+				rangePoints = rb.intersection(rangePoints, rb.build(cond))
+				pruned = true
+			}
+		}
+	}
+	if !pruned {
+		return nil
+	}
+	if pi.Func != "" {
+		var err error
+		rangePoints, err = partitionFuncPoints(pi, rangePoints)
+		if err != nil {
+			return nil
+		}
+	}
+	ranges := rb.buildTableRanges(rangePoints)
+	if rb.err != nil {
+		// The conditions whose values can't be converted to integers don't prune the partitions.
+		return nil
+	}
+	selected := make([]bool, len(pi.Definitions))
+	if len(rangePoints) > 0 && rangePoints[0].value.IsNull() {
+		// The NULL values are in the first partition.
+		selected[0] = true
+	}
+	for _, r := range ranges {
+		if pi.Type == model.PartitionTypeHash {
+			selectHashPartitions(pi, r, selected)
+		} else {
+			selectRangePartitions(pi, r, selected)
+		}
+	}
+	ids := make([]int64, 0, len(selected))
+	for i, ok := range selected {
+		if ok {
+			ids = append(ids, pi.Definitions[i].ID)
+		}
+	}
+	return ids
+}
+
+// partitionFuncPoints maps the range points of the partition column to the range points of the partition expression.
+// The partition functions are monotonic but not strictly, so the exclusive points become inclusive, except the
+// exclusive end point at the first value mapped to its function value, e.g. `d < '2017-01-01'` for TO_DAYS(d).
+// A zero date is mapped to NULL by TO_DAYS, it's the smallest date, so it can only be the first point.
+func partitionFuncPoints(pi *model.PartitionInfo, points []rangePoint) ([]rangePoint, error) {
+	funcPoints := make([]rangePoint, 0, len(points))
+	for _, point := range points {
+		switch point.value.Kind() {
+		case types.KindNull, types.KindMinNotNull, types.KindMaxValue:
+		default:
+			v, err := pi.Eval(point.value)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if point.excl && !point.start {
+				point.excl, err = isPartitionFuncBoundary(pi, point.value)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+			} else {
+				point.excl = false
+			}
+			point.value = v
+		}
+		funcPoints = append(funcPoints, point)
+	}
+	return funcPoints, nil
+}
+
+// isPartitionFuncBoundary checks if the date is the first value mapped to its value of the partition function, the
+// smaller dates are mapped to the smaller values.
+func isPartitionFuncBoundary(pi *model.PartitionInfo, d types.Datum) (bool, error) {
+	v, err := d.ConvertTo(types.NewFieldType(mysql.TypeDatetime))
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	t := v.GetMysqlTime()
+	if t.IsZero() || t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0 || t.Nanosecond() != 0 {
+		return false, nil
+	}
+	if pi.Func == model.PartitionFuncYear {
+		return t.Month() == 1 && t.Day() == 1, nil
+	}
+	return true, nil
+}
+
+// selectRangePartitions selects the RANGE partitions which overlap the range.
+func selectRangePartitions(pi *model.PartitionInfo, r TableRange, selected []bool) {
+	for i, def := range pi.Definitions {
+		if def.LessThan != nil && r.LowVal >= *def.LessThan {
+			continue
+		}
+		if i > 0 && r.HighVal < *pi.Definitions[i-1].LessThan {
+			continue
+		}
+		selected[i] = true
+	}
+}
+
+// selectHashPartitions selects the HASH partitions of the values in the range, all the partitions are selected if
+// there are more values than the partitions.
+func selectHashPartitions(pi *model.PartitionInfo, r TableRange, selected []bool) {
+	n := uint64(r.HighVal) - uint64(r.LowVal)
+	if n >= uint64(len(pi.Definitions)-1) {
+		for i := range selected {
+			selected[i] = true
+		}
+		return
+	}
+	for d := uint64(0); d <= n; d++ {
+		i, err := pi.LocatePartition(types.NewIntDatum(r.LowVal + int64(d)))
+		if err != nil || i < 0 {
+			for j := range selected {
+				selected[j] = true
+			}
+			return
+		}
+		selected[i] = true
+	}
+}
+
 func buildSelection(p *Selection) error {
 	var err error
 	var accessConditions []expression.Expression
 	switch p.GetChildByIndex(0).(type) {
 	case *NewTableScan:
 		tableScan := p.GetChildByIndex(0).(*NewTableScan)
+		if tableScan.Index != nil {
+			// The table is read by the index, the table ranges don't work.
+			return buildNewIndexRange(tableScan, p.Conditions)
+		}
 		accessConditions, p.Conditions = detachConditions(p.Conditions, tableScan.Table, nil, 0)
 		err = buildNewTableRange(tableScan, accessConditions)
 		// TODO: Implement NewIndexScan
@@ -160,6 +310,48 @@ func buildNewTableRange(p *NewTableScan, accessConditions []expression.Expressio
 	return errors.Trace(rb.err)
 }
 
+// buildNewIndexRange builds the ranges of the first index column for the table scan which reads the table
+// by the index, all the conditions are still evaluated as the filters.
+func buildNewIndexRange(p *NewTableScan, conditions []expression.Expression) error {
+	accessConditions := indexAccessConditions(p, p.Index, conditions)
+	if len(accessConditions) == 0 {
+		return nil
+	}
+	rb := rangeBuilder{}
+	rangePoints := fullRange
+	for _, cond := range accessConditions {
+		rangePoints = rb.intersection(rangePoints, rb.newBuild(cond))
+		if rb.err != nil {
+			return errors.Trace(rb.err)
+		}
+	}
+	p.IndexRanges = rb.buildIndexRanges(rangePoints)
+	return errors.Trace(rb.err)
+}
+
+// indexAccessConditions returns the conditions which compare the first column of the index with constants.
+func indexAccessConditions(p *NewTableScan, idx *model.IndexInfo, conditions []expression.Expression) []expression.Expression {
+	if idx.Columns[0].Length != types.UnspecifiedLength {
+		// The prefix index doesn't keep the order of the column values.
+		return nil
+	}
+	checker := conditionChecker{tableName: p.tableName(), idx: idx}
+	var accessConditions []expression.Expression
+	for _, cond := range conditions {
+		f, ok := cond.(*expression.ScalarFunction)
+		if !ok {
+			continue
+		}
+		switch f.FuncName.L {
+		case ast.EQ, ast.NE, ast.GE, ast.GT, ast.LE, ast.LT:
+			if checker.checkScalarFunction(f) {
+				accessConditions = append(accessConditions, cond)
+			}
+		}
+	}
+	return accessConditions
+}
+
 // conditionChecker checks if this condition can be pushed to index plan.
 type conditionChecker struct {
 	tableName model.CIStr
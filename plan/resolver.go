@@ -103,7 +103,7 @@ type resolverContext struct {
 	useOuterContext bool
 	// When visiting multi-table delete stmt table list.
 	inDeleteTableList bool
-	// When visiting create/drop table statement.
+	// When visiting create/drop/rename table statement.
 	inCreateOrDropTable bool
 	// When visiting show statement.
 	inShow bool
@@ -155,6 +155,11 @@ func (nr *nameResolver) Enter(inNode ast.Node) (outNode ast.Node, skipChildren b
 		}
 	case *ast.AlterTableStmt:
 		nr.pushContext()
+	case *ast.AlterTableSpec:
+		if v.Tp == ast.AlterTableRenameTable {
+			// The new table of the rename doesn't exist.
+			nr.currentContext().inCreateOrDropTable = true
+		}
 	case *ast.AnalyzeTableStmt:
 		nr.pushContext()
 	case *ast.ByItem:
@@ -184,6 +189,9 @@ func (nr *nameResolver) Enter(inNode ast.Node) (outNode ast.Node, skipChildren b
 	case *ast.DropTableStmt:
 		nr.pushContext()
 		nr.currentContext().inCreateOrDropTable = true
+	case *ast.RenameTableStmt:
+		nr.pushContext()
+		nr.currentContext().inCreateOrDropTable = true
 	case *ast.DropIndexStmt:
 		nr.pushContext()
 	case *ast.FieldList:
@@ -238,6 +246,8 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 		}
 	case *ast.AlterTableStmt:
 		nr.popContext()
+	case *ast.AlterTableSpec:
+		nr.currentContext().inCreateOrDropTable = false
 	case *ast.AnalyzeTableStmt:
 		nr.popContext()
 	case *ast.TableName:
@@ -256,6 +266,8 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 		nr.popContext()
 	case *ast.DropTableStmt:
 		nr.popContext()
+	case *ast.RenameTableStmt:
+		nr.popContext()
 	case *ast.TableSource:
 		nr.handleTableSource(v)
 	case *ast.OnCondition:
@@ -902,6 +914,13 @@ func (nr *nameResolver) fillShowFields(s *ast.ShowStmt) {
 	case ast.ShowProcedureStatus:
 		names = []string{}
 		ftypes = []byte{}
+	case ast.ShowProcessList:
+		names = []string{"Id", "User", "Host", "db", "Command", "Time", "State", "Info"}
+		ftypes = []byte{mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeVarchar,
+			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeVarchar}
+	case ast.ShowStatsMeta:
+		names = []string{"Db_name", "Table_name", "Update_time", "Modify_count", "Row_count"}
+		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeDatetime, mysql.TypeLonglong, mysql.TypeLonglong}
 	case ast.ShowIndex:
 		names = []string{"Table", "Non_unique", "Key_name", "Seq_in_index",
 			"Column_name", "Collation", "Cardinality", "Sub_part", "Packed",
@@ -0,0 +1,159 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// ColumnByID returns the statistics of the column, it returns nil if the column has no statistics.
+func (t *Table) ColumnByID(id int64) *Column {
+	for _, col := range t.Columns {
+		if col != nil && col.ID == id {
+			return col
+		}
+	}
+	return nil
+}
+
+// IndexByID returns the statistics of the index, it returns nil if the index has no statistics.
+func (t *Table) IndexByID(id int64) *Column {
+	for _, idx := range t.Indices {
+		if idx != nil && idx.ID == id {
+			return idx
+		}
+	}
+	return nil
+}
+
+// TotalRowCount returns the number of the non-null values.
+func (c *Column) TotalRowCount() int64 {
+	if len(c.Numbers) == 0 {
+		return 0
+	}
+	return c.Numbers[len(c.Numbers)-1]
+}
+
+// lowerBound returns the index of the first bucket whose value is not less than value,
+// and whether the bucket value equals to value.
+func (c *Column) lowerBound(value types.Datum) (int, bool, error) {
+	var err error
+	index := sort.Search(len(c.Values), func(i int) bool {
+		cmp, err1 := c.Values[i].CompareDatum(value)
+		if err1 != nil {
+			err = err1
+			return false
+		}
+		return cmp >= 0
+	})
+	if err != nil {
+		return 0, false, errors.Trace(err)
+	}
+	if index == len(c.Values) {
+		return index, false, nil
+	}
+	cmp, err := c.Values[index].CompareDatum(value)
+	if err != nil {
+		return 0, false, errors.Trace(err)
+	}
+	return index, cmp == 0, nil
+}
+
+// EqualRowCount estimates the number of the rows whose value equals to value.
+// The value which isn't a bucket value is assumed to match the average number of rows,
+// no matter whether it's out of the histogram range, as the histogram may be outdated.
+func (c *Column) EqualRowCount(value types.Datum) (int64, error) {
+	if value.IsNull() {
+		return c.NullCount, nil
+	}
+	if len(c.Numbers) == 0 {
+		return 0, nil
+	}
+	index, match, err := c.lowerBound(value)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if match {
+		return c.Repeats[index], nil
+	}
+	return c.avgRowCount(), nil
+}
+
+// LessRowCount estimates the number of the non-null rows whose value is less than value.
+// The rows in the bucket where the value falls are assumed to be half less than it.
+func (c *Column) LessRowCount(value types.Datum) (int64, error) {
+	switch value.Kind() {
+	case types.KindNull, types.KindMinNotNull:
+		return 0, nil
+	case types.KindMaxValue:
+		return c.TotalRowCount(), nil
+	}
+	if len(c.Numbers) == 0 {
+		return 0, nil
+	}
+	index, match, err := c.lowerBound(value)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if index == len(c.Numbers) {
+		return c.TotalRowCount(), nil
+	}
+	if match {
+		return c.Numbers[index] - c.Repeats[index], nil
+	}
+	var prevCount int64
+	if index > 0 {
+		prevCount = c.Numbers[index-1]
+	}
+	return (prevCount + c.Numbers[index] - c.Repeats[index]) / 2, nil
+}
+
+// BetweenRowCount estimates the number of the rows whose value is in [low, high).
+func (c *Column) BetweenRowCount(low, high types.Datum) (int64, error) {
+	lessLow, err := c.LessRowCount(low)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	lessHigh, err := c.LessRowCount(high)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if lessHigh > lessLow {
+		return lessHigh - lessLow, nil
+	}
+	// Both values fall in the same bucket or out of the histogram range, we can't tell the difference.
+	cmp, err := low.CompareDatum(high)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if cmp >= 0 {
+		return 0, nil
+	}
+	return c.avgRowCount(), nil
+}
+
+// avgRowCount returns the average number of the rows of a distinct value.
+func (c *Column) avgRowCount() int64 {
+	if c.NDV <= 0 {
+		return 0
+	}
+	count := c.TotalRowCount() / c.NDV
+	if count == 0 {
+		count = 1
+	}
+	return count
+}
@@ -0,0 +1,393 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// Handle loads the table statistics from the system tables and caches them,
+// the cache is shared by all the sessions of a domain.
+type Handle struct {
+	// ctx is used to read the statistics, it must not be used by others.
+	ctx context.Context
+
+	// mu serializes Update and protects versions, which is the versions of the loaded statistics.
+	mu       sync.Mutex
+	versions map[int64]uint64
+
+	// cacheMu serializes the writers of statsCache.
+	cacheMu    sync.Mutex
+	statsCache atomic.Value // map[int64]*Table
+
+	// deltaMu protects deltaMap, which is the row count changes of the committed transactions
+	// that are not dumped to the stats_meta table yet.
+	deltaMu  sync.Mutex
+	deltaMap map[int64]variable.TableDelta
+}
+
+// NewHandle creates a Handle which reads the statistics with ctx.
+func NewHandle(ctx context.Context) *Handle {
+	h := &Handle{
+		ctx:      ctx,
+		versions: make(map[int64]uint64),
+		deltaMap: make(map[int64]variable.TableDelta),
+	}
+	h.statsCache.Store(make(map[int64]*Table))
+	return h
+}
+
+// Update loads the statistics whose version differs from the loaded one. The version is the start TS of
+// the ANALYZE transaction, which may commit after the statistics of a larger version are loaded,
+// so the versions of all the tables are checked instead of the ones larger than the last loaded version.
+func (h *Handle) Update(is infoschema.InfoSchema) error {
+	if !is.TableExists(model.NewCIStr(mysql.SystemDB), model.NewCIStr(mysql.StatsHistogramsTable)) {
+		// The store is bootstrapped by an old version without the statistics tables.
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sql := fmt.Sprintf("SELECT table_id, version FROM %s.%s", mysql.SystemDB, mysql.StatsHistogramsTable)
+	rows, err := h.readRows(sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		tableID, version := row[0].GetInt64(), row[1].GetUint64()
+		if h.versions[tableID] == version {
+			continue
+		}
+		if _, ok := is.TableByID(tableID); !ok {
+			// The table is dropped.
+			continue
+		}
+		ids = append(ids, strconv.FormatInt(tableID, 10))
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	sql = fmt.Sprintf("SELECT table_id, version, data FROM %s.%s WHERE table_id IN (%s)",
+		mysql.SystemDB, mysql.StatsHistogramsTable, strings.Join(ids, ", "))
+	rows, err = h.readRows(sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	tables := make([]*Table, 0, len(rows))
+	for _, row := range rows {
+		tableID, version, data := row[0].GetInt64(), row[1].GetUint64(), row[2].GetBytes()
+		// The broken statistics are not loaded again until they are updated.
+		h.versions[tableID] = version
+		table, ok := is.TableByID(tableID)
+		if !ok {
+			continue
+		}
+		tpb := &TablePB{}
+		if err = proto.Unmarshal(data, tpb); err != nil {
+			log.Errorf("[stats] decode statistics of table %d error: %v", tableID, err)
+			continue
+		}
+		tbl, err := TableFromPB(table.Meta(), tpb)
+		if err != nil {
+			log.Errorf("[stats] load statistics of table %d error: %v", tableID, errors.ErrorStack(err))
+			continue
+		}
+		tables = append(tables, tbl)
+	}
+	h.UpdateTableStats(tables...)
+	return nil
+}
+
+// readRows executes the restricted SQL and reads all the rows in a new transaction.
+func (h *Handle) readRows(sql string) ([][]types.Datum, error) {
+	rs, err := h.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(h.ctx, sql)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var rows [][]types.Datum
+	for {
+		row, err := rs.Next()
+		if err != nil {
+			rs.Close()
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		rows = append(rows, row.Data)
+	}
+	if err = rs.Close(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	// Finish the transaction, so the next read can see the new statistics.
+	return rows, errors.Trace(h.ctx.CommitTxn())
+}
+
+// GetTableStats returns the statistics of the table, it returns nil if the table isn't analyzed.
+func (h *Handle) GetTableStats(tableID int64) *Table {
+	return h.statsCache.Load().(map[int64]*Table)[tableID]
+}
+
+// UpdateTableStats puts the table statistics into the cache, the older ones are ignored.
+func (h *Handle) UpdateTableStats(tables ...*Table) {
+	if len(tables) == 0 {
+		return
+	}
+	h.cacheMu.Lock()
+	defer h.cacheMu.Unlock()
+	oldCache := h.statsCache.Load().(map[int64]*Table)
+	newCache := make(map[int64]*Table, len(oldCache)+len(tables))
+	for id, tbl := range oldCache {
+		newCache[id] = tbl
+	}
+	for _, tbl := range tables {
+		if old, ok := newCache[tbl.info.ID]; ok && old.TS > tbl.TS {
+			continue
+		}
+		newCache[tbl.info.ID] = tbl
+	}
+	h.statsCache.Store(newCache)
+}
+
+// SaveToStorage saves the table statistics to the system tables in the transaction of ctx,
+// the TS of the statistics is used as the version.
+func SaveToStorage(ctx context.Context, t *Table) error {
+	tpb, err := t.ToPB()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	data, err := proto.Marshal(tpb)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	exec := ctx.(sqlexec.RestrictedSQLExecutor)
+	sql := fmt.Sprintf("REPLACE INTO %s.%s (table_id, version, data) VALUES (%d, %d, X'%x')",
+		mysql.SystemDB, mysql.StatsHistogramsTable, t.info.ID, t.TS, data)
+	if _, err = exec.ExecRestrictedSQL(ctx, sql); err != nil {
+		return errors.Trace(err)
+	}
+	sql = fmt.Sprintf("REPLACE INTO %s.%s (table_id, version, count, modify_count) VALUES (%d, %d, %d, 0)",
+		mysql.SystemDB, mysql.StatsMetaTable, t.info.ID, t.TS, t.Count)
+	_, err = exec.ExecRestrictedSQL(ctx, sql)
+	return errors.Trace(err)
+}
+
+// UpdateDelta merges the row count changes of a committed transaction, they are written to
+// the stats_meta table by DumpStatsDeltaToKV.
+func (h *Handle) UpdateDelta(deltaMap map[int64]variable.TableDelta) {
+	if len(deltaMap) == 0 {
+		return
+	}
+	h.deltaMu.Lock()
+	defer h.deltaMu.Unlock()
+	for id, delta := range deltaMap {
+		item := h.deltaMap[id]
+		item.Delta += delta.Delta
+		item.Count += delta.Count
+		h.deltaMap[id] = item
+	}
+}
+
+// DumpStatsDeltaToKV writes the row count changes to the stats_meta table, the changes which
+// fail to be written are kept for the next dump, and the first error is returned.
+func (h *Handle) DumpStatsDeltaToKV() error {
+	h.deltaMu.Lock()
+	deltaMap := h.deltaMap
+	h.deltaMap = make(map[int64]variable.TableDelta)
+	h.deltaMu.Unlock()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var firstErr error
+	for id, delta := range deltaMap {
+		err := h.dumpTableDelta(id, delta)
+		if err != nil {
+			h.UpdateDelta(map[int64]variable.TableDelta{id: delta})
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return errors.Trace(firstErr)
+}
+
+func (h *Handle) dumpTableDelta(id int64, delta variable.TableDelta) error {
+	err := h.updateTableMeta(id, delta)
+	if err != nil {
+		h.ctx.RollbackTxn()
+		return errors.Trace(err)
+	}
+	return errors.Trace(h.commitTxn())
+}
+
+func (h *Handle) updateTableMeta(id int64, delta variable.TableDelta) error {
+	txn, err := h.ctx.GetTxn(false)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	exec := h.ctx.(sqlexec.RestrictedSQLExecutor)
+	sql := fmt.Sprintf("SELECT count, modify_count FROM %s.%s WHERE table_id = %d",
+		mysql.SystemDB, mysql.StatsMetaTable, id)
+	rs, err := exec.ExecRestrictedSQL(h.ctx, sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	row, err := rs.Next()
+	if err != nil {
+		rs.Close()
+		return errors.Trace(err)
+	}
+	count, modifyCount := delta.Delta, delta.Count
+	if row != nil {
+		count += row.Data[0].GetInt64()
+		modifyCount += row.Data[1].GetInt64()
+	}
+	if err = rs.Close(); err != nil {
+		return errors.Trace(err)
+	}
+	if count < 0 {
+		// The rows inserted before the changes are tracked are deleted.
+		count = 0
+	}
+	sql = fmt.Sprintf("REPLACE INTO %s.%s (table_id, version, count, modify_count) VALUES (%d, %d, %d, %d)",
+		mysql.SystemDB, mysql.StatsMetaTable, id, txn.StartTS(), count, modifyCount)
+	_, err = exec.ExecRestrictedSQL(h.ctx, sql)
+	return errors.Trace(err)
+}
+
+// commitTxn commits the transaction of the handle, the changes made by the handle to the
+// statistics tables are not counted as the table modifications.
+func (h *Handle) commitTxn() error {
+	variable.GetSessionVars(h.ctx).TableDeltaMap = nil
+	return errors.Trace(h.ctx.CommitTxn())
+}
+
+// Meta is a row of the stats_meta table.
+type Meta struct {
+	TableID     int64
+	Version     uint64
+	Count       int64
+	ModifyCount int64
+}
+
+// LoadMetas reads the stats_meta table with ctx.
+func LoadMetas(ctx context.Context) ([]Meta, error) {
+	sql := fmt.Sprintf("SELECT table_id, version, count, modify_count FROM %s.%s ORDER BY table_id",
+		mysql.SystemDB, mysql.StatsMetaTable)
+	rs, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer rs.Close()
+	var metas []Meta
+	for {
+		row, err := rs.Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		metas = append(metas, Meta{
+			TableID:     row.Data[0].GetInt64(),
+			Version:     row.Data[1].GetUint64(),
+			Count:       row.Data[2].GetInt64(),
+			ModifyCount: row.Data[3].GetInt64(),
+		})
+	}
+	return metas, nil
+}
+
+// autoAnalyzeRatio returns the value of tidb_auto_analyze_ratio system variable.
+func (h *Handle) autoAnalyzeRatio() (float64, error) {
+	value, err := variable.GetGlobalVarAccessor(h.ctx).GetGlobalSysVar(h.ctx, variable.TiDBAutoAnalyzeRatio)
+	if terror.ErrorEqual(err, variable.UnknownSystemVar) {
+		// The store is bootstrapped by an old version without the variable.
+		value = variable.GetSysVar(variable.TiDBAutoAnalyzeRatio).Value
+	} else if err != nil {
+		return 0, errors.Trace(err)
+	}
+	ratio, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid %s %s", variable.TiDBAutoAnalyzeRatio, value)
+	}
+	return ratio, nil
+}
+
+// HandleAutoAnalyze analyzes the tables whose modify count exceeds tidb_auto_analyze_ratio of the row count,
+// only the tables which have been analyzed are checked. A ratio of 0 disables the auto analyze.
+func (h *Handle) HandleAutoAnalyze(is infoschema.InfoSchema) error {
+	if !is.TableExists(model.NewCIStr(mysql.SystemDB), model.NewCIStr(mysql.StatsMetaTable)) {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ratio, err := h.autoAnalyzeRatio()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if ratio <= 0 {
+		return nil
+	}
+	metas, err := LoadMetas(h.ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err = h.ctx.CommitTxn(); err != nil {
+		return errors.Trace(err)
+	}
+	dbNames := make(map[int64]string)
+	for _, db := range is.AllSchemas() {
+		for _, tbl := range db.Tables {
+			dbNames[tbl.ID] = db.Name.O
+		}
+	}
+	for _, meta := range metas {
+		if h.GetTableStats(meta.TableID) == nil || meta.ModifyCount == 0 ||
+			float64(meta.ModifyCount) <= ratio*float64(meta.Count) {
+			continue
+		}
+		tbl, ok := is.TableByID(meta.TableID)
+		if !ok {
+			continue
+		}
+		sql := fmt.Sprintf("ANALYZE TABLE `%s`.`%s`", dbNames[meta.TableID], tbl.Meta().Name.O)
+		log.Infof("[stats] auto analyze table %s.%s, modify count %d, row count %d",
+			dbNames[meta.TableID], tbl.Meta().Name.O, meta.ModifyCount, meta.Count)
+		if _, err = h.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(h.ctx, sql); err != nil {
+			h.ctx.RollbackTxn()
+			return errors.Trace(err)
+		}
+		if err = h.commitTxn(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
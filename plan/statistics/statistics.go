@@ -15,7 +15,9 @@ package statistics
 
 import (
 	"fmt"
+	"math/rand"
 	"strings"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/juju/errors"
@@ -25,10 +27,12 @@ import (
 	"github.com/pingcap/tidb/xapi/tablecodec"
 )
 
-// Column represents statistics for a column.
+// Column represents statistics for a column or an index. The values of an index are
+// the index column values encoded by codec.EncodeKey.
 type Column struct {
-	ID  int64 // Column ID.
-	NDV int64 // Number of distinct values.
+	ID        int64 // Column ID or index ID.
+	NDV       int64 // Number of distinct values.
+	NullCount int64 // Number of null values, the null values are not in the histogram.
 
 	// Histogram elements.
 	//
@@ -49,7 +53,7 @@ type Column struct {
 
 func (c *Column) String() string {
 	strs := make([]string, 0, len(c.Numbers)+1)
-	strs = append(strs, fmt.Sprintf("column:%d ndv:%d", c.ID, c.NDV))
+	strs = append(strs, fmt.Sprintf("column:%d ndv:%d nulls:%d", c.ID, c.NDV, c.NullCount))
 	for i := range c.Numbers {
 		strVal, _ := c.Values[i].ToString()
 		strs = append(strs, fmt.Sprintf("num: %d\tvalue: %s\trepeats: %d", c.Numbers[i], strVal, c.Repeats[i]))
@@ -57,99 +61,177 @@ func (c *Column) String() string {
 	return strings.Join(strs, "\n")
 }
 
+func (c *Column) toPB() (*ColumnPB, error) {
+	data, err := codec.EncodeValue(nil, c.Values...)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &ColumnPB{
+		Id:        proto.Int64(c.ID),
+		Ndv:       proto.Int64(c.NDV),
+		NullCount: proto.Int64(c.NullCount),
+		Numbers:   c.Numbers,
+		Value:     data,
+		Repeats:   c.Repeats,
+	}, nil
+}
+
+// columnFromPB creates a column statistics from protobuffer, ft is the field type of the column values,
+// it's nil for an index.
+func columnFromPB(cpb *ColumnPB, ft *types.FieldType) (*Column, error) {
+	values, err := codec.Decode(cpb.GetValue())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	c := &Column{
+		ID:        cpb.GetId(),
+		NDV:       cpb.GetNdv(),
+		NullCount: cpb.GetNullCount(),
+		Numbers:   cpb.GetNumbers(),
+		Values:    values,
+		Repeats:   cpb.GetRepeats(),
+	}
+	if ft == nil {
+		return c, nil
+	}
+	for i, val := range values {
+		c.Values[i], err = tablecodec.Unflatten(val, ft)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return c, nil
+}
+
 // Table represents statistics for a table.
 type Table struct {
 	info        *model.TableInfo
-	TS          int64 // build timestamp.
-	Columns     []*Column
-	Count       int64 // Total row count in a table.
-	BucketCount int64 // Number of histogram bucket.
+	TS          int64     // build timestamp.
+	Columns     []*Column // Columns have the same order as the table columns, the column may be nil if it has no statistics.
+	Indices     []*Column // Indices have the same order as the table indices, the index may be nil if it has no statistics.
+	Count       int64     // Total row count in a table.
+	BucketCount int64     // Number of histogram bucket.
+}
+
+// Info returns the table info the statistics is built for.
+func (t *Table) Info() *model.TableInfo {
+	return t.info
 }
 
 // String implements Stringer interface.
 func (t *Table) String() string {
-	strs := make([]string, 0, len(t.Columns)+1)
+	strs := make([]string, 0, len(t.Columns)+len(t.Indices)+1)
 	strs = append(strs, fmt.Sprintf("Table:%d ts:%d count:%d", t.info.ID, t.TS, t.Count))
 	for _, col := range t.Columns {
-		strs = append(strs, col.String())
+		if col != nil {
+			strs = append(strs, col.String())
+		}
+	}
+	for _, idx := range t.Indices {
+		if idx != nil {
+			strs = append(strs, "index "+idx.String())
+		}
 	}
 	return strings.Join(strs, "\n")
 }
 
-func (t *Table) toPB() (*TablePB, error) {
+// ToPB converts the table statistics to protobuffer.
+func (t *Table) ToPB() (*TablePB, error) {
 	tblPB := &TablePB{
-		Id:      proto.Int64(t.info.ID),
-		Ts:      proto.Int64(t.TS),
-		Count:   proto.Int64(t.Count),
-		Columns: make([]*ColumnPB, len(t.Columns)),
+		Id:    proto.Int64(t.info.ID),
+		Ts:    proto.Int64(t.TS),
+		Count: proto.Int64(t.Count),
 	}
-	for i, col := range t.Columns {
-		data, err := codec.EncodeValue(nil, col.Values...)
+	for _, col := range t.Columns {
+		if col == nil {
+			continue
+		}
+		cpb, err := col.toPB()
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		tblPB.Columns[i] = &ColumnPB{
-			Id:      proto.Int64(col.ID),
-			Ndv:     proto.Int64(col.NDV),
-			Numbers: col.Numbers,
-			Value:   data,
-			Repeats: col.Repeats,
+		tblPB.Columns = append(tblPB.Columns, cpb)
+	}
+	for _, idx := range t.Indices {
+		if idx == nil {
+			continue
+		}
+		ipb, err := idx.toPB()
+		if err != nil {
+			return nil, errors.Trace(err)
 		}
+		tblPB.Indices = append(tblPB.Indices, ipb)
 	}
 	return tblPB, nil
 }
 
-// buildColumn builds column statistics from samples.
-func (t *Table) buildColumn(offset int, samples []types.Datum) error {
-	err := types.SortDatums(samples)
-	if err != nil {
-		return errors.Trace(err)
+// buildHistogram builds the statistics of a column or an index from samples.
+func (t *Table) buildHistogram(id int64, samples []types.Datum) (*Column, error) {
+	col := &Column{ID: id}
+	if len(samples) == 0 {
+		return col, nil
+	}
+	// As we use samples to build the histogram, the bucket number and repeat should multiply a factor.
+	sampleFactor := float64(t.Count) / float64(len(samples))
+	values := make([]types.Datum, 0, len(samples))
+	for _, d := range samples {
+		if d.IsNull() {
+			col.NullCount++
+			continue
+		}
+		values = append(values, d)
+	}
+	col.NullCount = int64(float64(col.NullCount) * sampleFactor)
+	if len(values) == 0 {
+		return col, nil
 	}
-	estimatedNDV, err := estimateNDV(t.Count, samples)
+	err := types.SortDatums(values)
 	if err != nil {
-		return errors.Trace(err)
+		return nil, errors.Trace(err)
 	}
-	ci := t.info.Columns[offset]
-	col := &Column{
-		ID:      ci.ID,
-		NDV:     estimatedNDV,
-		Numbers: make([]int64, 1, t.BucketCount),
-		Values:  make([]types.Datum, 1, t.BucketCount),
-		Repeats: make([]int64, 1, t.BucketCount),
+	col.NDV, err = estimateNDV(t.Count-col.NullCount, values)
+	if err != nil {
+		return nil, errors.Trace(err)
 	}
-	valuesPerBucket := t.Count/t.BucketCount + 1
 
-	// As we use samples to build the histogram, the bucket number and repeat should multiply a factor.
-	sampleFactor := t.Count / int64(len(samples))
+	// The bucket numbers and repeats are the numbers of samples until they are multiplied by the factor.
+	valuesPerBucket := int64(len(values))/t.BucketCount + 1
+	col.Numbers = make([]int64, 1, t.BucketCount)
+	col.Values = make([]types.Datum, 1, t.BucketCount)
+	col.Repeats = make([]int64, 1, t.BucketCount)
+	col.Numbers[0], col.Values[0], col.Repeats[0] = 1, values[0], 1
 	bucketIdx := 0
 	var lastNumber int64
-	for i := int64(0); i < int64(len(samples)); i++ {
-		cmp, err := col.Values[bucketIdx].CompareDatum(samples[i])
+	for i := int64(1); i < int64(len(values)); i++ {
+		cmp, err := col.Values[bucketIdx].CompareDatum(values[i])
 		if err != nil {
-			return errors.Trace(err)
+			return nil, errors.Trace(err)
 		}
 		if cmp == 0 {
 			// The new item has the same value as current bucket value, to ensure that
 			// a same value only stored in a single bucket, we do not increase bucketIdx even if it exceeds
 			// valuesPerBucket.
-			col.Numbers[bucketIdx] = i * sampleFactor
-			col.Repeats[bucketIdx] += sampleFactor
-		} else if i*sampleFactor-lastNumber < valuesPerBucket {
+			col.Numbers[bucketIdx] = i + 1
+			col.Repeats[bucketIdx]++
+		} else if i+1-lastNumber <= valuesPerBucket {
 			// The bucket still have room to store a new item, update the bucket.
-			col.Numbers[bucketIdx] = i * sampleFactor
-			col.Values[bucketIdx] = samples[i]
-			col.Repeats[bucketIdx] = 0
+			col.Numbers[bucketIdx] = i + 1
+			col.Values[bucketIdx] = values[i]
+			col.Repeats[bucketIdx] = 1
 		} else {
 			// The bucket is full, store the item in the next bucket.
 			lastNumber = col.Numbers[bucketIdx]
 			bucketIdx++
-			col.Numbers = append(col.Numbers, i*sampleFactor)
-			col.Values = append(col.Values, samples[i])
-			col.Repeats = append(col.Repeats, 0)
+			col.Numbers = append(col.Numbers, i+1)
+			col.Values = append(col.Values, values[i])
+			col.Repeats = append(col.Repeats, 1)
 		}
 	}
-	t.Columns[offset] = col
-	return nil
+	for i := range col.Numbers {
+		col.Numbers[i] = int64(float64(col.Numbers[i]) * sampleFactor)
+		col.Repeats[i] = int64(float64(col.Repeats[i]) * sampleFactor)
+	}
+	return col, nil
 }
 
 // estimateNDV estimates the number of distinct value given a count and samples.
@@ -182,17 +264,32 @@ func estimateNDV(count int64, samples []types.Datum) (int64, error) {
 	return int64(estimatedDistinct), nil
 }
 
-// NewTable creates a table statistics.
-func NewTable(ti *model.TableInfo, ts, count, numBuckets int64, columnSamples [][]types.Datum) (*Table, error) {
+// NewTable creates a table statistics, columnSamples and indexSamples are the samples of the
+// table columns and indices in the order of the table info, nil samples mean no statistics.
+func NewTable(ti *model.TableInfo, ts, count, numBuckets int64, columnSamples, indexSamples [][]types.Datum) (*Table, error) {
 	t := &Table{
 		info:        ti,
 		TS:          ts,
 		Count:       count,
 		BucketCount: numBuckets,
 		Columns:     make([]*Column, len(columnSamples)),
+		Indices:     make([]*Column, len(indexSamples)),
 	}
+	var err error
 	for i, sample := range columnSamples {
-		err := t.buildColumn(i, sample)
+		if sample == nil {
+			continue
+		}
+		t.Columns[i], err = t.buildHistogram(ti.Columns[i].ID, sample)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	for i, sample := range indexSamples {
+		if sample == nil {
+			continue
+		}
+		t.Indices[i], err = t.buildHistogram(ti.Indices[i].ID, sample)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
@@ -200,43 +297,80 @@ func NewTable(ti *model.TableInfo, ts, count, numBuckets int64, columnSamples []
 	return t, nil
 }
 
-// TableFromPB creates a table statistics from protobuffer.
+// TableFromPB creates a table statistics from protobuffer. The columns and indices are matched by ID,
+// those created after the statistics is built have no statistics.
 func TableFromPB(ti *model.TableInfo, tpb *TablePB) (*Table, error) {
 	if tpb.GetId() != ti.ID {
 		return nil, errors.Errorf("table id not match, expected %d, got %d", ti.ID, tpb.GetId())
 	}
-	if len(tpb.Columns) != len(ti.Columns) {
-		return nil, errors.Errorf("column count not match, expected %d, got %d", len(ti.Columns), len(tpb.Columns))
-	}
-	for i := range ti.Columns {
-		if ti.Columns[i].ID != tpb.Columns[i].GetId() {
-			return nil, errors.Errorf("column ID not match, expected %d, got %d", ti.Columns[i].ID, tpb.Columns[i].GetId())
-		}
-	}
 	t := &Table{info: ti}
 	t.TS = tpb.GetTs()
 	t.Count = tpb.GetCount()
-	t.Columns = make([]*Column, len(tpb.GetColumns()))
-	for i, cInfo := range t.info.Columns {
-		cpb := tpb.Columns[i]
-		values, err := codec.Decode(cpb.GetValue())
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		c := &Column{
-			ID:      cpb.GetId(),
-			NDV:     cpb.GetNdv(),
-			Numbers: cpb.GetNumbers(),
-			Values:  make([]types.Datum, len(values)),
-			Repeats: cpb.GetRepeats(),
+	t.Columns = make([]*Column, len(ti.Columns))
+	t.Indices = make([]*Column, len(ti.Indices))
+	for _, cpb := range tpb.GetColumns() {
+		for i, cInfo := range ti.Columns {
+			if cInfo.ID != cpb.GetId() {
+				continue
+			}
+			c, err := columnFromPB(cpb, &cInfo.FieldType)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			t.Columns[i] = c
 		}
-		for i, val := range values {
-			c.Values[i], err = tablecodec.Unflatten(val, &cInfo.FieldType)
+	}
+	for _, ipb := range tpb.GetIndices() {
+		for i, idxInfo := range ti.Indices {
+			if idxInfo.ID != ipb.GetId() {
+				continue
+			}
+			idx, err := columnFromPB(ipb, nil)
 			if err != nil {
 				return nil, errors.Trace(err)
 			}
+			t.Indices[i] = idx
 		}
-		t.Columns[i] = c
 	}
 	return t, nil
 }
+
+// SampleCollector collects the samples of the rows with reservoir sampling,
+// see https://en.wikipedia.org/wiki/Reservoir_sampling.
+type SampleCollector struct {
+	MaxSampleSize int
+	// Count is the number of the collected rows.
+	Count int64
+	// Samples are the sampled rows.
+	Samples [][]types.Datum
+	rand    *rand.Rand
+}
+
+// NewSampleCollector creates a SampleCollector which keeps at most maxSampleSize samples.
+func NewSampleCollector(maxSampleSize int) *SampleCollector {
+	return &SampleCollector{
+		MaxSampleSize: maxSampleSize,
+		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Collect adds a row to the collector, the row must not be modified after collected.
+func (c *SampleCollector) Collect(row []types.Datum) {
+	c.Count++
+	if len(c.Samples) < c.MaxSampleSize {
+		c.Samples = append(c.Samples, row)
+		return
+	}
+	if i := c.rand.Int63n(c.Count); i < int64(c.MaxSampleSize) {
+		c.Samples[i] = row
+	}
+}
+
+// ColumnSamples returns the samples of the i-th value of the rows.
+func (c *SampleCollector) ColumnSamples(i int) []types.Datum {
+	samples := make([]types.Datum, len(c.Samples))
+	for j, row := range c.Samples {
+		samples[j] = row[i]
+	}
+	return samples
+}
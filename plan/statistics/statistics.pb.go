@@ -33,6 +33,7 @@ type ColumnPB struct {
 	Numbers          []int64 `protobuf:"varint,3,rep,name=numbers" json:"numbers,omitempty"`
 	Value            []byte  `protobuf:"bytes,4,opt,name=value" json:"value,omitempty"`
 	Repeats          []int64 `protobuf:"varint,5,rep,name=repeats" json:"repeats,omitempty"`
+	NullCount        *int64  `protobuf:"varint,6,opt,name=null_count" json:"null_count,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
 }
 
@@ -76,11 +77,19 @@ func (m *ColumnPB) GetRepeats() []int64 {
 	return nil
 }
 
+func (m *ColumnPB) GetNullCount() int64 {
+	if m != nil && m.NullCount != nil {
+		return *m.NullCount
+	}
+	return 0
+}
+
 type TablePB struct {
 	Id               *int64      `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
 	Ts               *int64      `protobuf:"varint,2,opt,name=ts" json:"ts,omitempty"`
 	Count            *int64      `protobuf:"varint,3,opt,name=count" json:"count,omitempty"`
 	Columns          []*ColumnPB `protobuf:"bytes,4,rep,name=columns" json:"columns,omitempty"`
+	Indices          []*ColumnPB `protobuf:"bytes,5,rep,name=indices" json:"indices,omitempty"`
 	XXX_unrecognized []byte      `json:"-"`
 }
 
@@ -117,22 +126,31 @@ func (m *TablePB) GetColumns() []*ColumnPB {
 	return nil
 }
 
+func (m *TablePB) GetIndices() []*ColumnPB {
+	if m != nil {
+		return m.Indices
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*ColumnPB)(nil), "statistics.ColumnPB")
 	proto.RegisterType((*TablePB)(nil), "statistics.TablePB")
 }
 
 var fileDescriptor0 = []byte{
-	// 170 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0x12, 0x28, 0x2e, 0x49, 0x2c,
-	0xc9, 0x2c, 0x2e, 0xc9, 0x4c, 0x2e, 0xd6, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0x42, 0x88,
-	0x28, 0x85, 0x70, 0x71, 0x38, 0xe7, 0xe7, 0x94, 0xe6, 0xe6, 0x05, 0x38, 0x09, 0x71, 0x71, 0x31,
-	0x65, 0xa6, 0x48, 0x30, 0x2a, 0x30, 0x6a, 0x30, 0x0b, 0x71, 0x73, 0x31, 0xe7, 0xa5, 0x94, 0x49,
-	0x30, 0x81, 0x39, 0xfc, 0x5c, 0xec, 0x79, 0xa5, 0xb9, 0x49, 0xa9, 0x45, 0xc5, 0x12, 0xcc, 0x0a,
-	0xcc, 0x40, 0x01, 0x5e, 0x2e, 0xd6, 0xb2, 0xc4, 0x9c, 0xd2, 0x54, 0x09, 0x16, 0xa0, 0x3c, 0x0f,
-	0x48, 0xbe, 0x28, 0xb5, 0x20, 0x35, 0xb1, 0xa4, 0x58, 0x82, 0x15, 0x24, 0xaf, 0x14, 0xce, 0xc5,
-	0x1e, 0x92, 0x98, 0x94, 0x93, 0x8a, 0x66, 0x28, 0x90, 0x0d, 0x54, 0x02, 0x31, 0x13, 0x68, 0x44,
-	0x72, 0x7e, 0x69, 0x5e, 0x09, 0xd0, 0x44, 0x10, 0x57, 0x95, 0x8b, 0x3d, 0x19, 0xec, 0x8e, 0x62,
-	0xa0, 0x99, 0xcc, 0x1a, 0xdc, 0x46, 0x22, 0x7a, 0x48, 0xee, 0x86, 0x39, 0x11, 0x10, 0x00, 0x00,
-	0xff, 0xff, 0xe6, 0x97, 0x42, 0x05, 0xcd, 0x00, 0x00, 0x00,
+	// 193 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x8f, 0xc1, 0x4a, 0xc6, 0x30,
+	0x10, 0x84, 0x69, 0xf3, 0xd7, 0xc8, 0xd4, 0xa2, 0x04, 0x0f, 0x39, 0x86, 0x42, 0x21, 0xa7, 0x1e,
+	0x7c, 0x04, 0x7d, 0x01, 0x0f, 0xde, 0x25, 0x4d, 0x03, 0x06, 0xd2, 0xa4, 0x34, 0x49, 0x8f, 0x3e,
+	0xbb, 0x34, 0x15, 0x15, 0xe1, 0x3f, 0xce, 0xce, 0xee, 0x7e, 0x33, 0x78, 0x88, 0x49, 0x25, 0x1b,
+	0x93, 0xd5, 0x71, 0x5c, 0xb7, 0x90, 0x02, 0xc3, 0xef, 0xa4, 0xff, 0xc0, 0xed, 0x4b, 0x70, 0x79,
+	0xf1, 0xaf, 0xcf, 0x0c, 0xa8, 0xed, 0xcc, 0x2b, 0x51, 0x49, 0xc2, 0x5a, 0x10, 0x3f, 0xef, 0xbc,
+	0x2e, 0xe2, 0x1e, 0xd4, 0xe7, 0x65, 0x32, 0x5b, 0xe4, 0x44, 0x10, 0x49, 0x58, 0x87, 0x66, 0x57,
+	0x2e, 0x1b, 0x7e, 0x11, 0x95, 0xbc, 0x3b, 0xfc, 0xcd, 0xac, 0x46, 0xa5, 0xc8, 0x9b, 0xe2, 0x33,
+	0xc0, 0x67, 0xe7, 0xde, 0x75, 0xc8, 0x3e, 0xf1, 0x9b, 0xe3, 0x49, 0xff, 0x09, 0xfa, 0xa6, 0x26,
+	0x67, 0xfe, 0x81, 0x80, 0x3a, 0xc5, 0x6f, 0x4e, 0x87, 0xe6, 0xbc, 0x20, 0x45, 0x0e, 0xa0, 0xba,
+	0x64, 0x8b, 0xfc, 0x22, 0x88, 0x6c, 0x9f, 0x1e, 0xc7, 0x3f, 0x5d, 0x7e, 0x62, 0x0f, 0xa0, 0xd6,
+	0xcf, 0x56, 0x9b, 0x93, 0x7e, 0x65, 0xed, 0x6b, 0x00, 0xdf, 0x4b, 0xdc, 0x36, 0x08, 0x01, 0x00,
+	0x00,
 }
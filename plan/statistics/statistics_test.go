@@ -77,13 +77,18 @@ func (s *testStatisticsSuite) TestTable(c *C) {
 	tblInfo.Columns = columns
 	timestamp := int64(10)
 	bucketCount := int64(256)
-	t, err := NewTable(tblInfo, timestamp, s.count, bucketCount, [][]types.Datum{s.samples})
+	t, err := NewTable(tblInfo, timestamp, s.count, bucketCount, [][]types.Datum{s.samples}, nil)
 	c.Check(err, IsNil)
 	str := t.String()
 	log.Debug(str)
 	c.Check(len(str), Greater, 0)
 
-	tpb, err := t.toPB()
+	col := t.Columns[0]
+	c.Check(col.NullCount, Equals, int64(10000))
+	c.Check(col.Numbers[len(col.Numbers)-1], Equals, s.count-col.NullCount)
+	c.Check(len(col.Numbers), LessEqual, int(bucketCount))
+
+	tpb, err := t.ToPB()
 	c.Check(err, IsNil)
 	data, err := proto.Marshal(tpb)
 	c.Check(err, IsNil)
@@ -94,3 +99,108 @@ func (s *testStatisticsSuite) TestTable(c *C) {
 	c.Check(err, IsNil)
 	c.Check(nt.String(), Equals, str)
 }
+
+func (s *testStatisticsSuite) TestIndexAndNewColumn(c *C) {
+	tblInfo := &model.TableInfo{
+		ID: 1,
+		Columns: []*model.ColumnInfo{
+			{ID: 1, FieldType: *types.NewFieldType(mysql.TypeLonglong)},
+		},
+		Indices: []*model.IndexInfo{{ID: 1}},
+	}
+	colSamples := []types.Datum{types.NewIntDatum(1), types.NewIntDatum(2), types.NewIntDatum(2)}
+	idxSamples := []types.Datum{types.NewBytesDatum([]byte("a")), types.NewBytesDatum([]byte("b")), types.NewBytesDatum([]byte("b"))}
+	t, err := NewTable(tblInfo, 10, 3, 256, [][]types.Datum{colSamples}, [][]types.Datum{idxSamples})
+	c.Assert(err, IsNil)
+	c.Assert(t.Indices[0].NDV, Equals, int64(2))
+	c.Assert(t.Indices[0].Numbers, DeepEquals, []int64{1, 3})
+	c.Assert(t.Indices[0].Repeats, DeepEquals, []int64{1, 2})
+
+	tpb, err := t.ToPB()
+	c.Assert(err, IsNil)
+	// A column and an index are added after the statistics is built.
+	tblInfo.Columns = append(tblInfo.Columns, &model.ColumnInfo{ID: 2, FieldType: *types.NewFieldType(mysql.TypeLonglong)})
+	tblInfo.Indices = append([]*model.IndexInfo{{ID: 2}}, tblInfo.Indices...)
+	nt, err := TableFromPB(tblInfo, tpb)
+	c.Assert(err, IsNil)
+	c.Assert(nt.Columns, HasLen, 2)
+	c.Assert(nt.Columns[0].String(), Equals, t.Columns[0].String())
+	c.Assert(nt.Columns[1], IsNil)
+	c.Assert(nt.Indices, HasLen, 2)
+	c.Assert(nt.Indices[0], IsNil)
+	c.Assert(nt.Indices[1].String(), Equals, t.Indices[0].String())
+}
+
+func (s *testStatisticsSuite) TestSampleCollector(c *C) {
+	collector := NewSampleCollector(100)
+	for i := 0; i < 1000; i++ {
+		collector.Collect([]types.Datum{types.NewIntDatum(int64(i))})
+	}
+	c.Assert(collector.Count, Equals, int64(1000))
+	c.Assert(collector.Samples, HasLen, 100)
+	samples := collector.ColumnSamples(0)
+	c.Assert(samples, HasLen, 100)
+	// The samples are not only the first rows.
+	var maxValue int64
+	for _, d := range samples {
+		if d.GetInt64() > maxValue {
+			maxValue = d.GetInt64()
+		}
+	}
+	c.Assert(maxValue, Greater, int64(100))
+}
+
+func (s *testStatisticsSuite) TestEstimateRowCount(c *C) {
+	tblInfo := &model.TableInfo{
+		ID: 1,
+		Columns: []*model.ColumnInfo{
+			{ID: 1, FieldType: *types.NewFieldType(mysql.TypeLonglong)},
+		},
+	}
+	samples := make([]types.Datum, 100)
+	for i := range samples {
+		samples[i].SetInt64(int64(i + 1))
+	}
+	t, err := NewTable(tblInfo, 10, 100, 10, [][]types.Datum{samples}, nil)
+	c.Assert(err, IsNil)
+	col := t.ColumnByID(1)
+	c.Assert(col, NotNil)
+	c.Assert(t.ColumnByID(2), IsNil)
+	c.Assert(col.Values[4].GetInt64(), Equals, int64(55))
+
+	tests := []struct {
+		value int64
+		equal int64
+		less  int64
+	}{
+		{value: 55, equal: 1, less: 54},
+		{value: 50, equal: 1, less: 49},
+		{value: 1000, equal: 1, less: 100},
+		{value: -1000, equal: 1, less: 5},
+	}
+	for _, tt := range tests {
+		count, err := col.EqualRowCount(types.NewIntDatum(tt.value))
+		c.Assert(err, IsNil)
+		c.Assert(count, Equals, tt.equal, Commentf("equal %d", tt.value))
+		count, err = col.LessRowCount(types.NewIntDatum(tt.value))
+		c.Assert(err, IsNil)
+		c.Assert(count, Equals, tt.less, Commentf("less %d", tt.value))
+	}
+	count, err := col.LessRowCount(types.MinNotNullDatum())
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, int64(0))
+	count, err = col.BetweenRowCount(types.NewIntDatum(22), types.NewIntDatum(55))
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, int64(33))
+	// Both values are in the same bucket.
+	count, err = col.BetweenRowCount(types.NewIntDatum(50), types.NewIntDatum(52))
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, int64(1))
+	// Out of range.
+	count, err = col.BetweenRowCount(types.NewIntDatum(200), types.NewIntDatum(300))
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, int64(1))
+	count, err = col.BetweenRowCount(types.NewIntDatum(-300), types.NewIntDatum(-200))
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, int64(1))
+}
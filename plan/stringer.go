@@ -58,6 +58,10 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 		str = "Lock"
 	case *ShowDDL:
 		str = "ShowDDL"
+	case *ShowDDLJobs:
+		str = "ShowDDLJobs"
+	case *CancelDDLJobs:
+		str = "CancelDDLJobs"
 	case *Filter:
 		str = "Filter"
 	case *Sort:
@@ -98,7 +102,17 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 		idx := idxs[last]
 		children := strs[idx:]
 		strs = strs[:idx]
-		str = "Join{" + strings.Join(children, "->") + "}"
+		switch {
+		case x.JoinType == SemiJoin && x.WithAux:
+			str = "SemiJoinWithAux{"
+		case x.JoinType == SemiJoin:
+			str = "SemiJoin{"
+		case x.JoinType == AntiSemiJoin:
+			str = "AntiSemiJoin{"
+		default:
+			str = "Join{"
+		}
+		str += strings.Join(children, "->") + "}"
 		idxs = idxs[:last]
 	case *Union:
 		last := len(idxs) - 1
@@ -119,6 +133,10 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 		str = "Aggregate"
 	case *Distinct:
 		str = "Distinct"
+	case *Update:
+		str = "Update"
+	case *Delete:
+		str = "Delete"
 	default:
 		str = fmt.Sprintf("%T", in)
 	}
@@ -261,6 +261,30 @@ func (s *testPrivilegeSuite) TestDropTablePriv(c *C) {
 	mustExec(c, se1, `DROP TABLE todrop;`)
 }
 
+func (s *testPrivilegeSuite) TestRenameTablePriv(c *C) {
+	defer testleak.AfterTest(c)()
+	se := newSession(c, s.store, s.dbName)
+	ctx, _ := se.(context.Context)
+	mustExec(c, se, `CREATE TABLE torename(c int);`)
+	variable.GetSessionVars(ctx).User = "root@localhost"
+	mustExec(c, se, `CREATE USER 'rename'@'localhost' identified by '123';`)
+	mustExec(c, se, `GRANT Alter, Drop ON test.torename TO 'rename'@'localhost';`)
+
+	variable.GetSessionVars(ctx).User = "rename@localhost"
+	_, err := se.Execute("RENAME TABLE torename TO renamed;")
+	c.Assert(err, NotNil)
+
+	variable.GetSessionVars(ctx).User = "root@localhost"
+	mustExec(c, se, `GRANT Create, Insert ON test.* TO 'rename'@'localhost';`)
+
+	se1 := newSession(c, s.store, s.dbName)
+	ctx1, _ := se1.(context.Context)
+	variable.GetSessionVars(ctx1).User = "rename@localhost"
+	mustExec(c, se1, `RENAME TABLE torename TO renamed;`)
+	_, err = se1.Execute("ALTER TABLE renamed RENAME TO torename;")
+	c.Assert(err, NotNil)
+}
+
 func mustExec(c *C, se tidb.Session, sql string) {
 	_, err := se.Execute(sql)
 	c.Assert(err, IsNil)
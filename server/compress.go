@@ -0,0 +1,161 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+)
+
+const (
+	// compressedHeaderLen is the length of the compressed packet header:
+	// 3 bytes compressed payload length, 1 byte sequence and 3 bytes uncompressed payload length.
+	compressedHeaderLen = 7
+	// minCompressLen is the minimal payload length to compress, smaller payloads
+	// are sent as is, the same as MIN_COMPRESS_LENGTH in MySQL.
+	minCompressLen = 50
+)
+
+// compressedReadWriter implements the compressed packet protocol of MySQL.
+// The uncompressed packet stream of packetIO is split into compressed packets,
+// which carry their own sequence numbers.
+// See: https://dev.mysql.com/doc/internals/en/compressed-packet-header.html
+type compressedReadWriter struct {
+	rb *bufio.Reader
+	wb *bufio.Writer
+
+	// readBuf holds the decompressed data that has not been read yet.
+	readBuf  []byte
+	zbuf     bytes.Buffer
+	sequence uint8
+}
+
+func newCompressedReadWriter(rb *bufio.Reader, wb *bufio.Writer) *compressedReadWriter {
+	return &compressedReadWriter{
+		rb: rb,
+		wb: wb,
+	}
+}
+
+// Read implements io.Reader interface, it reads the decompressed packet stream.
+func (c *compressedReadWriter) Read(b []byte) (int, error) {
+	for len(c.readBuf) == 0 {
+		if err := c.readCompressedPacket(); err != nil {
+			return 0, errors.Trace(err)
+		}
+	}
+	n := copy(b, c.readBuf)
+	c.readBuf = c.readBuf[n:]
+	return n, nil
+}
+
+func (c *compressedReadWriter) readCompressedPacket() error {
+	var header [compressedHeaderLen]byte
+	if _, err := io.ReadFull(c.rb, header[:]); err != nil {
+		return errors.Trace(err)
+	}
+
+	length := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
+	sequence := uint8(header[3])
+	if sequence != c.sequence {
+		return errInvalidSequence.Gen("invalid compressed sequence %d != %d", sequence, c.sequence)
+	}
+	c.sequence++
+	uncompressedLength := int(uint32(header[4]) | uint32(header[5])<<8 | uint32(header[6])<<16)
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(c.rb, data); err != nil {
+		return errors.Trace(err)
+	}
+	if uncompressedLength == 0 {
+		// The payload is not compressed.
+		c.readBuf = data
+		return nil
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer r.Close()
+	c.readBuf, err = ioutil.ReadAll(r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(c.readBuf) != uncompressedLength {
+		return errInvalidPayloadLen.Gen("invalid uncompressed payload length %d != %d", len(c.readBuf), uncompressedLength)
+	}
+	return nil
+}
+
+// Write implements io.Writer interface, it writes data as one or more compressed packets.
+func (c *compressedReadWriter) Write(data []byte) (int, error) {
+	n := len(data)
+	for len(data) > 0 {
+		chunk := data
+		if len(chunk) > mysql.MaxPayloadLen {
+			chunk = chunk[:mysql.MaxPayloadLen]
+		}
+		if err := c.writeCompressedPacket(chunk); err != nil {
+			return 0, errors.Trace(err)
+		}
+		data = data[len(chunk):]
+	}
+	return n, nil
+}
+
+func (c *compressedReadWriter) writeCompressedPacket(data []byte) error {
+	payload := data
+	uncompressedLength := 0
+	if len(data) >= minCompressLen {
+		c.zbuf.Reset()
+		w := zlib.NewWriter(&c.zbuf)
+		if _, err := w.Write(data); err != nil {
+			return errors.Trace(err)
+		}
+		if err := w.Close(); err != nil {
+			return errors.Trace(err)
+		}
+		// Send the data as is if compressing doesn't make it smaller.
+		if c.zbuf.Len() < len(data) {
+			payload = c.zbuf.Bytes()
+			uncompressedLength = len(data)
+		}
+	}
+
+	length := len(payload)
+	header := [compressedHeaderLen]byte{
+		byte(length), byte(length >> 8), byte(length >> 16),
+		c.sequence,
+		byte(uncompressedLength), byte(uncompressedLength >> 8), byte(uncompressedLength >> 16),
+	}
+	if _, err := c.wb.Write(header[:]); err != nil {
+		return errors.Trace(mysql.ErrBadConn)
+	}
+	if _, err := c.wb.Write(payload); err != nil {
+		return errors.Trace(mysql.ErrBadConn)
+	}
+	c.sequence++
+	return nil
+}
+
+func (c *compressedReadWriter) flush() error {
+	return c.wb.Flush()
+}
@@ -14,10 +14,16 @@
 package server
 
 // Config contains configuration options.
+// LogLevel is not read from the server section of the configuration file, tidb-server sets it from the log section.
 type Config struct {
-	Addr       string `json:"addr" toml:"addr"`
-	LogLevel   string `json:"log_level" toml:"log_level"`
-	SkipAuth   bool   `json:"skip_auth" toml:"skip_auth"`
-	StatusAddr string `json:"status_addr" toml:"status_addr"`
-	Socket     string `json:"socket" toml:"socket"`
+	Addr            string `json:"addr" toml:"addr"`
+	LogLevel        string `json:"log_level" toml:"-"`
+	SkipAuth        bool   `json:"skip_auth" toml:"skip_auth"`
+	StatusAddr      string `json:"status_addr" toml:"status_addr"`
+	Socket          string `json:"socket" toml:"socket"`
+	SSLCA           string `json:"ssl_ca" toml:"ssl_ca"`
+	SSLCert         string `json:"ssl_cert" toml:"ssl_cert"`
+	SSLKey          string `json:"ssl_key" toml:"ssl_key"`
+	SSLVerifyClient bool   `json:"ssl_verify_client" toml:"ssl_verify_client"`
+	TokenLimit      int    `json:"token_limit" toml:"token_limit"` // 0 means the default limit of running statements
 }
@@ -35,7 +35,9 @@
 package server
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/tls"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -54,11 +56,13 @@ import (
 
 var defaultCapability = mysql.ClientLongPassword | mysql.ClientLongFlag |
 	mysql.ClientConnectWithDB | mysql.ClientProtocol41 |
-	mysql.ClientTransactions | mysql.ClientSecureConnection | mysql.ClientFoundRows
+	mysql.ClientTransactions | mysql.ClientSecureConnection | mysql.ClientFoundRows |
+	mysql.ClientCompress | mysql.ClientMultiStatements | mysql.ClientMultiResults
 
 type clientConn struct {
 	pkg          *packetIO
 	conn         net.Conn
+	tlsConn      *tls.Conn
 	server       *Server
 	capability   uint32
 	connectionID uint32
@@ -95,12 +99,18 @@ func (cc *clientConn) handshake() error {
 	}
 
 	err := cc.writePacket(data)
-	cc.pkg.sequence = 0
+	cc.pkg.resetSequence()
 	if err != nil {
 		return errors.Trace(err)
 	}
-
-	return errors.Trace(cc.flush())
+	if err = cc.flush(); err != nil {
+		return errors.Trace(err)
+	}
+	// The packets after the handshake are compressed if the client asks for it.
+	if cc.capability&mysql.ClientCompress > 0 {
+		cc.pkg.enableCompression()
+	}
+	return nil
 }
 
 func (cc *clientConn) Close() error {
@@ -128,15 +138,15 @@ func (cc *clientConn) writeInitialHandshake() error {
 	data = append(data, cc.salt[0:8]...)
 	// filler [00]
 	data = append(data, 0)
-	// capability flag lower 2 bytes, using default capability here
-	data = append(data, byte(defaultCapability), byte(defaultCapability>>8))
+	// capability flag lower 2 bytes
+	data = append(data, byte(cc.server.capability), byte(cc.server.capability>>8))
 	// charset, utf-8 default
 	data = append(data, uint8(mysql.DefaultCollationID))
 	//status
 	data = append(data, dumpUint16(mysql.ServerStatusAutocommit)...)
 	// below 13 byte may not be used
-	// capability flag upper 2 bytes, using default capability here
-	data = append(data, byte(defaultCapability>>16), byte(defaultCapability>>24))
+	// capability flag upper 2 bytes
+	data = append(data, byte(cc.server.capability>>16), byte(cc.server.capability>>24))
 	// filler [0x15], for wireshark dump, value is 0x15
 	data = append(data, 0x15)
 	// reserved 10 [00]
@@ -160,11 +170,31 @@ func (cc *clientConn) writePacket(data []byte) error {
 	return cc.pkg.writePacket(data)
 }
 
+// sslRequestLen is the length of the SSL request packet, which is a truncated
+// handshake response that only contains the capability, max packet size and charset.
+const sslRequestLen = 32
+
+// tlsHandshakeTimeout is the max time the TLS handshake of a connection can take.
+const tlsHandshakeTimeout = 10 * time.Second
+
 func (cc *clientConn) readHandshakeResponse() error {
 	data, err := cc.readPacket()
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if len(data) == sslRequestLen && binary.LittleEndian.Uint32(data[:4])&mysql.ClientSSL > 0 {
+		if err = cc.upgradeToTLS(); err != nil {
+			return errors.Trace(err)
+		}
+		// The real handshake response is sent over the encrypted connection.
+		data, err = cc.readPacket()
+		if err != nil {
+			return errors.Trace(err)
+		}
+	} else if cc.server.tlsConfig != nil && cc.server.tlsConfig.ClientAuth == tls.RequireAndVerifyClientCert {
+		// The client certificate can't be verified without TLS.
+		return errors.Trace(errSecureTransportReq)
+	}
 
 	pos := 0
 	// capability
@@ -191,10 +221,19 @@ func (cc *clientConn) readHandshakeResponse() error {
 			cc.dbname = string(data[pos : pos+idx])
 		}
 	}
-	// Open session and do auth
-	cc.ctx, err = cc.server.driver.OpenCtx(uint64(cc.connectionID), cc.capability, uint8(cc.collation), cc.dbname)
+	return errors.Trace(cc.openSessionAndDoAuth(auth))
+}
+
+// openSessionAndDoAuth opens a new session for cc.user and cc.dbname and authenticates it with auth.
+// The new session replaces the old one only if the authentication succeeds.
+func (cc *clientConn) openSessionAndDoAuth(auth []byte) error {
+	var tlsState *tls.ConnectionState
+	if cc.tlsConn != nil {
+		state := cc.tlsConn.ConnectionState()
+		tlsState = &state
+	}
+	ctx, err := cc.server.driver.OpenCtx(uint64(cc.connectionID), cc.capability, uint8(cc.collation), cc.dbname, tlsState)
 	if err != nil {
-		cc.Close()
 		return errors.Trace(err)
 	}
 	if !cc.server.skipAuth() {
@@ -202,13 +241,59 @@ func (cc *clientConn) readHandshakeResponse() error {
 		addr := cc.conn.RemoteAddr().String()
 		host, _, err1 := net.SplitHostPort(addr)
 		if err1 != nil {
+			ctx.Close()
 			return errors.Trace(mysql.NewErr(mysql.ErrAccessDenied, cc.user, addr, "Yes"))
 		}
 		user := fmt.Sprintf("%s@%s", cc.user, host)
-		if !cc.ctx.Auth(user, auth, cc.salt) {
+		if !ctx.Auth(user, auth, cc.salt) {
+			ctx.Close()
 			return errors.Trace(mysql.NewErr(mysql.ErrAccessDenied, cc.user, host, "Yes"))
 		}
 	}
+	ctx.SetSessionManager(cc.server)
+	ctx.SetProcessInfo(mysql.Command2Str[mysql.ComSleep], "")
+	// The context may be read by other connections which kill this one.
+	cc.server.rwlock.Lock()
+	oldCtx := cc.ctx
+	cc.ctx = ctx
+	cc.server.rwlock.Unlock()
+	if oldCtx != nil {
+		return errors.Trace(oldCtx.Close())
+	}
+	return nil
+}
+
+// bufferedReadConn is a net.Conn that reads through a bufio.Reader, so bytes
+// already buffered by the packetIO are not lost when the connection is upgraded.
+type bufferedReadConn struct {
+	net.Conn
+	rb *bufio.Reader
+}
+
+func (conn bufferedReadConn) Read(b []byte) (int, error) {
+	return conn.rb.Read(b)
+}
+
+// upgradeToTLS performs the TLS handshake after an SSL request packet and
+// switches the packetIO to the encrypted connection.
+func (cc *clientConn) upgradeToTLS() error {
+	if cc.server.tlsConfig == nil {
+		return errors.Trace(errTLSNotConfigured)
+	}
+	tlsConn := tls.Server(bufferedReadConn{Conn: cc.conn, rb: cc.pkg.rb}, cc.server.tlsConfig)
+	// A client which stops in the middle of the handshake shouldn't hold the connection forever.
+	if err := cc.conn.SetDeadline(time.Now().Add(tlsHandshakeTimeout)); err != nil {
+		return errors.Trace(err)
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := cc.conn.SetDeadline(time.Time{}); err != nil {
+		return errors.Trace(err)
+	}
+	cc.tlsConn = tlsConn
+	cc.conn = tlsConn
+	cc.pkg.setConn(tlsConn)
 	return nil
 }
 
@@ -243,7 +328,7 @@ func (cc *clientConn) Run() {
 			cc.writeError(err)
 		}
 
-		cc.pkg.sequence = 0
+		cc.pkg.resetSequence()
 	}
 }
 
@@ -255,8 +340,15 @@ func (cc *clientConn) dispatch(data []byte) error {
 	token := cc.server.getToken()
 
 	startTs := time.Now()
+	var sql string
+	if cmd == mysql.ComQuery {
+		// Copy the query, it's read by other connections after the packet buffer is reused.
+		sql = string(data)
+	}
+	cc.ctx.SetProcessInfo(mysql.Command2Str[cmd], sql)
 	defer func() {
 		cc.server.releaseToken(token)
+		cc.ctx.SetProcessInfo(mysql.Command2Str[mysql.ComSleep], "")
 		log.Debugf("[TIME_CMD] %v %d", time.Now().Sub(startTs), cmd)
 	}()
 
@@ -290,6 +382,14 @@ func (cc *clientConn) dispatch(data []byte) error {
 		return cc.handleStmtSendLongData(data)
 	case mysql.ComStmtReset:
 		return cc.handleStmtReset(data)
+	case mysql.ComSetOption:
+		return cc.handleSetOption(data)
+	case mysql.ComChangeUser:
+		return cc.handleChangeUser(data)
+	case mysql.ComResetConnection:
+		return cc.handleResetConnection()
+	case mysql.ComProcessKill:
+		return cc.handleProcessKill(data)
 	default:
 		return mysql.NewErrf(mysql.ErrUnknown, "command %d not supported now", cmd)
 	}
@@ -308,13 +408,27 @@ func (cc *clientConn) flush() error {
 	return cc.pkg.flush()
 }
 
+// serverStatus returns the status flags sent to the client,
+// more means there are more results of a multiple statement query to send.
+func (cc *clientConn) serverStatus(more bool) uint16 {
+	status := cc.ctx.Status()
+	if more {
+		status |= mysql.ServerMoreResultsExists
+	}
+	return status
+}
+
 func (cc *clientConn) writeOK() error {
+	return errors.Trace(cc.writeOKWithMore(false))
+}
+
+func (cc *clientConn) writeOKWithMore(more bool) error {
 	data := cc.alloc.AllocWithLen(4, 32)
 	data = append(data, mysql.OKHeader)
 	data = append(data, dumpLengthEncodedInt(uint64(cc.ctx.AffectedRows()))...)
 	data = append(data, dumpLengthEncodedInt(uint64(cc.ctx.LastInsertID()))...)
 	if cc.capability&mysql.ClientProtocol41 > 0 {
-		data = append(data, dumpUint16(cc.ctx.Status())...)
+		data = append(data, dumpUint16(cc.serverStatus(more))...)
 		data = append(data, dumpUint16(cc.ctx.WarningCount())...)
 	}
 
@@ -327,15 +441,13 @@ func (cc *clientConn) writeOK() error {
 }
 
 func (cc *clientConn) writeError(e error) error {
-	var (
-		m  *mysql.SQLError
-		te *terror.Error
-		ok bool
-	)
-	originErr := errors.Cause(e)
-	if te, ok = originErr.(*terror.Error); ok {
-		m = te.ToSQLError()
-	} else {
+	var m *mysql.SQLError
+	switch originErr := errors.Cause(e).(type) {
+	case *terror.Error:
+		m = originErr.ToSQLError()
+	case *mysql.SQLError:
+		m = originErr
+	default:
 		m = mysql.NewErrf(mysql.ErrUnknown, e.Error())
 	}
 
@@ -356,32 +468,156 @@ func (cc *clientConn) writeError(e error) error {
 	return errors.Trace(cc.flush())
 }
 
-func (cc *clientConn) writeEOF() error {
+func (cc *clientConn) writeEOF(more bool) error {
 	data := cc.alloc.AllocWithLen(4, 9)
 
 	data = append(data, mysql.EOFHeader)
 	if cc.capability&mysql.ClientProtocol41 > 0 {
 		data = append(data, dumpUint16(cc.ctx.WarningCount())...)
-		data = append(data, dumpUint16(cc.ctx.Status())...)
+		data = append(data, dumpUint16(cc.serverStatus(more))...)
 	}
 
 	err := cc.writePacket(data)
 	return errors.Trace(err)
 }
 
+// handleQuery executes the statements in sql one by one and writes their results,
+// the first failed statement stops the execution of the rest ones.
 func (cc *clientConn) handleQuery(sql string) (err error) {
 	startTs := time.Now()
-	rs, err := cc.ctx.Execute(sql)
+	stmts, err := cc.ctx.Parse(sql)
 	if err != nil {
 		return errors.Trace(err)
 	}
-	if rs != nil {
-		err = cc.writeResultset(rs, false)
-	} else {
-		err = cc.writeOK()
+	if len(stmts) > 1 && cc.capability&mysql.ClientMultiStatements == 0 {
+		return errors.Trace(errMultiStatementDisabled)
+	}
+	if len(stmts) == 0 {
+		return errors.Trace(cc.writeOK())
+	}
+	for i, stmt := range stmts {
+		more := i < len(stmts)-1
+		var rs ResultSet
+		rs, err = cc.ctx.ExecuteStmt(stmt)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if rs != nil {
+			err = cc.writeResultset(rs, false, more)
+		} else {
+			err = cc.writeOKWithMore(more)
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
 	}
 	log.Debugf("[TIME_QUERY] %v %s", time.Now().Sub(startTs), sql)
-	return errors.Trace(err)
+	return nil
+}
+
+// Options of COM_SET_OPTION.
+const (
+	mysqlOptionMultiStatementsOn  uint16 = 0
+	mysqlOptionMultiStatementsOff uint16 = 1
+)
+
+// handleSetOption handles COM_SET_OPTION, which turns multiple statements support on or off.
+func (cc *clientConn) handleSetOption(data []byte) error {
+	if len(data) < 2 {
+		return mysql.ErrMalformPacket
+	}
+	switch binary.LittleEndian.Uint16(data[:2]) {
+	case mysqlOptionMultiStatementsOn:
+		cc.capability |= mysql.ClientMultiStatements
+	case mysqlOptionMultiStatementsOff:
+		cc.capability &^= mysql.ClientMultiStatements
+	default:
+		return mysql.ErrMalformPacket
+	}
+	if err := cc.writeEOF(false); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.flush())
+}
+
+// handleChangeUser handles COM_CHANGE_USER. It authenticates the new user with a new session,
+// and the connection is closed if the authentication fails.
+func (cc *clientConn) handleChangeUser(data []byte) error {
+	// user name
+	idx := bytes.IndexByte(data, 0)
+	if idx < 0 {
+		return mysql.ErrMalformPacket
+	}
+	user := string(data[:idx])
+	data = data[idx+1:]
+	// auth length and auth
+	if len(data) == 0 || len(data) < int(data[0])+1 {
+		return mysql.ErrMalformPacket
+	}
+	auth := data[1 : int(data[0])+1]
+	data = data[int(data[0])+1:]
+	// database name
+	dbname := ""
+	if idx = bytes.IndexByte(data, 0); idx >= 0 {
+		dbname = string(data[:idx])
+		data = data[idx+1:]
+	}
+	// charset
+	if len(data) >= 2 {
+		cc.collation = uint8(binary.LittleEndian.Uint16(data[:2]))
+	}
+
+	cc.server.rwlock.Lock()
+	cc.user = user
+	cc.server.rwlock.Unlock()
+	cc.dbname = dbname
+	if err := cc.openSessionAndDoAuth(auth); err != nil {
+		cc.writeError(err)
+		return io.EOF
+	}
+	return errors.Trace(cc.writeOK())
+}
+
+// handleResetConnection handles COM_RESET_CONNECTION, which resets the session state
+// without re-authentication.
+func (cc *clientConn) handleResetConnection() error {
+	if err := cc.ctx.Reset(); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.writeOK())
+}
+
+// handleProcessKill handles COM_PROCESS_KILL, which interrupts the running statement
+// of the connection and closes it. There is no SUPER privilege yet, so only the
+// connections of the same user can be killed.
+func (cc *clientConn) handleProcessKill(data []byte) error {
+	if len(data) < 4 {
+		return mysql.ErrMalformPacket
+	}
+	connectionID := binary.LittleEndian.Uint32(data[:4])
+	s := cc.server
+	s.rwlock.RLock()
+	target, ok := s.clients[connectionID]
+	var err error
+	if !ok {
+		err = mysql.NewErrf(mysql.ErrNoSuchThread, "Unknown thread id: %d", connectionID)
+	} else if target != cc && target.user != cc.user {
+		err = mysql.NewErrf(mysql.ErrKillDenied, "You are not owner of thread %d", connectionID)
+	} else {
+		target.ctx.Cancel()
+	}
+	s.rwlock.RUnlock()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if target == cc {
+		// Killing itself closes the connection without a reply.
+		return io.EOF
+	}
+	// The blocked read or write of the killed connection returns an error,
+	// then its goroutine exits and cleans up the session.
+	target.conn.Close()
+	return errors.Trace(cc.writeOK())
 }
 
 func (cc *clientConn) handleFieldList(sql string) (err error) {
@@ -398,13 +634,15 @@ func (cc *clientConn) handleFieldList(sql string) (err error) {
 			return errors.Trace(err)
 		}
 	}
-	if err := cc.writeEOF(); err != nil {
+	if err := cc.writeEOF(false); err != nil {
 		return errors.Trace(err)
 	}
 	return errors.Trace(cc.flush())
 }
 
-func (cc *clientConn) writeResultset(rs ResultSet, binary bool) error {
+// writeResultset writes the result set to the client,
+// more means there are more results of a multiple statement query to send.
+func (cc *clientConn) writeResultset(rs ResultSet, binary bool, more bool) error {
 	defer rs.Close()
 	// We need to call Next before we get columns.
 	// Otherwise, we will get incorrect columns info.
@@ -432,7 +670,7 @@ func (cc *clientConn) writeResultset(rs ResultSet, binary bool) error {
 		}
 	}
 
-	if err = cc.writeEOF(); err != nil {
+	if err = cc.writeEOF(false); err != nil {
 		return errors.Trace(err)
 	}
 
@@ -472,7 +710,7 @@ func (cc *clientConn) writeResultset(rs ResultSet, binary bool) error {
 		row, err = rs.Next()
 	}
 
-	err = cc.writeEOF()
+	err = cc.writeEOF(more)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -78,7 +78,7 @@ func (cc *clientConn) handleStmtPrepare(sql string) error {
 			}
 		}
 
-		if err := cc.writeEOF(); err != nil {
+		if err := cc.writeEOF(false); err != nil {
 			return errors.Trace(err)
 		}
 	}
@@ -93,7 +93,7 @@ func (cc *clientConn) handleStmtPrepare(sql string) error {
 			}
 		}
 
-		if err := cc.writeEOF(); err != nil {
+		if err := cc.writeEOF(false); err != nil {
 			return errors.Trace(err)
 		}
 
@@ -166,7 +166,7 @@ func (cc *clientConn) handleStmtExecute(data []byte) (err error) {
 		return errors.Trace(cc.writeOK())
 	}
 
-	return errors.Trace(cc.writeResultset(rs, true))
+	return errors.Trace(cc.writeResultset(rs, true, false))
 }
 
 func parseStmtArgs(args []interface{}, boundParams [][]byte, nullBitmap, paramTypes, paramValues []byte) (err error) {
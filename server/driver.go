@@ -13,12 +13,19 @@
 
 package server
 
-import "github.com/pingcap/tidb/util/types"
+import (
+	"crypto/tls"
+
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/types"
+)
 
 // IDriver opens IContext.
 type IDriver interface {
-	// OpenCtx opens an IContext with connection id, client capability, collation and dbname.
-	OpenCtx(connID uint64, capability uint32, collation uint8, dbname string) (IContext, error)
+	// OpenCtx opens an IContext with connection id, client capability, collation, dbname
+	// and the TLS state of the connection, which is nil for plaintext connections.
+	OpenCtx(connID uint64, capability uint32, collation uint8, dbname string, tlsState *tls.ConnectionState) (IContext, error)
 }
 
 // IContext is the interface to execute commant.
@@ -41,6 +48,12 @@ type IContext interface {
 	// Execute executes a SQL statement.
 	Execute(sql string) (ResultSet, error)
 
+	// Parse parses a SQL string to statements.
+	Parse(sql string) ([]ast.StmtNode, error)
+
+	// ExecuteStmt executes a statement returned by Parse.
+	ExecuteStmt(stmt ast.StmtNode) (ResultSet, error)
+
 	// Prepare prepares a statement.
 	Prepare(sql string) (statement IStatement, columns, params []*ColumnInfo, err error)
 
@@ -50,6 +63,21 @@ type IContext interface {
 	// FieldList returns columns of a table.
 	FieldList(tableName string) (columns []*ColumnInfo, err error)
 
+	// Reset resets the session state, the current user and database are kept.
+	Reset() error
+
+	// Cancel interrupts the running statement, it can be called by other goroutines.
+	Cancel()
+
+	// SetSessionManager sets the session manager used by show processlist and kill statements.
+	SetSessionManager(util.SessionManager)
+
+	// SetProcessInfo records the command which is running.
+	SetProcessInfo(command, sql string)
+
+	// ShowProcess returns the process info of the context, it can be called by other goroutines.
+	ShowProcess() util.ProcessInfo
+
 	// Close closes the IContext.
 	Close() error
 
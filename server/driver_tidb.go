@@ -14,11 +14,14 @@
 package server
 
 import (
+	"crypto/tls"
+
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -37,10 +40,9 @@ func NewTiDBDriver(store kv.Storage) *TiDBDriver {
 
 // TiDBContext implements IContext.
 type TiDBContext struct {
-	session      tidb.Session
-	currentDB    string
-	warningCount uint16
-	stmts        map[int]*TiDBStatement
+	session   tidb.Session
+	currentDB string
+	stmts     map[int]*TiDBStatement
 }
 
 // TiDBStatement implements IStatement.
@@ -109,10 +111,11 @@ func (ts *TiDBStatement) Close() error {
 }
 
 // OpenCtx implements IDriver.
-func (qd *TiDBDriver) OpenCtx(connID uint64, capability uint32, collation uint8, dbname string) (IContext, error) {
+func (qd *TiDBDriver) OpenCtx(connID uint64, capability uint32, collation uint8, dbname string, tlsState *tls.ConnectionState) (IContext, error) {
 	session, _ := tidb.CreateSession(qd.store)
 	session.SetClientCapability(capability)
 	session.SetConnectionID(connID)
+	session.SetTLSState(tlsState)
 	if dbname != "" {
 		_, err := session.Execute("use " + dbname)
 		if err != nil {
@@ -149,7 +152,7 @@ func (tc *TiDBContext) CurrentDB() string {
 
 // WarningCount implements IContext WarningCount method.
 func (tc *TiDBContext) WarningCount() uint16 {
-	return tc.warningCount
+	return tc.session.WarningCount()
 }
 
 // Execute implements IContext Execute method.
@@ -167,6 +170,52 @@ func (tc *TiDBContext) Execute(sql string) (rs ResultSet, err error) {
 	return
 }
 
+// Parse implements IContext Parse method.
+func (tc *TiDBContext) Parse(sql string) ([]ast.StmtNode, error) {
+	return tc.session.Parse(sql)
+}
+
+// ExecuteStmt implements IContext ExecuteStmt method.
+func (tc *TiDBContext) ExecuteStmt(stmt ast.StmtNode) (ResultSet, error) {
+	recordSet, err := tc.session.ExecuteStmt(stmt)
+	if err != nil {
+		return nil, err
+	}
+	if recordSet == nil {
+		return nil, nil
+	}
+	return &tidbResultSet{recordSet: recordSet}, nil
+}
+
+// Reset implements IContext Reset method.
+func (tc *TiDBContext) Reset() error {
+	if err := tc.session.Reset(); err != nil {
+		return errors.Trace(err)
+	}
+	tc.stmts = make(map[int]*TiDBStatement)
+	return nil
+}
+
+// Cancel implements IContext Cancel method.
+func (tc *TiDBContext) Cancel() {
+	tc.session.Cancel()
+}
+
+// SetSessionManager implements IContext SetSessionManager method.
+func (tc *TiDBContext) SetSessionManager(sm util.SessionManager) {
+	tc.session.SetSessionManager(sm)
+}
+
+// SetProcessInfo implements IContext SetProcessInfo method.
+func (tc *TiDBContext) SetProcessInfo(command, sql string) {
+	tc.session.SetProcessInfo(command, sql)
+}
+
+// ShowProcess implements IContext ShowProcess method.
+func (tc *TiDBContext) ShowProcess() util.ProcessInfo {
+	return tc.session.ShowProcess()
+}
+
 // Close implements IContext Close method.
 func (tc *TiDBContext) Close() (err error) {
 	return tc.session.Close()
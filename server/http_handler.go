@@ -0,0 +1,260 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/terror"
+)
+
+// The admin API on the status port, all the responses are in JSON:
+//
+//	GET /schema                              the databases and the schema version
+//	GET /schema/{db}                         the tables of the database
+//	GET /schema/{db}/{table}                 the table
+//	GET /tables/{db}/{table}/regions         the regions of the table records and indices, TiKV only
+//	GET /ddl/jobs                            the DDL job queue
+//	GET /ddl/history                         the history DDL jobs
+type adminHandler struct {
+	store kv.Storage
+}
+
+// regionCacheGetter is implemented by the storages that have a region cache.
+type regionCacheGetter interface {
+	GetRegionCache() *tikv.RegionCache
+}
+
+var (
+	errNotFound     = errors.New("not found")
+	errNotSupported = errors.New("not supported by the storage")
+)
+
+func registerAdminHandler(store kv.Storage) {
+	h := &adminHandler{store: store}
+	http.HandleFunc("/schema", h.handleSchema)
+	http.HandleFunc("/schema/", h.handleSchema)
+	http.HandleFunc("/tables/", h.handleTableRegions)
+	http.HandleFunc("/ddl/jobs", h.handleDDLJobs)
+	http.HandleFunc("/ddl/history", h.handleDDLHistory)
+}
+
+// pathParams splits the path after the prefix into parameters.
+func pathParams(path, prefix string) []string {
+	path = strings.Trim(strings.TrimPrefix(path, prefix), "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func writeJSON(w http.ResponseWriter, data interface{}) {
+	js, err := json.MarshalIndent(data, "", " ")
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(js)
+}
+
+func writeHTTPError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case terror.ErrorEqual(err, errNotFound), terror.ErrorEqual(err, infoschema.ErrDatabaseNotExists),
+		terror.ErrorEqual(err, infoschema.ErrTableNotExists):
+		code = http.StatusNotFound
+	case terror.ErrorEqual(err, errNotSupported):
+		code = http.StatusNotImplemented
+	default:
+		log.Errorf("[http] %v", errors.ErrorStack(err))
+	}
+	w.WriteHeader(code)
+	w.Write([]byte(err.Error()))
+}
+
+func (h *adminHandler) infoSchema() (infoschema.InfoSchema, error) {
+	do, err := tidb.GetDomain(h.store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return do.InfoSchema(), nil
+}
+
+func (h *adminHandler) getTable(is infoschema.InfoSchema, dbName, tableName string) (table.Table, error) {
+	t, err := is.TableByName(model.NewCIStr(dbName), model.NewCIStr(tableName))
+	return t, errors.Trace(err)
+}
+
+type schemaInfo struct {
+	SchemaVersion int64           `json:"schema_version"`
+	Databases     []*model.DBInfo `json:"databases"`
+}
+
+func (h *adminHandler) handleSchema(w http.ResponseWriter, req *http.Request) {
+	is, err := h.infoSchema()
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+	params := pathParams(req.URL.Path, "/schema")
+	switch len(params) {
+	case 0:
+		writeJSON(w, &schemaInfo{SchemaVersion: is.SchemaMetaVersion(), Databases: is.AllSchemas()})
+	case 1:
+		dbName := model.NewCIStr(params[0])
+		if !is.SchemaExists(dbName) {
+			writeHTTPError(w, infoschema.ErrDatabaseNotExists)
+			return
+		}
+		tbls := is.SchemaTables(dbName)
+		infos := make([]*model.TableInfo, 0, len(tbls))
+		for _, t := range tbls {
+			infos = append(infos, t.Meta())
+		}
+		writeJSON(w, infos)
+	case 2:
+		t, err := h.getTable(is, params[0], params[1])
+		if err != nil {
+			writeHTTPError(w, err)
+			return
+		}
+		writeJSON(w, t.Meta())
+	default:
+		writeHTTPError(w, errNotFound)
+	}
+}
+
+type regionMeta struct {
+	ID       uint64 `json:"region_id"`
+	StartKey string `json:"start_key"`
+	EndKey   string `json:"end_key"`
+	Addr     string `json:"addr"`
+}
+
+type indexRegions struct {
+	Name    string       `json:"name"`
+	ID      int64        `json:"id"`
+	Regions []regionMeta `json:"regions"`
+}
+
+type tableRegions struct {
+	Name          string         `json:"name"`
+	ID            int64          `json:"id"`
+	RecordRegions []regionMeta   `json:"record_regions"`
+	Indices       []indexRegions `json:"indices"`
+}
+
+func listRegions(cache *tikv.RegionCache, prefix kv.Key) ([]regionMeta, error) {
+	regions, err := cache.ListRegionsInRange(prefix, prefix.PrefixNext())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	metas := make([]regionMeta, 0, len(regions))
+	for _, r := range regions {
+		metas = append(metas, regionMeta{
+			ID:       r.GetID(),
+			StartKey: hex.EncodeToString(r.StartKey()),
+			EndKey:   hex.EncodeToString(r.EndKey()),
+			Addr:     r.GetAddress(),
+		})
+	}
+	return metas, nil
+}
+
+func (h *adminHandler) handleTableRegions(w http.ResponseWriter, req *http.Request) {
+	params := pathParams(req.URL.Path, "/tables")
+	if len(params) != 3 || params[2] != "regions" {
+		writeHTTPError(w, errNotFound)
+		return
+	}
+	getter, ok := h.store.(regionCacheGetter)
+	if !ok {
+		writeHTTPError(w, errNotSupported)
+		return
+	}
+	is, err := h.infoSchema()
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+	t, err := h.getTable(is, params[0], params[1])
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	cache := getter.GetRegionCache()
+	result := &tableRegions{Name: t.Meta().Name.O, ID: t.Meta().ID}
+	result.RecordRegions, err = listRegions(cache, t.RecordPrefix())
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+	for _, idx := range t.Meta().Indices {
+		regions, err := listRegions(cache, tables.GenIndexPrefix(t.IndexPrefix(), idx.ID))
+		if err != nil {
+			writeHTTPError(w, err)
+			return
+		}
+		result.Indices = append(result.Indices, indexRegions{Name: idx.Name.O, ID: idx.ID, Regions: regions})
+	}
+	writeJSON(w, result)
+}
+
+func (h *adminHandler) getDDLJobs(history bool) ([]*model.Job, error) {
+	var jobs []*model.Job
+	err := kv.RunInNewTxn(h.store, false, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		var err error
+		if history {
+			jobs, err = t.GetAllHistoryDDLJobs()
+		} else {
+			jobs, err = t.GetAllDDLJobs()
+		}
+		return errors.Trace(err)
+	})
+	return jobs, errors.Trace(err)
+}
+
+func (h *adminHandler) handleDDLJobs(w http.ResponseWriter, req *http.Request) {
+	jobs, err := h.getDDLJobs(false)
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+	writeJSON(w, jobs)
+}
+
+func (h *adminHandler) handleDDLHistory(w http.ResponseWriter, req *http.Request) {
+	jobs, err := h.getDDLJobs(true)
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+	writeJSON(w, jobs)
+}
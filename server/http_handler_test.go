@@ -0,0 +1,63 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+var _ = Suite(&testHTTPHandlerSuite{})
+
+type testHTTPHandlerSuite struct{}
+
+func (s *testHTTPHandlerSuite) TestTableRegions(c *C) {
+	defer testleak.AfterTest(c)()
+	store := tikv.NewMockTikvStore()
+	defer store.Close()
+	tidb.SetSchemaLease(0)
+	se, err := tidb.CreateSession(store)
+	c.Assert(err, IsNil)
+	defer se.Close()
+	_, err = se.Execute("create table test.t (a int, b int, index idx(b))")
+	c.Assert(err, IsNil)
+
+	h := &adminHandler{store: store}
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/tables/test/t/regions", nil)
+	c.Assert(err, IsNil)
+	h.handleTableRegions(w, req)
+	c.Assert(w.Code, Equals, http.StatusOK)
+
+	var result tableRegions
+	c.Assert(json.Unmarshal(w.Body.Bytes(), &result), IsNil)
+	c.Assert(result.Name, Equals, "t")
+	c.Assert(result.RecordRegions, HasLen, 1)
+	c.Assert(result.Indices, HasLen, 1)
+	c.Assert(result.Indices[0].Name, Equals, "idx")
+	c.Assert(result.Indices[0].Regions, HasLen, 1)
+	c.Assert(result.Indices[0].Regions[0].ID, Equals, result.RecordRegions[0].ID)
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/tables/test/no_such_table/regions", nil)
+	c.Assert(err, IsNil)
+	h.handleTableRegions(w, req)
+	c.Assert(w.Code, Equals, http.StatusNotFound)
+}
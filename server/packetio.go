@@ -52,18 +52,39 @@ type packetIO struct {
 	rb *bufio.Reader
 	wb *bufio.Writer
 
+	// compressor is not nil if the compressed protocol is used.
+	compressor *compressedReadWriter
+
 	sequence uint8
 }
 
 func newPacketIO(conn net.Conn) *packetIO {
-	p := &packetIO{
-		rb: bufio.NewReaderSize(conn, defaultReaderSize),
-		wb: bufio.NewWriterSize(conn, defaultWriterSize),
-	}
-
+	p := &packetIO{}
+	p.setConn(conn)
 	return p
 }
 
+// setConn makes packetIO read and write through conn, the sequence is kept.
+func (p *packetIO) setConn(conn net.Conn) {
+	p.rb = bufio.NewReaderSize(conn, defaultReaderSize)
+	p.wb = bufio.NewWriterSize(conn, defaultWriterSize)
+}
+
+// enableCompression makes the following packets use the compressed protocol.
+func (p *packetIO) enableCompression() {
+	p.compressor = newCompressedReadWriter(p.rb, p.wb)
+	p.rb = bufio.NewReaderSize(p.compressor, defaultReaderSize)
+	p.wb = bufio.NewWriterSize(p.compressor, defaultWriterSize)
+}
+
+// resetSequence resets the sequence before a new command.
+func (p *packetIO) resetSequence() {
+	p.sequence = 0
+	if p.compressor != nil {
+		p.compressor.sequence = 0
+	}
+}
+
 func (p *packetIO) readPacket() ([]byte, error) {
 	var header [4]byte
 
@@ -137,5 +158,13 @@ func (p *packetIO) writePacket(data []byte) error {
 }
 
 func (p *packetIO) flush() error {
-	return p.wb.Flush()
+	if err := p.wb.Flush(); err != nil {
+		return errors.Trace(err)
+	}
+	if p.compressor == nil {
+		return nil
+	}
+	// Like MySQL, the sequence is synchronized with the compressed sequence after flush.
+	p.sequence = p.compressor.sequence
+	return errors.Trace(p.compressor.flush())
 }
@@ -0,0 +1,76 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+var _ = Suite(&testPacketIOSuite{})
+
+type testPacketIOSuite struct {
+}
+
+func newTestPacketIO(buf *bytes.Buffer) *packetIO {
+	return &packetIO{
+		rb: bufio.NewReader(buf),
+		wb: bufio.NewWriter(buf),
+	}
+}
+
+func (s *testPacketIOSuite) TestCompressedPacket(c *C) {
+	defer testleak.AfterTest(c)()
+	var buf bytes.Buffer
+	w := newTestPacketIO(&buf)
+	w.enableCompression()
+	r := newTestPacketIO(&buf)
+	r.enableCompression()
+
+	payloads := [][]byte{
+		[]byte("select 1"),
+		bytes.Repeat([]byte("abcdefgh"), 1024),
+		bytes.Repeat([]byte{0x1}, 100*1024),
+	}
+	for _, payload := range payloads {
+		data := make([]byte, 4, 4+len(payload))
+		data = append(data, payload...)
+		c.Assert(w.writePacket(data), IsNil)
+	}
+	c.Assert(w.flush(), IsNil)
+	// Compressed data is much smaller than the payloads.
+	c.Assert(buf.Len(), Less, 10*1024)
+	// The sequence is synchronized with the compressed sequence after flush.
+	c.Assert(w.sequence, Equals, w.compressor.sequence)
+
+	for _, payload := range payloads {
+		data, err := r.readPacket()
+		c.Assert(err, IsNil)
+		c.Assert(data, DeepEquals, payload)
+	}
+	c.Assert(r.compressor.sequence, Equals, w.compressor.sequence)
+
+	// A compressed packet with wrong sequence is rejected.
+	r.resetSequence()
+	c.Assert(r.sequence, Equals, uint8(0))
+	c.Assert(r.compressor.sequence, Equals, uint8(0))
+	c.Assert(w.writePacket(make([]byte, 5)), IsNil)
+	c.Assert(w.flush(), IsNil)
+	r.compressor.sequence = 100
+	_, err := r.readPacket()
+	c.Assert(err, NotNil)
+}
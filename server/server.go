@@ -29,7 +29,10 @@
 package server
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"io/ioutil"
 	"math/rand"
 	"net"
 	"net/http"
@@ -42,7 +45,9 @@ import (
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/arena"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 var (
@@ -50,12 +55,18 @@ var (
 )
 
 var (
-	errUnknownFieldType  = terror.ClassServer.New(codeUnknownFieldType, "unknown field type")
-	errInvalidPayloadLen = terror.ClassServer.New(codeInvalidPayloadLen, "invalid payload length")
-	errInvalidSequence   = terror.ClassServer.New(codeInvalidSequence, "invalid sequence")
-	errInvalidType       = terror.ClassServer.New(codeInvalidType, "invalid type")
+	errUnknownFieldType       = terror.ClassServer.New(codeUnknownFieldType, "unknown field type")
+	errInvalidPayloadLen      = terror.ClassServer.New(codeInvalidPayloadLen, "invalid payload length")
+	errInvalidSequence        = terror.ClassServer.New(codeInvalidSequence, "invalid sequence")
+	errInvalidType            = terror.ClassServer.New(codeInvalidType, "invalid type")
+	errTLSNotConfigured       = terror.ClassServer.New(codeTLSNotConfigured, "TLS is not configured on the server")
+	errMultiStatementDisabled = terror.ClassServer.New(codeMultiStatementDisabled, "client has multi-statement capability disabled")
+	errSecureTransportReq     = terror.ClassServer.New(codeSecureTransportReq, "connections using insecure transport are prohibited while ssl-verify-client is set")
 )
 
+// defaultTokenLimit is the max number of the statements running concurrently if it's not configured.
+const defaultTokenLimit = 100
+
 // Server is the MySQL protocol server
 type Server struct {
 	cfg               *Config
@@ -64,6 +75,8 @@ type Server struct {
 	rwlock            *sync.RWMutex
 	concurrentLimiter *TokenLimiter
 	clients           map[uint32]*clientConn
+	tlsConfig         *tls.Config
+	capability        uint32
 }
 
 // ConnectionCount gets current connection count.
@@ -113,15 +126,29 @@ func (s *Server) skipAuth() bool {
 
 // NewServer creates a new Server.
 func NewServer(cfg *Config, driver IDriver) (*Server, error) {
+	tokenLimit := cfg.TokenLimit
+	if tokenLimit <= 0 {
+		tokenLimit = defaultTokenLimit
+	}
 	s := &Server{
 		cfg:               cfg,
 		driver:            driver,
-		concurrentLimiter: NewTokenLimiter(100),
+		concurrentLimiter: NewTokenLimiter(tokenLimit),
 		rwlock:            &sync.RWMutex{},
 		clients:           make(map[uint32]*clientConn),
+		capability:        defaultCapability,
+	}
+
+	tlsConfig, err := loadTLSConfig(cfg)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if tlsConfig != nil {
+		s.tlsConfig = tlsConfig
+		s.capability |= mysql.ClientSSL
+		log.Info("TLS is enabled for client connections")
 	}
 
-	var err error
 	if cfg.Socket != "" {
 		cfg.SkipAuth = true
 		s.listener, err = net.Listen("unix", cfg.Socket)
@@ -138,6 +165,43 @@ func NewServer(cfg *Config, driver IDriver) (*Server, error) {
 	return s, nil
 }
 
+// loadTLSConfig builds the TLS configuration from cfg.
+// It returns nil if no server certificate is configured.
+func loadTLSConfig(cfg *Config) (*tls.Config, error) {
+	if cfg.SSLCert == "" && cfg.SSLKey == "" {
+		if cfg.SSLCA != "" || cfg.SSLVerifyClient {
+			return nil, errors.New("ssl-ca and ssl-verify-client require ssl-cert and ssl-key")
+		}
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(cfg.SSLCert, cfg.SSLKey)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+	if cfg.SSLCA != "" {
+		ca, err := ioutil.ReadFile(cfg.SSLCA)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.Errorf("no valid certificate found in %s", cfg.SSLCA)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	if cfg.SSLVerifyClient {
+		if cfg.SSLCA == "" {
+			return nil, errors.New("ssl-verify-client requires ssl-ca")
+		}
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
 // Run runs the server.
 func (s *Server) Run() error {
 
@@ -160,6 +224,38 @@ func (s *Server) Run() error {
 	}
 }
 
+// ShowProcessList implements the SessionManager interface.
+func (s *Server) ShowProcessList() []util.ProcessInfo {
+	s.rwlock.RLock()
+	defer s.rwlock.RUnlock()
+	rs := make([]util.ProcessInfo, 0, len(s.clients))
+	for _, client := range s.clients {
+		pi := client.ctx.ShowProcess()
+		pi.ID = uint64(client.connectionID)
+		pi.User = client.user
+		pi.Host = client.conn.RemoteAddr().String()
+		rs = append(rs, pi)
+	}
+	return rs
+}
+
+// Kill implements the SessionManager interface.
+// The blocked read or write of a killed connection returns an error,
+// then its goroutine exits and cleans up the session.
+func (s *Server) Kill(connectionID uint64, query bool) bool {
+	s.rwlock.RLock()
+	defer s.rwlock.RUnlock()
+	client, ok := s.clients[uint32(connectionID)]
+	if !ok {
+		return false
+	}
+	client.ctx.Cancel()
+	if !query {
+		client.conn.Close()
+	}
+	return true
+}
+
 // Close closes the server.
 func (s *Server) Close() {
 	s.rwlock.Lock()
@@ -212,6 +308,10 @@ func (s *Server) startStatusHTTP() {
 				}
 
 			})
+			http.Handle("/metrics", promhttp.Handler())
+			if tidbDriver, ok := s.driver.(*TiDBDriver); ok {
+				registerAdminHandler(tidbDriver.store)
+			}
 			addr := s.cfg.StatusAddr
 			if len(addr) == 0 {
 				addr = defaultStatusAddr
@@ -230,8 +330,11 @@ type status struct {
 
 // Server error codes.
 const (
-	codeUnknownFieldType  = 1
-	codeInvalidPayloadLen = 2
-	codeInvalidSequence   = 3
-	codeInvalidType       = 4
+	codeUnknownFieldType       = 1
+	codeInvalidPayloadLen      = 2
+	codeInvalidSequence        = 3
+	codeInvalidType            = 4
+	codeTLSNotConfigured       = 5
+	codeMultiStatementDisabled = 6
+	codeSecureTransportReq     = 7
 )
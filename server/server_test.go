@@ -14,14 +14,30 @@
 package server
 
 import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"database/sql"
+	"encoding/binary"
 	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
 	"net/http"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
 	. "github.com/pingcap/check"
 	tmysql "github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util"
 )
 
 func TestT(t *testing.T) {
@@ -317,3 +333,455 @@ func runTestStatusAPI(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(data.Version, Equals, tmysql.ServerVersion)
 }
+
+func getJSON(c *C, path string, code int, v interface{}) {
+	resp, err := http.Get("http://127.0.0.1:10090" + path)
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, code)
+	if v != nil {
+		c.Assert(json.NewDecoder(resp.Body).Decode(v), IsNil)
+	}
+}
+
+func runTestAdminAPI(c *C) {
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec("drop table if exists admin_api")
+		dbt.mustExec("create table admin_api (a int, b varchar(10))")
+	})
+
+	var schema struct {
+		SchemaVersion int64 `json:"schema_version"`
+		Databases     []struct {
+			Name struct {
+				L string `json:"L"`
+			} `json:"db_name"`
+		} `json:"databases"`
+	}
+	getJSON(c, "/schema", http.StatusOK, &schema)
+	c.Assert(schema.SchemaVersion, Greater, int64(0))
+	var dbNames []string
+	for _, db := range schema.Databases {
+		dbNames = append(dbNames, db.Name.L)
+	}
+	c.Assert(strings.Join(dbNames, ","), Matches, ".*test.*")
+
+	var tbls []map[string]interface{}
+	getJSON(c, "/schema/test", http.StatusOK, &tbls)
+	c.Assert(len(tbls), Greater, 0)
+	var tbl struct {
+		ID   int64 `json:"id"`
+		Name struct {
+			O string `json:"O"`
+		} `json:"name"`
+	}
+	getJSON(c, "/schema/test/admin_api", http.StatusOK, &tbl)
+	c.Assert(tbl.Name.O, Equals, "admin_api")
+	getJSON(c, "/schema/test/no_such_table", http.StatusNotFound, nil)
+	getJSON(c, "/schema/no_such_db", http.StatusNotFound, nil)
+
+	// The local storage has no regions.
+	getJSON(c, "/tables/test/admin_api/regions", http.StatusNotImplemented, nil)
+
+	var jobs []struct {
+		ID    int64 `json:"id"`
+		State int   `json:"state"`
+	}
+	getJSON(c, "/ddl/jobs", http.StatusOK, &jobs)
+	c.Assert(jobs, HasLen, 0)
+	getJSON(c, "/ddl/history", http.StatusOK, &jobs)
+	c.Assert(len(jobs), Greater, 0)
+
+}
+
+func runTestMetricsAPI(c *C) {
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec("select 1")
+	})
+	resp, err := http.Get("http://127.0.0.1:10090/metrics")
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	body, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, IsNil)
+	text := string(body)
+	c.Assert(text, Matches, `(?s).*# TYPE tidb_server_query_duration_seconds histogram\n.*`)
+	c.Assert(text, Matches, `(?s).*tidb_server_query_duration_seconds_count\{sql_type="Select"\} [1-9].*`)
+	c.Assert(text, Matches, `(?s).*# TYPE tidb_session_transaction_total counter\n.*`)
+}
+
+// generateCert writes a self-signed certificate and its private key to dir
+// and returns their paths.
+func generateCert(c *C, dir string) (certPath, keyPath string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	c.Assert(err, IsNil)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "tidb-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	c.Assert(err, IsNil)
+	certPath = filepath.Join(dir, "cert.pem")
+	keyPath = filepath.Join(dir, "key.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	c.Assert(ioutil.WriteFile(certPath, certPEM, 0600), IsNil)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	c.Assert(ioutil.WriteFile(keyPath, keyPEM, 0600), IsNil)
+	return
+}
+
+func runTestTLSConnection(c *C, tlsDsn string) {
+	err := mysql.RegisterTLSConfig("tidb-test", &tls.Config{InsecureSkipVerify: true})
+	c.Assert(err, IsNil)
+	runTests(c, tlsDsn+"&tls=tidb-test", func(dbt *DBTest) {
+		var name, cipher string
+		err := dbt.db.QueryRow("show status like 'Ssl_cipher'").Scan(&name, &cipher)
+		dbt.Assert(err, IsNil)
+		dbt.Assert(name, Equals, "Ssl_cipher")
+		dbt.Assert(cipher, Not(Equals), "")
+		dbt.mustExec("create table test (a int)")
+		dbt.mustExec("insert test values (1)")
+		dbt.mustQueryRows("select * from test")
+	})
+	// Plaintext connections still work on a TLS enabled server.
+	runTests(c, tlsDsn, func(dbt *DBTest) {
+		var name, cipher string
+		err := dbt.db.QueryRow("show status like 'Ssl_cipher'").Scan(&name, &cipher)
+		dbt.Assert(err, IsNil)
+		dbt.Assert(cipher, Equals, "")
+	})
+}
+
+func runTestVerifyClientConnection(c *C, tlsDsn, certPath, keyPath string) {
+	db, err := sql.Open("mysql", tlsDsn)
+	c.Assert(err, IsNil)
+	err = db.Ping()
+	c.Assert(err, NotNil)
+	db.Close()
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	c.Assert(err, IsNil)
+	err = mysql.RegisterTLSConfig("tidb-test-client", &tls.Config{
+		Certificates:       []tls.Certificate{cert},
+		InsecureSkipVerify: true,
+	})
+	c.Assert(err, IsNil)
+	runTests(c, tlsDsn+"&tls=tidb-test-client", func(dbt *DBTest) {
+		var name, cipher string
+		err := dbt.db.QueryRow("show status like 'Ssl_cipher'").Scan(&name, &cipher)
+		dbt.Assert(err, IsNil)
+		dbt.Assert(cipher, Not(Equals), "")
+	})
+}
+
+// rawClient is a minimal MySQL protocol client, it's used to test the features
+// which are not supported by the go-sql-driver.
+type rawClient struct {
+	conn         net.Conn
+	pkg          *packetIO
+	connectionID uint32
+	salt         []byte
+}
+
+// rawResult is the result of a statement read by rawClient.
+type rawResult struct {
+	errCode      uint16
+	affectedRows uint64
+	rows         [][]byte
+	status       uint16
+}
+
+// newRawClient connects to the server at addr as root without password and uses the test database.
+func newRawClient(c *C, addr string, capability uint32) *rawClient {
+	conn, err := net.Dial("tcp", addr)
+	c.Assert(err, IsNil)
+	rc := &rawClient{conn: conn, pkg: newPacketIO(conn)}
+	// Initial handshake packet.
+	data, err := rc.pkg.readPacket()
+	c.Assert(err, IsNil)
+	pos := 1 + bytes.IndexByte(data[1:], 0) + 1
+	rc.connectionID = binary.LittleEndian.Uint32(data[pos:])
+	pos += 4
+	rc.salt = append(rc.salt, data[pos:pos+8]...)
+	// Skip filler, capability, charset, status, capability, auth data length and reserved bytes.
+	pos += 8 + 1 + 2 + 1 + 2 + 2 + 1 + 10
+	rc.salt = append(rc.salt, data[pos:pos+12]...)
+
+	capability |= tmysql.ClientProtocol41 | tmysql.ClientSecureConnection | tmysql.ClientConnectWithDB
+	data = make([]byte, 4, 64)
+	data = append(data, dumpUint32(capability)...)
+	data = append(data, dumpUint32(uint32(tmysql.MaxPayloadLen))...)
+	data = append(data, tmysql.DefaultCollationID)
+	data = append(data, make([]byte, 23)...)
+	data = append(data, "root"...)
+	// Empty auth data.
+	data = append(data, 0, 0)
+	data = append(data, "test"...)
+	data = append(data, 0)
+	c.Assert(rc.pkg.writePacket(data), IsNil)
+	c.Assert(rc.pkg.flush(), IsNil)
+	data, err = rc.pkg.readPacket()
+	c.Assert(err, IsNil)
+	c.Assert(data[0], Equals, tmysql.OKHeader)
+	if capability&tmysql.ClientCompress > 0 {
+		rc.pkg.enableCompression()
+	}
+	return rc
+}
+
+func (rc *rawClient) close() {
+	rc.conn.Close()
+}
+
+// command sends a command packet to the server.
+func (rc *rawClient) command(c *C, cmd byte, arg []byte) {
+	rc.pkg.resetSequence()
+	data := make([]byte, 4, 5+len(arg))
+	data = append(data, cmd)
+	data = append(data, arg...)
+	c.Assert(rc.pkg.writePacket(data), IsNil)
+	c.Assert(rc.pkg.flush(), IsNil)
+}
+
+// query sends a COM_QUERY and reads all the results of it,
+// each row is returned as the concatenation of its values.
+// changeUser sends a COM_CHANGE_USER and returns the reply.
+func (rc *rawClient) changeUser(c *C, user, password, dbname string) *rawResult {
+	var auth []byte
+	if password != "" {
+		auth = util.CalcPassword(rc.salt, util.Sha1Hash([]byte(password)))
+	}
+	var arg []byte
+	arg = append(arg, user...)
+	arg = append(arg, 0, byte(len(auth)))
+	arg = append(arg, auth...)
+	arg = append(arg, dbname...)
+	arg = append(arg, 0)
+	arg = append(arg, dumpUint16(uint16(tmysql.DefaultCollationID))...)
+	rc.command(c, tmysql.ComChangeUser, arg)
+	return rc.readResult(c)
+}
+
+func (rc *rawClient) query(c *C, sql string) []*rawResult {
+	rc.command(c, tmysql.ComQuery, []byte(sql))
+	return rc.readResults(c)
+}
+
+func (rc *rawClient) readResults(c *C) []*rawResult {
+	var results []*rawResult
+	for {
+		r := rc.readResult(c)
+		results = append(results, r)
+		if r.errCode != 0 || r.status&tmysql.ServerMoreResultsExists == 0 {
+			return results
+		}
+	}
+}
+
+func (rc *rawClient) readResult(c *C) *rawResult {
+	data, err := rc.pkg.readPacket()
+	c.Assert(err, IsNil)
+	r := &rawResult{}
+	switch data[0] {
+	case tmysql.ErrHeader:
+		r.errCode = binary.LittleEndian.Uint16(data[1:3])
+	case tmysql.OKHeader:
+		pos := 1
+		affectedRows, _, n := parseLengthEncodedInt(data[pos:])
+		pos += n
+		_, _, n = parseLengthEncodedInt(data[pos:])
+		pos += n
+		r.affectedRows = affectedRows
+		r.status = binary.LittleEndian.Uint16(data[pos:])
+	default:
+		columns, _, _ := parseLengthEncodedInt(data)
+		for i := uint64(0); i < columns; i++ {
+			_, err = rc.pkg.readPacket()
+			c.Assert(err, IsNil)
+		}
+		data, err = rc.pkg.readPacket()
+		c.Assert(err, IsNil)
+		c.Assert(data[0], Equals, tmysql.EOFHeader)
+		for {
+			data, err = rc.pkg.readPacket()
+			c.Assert(err, IsNil)
+			if data[0] == tmysql.EOFHeader && len(data) < 9 {
+				r.status = binary.LittleEndian.Uint16(data[3:])
+				break
+			}
+			var row []byte
+			for pos := 0; pos < len(data); {
+				v, _, n, err := parseLengthEncodedBytes(data[pos:])
+				c.Assert(err, IsNil)
+				row = append(row, v...)
+				pos += n
+			}
+			r.rows = append(r.rows, row)
+		}
+	}
+	return r
+}
+
+func runTestMultiStatements(c *C) {
+	rc := newRawClient(c, "localhost:4001", tmysql.ClientMultiStatements|tmysql.ClientMultiResults)
+	defer rc.close()
+	results := rc.query(c, "drop table if exists test; create table test (a int)")
+	c.Assert(results, HasLen, 2)
+	c.Assert(results[1].errCode, Equals, uint16(0))
+	results = rc.query(c, "insert test values (1), (2); select a from test order by a; update test set a = a + 10; select a from test order by a")
+	c.Assert(results, HasLen, 4)
+	c.Assert(results[0].affectedRows, Equals, uint64(2))
+	c.Assert(results[1].rows, DeepEquals, [][]byte{[]byte("1"), []byte("2")})
+	c.Assert(results[2].affectedRows, Equals, uint64(2))
+	c.Assert(results[3].rows, DeepEquals, [][]byte{[]byte("11"), []byte("12")})
+	for i, r := range results {
+		more := r.status&tmysql.ServerMoreResultsExists > 0
+		c.Assert(more, Equals, i < len(results)-1)
+	}
+
+	// An error stops the rest statements.
+	results = rc.query(c, "insert test values (3); select * from unknown_table; insert test values (4)")
+	c.Assert(results, HasLen, 2)
+	c.Assert(results[0].affectedRows, Equals, uint64(1))
+	c.Assert(results[1].errCode, Equals, uint16(tmysql.ErrNoSuchTable))
+	results = rc.query(c, "select count(*) from test")
+	c.Assert(results[0].rows, DeepEquals, [][]byte{[]byte("3")})
+
+	// Multiple statements are rejected after they are turned off.
+	rc.command(c, tmysql.ComSetOption, dumpUint16(mysqlOptionMultiStatementsOff))
+	data, err := rc.pkg.readPacket()
+	c.Assert(err, IsNil)
+	c.Assert(data[0], Equals, tmysql.EOFHeader)
+	results = rc.query(c, "select 1; select 2")
+	c.Assert(results[0].errCode, Not(Equals), uint16(0))
+	results = rc.query(c, "drop table test")
+	c.Assert(results[0].errCode, Equals, uint16(0))
+	// An empty query gets an OK packet.
+	results = rc.query(c, "")
+	c.Assert(results, HasLen, 1)
+	c.Assert(results[0].errCode, Equals, uint16(0))
+}
+
+func runTestChangeUser(c *C) {
+	rc := newRawClient(c, "localhost:4001", 0)
+	defer rc.close()
+	results := rc.query(c, "create user 'changeuser'@'%' identified by '123'")
+	c.Assert(results[0].errCode, Equals, uint16(0))
+	results = rc.query(c, "set @a = 1")
+	c.Assert(results[0].errCode, Equals, uint16(0))
+
+	r := rc.changeUser(c, "changeuser", "123", "mysql")
+	c.Assert(r.errCode, Equals, uint16(0))
+	results = rc.query(c, "select current_user(), database(), @a")
+	c.Assert(results[0].rows, DeepEquals, [][]byte{[]byte("changeuser@127.0.0.1mysql")})
+
+	// A wrong password closes the connection.
+	r = rc.changeUser(c, "root", "wrong", "")
+	c.Assert(r.errCode, Equals, uint16(tmysql.ErrAccessDenied))
+	_, err := rc.pkg.readPacket()
+	c.Assert(err, NotNil)
+}
+
+func runTestResetConnection(c *C) {
+	rc := newRawClient(c, "localhost:4001", 0)
+	defer rc.close()
+	for _, sql := range []string{
+		"drop table if exists test",
+		"create table test (a int)",
+		"set @a = 1",
+		"prepare s from 'select 1'",
+		"begin",
+		"insert test values (1)",
+	} {
+		results := rc.query(c, sql)
+		c.Assert(results[0].errCode, Equals, uint16(0), Commentf("sql %s", sql))
+	}
+
+	rc.command(c, tmysql.ComResetConnection, nil)
+	r := rc.readResult(c)
+	c.Assert(r.errCode, Equals, uint16(0))
+	c.Assert(r.status&tmysql.ServerStatusInTrans, Equals, uint16(0))
+	c.Assert(r.status&tmysql.ServerStatusAutocommit, Not(Equals), uint16(0))
+
+	// The user variables, prepared statements and the transaction are dropped, but the database is kept.
+	results := rc.query(c, "select @a, database()")
+	c.Assert(results[0].rows, DeepEquals, [][]byte{[]byte("test")})
+	results = rc.query(c, "execute s")
+	c.Assert(results[0].errCode, Not(Equals), uint16(0))
+	results = rc.query(c, "select count(*) from test")
+	c.Assert(results[0].rows, DeepEquals, [][]byte{[]byte("0")})
+	results = rc.query(c, "drop table test")
+	c.Assert(results[0].errCode, Equals, uint16(0))
+}
+
+func runTestProcessKill(c *C) {
+	rc1 := newRawClient(c, "localhost:4001", 0)
+	defer rc1.close()
+	rc2 := newRawClient(c, "localhost:4001", 0)
+	defer rc2.close()
+	// Make sure rc2 is registered to the server.
+	results := rc2.query(c, "select 1")
+	c.Assert(results[0].errCode, Equals, uint16(0))
+
+	rc1.command(c, tmysql.ComProcessKill, dumpUint32(rc2.connectionID))
+	r := rc1.readResult(c)
+	c.Assert(r.errCode, Equals, uint16(0))
+	// The killed connection is closed.
+	rc2.command(c, tmysql.ComPing, nil)
+	_, err := rc2.pkg.readPacket()
+	c.Assert(err, NotNil)
+
+	rc1.command(c, tmysql.ComProcessKill, dumpUint32(rc2.connectionID))
+	r = rc1.readResult(c)
+	c.Assert(r.errCode, Equals, uint16(tmysql.ErrNoSuchThread))
+}
+
+func runTestShowProcessList(c *C) {
+	rc1 := newRawClient(c, "localhost:4001", 0)
+	defer rc1.close()
+	rc2 := newRawClient(c, "localhost:4001", 0)
+	defer rc2.close()
+	results := rc2.query(c, "select 1")
+	c.Assert(results[0].errCode, Equals, uint16(0))
+
+	results = rc1.query(c, "show full processlist")
+	c.Assert(results[0].errCode, Equals, uint16(0))
+	c.Assert(len(results[0].rows) >= 2, IsTrue)
+
+	sql := "select command, info from information_schema.processlist where id = %d"
+	results = rc1.query(c, fmt.Sprintf(sql, rc2.connectionID))
+	c.Assert(results[0].rows, DeepEquals, [][]byte{[]byte("Sleep")})
+	query := fmt.Sprintf(sql, rc1.connectionID)
+	results = rc1.query(c, query)
+	c.Assert(results[0].rows, DeepEquals, [][]byte{[]byte("Query" + query)})
+}
+
+func runTestKillStmt(c *C) {
+	rc1 := newRawClient(c, "localhost:4001", 0)
+	defer rc1.close()
+	rc2 := newRawClient(c, "localhost:4001", 0)
+	defer rc2.close()
+	results := rc2.query(c, "select 1")
+	c.Assert(results[0].errCode, Equals, uint16(0))
+
+	// The connection is still usable after its query is killed.
+	results = rc1.query(c, fmt.Sprintf("kill query %d", rc2.connectionID))
+	c.Assert(results[0].errCode, Equals, uint16(0))
+	results = rc2.query(c, "select 1")
+	c.Assert(results[0].rows, DeepEquals, [][]byte{[]byte("1")})
+
+	results = rc1.query(c, fmt.Sprintf("kill connection %d", rc2.connectionID))
+	c.Assert(results[0].errCode, Equals, uint16(0))
+	rc2.command(c, tmysql.ComPing, nil)
+	_, err := rc2.pkg.readPacket()
+	c.Assert(err, NotNil)
+
+	results = rc1.query(c, fmt.Sprintf("kill %d", rc2.connectionID))
+	c.Assert(results[0].errCode, Equals, uint16(tmysql.ErrNoSuchThread))
+}
@@ -14,11 +14,14 @@
 package server
 
 import (
+	"io/ioutil"
+	"os"
 	"time"
 
 	"github.com/ngaut/log"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
+	tmysql "github.com/pingcap/tidb/mysql"
 )
 
 type TidbTestSuite struct {
@@ -90,10 +93,42 @@ func (ts *TidbTestSuite) TestResultFieldTableIsNull(c *C) {
 	runTestResultFieldTableIsNull(c)
 }
 
+func (ts *TidbTestSuite) TestMultiStatements(c *C) {
+	runTestMultiStatements(c)
+}
+
+func (ts *TidbTestSuite) TestChangeUser(c *C) {
+	runTestChangeUser(c)
+}
+
+func (ts *TidbTestSuite) TestResetConnection(c *C) {
+	runTestResetConnection(c)
+}
+
+func (ts *TidbTestSuite) TestProcessKill(c *C) {
+	runTestProcessKill(c)
+}
+
+func (ts *TidbTestSuite) TestShowProcessList(c *C) {
+	runTestShowProcessList(c)
+}
+
+func (ts *TidbTestSuite) TestKillStmt(c *C) {
+	runTestKillStmt(c)
+}
+
 func (ts *TidbTestSuite) TestStatusAPI(c *C) {
 	runTestStatusAPI(c)
 }
 
+func (ts *TidbTestSuite) TestMetricsAPI(c *C) {
+	runTestMetricsAPI(c)
+}
+
+func (ts *TidbTestSuite) TestAdminAPI(c *C) {
+	runTestAdminAPI(c)
+}
+
 func (ts *TidbTestSuite) TestSocket(c *C) {
 	cfg := &Config{
 		LogLevel:   "debug",
@@ -110,3 +145,38 @@ func (ts *TidbTestSuite) TestSocket(c *C) {
 	dsn = tcpDsn
 	server.Close()
 }
+
+func (ts *TidbTestSuite) TestTLS(c *C) {
+	dir, err := ioutil.TempDir("", "tidb-tls")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	certPath, keyPath := generateCert(c, dir)
+	cfg := &Config{
+		Addr:       ":4002",
+		LogLevel:   "debug",
+		StatusAddr: ":10092",
+		SSLCert:    certPath,
+		SSLKey:     keyPath,
+	}
+	server, err := NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, IsNil)
+	c.Assert(server.capability&tmysql.ClientSSL, Greater, uint32(0))
+	go server.Run()
+	time.Sleep(time.Millisecond * 100)
+	runTestTLSConnection(c, "root@tcp(localhost:4002)/test?strict=true")
+	server.Close()
+
+	// The plaintext connections are rejected if the client certificate is required.
+	cfg.SSLCA = certPath
+	cfg.SSLVerifyClient = true
+	server, err = NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, IsNil)
+	go server.Run()
+	time.Sleep(time.Millisecond * 100)
+	runTestVerifyClientConnection(c, "root@tcp(localhost:4002)/test?strict=true", certPath, keyPath)
+	server.Close()
+
+	cfg.SSLKey = ""
+	_, err = NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, NotNil)
+}
@@ -19,8 +19,10 @@ package tidb
 
 import (
 	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -30,6 +32,7 @@ import (
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
@@ -50,11 +53,14 @@ import (
 
 // Session context
 type Session interface {
-	Status() uint16                              // Flag of current status, such as autocommit
-	LastInsertID() uint64                        // Last inserted auto_increment id
-	AffectedRows() uint64                        // Affected rows by latest executed stmt
-	Execute(sql string) ([]ast.RecordSet, error) // Execute a sql statement
-	String() string                              // For debug
+	Status() uint16                                  // Flag of current status, such as autocommit
+	LastInsertID() uint64                            // Last inserted auto_increment id
+	AffectedRows() uint64                            // Affected rows by latest executed stmt
+	WarningCount() uint16                            // Warning count of latest executed stmt
+	Execute(sql string) ([]ast.RecordSet, error)     // Execute a sql statement
+	Parse(sql string) ([]ast.StmtNode, error)        // Parse a sql to statement nodes
+	ExecuteStmt(ast.StmtNode) (ast.RecordSet, error) // Execute a parsed statement
+	String() string                                  // For debug
 	CommitTxn() error
 	RollbackTxn() error
 	// For execute prepare statement in binary protocol
@@ -64,6 +70,12 @@ type Session interface {
 	DropPreparedStmt(stmtID uint32) error
 	SetClientCapability(uint32) // Set client capability flags
 	SetConnectionID(uint64)
+	SetTLSState(*tls.ConnectionState)
+	Reset() error // Reset the session state, but keep the connection and the current user
+	Cancel()      // Interrupt the running statement, it's safe to be called by other goroutines
+	SetSessionManager(util.SessionManager)
+	SetProcessInfo(command, sql string) // Record the command for show processlist
+	ShowProcess() util.ProcessInfo      // Get the process info, it's safe to be called by other goroutines
 	Close() error
 	Retry() error
 	Auth(user string, auth []byte, salt []byte) bool
@@ -122,6 +134,13 @@ type session struct {
 
 	// For performance_schema only.
 	stmtState *perfschema.StatementState
+
+	// processInfo holds the *util.ProcessInfo of the current command.
+	processInfo atomic.Value
+
+	// killed is the kill flag of the session, the session vars point to it. It's set by another goroutine,
+	// so it must be accessed atomically.
+	killed uint32
 }
 
 func (s *session) cleanRetryInfo() {
@@ -142,6 +161,10 @@ func (s *session) AffectedRows() uint64 {
 	return variable.GetSessionVars(s).AffectedRows
 }
 
+func (s *session) WarningCount() uint16 {
+	return uint16(len(variable.GetSessionVars(s).StmtWarnings))
+}
+
 func (s *session) resetHistory() {
 	s.ClearValue(forupdate.ForUpdateKey)
 	s.history.reset()
@@ -155,6 +178,65 @@ func (s *session) SetConnectionID(connectionID uint64) {
 	variable.GetSessionVars(s).ConnectionID = connectionID
 }
 
+func (s *session) SetTLSState(tlsState *tls.ConnectionState) {
+	variable.GetSessionVars(s).TLSConnectionState = tlsState
+}
+
+// Reset rolls back the current transaction and binds fresh session variables,
+// so the user variables, session system variables and prepared statements are dropped.
+// The connection level states and the current user are kept.
+func (s *session) Reset() error {
+	if err := s.RollbackTxn(); err != nil {
+		return errors.Trace(err)
+	}
+	old := variable.GetSessionVars(s)
+	variable.BindSessionVars(s)
+	vars := variable.GetSessionVars(s)
+	vars.SetStatusFlag(mysql.ServerStatusAutocommit, true)
+	vars.ClientCapability = old.ClientCapability
+	vars.ConnectionID = old.ConnectionID
+	vars.TLSConnectionState = old.TLSConnectionState
+	vars.User = old.User
+	vars.Killed = &s.killed
+	s.history = stmtHistory{}
+	return nil
+}
+
+// Cancel marks the session as killed, the running statement returns an error
+// when it fetches the next row.
+func (s *session) Cancel() {
+	atomic.StoreUint32(&s.killed, 1)
+}
+
+func (s *session) SetSessionManager(sm util.SessionManager) {
+	util.BindSessionManager(s, sm)
+}
+
+// SetProcessInfo records the command the session is running and the time it starts.
+func (s *session) SetProcessInfo(command, sql string) {
+	vars := variable.GetSessionVars(s)
+	pi := &util.ProcessInfo{
+		ID:      vars.ConnectionID,
+		DB:      db.GetCurrentSchema(s),
+		Command: command,
+		Time:    time.Now(),
+		State:   vars.Status,
+		Info:    sql,
+	}
+	if strs := strings.Split(vars.User, "@"); len(strs) == 2 {
+		pi.User, pi.Host = strs[0], strs[1]
+	}
+	s.processInfo.Store(pi)
+}
+
+// ShowProcess returns the process info recorded by SetProcessInfo.
+func (s *session) ShowProcess() util.ProcessInfo {
+	if pi, ok := s.processInfo.Load().(*util.ProcessInfo); ok {
+		return *pi
+	}
+	return util.ProcessInfo{}
+}
+
 func (s *session) finishTxn(rollback bool) error {
 	// transaction has already been committed or rolled back
 	if s.txn == nil {
@@ -163,6 +245,7 @@ func (s *session) finishTxn(rollback bool) error {
 	defer func() {
 		s.ClearValue(executor.DirtyDBKey)
 		s.txn = nil
+		variable.GetSessionVars(s).TableDeltaMap = nil
 		variable.GetSessionVars(s).SetStatusFlag(mysql.ServerStatusInTrans, false)
 		// Update tps metrics
 		if !variable.GetSessionVars(s).RetryInfo.Retrying {
@@ -173,18 +256,27 @@ func (s *session) finishTxn(rollback bool) error {
 	if rollback {
 		s.resetHistory()
 		s.cleanRetryInfo()
-		return s.txn.Rollback()
+		err := s.txn.Rollback()
+		if !variable.GetSessionVars(s).RetryInfo.Retrying {
+			txnCounter.WithLabelValues("rollback", resultLabel(err)).Inc()
+		}
+		return err
 	}
 
+	retrying := variable.GetSessionVars(s).RetryInfo.Retrying
 	err := s.txn.Commit()
+	if err != nil && !retrying && kv.IsRetryableError(err) {
+		err = s.Retry()
+	}
+	if !retrying {
+		txnCounter.WithLabelValues("commit", resultLabel(err)).Inc()
+	}
 	if err != nil {
-		if !variable.GetSessionVars(s).RetryInfo.Retrying && kv.IsRetryableError(err) {
-			err = s.Retry()
-		}
-		if err != nil {
-			log.Warnf("txn:%s, %v", s.txn, err)
-			return errors.Trace(err)
-		}
+		log.Warnf("txn:%s, %v", s.txn, err)
+		return errors.Trace(err)
+	}
+	if h := sessionctx.GetDomain(s).StatsHandle(); h != nil {
+		h.UpdateDelta(variable.GetSessionVars(s).TableDeltaMap)
 	}
 
 	s.resetHistory()
@@ -216,7 +308,7 @@ func (s *session) String() string {
 	return string(b)
 }
 
-func (s *session) Retry() error {
+func (s *session) Retry() (err error) {
 	variable.GetSessionVars(s).RetryInfo.Retrying = true
 	nh := s.history.clone()
 	// Debug infos.
@@ -228,12 +320,12 @@ func (s *session) Retry() error {
 	defer func() {
 		s.history.history = nh.history
 		variable.GetSessionVars(s).RetryInfo.Retrying = false
+		retryCounter.WithLabelValues(resultLabel(err)).Inc()
 	}()
 
 	if forUpdate := s.Value(forupdate.ForUpdateKey); forUpdate != nil {
 		return errors.Errorf("can not retry select for update statement")
 	}
-	var err error
 	retryCnt := 0
 	for {
 		variable.GetSessionVars(s).RetryInfo.Attempts = retryCnt + 1
@@ -289,6 +381,9 @@ func (s *session) ExecRestrictedSQL(ctx context.Context, sql string) (ast.Record
 	// For example only support DML on system meta table.
 	// TODO: Add more restrictions.
 	log.Debugf("Executing %s [%s]", st.OriginText(), sql)
+	// The restricted SQL may run inside a user statement, keep the plan of the latter for the slow query log.
+	vars := variable.GetSessionVars(ctx)
+	defer func(p interface{}) { vars.StmtStats.Plan = p }(vars.StmtStats.Plan)
 	rs, err := st.Exec(ctx)
 	return rs, errors.Trace(err)
 }
@@ -340,7 +435,75 @@ func (s *session) SetGlobalSysVar(ctx context.Context, name string, value string
 	sql := fmt.Sprintf(`UPDATE  %s.%s SET VARIABLE_VALUE="%s" WHERE VARIABLE_NAME="%s";`,
 		mysql.SystemDB, mysql.GlobalVariablesTable, value, strings.ToLower(name))
 	_, err := s.ExecRestrictedSQL(ctx, sql)
-	return errors.Trace(err)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	switch strings.ToLower(name) {
+	case variable.SlowQueryLog, variable.SlowQueryLogFile, variable.LongQueryTime:
+		slowLog.expire()
+	}
+	return nil
+}
+
+// setStmtDeadline sets the deadline of the statement to be executed. Only SELECT and UNION statements
+// have deadlines, which are set by the MAX_EXECUTION_TIME hint or max_execution_time system variable.
+// The hint of a UNION statement is taken from its first SELECT.
+func (s *session) setStmtDeadline(node ast.StmtNode) {
+	vars := variable.GetSessionVars(s)
+	vars.StmtDeadline = time.Time{}
+	var sel *ast.SelectStmt
+	switch x := node.(type) {
+	case *ast.SelectStmt:
+		sel = x
+	case *ast.UnionStmt:
+		sel = x.SelectList.Selects[0]
+	default:
+		return
+	}
+	timeout := s.maxExecutionTime(s)
+	for _, hint := range sel.TableHints {
+		if hint.HintName.L == "max_execution_time" {
+			timeout = hint.MaxExecutionTime
+		}
+	}
+	if timeout > 0 {
+		vars.StmtDeadline = time.Now().Add(time.Duration(timeout) * time.Millisecond)
+	}
+}
+
+// resetStmtWarnings clears the warnings of the last statement before a new statement is executed,
+// SHOW WARNINGS keeps them to show.
+func (s *session) resetStmtWarnings(node ast.StmtNode) {
+	if show, ok := node.(*ast.ShowStmt); ok && show.Tp == ast.ShowWarnings {
+		return
+	}
+	variable.GetSessionVars(s).StmtWarnings = nil
+}
+
+// maxExecutionTime returns the value of max_execution_time system variable.
+func (s *session) maxExecutionTime(ctx context.Context) uint64 {
+	sessionVar := variable.GetSessionVars(ctx)
+	timeout := sessionVar.GetSystemVar(variable.MaxExecutionTime)
+	if timeout.IsNull() {
+		if s.initing {
+			return 0
+		}
+		timeoutStr, err := s.GetGlobalSysVar(ctx, variable.MaxExecutionTime)
+		if err != nil {
+			log.Errorf("Get global sys var error: %v", err)
+			return 0
+		}
+		timeout.SetString(timeoutStr)
+		err = sessionVar.SetSystemVar(variable.MaxExecutionTime, timeout)
+		if err != nil {
+			log.Errorf("Set session sys var error: %v", err)
+		}
+	}
+	v, err := strconv.ParseUint(timeout.GetString(), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
 }
 
 // IsAutocommit checks if it is in the auto-commit mode.
@@ -381,25 +544,14 @@ func (s *session) ShouldAutocommit(ctx context.Context) bool {
 }
 
 func (s *session) Execute(sql string) ([]ast.RecordSet, error) {
-	rawStmts, err := Parse(s, sql)
+	rawStmts, err := s.Parse(sql)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 	var rs []ast.RecordSet
-	ph := sessionctx.GetDomain(s).PerfSchema()
-	for i, rst := range rawStmts {
-		st, err1 := Compile(s, rst)
-		if err1 != nil {
-			log.Errorf("Syntax error: %s", sql)
-			log.Errorf("Error occurs at %s.", err1)
-			return nil, errors.Trace(err1)
-		}
-		id := variable.GetSessionVars(s).ConnectionID
-		s.stmtState = ph.StartStatement(sql, id, perfschema.CallerNameSessionExecute, rawStmts[i])
-		r, err := runStmt(s, st)
-		ph.EndStatement(s.stmtState)
+	for _, rst := range rawStmts {
+		r, err := s.executeStmt(sql, rst)
 		if err != nil {
-			log.Warnf("session:%v, err:%v", s, err)
 			return nil, errors.Trace(err)
 		}
 		if r != nil {
@@ -409,6 +561,50 @@ func (s *session) Execute(sql string) ([]ast.RecordSet, error) {
 	return rs, nil
 }
 
+// Parse parses a query string to raw statement nodes.
+func (s *session) Parse(sql string) ([]ast.StmtNode, error) {
+	rawStmts, err := Parse(s, sql)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return rawStmts, nil
+}
+
+// ExecuteStmt executes a raw statement node returned by Parse.
+func (s *session) ExecuteStmt(rawStmt ast.StmtNode) (ast.RecordSet, error) {
+	r, err := s.executeStmt(rawStmt.Text(), rawStmt)
+	return r, errors.Trace(err)
+}
+
+func (s *session) executeStmt(sql string, rawStmt ast.StmtNode) (ast.RecordSet, error) {
+	s.resetStmtWarnings(rawStmt)
+	st, err := Compile(s, rawStmt)
+	if err != nil {
+		log.Errorf("Syntax error: %s", sql)
+		log.Errorf("Error occurs at %s.", err)
+		return nil, errors.Trace(err)
+	}
+	id := variable.GetSessionVars(s).ConnectionID
+	ph := sessionctx.GetDomain(s).PerfSchema()
+	s.stmtState = ph.StartStatement(sql, id, perfschema.CallerNameSessionExecute, rawStmt)
+	s.setStmtDeadline(rawStmt)
+	text := rawStmt.Text()
+	if text == "" {
+		text = sql
+	}
+	tracker := s.trackSlowQuery(text)
+	startTime := time.Now()
+	r, err := runStmt(s, st)
+	r = observeQuery(r, stmtLabel(rawStmt), startTime)
+	ph.EndStatement(s.stmtState)
+	r = tracker.track(r)
+	if err != nil {
+		log.Warnf("session:%v, err:%v", s, err)
+		return nil, errors.Trace(err)
+	}
+	return r, nil
+}
+
 // For execute prepare statement in binary protocol
 func (s *session) PrepareStmt(sql string) (stmtID uint32, paramCount int, fields []*ast.ResultField, err error) {
 	prepareExec := &executor.PrepareExec{
@@ -473,7 +669,19 @@ func (s *session) ExecutePreparedStmt(stmtID uint32, args ...interface{}) (ast.R
 		return nil, err
 	}
 	st := executor.CompileExecutePreparedStmt(s, stmtID, args...)
+	var rawStmt ast.StmtNode
+	var text string
+	if prepared, ok := variable.GetSessionVars(s).PreparedStmts[stmtID].(*executor.Prepared); ok {
+		rawStmt = prepared.Stmt
+		text = rawStmt.Text()
+	}
+	s.resetStmtWarnings(rawStmt)
+	s.setStmtDeadline(rawStmt)
+	tracker := s.trackSlowQuery(text)
+	startTime := time.Now()
 	r, err := runStmt(s, st, args...)
+	r = observeQuery(r, stmtLabel(rawStmt), startTime)
+	r = tracker.track(r)
 	return r, errors.Trace(err)
 }
 
@@ -515,6 +723,7 @@ func (s *session) GetTxn(forceNew bool) (kv.Transaction, error) {
 		}
 		log.Warnf("Force new txn:%s in session:%d", s.txn, s.sid)
 	}
+	variable.GetSessionVars(s).StmtStats.TxnStartTS = s.txn.StartTS()
 	retryInfo := variable.GetSessionVars(s).RetryInfo
 	if retryInfo.Retrying {
 		s.txn.SetOption(kv.RetryAttempts, retryInfo.Attempts)
@@ -604,12 +813,40 @@ func chooseMinLease(n1 time.Duration, n2 time.Duration) time.Duration {
 
 // CreateSession creates a new session environment.
 func CreateSession(store kv.Storage) (Session, error) {
+	s, err := createSession(store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	err = loadStatistics(store, sessionctx.GetDomain(s))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return s, nil
+}
+
+var statsMu sync.Mutex
+
+// loadStatistics makes the domain load the table statistics with a dedicated session if it hasn't.
+func loadStatistics(store kv.Storage, do *domain.Domain) error {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	if do.StatsHandle() != nil {
+		return nil
+	}
+	se, err := createSession(store)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(do.UpdateTableStatsLoop(se))
+}
+
+func createSession(store kv.Storage) (*session, error) {
 	s := &session{
 		values:      make(map[fmt.Stringer]interface{}),
 		store:       store,
 		sid:         atomic.AddInt64(&sessionID, 1),
 		debugInfos:  make(map[string]interface{}),
-		maxRetryCnt: 10,
+		maxRetryCnt: commitRetryLimit,
 	}
 	domain, err := domap.Get(store)
 	if err != nil {
@@ -619,6 +856,7 @@ func CreateSession(store kv.Storage) (Session, error) {
 
 	variable.BindSessionVars(s)
 	variable.GetSessionVars(s).SetStatusFlag(mysql.ServerStatusAutocommit, true)
+	variable.GetSessionVars(s).Killed = &s.killed
 
 	// session implements variable.GlobalVarAccessor. Bind it to ctx.
 	variable.BindGlobalVarAccessor(s, s)
@@ -628,8 +866,8 @@ func CreateSession(store kv.Storage) (Session, error) {
 	sessionMu.Lock()
 	defer sessionMu.Unlock()
 
-	ok := isBoostrapped(store)
-	if !ok {
+	ver := getStoreBootstrapVersion(store)
+	if ver < currentBootstrapVersion {
 		// if no bootstrap and storage is remote, we must use a little lease time to
 		// bootstrap quickly, after bootstrapped, we will reset the lease time.
 		// TODO: Using a bootstap tool for doing this may be better later.
@@ -654,18 +892,20 @@ func CreateSession(store kv.Storage) (Session, error) {
 	return s, nil
 }
 
-func isBoostrapped(store kv.Storage) bool {
+// getStoreBootstrapVersion returns the bootstrap version of the store, 0 means it isn't bootstrapped.
+func getStoreBootstrapVersion(store kv.Storage) int64 {
 	// check in memory
 	_, ok := storeBootstrapped[store.UUID()]
 	if ok {
-		return true
+		return currentBootstrapVersion
 	}
 
+	var ver int64
 	// check in kv store
 	err := kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
 		var err error
 		t := meta.NewMeta(txn)
-		ok, err = t.IsBootstrapped()
+		ver, err = t.GetBootstrapVersion()
 		return errors.Trace(err)
 	})
 
@@ -673,12 +913,12 @@ func isBoostrapped(store kv.Storage) bool {
 		log.Fatalf("check bootstrapped err %v", err)
 	}
 
-	if ok {
+	if ver >= currentBootstrapVersion {
 		// here mean memory is not ok, but other server has already finished it
 		storeBootstrapped[store.UUID()] = true
 	}
 
-	return ok
+	return ver
 }
 
 func finishBoostrap(store kv.Storage) {
@@ -686,7 +926,7 @@ func finishBoostrap(store kv.Storage) {
 
 	err := kv.RunInNewTxn(store, true, func(txn kv.Transaction) error {
 		t := meta.NewMeta(txn)
-		err := t.FinishBootstrap()
+		err := t.FinishBootstrap(currentBootstrapVersion)
 		return errors.Trace(err)
 	})
 	if err != nil {
@@ -15,6 +15,9 @@ package tidb
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -25,6 +28,7 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
@@ -33,6 +37,8 @@ import (
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 )
 
 var _ = Suite(&testSessionSuite{})
@@ -1400,6 +1406,35 @@ func (s *testSessionSuite) TestBootstrapWithError(c *C) {
 	c.Assert(err, IsNil)
 }
 
+// TestUpgrade tests upgrading the store bootstrapped by version1, which has no statistics tables.
+func (s *testSessionSuite) TestUpgrade(c *C) {
+	defer testleak.AfterTest(c)()
+	store := newStore(c, s.dbNameBootstrap+"_upgrade")
+	se := newSession(c, store, s.dbName)
+	mustExecSQL(c, se, "drop table mysql.stats_meta, mysql.stats_histograms")
+	mustExecSQL(c, se, fmt.Sprintf(`delete from mysql.TiDB where VARIABLE_NAME="%s"`, tidbServerVersionVar))
+	err := kv.RunInNewTxn(store, true, func(txn kv.Transaction) error {
+		return meta.NewMeta(txn).FinishBootstrap(version1)
+	})
+	c.Assert(err, IsNil)
+	delete(storeBootstrapped, store.UUID())
+	se.Close()
+
+	se = newSession(c, store, s.dbName)
+	mustExecSQL(c, se, "select * from mysql.stats_meta")
+	mustExecSQL(c, se, "select * from mysql.stats_histograms")
+	r := mustExecSQL(c, se, fmt.Sprintf(`select VARIABLE_VALUE from mysql.TiDB where VARIABLE_NAME="%s"`, tidbServerVersionVar))
+	row, err := r.Next()
+	c.Assert(err, IsNil)
+	c.Assert(row, NotNil)
+	c.Assert(row.Data[0].GetString(), Equals, fmt.Sprintf("%d", currentBootstrapVersion))
+	c.Assert(getStoreBootstrapVersion(store), Equals, currentBootstrapVersion)
+	se.Close()
+
+	err = store.Close()
+	c.Assert(err, IsNil)
+}
+
 func (s *testSessionSuite) TestEnum(c *C) {
 	defer testleak.AfterTest(c)()
 	store := newStore(c, s.dbName)
@@ -2332,3 +2367,186 @@ func (s *testSessionSuite) TestSelectHaving(c *C) {
 	mustExecMultiSQL(c, se, "select * from select_having_test group by id having null is not null;")
 	mustExecMultiSQL(c, se, "drop table select_having_test")
 }
+
+func (s *testSessionSuite) TestSessionReset(c *C) {
+	defer testleak.AfterTest(c)()
+	store := newStore(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+	se.SetConnectionID(100)
+	mustExecMultiSQL(c, se, `drop table if exists t; create table t (c int);
+		set @a = 1; prepare s from 'select 1'; begin; insert t values (1);`)
+
+	err := se.Reset()
+	c.Assert(err, IsNil)
+	c.Assert(se.Status()&mysql.ServerStatusInTrans, Equals, uint16(0))
+	vars := variable.GetSessionVars(se.(*session))
+	c.Assert(vars.ConnectionID, Equals, uint64(100))
+	c.Assert(vars.User, Equals, "root@%")
+	mustExecMatch(c, se, "select @a, count(*) from t", [][]interface{}{{nil, "0"}})
+	mustExecFailed(c, se, "execute s")
+	mustExecSQL(c, se, "drop table t")
+}
+
+func (s *testSessionSuite) TestSessionCancel(c *C) {
+	defer testleak.AfterTest(c)()
+	store := newStore(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+	mustExecMultiSQL(c, se, "drop table if exists t; create table t (c int); insert t values (1), (2);")
+
+	r := mustExecSQL(c, se, "select * from t")
+	_, err := r.Next()
+	c.Assert(err, IsNil)
+	se.Cancel()
+	_, err = r.Next()
+	c.Assert(terror.ErrorEqual(err, executor.ErrQueryInterrupted), IsTrue)
+	c.Assert(r.Close(), IsNil)
+
+	// The kill flag is cleared by the next statement.
+	mustExecMatch(c, se, "select count(*) from t", [][]interface{}{{"2"}})
+
+	// The session is still killed by another goroutine after it's reset.
+	c.Assert(se.Reset(), IsNil)
+	r = mustExecSQL(c, se, "select * from t")
+	done := make(chan struct{})
+	go func() {
+		se.Cancel()
+		close(done)
+	}()
+	<-done
+	_, err = r.Next()
+	c.Assert(terror.ErrorEqual(err, executor.ErrQueryInterrupted), IsTrue)
+	c.Assert(r.Close(), IsNil)
+	mustExecSQL(c, se, "drop table t")
+}
+
+func (s *testSessionSuite) TestMaxExecutionTime(c *C) {
+	defer testleak.AfterTest(c)()
+	store := newStore(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+	mustExecMultiSQL(c, se, "drop table if exists t; create table t (c int); insert t values (1), (2);")
+
+	// The statement times out after the time set by the hint.
+	r := mustExecSQL(c, se, "select /*+ MAX_EXECUTION_TIME(1) */ * from t")
+	time.Sleep(10 * time.Millisecond)
+	_, err := r.Next()
+	c.Assert(terror.ErrorEqual(err, kv.ErrQueryTimeout), IsTrue)
+	c.Assert(r.Close(), IsNil)
+	mustExecMatch(c, se, "select /*+ MAX_EXECUTION_TIME(100000) */ count(*) from t", [][]interface{}{{"2"}})
+
+	// The same with the system variable.
+	mustExecSQL(c, se, "set @@max_execution_time = 1")
+	r = mustExecSQL(c, se, "select * from t")
+	time.Sleep(10 * time.Millisecond)
+	_, err = r.Next()
+	c.Assert(terror.ErrorEqual(err, kv.ErrQueryTimeout), IsTrue)
+	c.Assert(r.Close(), IsNil)
+	// The hint overrides the system variable.
+	r = mustExecSQL(c, se, "select /*+ MAX_EXECUTION_TIME(0) */ * from t")
+	time.Sleep(10 * time.Millisecond)
+	_, err = r.Next()
+	c.Assert(err, IsNil)
+	c.Assert(r.Close(), IsNil)
+	// A UNION statement times out by the system variable or the hint of its first SELECT.
+	r = mustExecSQL(c, se, "select * from t union select * from t")
+	time.Sleep(10 * time.Millisecond)
+	_, err = r.Next()
+	c.Assert(terror.ErrorEqual(err, kv.ErrQueryTimeout), IsTrue)
+	c.Assert(r.Close(), IsNil)
+	mustExecMatch(c, se, "select /*+ MAX_EXECUTION_TIME(0) */ c from t union select c from t order by c", [][]interface{}{{"1"}, {"2"}})
+	mustExecSQL(c, se, "set @@max_execution_time = 0")
+	r = mustExecSQL(c, se, "select /*+ MAX_EXECUTION_TIME(1) */ * from t union all select * from t")
+	time.Sleep(10 * time.Millisecond)
+	_, err = r.Next()
+	c.Assert(terror.ErrorEqual(err, kv.ErrQueryTimeout), IsTrue)
+	c.Assert(r.Close(), IsNil)
+	mustExecSQL(c, se, "set @@max_execution_time = 1")
+	// Only SELECT statements time out.
+	mustExecSQL(c, se, "insert t values (3)")
+	mustExecSQL(c, se, "set @@max_execution_time = 0")
+	mustExecMatch(c, se, "select count(*) from t", [][]interface{}{{"3"}})
+	mustExecSQL(c, se, "drop table t")
+}
+
+func (s *testSessionSuite) TestSlowQueryLog(c *C) {
+	defer testleak.AfterTest(c)()
+	dir, err := ioutil.TempDir("", "slow_query_log")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "slow.log")
+
+	store := newStore(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+	mustExecMultiSQL(c, se, "drop table if exists t; create table t (c int); insert t values (1), (2), (3);")
+	mustExecSQL(c, se, fmt.Sprintf("set @@global.slow_query_log_file = '%s'", path))
+	mustExecSQL(c, se, "set @@global.slow_query_log = 1")
+	defer mustExecSQL(c, se, "set @@global.slow_query_log = 0")
+	// Only the statements slower than long_query_time are logged.
+	mustExecSQL(c, se, "insert t values (4)")
+	mustExecSQL(c, se, "set @@long_query_time = 0")
+	mustExecMatch(c, se, "select * from t", [][]interface{}{{"1"}, {"2"}, {"3"}, {"4"}})
+
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	content := string(data)
+	c.Assert(content, Not(Matches), "(?s).*insert t values \\(4\\).*")
+	c.Assert(content, Matches, "(?s).*# Time: .*")
+	c.Assert(content, Matches, fmt.Sprintf("(?s).*# User@Host: .* Id: %d\n.*", variable.GetSessionVars(se.(context.Context)).ConnectionID))
+	c.Assert(content, Matches, "(?s).*# Query_time: [0-9.]+  Lock_time: 0.000000  Rows_sent: 4  Rows_examined: 4\n.*")
+	c.Assert(content, Matches, "(?s).*# Txn_start_ts: [1-9][0-9]*  Cop_tasks: [0-9]+  Cop_retries: 0\n.*")
+	c.Assert(content, Matches, "(?s).*# Plan: .*\n.*")
+	c.Assert(content, Matches, fmt.Sprintf("(?s).*use %s;\nSET timestamp=[0-9]+;\nselect \\* from t;\n", s.dbName))
+
+	// Disabling the slow query log stops logging.
+	mustExecSQL(c, se, "set @@global.slow_query_log = 0")
+	mustExecSQL(c, se, "select count(*) from t")
+	data, err = ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Not(Matches), "(?s).*count.*")
+
+	// The global variables are cached by the statements.
+	_, _, ok := slowLog.config()
+	c.Assert(ok, IsTrue)
+
+	// The global variables changed by the other servers take effect when they're reloaded after a schema lease,
+	// the global long_query_time is used by the sessions which don't set it.
+	mustExecSQL(c, se, `update mysql.global_variables set variable_value = "1" where variable_name = "slow_query_log"`)
+	mustExecSQL(c, se, `update mysql.global_variables set variable_value = "0" where variable_name = "long_query_time"`)
+	defer mustExecSQL(c, se, "set @@global.long_query_time = 10")
+	slowLog.expire()
+	se1 := newSession(c, store, s.dbName)
+	mustExecMatch(c, se1, "select c from t where c = 1", [][]interface{}{{"1"}})
+	data, err = ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Matches, "(?s).*select c from t where c = 1;\n")
+	se1.Close()
+	mustExecSQL(c, se, "drop table t")
+}
+
+func queryCount(c *C, label string) uint64 {
+	m := &dto.Metric{}
+	c.Assert(queryHistogram.WithLabelValues(label).(prometheus.Histogram).Write(m), IsNil)
+	return m.GetHistogram().GetSampleCount()
+}
+
+func (s *testSessionSuite) TestQueryDurationMetrics(c *C) {
+	defer testleak.AfterTest(c)()
+	store := newStore(c, s.dbName)
+	se := newSession(c, store, s.dbName)
+	mustExecMultiSQL(c, se, "drop table if exists t; create table t (c int); insert t values (1), (2);")
+
+	// The statement without a record set is observed when it's done.
+	count := queryCount(c, "Use")
+	mustExecSQL(c, se, s.useDBSQL)
+	c.Assert(queryCount(c, "Use"), Equals, count+1)
+
+	// The statement with a record set is observed when the record set is closed.
+	count = queryCount(c, "Explain")
+	r := mustExecSQL(c, se, "explain select * from t")
+	c.Assert(queryCount(c, "Explain"), Equals, count)
+	_, err := r.Next()
+	c.Assert(err, IsNil)
+	c.Assert(queryCount(c, "Explain"), Equals, count)
+	c.Assert(r.Close(), IsNil)
+	c.Assert(queryCount(c, "Explain"), Equals, count+1)
+	mustExecSQL(c, se, "drop table t")
+}
@@ -14,11 +14,15 @@
 package variable
 
 import (
+	"crypto/tls"
+	"strings"
+	"time"
+
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/types"
-	"strings"
 )
 
 const (
@@ -96,6 +100,9 @@ type SessionVars struct {
 	// Connection ID
 	ConnectionID uint64
 
+	// TLS state of the client connection, nil if the connection is not encrypted.
+	TLSConnectionState *tls.ConnectionState
+
 	// Found rows
 	FoundRows uint64
 
@@ -104,6 +111,60 @@ type SessionVars struct {
 
 	// Strict SQL mode
 	StrictSQLMode bool
+
+	// Killed points to the kill flag of the session, it's nil if the session can't be killed.
+	// The flag is set to 1 by another goroutine to interrupt the running statement,
+	// so it must be accessed atomically.
+	Killed *uint32
+
+	// StmtDeadline is the time the running statement times out, it's zero if there is no timeout.
+	StmtDeadline time.Time
+
+	// StmtStats is the execution statistics of the running statement.
+	StmtStats StmtStats
+
+	// StmtWarnings are the warnings of the last statement, they are shown by SHOW WARNINGS.
+	StmtWarnings []error
+
+	// TableDeltaMap is the row count changes of the tables in the current transaction, the key is the table ID.
+	TableDeltaMap map[int64]TableDelta
+}
+
+// AppendWarning appends a warning to the warnings of the running statement.
+func (s *SessionVars) AppendWarning(err error) {
+	s.StmtWarnings = append(s.StmtWarnings, err)
+}
+
+// TableDelta is the row count changes of a table.
+type TableDelta struct {
+	// Delta is the change of the row count.
+	Delta int64
+	// Count is the number of the added, updated and removed rows.
+	Count int64
+}
+
+// UpdateDeltaForTable updates the row count changes of the table in the current transaction.
+func (s *SessionVars) UpdateDeltaForTable(tableID int64, delta int64, count int64) {
+	if s.TableDeltaMap == nil {
+		s.TableDeltaMap = make(map[int64]TableDelta)
+	}
+	item := s.TableDeltaMap[tableID]
+	item.Delta += delta
+	item.Count += count
+	s.TableDeltaMap[tableID] = item
+}
+
+// StmtStats is the execution statistics of a statement, it's written to the slow query log.
+// The counters must be accessed atomically.
+type StmtStats struct {
+	// TxnStartTS is the start timestamp of the transaction the statement runs in.
+	TxnStartTS uint64
+	// RowsExamined is the number of rows read from the storage.
+	RowsExamined uint64
+	// CopStats is the statistics of the coprocessor requests.
+	CopStats kv.CopStats
+	// Plan is the plan.Plan of the statement, it's nil if the statement is not planned.
+	Plan interface{}
 }
 
 // sessionVarsKeyType is a dummy type to avoid naming collision in context.
@@ -14,6 +14,8 @@
 package variable
 
 import (
+	"crypto/tls"
+
 	"github.com/juju/errors"
 )
 
@@ -42,8 +44,66 @@ func RegisterStatistics(s Statistics) {
 	statisticsList = append(statisticsList, s)
 }
 
-// GetStatusVars gets registered statistics status variables.
-func GetStatusVars() (map[string]*StatusVal, error) {
+// Session status variables about the TLS state of the client connection.
+const (
+	sslCipher  = "Ssl_cipher"
+	sslVersion = "Ssl_version"
+)
+
+// tlsVersions maps TLS protocol versions to the names MySQL reports.
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "TLSv1",
+	tls.VersionTLS11: "TLSv1.1",
+	tls.VersionTLS12: "TLSv1.2",
+	tls.VersionTLS13: "TLSv1.3",
+}
+
+// tlsCiphers maps TLS cipher suites to the OpenSSL names MySQL reports.
+var tlsCiphers = map[uint16]string{
+	tls.TLS_RSA_WITH_RC4_128_SHA:                "RC4-SHA",
+	tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA:           "DES-CBC3-SHA",
+	tls.TLS_RSA_WITH_AES_128_CBC_SHA:            "AES128-SHA",
+	tls.TLS_RSA_WITH_AES_256_CBC_SHA:            "AES256-SHA",
+	tls.TLS_RSA_WITH_AES_128_GCM_SHA256:         "AES128-GCM-SHA256",
+	tls.TLS_RSA_WITH_AES_256_GCM_SHA384:         "AES256-GCM-SHA384",
+	tls.TLS_ECDHE_ECDSA_WITH_RC4_128_SHA:        "ECDHE-ECDSA-RC4-SHA",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA:    "ECDHE-ECDSA-AES128-SHA",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA:    "ECDHE-ECDSA-AES256-SHA",
+	tls.TLS_ECDHE_RSA_WITH_RC4_128_SHA:          "ECDHE-RSA-RC4-SHA",
+	tls.TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA:     "ECDHE-RSA-DES-CBC3-SHA",
+	tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA:      "ECDHE-RSA-AES128-SHA",
+	tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA:      "ECDHE-RSA-AES256-SHA",
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256:   "ECDHE-RSA-AES128-GCM-SHA256",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256: "ECDHE-ECDSA-AES128-GCM-SHA256",
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384:   "ECDHE-RSA-AES256-GCM-SHA384",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384: "ECDHE-ECDSA-AES256-GCM-SHA384",
+}
+
+// tlsStatus returns the TLS status variables of the session, the values are empty
+// if the client connection is not encrypted.
+func (s *SessionVars) tlsStatus() map[string]interface{} {
+	m := map[string]interface{}{
+		sslCipher:  "",
+		sslVersion: "",
+	}
+	state := s.TLSConnectionState
+	if state == nil {
+		return m
+	}
+	if name, ok := tlsCiphers[state.CipherSuite]; ok {
+		m[sslCipher] = name
+	} else {
+		m[sslCipher] = tls.CipherSuiteName(state.CipherSuite)
+	}
+	if name, ok := tlsVersions[state.Version]; ok {
+		m[sslVersion] = name
+	}
+	return m
+}
+
+// GetStatusVars gets registered statistics status variables and
+// the status variables of the session vars if it's not nil.
+func GetStatusVars(vars *SessionVars) (map[string]*StatusVal, error) {
 	statusVars := make(map[string]*StatusVal)
 
 	for _, statistics := range statisticsList {
@@ -58,5 +118,11 @@ func GetStatusVars() (map[string]*StatusVal, error) {
 		}
 	}
 
+	if vars != nil {
+		for name, val := range vars.tlsStatus() {
+			statusVars[name] = &StatusVal{Value: val, Scope: ScopeSession}
+		}
+	}
+
 	return statusVars, nil
 }
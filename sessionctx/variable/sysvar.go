@@ -14,6 +14,7 @@
 package variable
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/pingcap/tidb/context"
@@ -58,12 +59,15 @@ func GetSysVar(name string) *SysVar {
 const (
 	CodeUnknownStatusVar terror.ErrCode = 1
 	CodeUnknownSystemVar terror.ErrCode = 1193
+	CodeWrongTypeForVar  terror.ErrCode = 1232
 )
 
 // Variable errors
 var (
 	UnknownStatusVar = terror.ClassVariable.New(CodeUnknownStatusVar, "unknown status variable")
 	UnknownSystemVar = terror.ClassVariable.New(CodeUnknownSystemVar, "unknown system variable")
+	// ErrWrongTypeForVar is returned if the value of the variable isn't of its type.
+	ErrWrongTypeForVar = terror.ClassVariable.New(CodeWrongTypeForVar, "incorrect argument type to variable")
 )
 
 func init() {
@@ -75,6 +79,7 @@ func init() {
 	// Register terror to mysql error map.
 	mySQLErrCodes := map[terror.ErrCode]uint16{
 		CodeUnknownSystemVar: mysql.ErrUnknownSystemVariable,
+		CodeWrongTypeForVar:  mysql.ErrWrongTypeForVar,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassVariable] = mySQLErrCodes
 }
@@ -297,6 +302,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal, "delayed_insert_timeout", "300"},
 	{ScopeGlobal, "max_relay_log_size", "0"},
 	{ScopeGlobal | ScopeSession, "max_sort_length", "1024"},
+	{ScopeGlobal | ScopeSession, MaxExecutionTime, "0"},
 	{ScopeNone, "metadata_locks_hash_instances", "8"},
 	{ScopeGlobal, "ndb_eventbuffer_free_percent", ""},
 	{ScopeNone, "large_files_support", "ON"},
@@ -348,7 +354,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal, "log_error_verbosity", ""},
 	{ScopeNone, "performance_schema_hosts_size", "100"},
 	{ScopeGlobal, "innodb_replication_delay", "0"},
-	{ScopeGlobal, "slow_query_log", "OFF"},
+	{ScopeGlobal, SlowQueryLog, "OFF"},
 	{ScopeSession, "debug_sync", ""},
 	{ScopeGlobal, "innodb_stats_auto_recalc", "ON"},
 	{ScopeGlobal, "timed_mutexes", "OFF"},
@@ -410,7 +416,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal, "executed_gtids_compression_period", ""},
 	{ScopeNone, "time_format", "%H:%i:%s"},
 	{ScopeGlobal | ScopeSession, "old_alter_table", "OFF"},
-	{ScopeGlobal | ScopeSession, "long_query_time", "10.000000"},
+	{ScopeGlobal | ScopeSession, LongQueryTime, "10.000000"},
 	{ScopeNone, "innodb_use_native_aio", "OFF"},
 	{ScopeGlobal, "log_throttle_queries_not_using_indexes", "0"},
 	{ScopeNone, "locked_in_memory", "OFF"},
@@ -439,7 +445,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal | ScopeSession, "max_sp_recursion_depth", "0"},
 	{ScopeNone, "ignore_builtin_innodb", "OFF"},
 	{ScopeGlobal, "rpl_semi_sync_master_enabled", ""},
-	{ScopeGlobal, "slow_query_log_file", "/usr/local/mysql/data/localhost-slow.log"},
+	{ScopeGlobal, SlowQueryLogFile, "tidb-slow.log"},
 	{ScopeGlobal, "innodb_thread_sleep_delay", "10000"},
 	{ScopeNone, "license", "GPL"},
 	{ScopeGlobal, "innodb_ft_aux_table", ""},
@@ -580,6 +586,28 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal | ScopeSession, "min_examined_row_limit", "0"},
 	{ScopeGlobal, "sync_frm", "ON"},
 	{ScopeGlobal, "innodb_online_alter_log_max_size", "134217728"},
+	/* TiDB specific variables */
+	{ScopeGlobal, TiDBAutoAnalyzeRatio, "0.5"},
+	{ScopeGlobal | ScopeSession, TiDBDDLReorgWorkerCount, "4"},
+	{ScopeGlobal | ScopeSession, TiDBDDLReorgBatchSize, "128"},
+	{ScopeGlobal | ScopeSession, TiDBDDLReorgRateLimit, "0"},
+}
+
+// intSysVars are the system variables whose values must be integers.
+var intSysVars = map[string]bool{
+	TiDBDDLReorgWorkerCount: true,
+	TiDBDDLReorgBatchSize:   true,
+	TiDBDDLReorgRateLimit:   true,
+}
+
+// ValidateSysVar checks the value to set to the system variable is of its type.
+func ValidateSysVar(name string, value string) error {
+	if intSysVars[name] {
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return ErrWrongTypeForVar.Gen("Incorrect argument type to variable '%s'", name)
+		}
+	}
+	return nil
 }
 
 // SetNamesVariables is the system variable names related to set names statements.
@@ -596,6 +624,28 @@ const (
 	CharsetDatabase = "character_set_database"
 	// CollationDatabase is the name for collation_database system variable.
 	CollationDatabase = "collation_database"
+	// MaxExecutionTime is the name for max_execution_time system variable, it's the execution
+	// timeout in milliseconds for SELECT statements, 0 means no timeout.
+	MaxExecutionTime = "max_execution_time"
+	// SlowQueryLog is the name for slow_query_log system variable, it enables the slow query log.
+	SlowQueryLog = "slow_query_log"
+	// SlowQueryLogFile is the name for slow_query_log_file system variable, it's the path of the slow query log.
+	SlowQueryLogFile = "slow_query_log_file"
+	// LongQueryTime is the name for long_query_time system variable, statements that take longer
+	// than it in seconds are written to the slow query log.
+	LongQueryTime = "long_query_time"
+	// TiDBAutoAnalyzeRatio is the name for tidb_auto_analyze_ratio system variable, a table is analyzed
+	// automatically when its modify count exceeds the ratio of its row count, 0 disables the auto analyze.
+	TiDBAutoAnalyzeRatio = "tidb_auto_analyze_ratio"
+	// TiDBDDLReorgWorkerCount is the name for tidb_ddl_reorg_worker_cnt system variable, it's the number of the
+	// workers which backfill an index added by ADD INDEX concurrently.
+	TiDBDDLReorgWorkerCount = "tidb_ddl_reorg_worker_cnt"
+	// TiDBDDLReorgBatchSize is the name for tidb_ddl_reorg_batch_size system variable, it's the number of the rows
+	// backfilled in a transaction by a worker of ADD INDEX.
+	TiDBDDLReorgBatchSize = "tidb_ddl_reorg_batch_size"
+	// TiDBDDLReorgRateLimit is the name for tidb_ddl_reorg_rate_limit system variable, it's the maximum number of
+	// the rows backfilled by all the workers of ADD INDEX per second, 0 means no limit.
+	TiDBDDLReorgRateLimit = "tidb_ddl_reorg_rate_limit"
 )
 
 // GlobalVarAccessor is the interface for accessing global scope system and status variables.
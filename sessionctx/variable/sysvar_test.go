@@ -17,6 +17,7 @@ import (
 	"testing"
 
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/terror"
 )
 
 func TestT(t *testing.T) {
@@ -34,4 +35,8 @@ func (*testSysVarSuite) TestSysVar(c *C) {
 
 	f = GetSysVar("wrong-var-name")
 	c.Assert(f, IsNil)
+
+	c.Assert(ValidateSysVar(TiDBDDLReorgBatchSize, "10"), IsNil)
+	c.Assert(terror.ErrorEqual(ValidateSysVar(TiDBDDLReorgBatchSize, "abc"), ErrWrongTypeForVar), IsTrue)
+	c.Assert(ValidateSysVar("autocommit", "abc"), IsNil)
 }
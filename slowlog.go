@@ -0,0 +1,301 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidb
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/db"
+	"github.com/pingcap/tidb/sessionctx/variable"
+)
+
+// slowQueryLogger writes the statements that take longer than long_query_time to the slow query log.
+// The entries are in the format of MySQL, so the log can be analyzed by tools like pt-query-digest.
+// It's configured by the global system variables slow_query_log, slow_query_log_file and long_query_time.
+// They're cached and reloaded every schema lease, so the changes made by SET GLOBAL on the other servers
+// take effect in a lease, and the changes made on this server take effect at once.
+type slowQueryLogger struct {
+	mu      sync.Mutex
+	enabled bool
+	path    string
+	file    *os.File
+	// longQueryTime is the global value of long_query_time.
+	longQueryTime string
+	// loadTime is the time the global system variables are loaded, it's zero if they need to be reloaded.
+	loadTime time.Time
+}
+
+var slowLog = &slowQueryLogger{}
+
+func parseBoolVar(value string) bool {
+	switch strings.ToUpper(value) {
+	case "ON", "1", "TRUE":
+		return true
+	}
+	return false
+}
+
+// config returns the cached configuration, ok is false if it's loaded more than a schema lease ago.
+func (l *slowQueryLogger) config() (enabled bool, longQueryTime string, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.loadTime.IsZero() || time.Since(l.loadTime) >= schemaLease {
+		return false, "", false
+	}
+	return l.enabled, l.longQueryTime, true
+}
+
+// update applies the configuration loaded from the global system variables.
+func (l *slowQueryLogger) update(enabled bool, path, longQueryTime string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !enabled || l.path != path {
+		l.closeFile()
+	}
+	l.enabled = enabled
+	l.path = path
+	l.longQueryTime = longQueryTime
+	l.loadTime = time.Now()
+}
+
+// expire makes the next statement reload the configuration, it's called when SET GLOBAL changes it.
+func (l *slowQueryLogger) expire() {
+	l.mu.Lock()
+	l.loadTime = time.Time{}
+	l.mu.Unlock()
+}
+
+func (l *slowQueryLogger) closeFile() {
+	if l.file == nil {
+		return
+	}
+	if err := l.file.Close(); err != nil {
+		log.Errorf("Close slow query log error: %v", err)
+	}
+	l.file = nil
+}
+
+func (l *slowQueryLogger) write(entry []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.enabled {
+		return nil
+	}
+	if l.file == nil {
+		f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		l.file = f
+	}
+	_, err := l.file.Write(entry)
+	return errors.Trace(err)
+}
+
+// slowQueryEntry is the information of a statement written to the slow query log.
+type slowQueryEntry struct {
+	sql       string
+	startTime time.Time
+	costTime  time.Duration
+	connID    uint64
+	user      string
+	db        string
+	rowsSent  uint64
+	stats     *variable.StmtStats
+}
+
+// formatSlowQuery formats the entry as MySQL does, with the TiDB specific fields in comment lines.
+func formatSlowQuery(e *slowQueryEntry) []byte {
+	var buf bytes.Buffer
+	user, host := e.user, ""
+	if i := strings.LastIndex(user, "@"); i >= 0 {
+		user, host = user[:i], user[i+1:]
+	}
+	fmt.Fprintf(&buf, "# Time: %s\n", e.startTime.UTC().Format("2006-01-02T15:04:05.000000Z"))
+	fmt.Fprintf(&buf, "# User@Host: %s[%s] @ %s []  Id: %d\n", user, user, host, e.connID)
+	fmt.Fprintf(&buf, "# Query_time: %.6f  Lock_time: 0.000000  Rows_sent: %d  Rows_examined: %d\n",
+		e.costTime.Seconds(), e.rowsSent, atomic.LoadUint64(&e.stats.RowsExamined))
+	fmt.Fprintf(&buf, "# Txn_start_ts: %d  Cop_tasks: %d  Cop_retries: %d\n", e.stats.TxnStartTS,
+		atomic.LoadInt64(&e.stats.CopStats.Tasks), atomic.LoadInt64(&e.stats.CopStats.Retries))
+	if p, ok := e.stats.Plan.(plan.Plan); ok && p != nil {
+		fmt.Fprintf(&buf, "# Plan: %s\n", plan.ToString(p))
+	}
+	if e.db != "" {
+		fmt.Fprintf(&buf, "use %s;\n", e.db)
+	}
+	fmt.Fprintf(&buf, "SET timestamp=%d;\n", e.startTime.Unix())
+	sql := strings.TrimSpace(e.sql)
+	buf.WriteString(sql)
+	if !strings.HasSuffix(sql, ";") {
+		buf.WriteString(";")
+	}
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
+
+// slowQueryVars reads the global system variables of the slow query log in one query.
+func (s *session) slowQueryVars() (map[string]string, error) {
+	sql := fmt.Sprintf(`SELECT VARIABLE_NAME, VARIABLE_VALUE FROM %s.%s WHERE VARIABLE_NAME IN ("%s", "%s", "%s");`,
+		mysql.SystemDB, mysql.GlobalVariablesTable, variable.SlowQueryLog, variable.SlowQueryLogFile, variable.LongQueryTime)
+	if s.txn == nil {
+		// Reading the variables shouldn't leave a transaction behind or mark the session in transaction.
+		defer func() {
+			if s.txn != nil {
+				if err := s.txn.Rollback(); err != nil {
+					log.Errorf("Rollback txn error: %v", err)
+				}
+			}
+			s.txn = nil
+			variable.GetSessionVars(s).SetStatusFlag(mysql.ServerStatusInTrans, false)
+		}()
+	}
+	rs, err := s.ExecRestrictedSQL(s, sql)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer rs.Close()
+	values := make(map[string]string, 3)
+	for {
+		row, err := rs.Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		values[row.Data[0].GetString()] = row.Data[1].GetString()
+	}
+	return values, nil
+}
+
+// longQueryTime returns the value of long_query_time system variable, the global value is used
+// if the session value isn't set.
+func (s *session) longQueryTime(globalValue string) time.Duration {
+	value := globalValue
+	threshold := variable.GetSessionVars(s).GetSystemVar(variable.LongQueryTime)
+	if !threshold.IsNull() {
+		value = threshold.GetString()
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(v * float64(time.Second))
+}
+
+// slowQueryTracker tracks a statement for the slow query log, it's created before the statement runs.
+type slowQueryTracker struct {
+	se        *session
+	sql       string
+	startTime time.Time
+	threshold time.Duration
+}
+
+// trackSlowQuery resets the statistics of the statement and starts tracking it,
+// it returns nil if the slow query log is disabled.
+func (s *session) trackSlowQuery(sql string) *slowQueryTracker {
+	vars := variable.GetSessionVars(s)
+	// The statistics are reset after reloading the global variables, which isn't a part of the statement.
+	defer func() { vars.StmtStats = variable.StmtStats{} }()
+	if s.initing {
+		return nil
+	}
+	enabled, longQueryTime, ok := slowLog.config()
+	if !ok {
+		values, err := s.slowQueryVars()
+		if err != nil {
+			log.Errorf("Get global sys var error: %v", errors.ErrorStack(err))
+			return nil
+		}
+		enabled, longQueryTime = parseBoolVar(values[variable.SlowQueryLog]), values[variable.LongQueryTime]
+		slowLog.update(enabled, values[variable.SlowQueryLogFile], longQueryTime)
+	}
+	if !enabled {
+		return nil
+	}
+	return &slowQueryTracker{
+		se:        s,
+		sql:       sql,
+		startTime: time.Now(),
+		threshold: s.longQueryTime(longQueryTime),
+	}
+}
+
+// finish writes the statement to the slow query log if it's slow.
+func (t *slowQueryTracker) finish(rowsSent uint64) {
+	costTime := time.Since(t.startTime)
+	if costTime < t.threshold {
+		return
+	}
+	vars := variable.GetSessionVars(t.se)
+	entry := &slowQueryEntry{
+		sql:       t.sql,
+		startTime: t.startTime,
+		costTime:  costTime,
+		connID:    vars.ConnectionID,
+		user:      vars.User,
+		db:        db.GetCurrentSchema(t.se),
+		rowsSent:  rowsSent,
+		stats:     &vars.StmtStats,
+	}
+	if err := slowLog.write(formatSlowQuery(entry)); err != nil {
+		log.Errorf("Write slow query log error: %v", errors.ErrorStack(err))
+	}
+}
+
+// track finishes the tracking when the statement is done, the statement with a record set is done
+// when the record set is closed.
+func (t *slowQueryTracker) track(rs ast.RecordSet) ast.RecordSet {
+	if t == nil {
+		return rs
+	}
+	if rs == nil {
+		t.finish(0)
+		return nil
+	}
+	return &slowQueryRecordSet{RecordSet: rs, tracker: t}
+}
+
+// slowQueryRecordSet counts the rows sent and finishes the tracking on Close.
+type slowQueryRecordSet struct {
+	ast.RecordSet
+	tracker  *slowQueryTracker
+	rowsSent uint64
+}
+
+func (rs *slowQueryRecordSet) Next() (*ast.Row, error) {
+	row, err := rs.RecordSet.Next()
+	if row != nil {
+		rs.rowsSent++
+	}
+	return row, errors.Trace(err)
+}
+
+func (rs *slowQueryRecordSet) Close() error {
+	err := rs.RecordSet.Close()
+	rs.tracker.finish(rs.rowsSent)
+	return errors.Trace(err)
+}
@@ -102,6 +102,12 @@ func (n *aggregateFuncExpr) update(ctx *selectContext, args []types.Datum) error
 		return n.updateCount(ctx, args)
 	case tipb.ExprType_First:
 		return n.updateFirst(ctx, args)
+	case tipb.ExprType_Sum, tipb.ExprType_Avg:
+		return n.updateSum(ctx, args)
+	case tipb.ExprType_Max:
+		return n.updateMaxMin(ctx, args, true)
+	case tipb.ExprType_Min:
+		return n.updateMaxMin(ctx, args, false)
 	}
 	return errors.Errorf("Unknown AggExpr: %v", n.expr.GetTp())
 }
@@ -110,8 +116,10 @@ func (n *aggregateFuncExpr) toDatums() []types.Datum {
 	switch n.expr.GetTp() {
 	case tipb.ExprType_Count:
 		return n.getCountDatum()
-	case tipb.ExprType_First:
+	case tipb.ExprType_First, tipb.ExprType_Sum, tipb.ExprType_Max, tipb.ExprType_Min:
 		return n.getValueDatum()
+	case tipb.ExprType_Avg:
+		return append(n.getCountDatum(), n.getValueDatum()...)
 	}
 	return nil
 }
@@ -168,3 +176,43 @@ func (n *aggregateFuncExpr) updateFirst(ctx *selectContext, args []types.Datum)
 	aggItem.evaluated = true
 	return nil
 }
+
+func (n *aggregateFuncExpr) updateSum(ctx *selectContext, args []types.Datum) error {
+	if len(args) != 1 {
+		return errors.New("Wrong number of args for AggFuncSum")
+	}
+	if args[0].IsNull() {
+		return nil
+	}
+	aggItem := n.getAggItem()
+	sum, err := types.CalculateSum(aggItem.value.GetValue(), args[0].GetValue())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	aggItem.value.SetValue(sum)
+	aggItem.count++
+	return nil
+}
+
+func (n *aggregateFuncExpr) updateMaxMin(ctx *selectContext, args []types.Datum, max bool) error {
+	if len(args) != 1 {
+		return errors.New("Wrong number of args for AggFuncMaxMin")
+	}
+	if args[0].IsNull() {
+		return nil
+	}
+	aggItem := n.getAggItem()
+	if !aggItem.evaluated {
+		aggItem.value = args[0]
+		aggItem.evaluated = true
+		return nil
+	}
+	cmp, err := aggItem.value.CompareDatum(args[0])
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if (max && cmp < 0) || (!max && cmp > 0) {
+		aggItem.value = args[0]
+	}
+	return nil
+}
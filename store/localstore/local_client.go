@@ -18,6 +18,7 @@ func (c *dbClient) Send(req *kv.Request) kv.Response {
 		concurrency: req.Concurrency,
 	}
 	it.tasks = buildRegionTasks(c, req)
+	req.Stats.AddTasks(len(it.tasks))
 	if len(it.tasks) == 0 {
 		// Empty range doesn't produce any task.
 		it.finished = true
@@ -39,7 +40,7 @@ func (c *dbClient) SupportRequestType(reqType, subType int64) bool {
 	switch reqType {
 	case kv.ReqTypeSelect:
 		switch subType {
-		case kv.ReqSubTypeGroupBy:
+		case kv.ReqSubTypeGroupBy, kv.ReqSubTypeTopN:
 			return true
 		default:
 			return supportExpr(tipb.ExprType(subType))
@@ -68,7 +69,8 @@ func supportExpr(exprType tipb.ExprType) bool {
 		return true
 	case tipb.ExprType_Plus, tipb.ExprType_Div:
 		return true
-	case tipb.ExprType_Count, tipb.ExprType_First:
+	case tipb.ExprType_Count, tipb.ExprType_First, tipb.ExprType_Sum, tipb.ExprType_Avg,
+		tipb.ExprType_Max, tipb.ExprType_Min:
 		return true
 	case kv.ReqSubTypeDesc:
 		return true
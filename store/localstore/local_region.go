@@ -49,6 +49,9 @@ type selectContext struct {
 	groupKeys    [][]byte
 	aggregates   []*aggregateFuncExpr
 	aggregate    bool
+	// topn is true if the request asks for the first rows in the order of some expressions.
+	topn     bool
+	topnHeap *topnHeap
 }
 
 func (rs *localRegion) Handle(req *regionRequest) (*regionResponse, error) {
@@ -82,6 +85,13 @@ func (rs *localRegion) Handle(req *regionRequest) (*regionResponse, error) {
 			ctx.groups = make(map[string]bool)
 			ctx.groupKeys = make([][]byte, 0)
 		}
+		ctx.topn = len(sel.OrderBy) > 0 && sel.OrderBy[0].Expr != nil && sel.Limit != nil
+		if ctx.topn {
+			ctx.topnHeap = &topnHeap{
+				orderByItems: sel.OrderBy,
+				totalCount:   int(sel.GetLimit()),
+			}
+		}
 
 		var rows []*tipb.Row
 		if req.Tp == kv.ReqTypeSelect {
@@ -143,7 +153,7 @@ func (rs *localRegion) getRowsFromSelectReq(ctx *selectContext) ([]*tipb.Row, er
 	kvRanges, desc := rs.extractKVRanges(ctx.sel)
 	var rows []*tipb.Row
 	limit := int64(-1)
-	if ctx.sel.Limit != nil {
+	if ctx.sel.Limit != nil && !ctx.topn {
 		limit = ctx.sel.GetLimit()
 	}
 	for _, ran := range kvRanges {
@@ -160,6 +170,9 @@ func (rs *localRegion) getRowsFromSelectReq(ctx *selectContext) ([]*tipb.Row, er
 	if ctx.aggregate {
 		return rs.getRowsFromAgg(ctx)
 	}
+	if ctx.topn {
+		return rs.getRowsFromTopN(ctx)
+	}
 	return rows, nil
 }
 
@@ -236,7 +249,8 @@ func (rs *localRegion) extractKVRanges(sel *tipb.SelectRequest) (kvRanges []kv.K
 		}
 		kvRanges = append(kvRanges, kvr)
 	}
-	if sel.OrderBy != nil {
+	// The order by items of expressions are for the top n rows, the scan order is ascending.
+	if sel.OrderBy != nil && sel.OrderBy[0].Expr == nil {
 		desc = *sel.OrderBy[0].Desc
 	}
 	if desc {
@@ -273,6 +287,9 @@ func (rs *localRegion) getRowsFromRange(ctx *selectContext, ran kv.KeyRange, lim
 			return nil, errors.Trace(err)
 		}
 		if row != nil {
+			if ctx.topn {
+				return nil, errors.Trace(rs.evalTopN(ctx, row))
+			}
 			rows = append(rows, row)
 		}
 		return rows, nil
@@ -329,6 +346,12 @@ func (rs *localRegion) getRowsFromRange(ctx *selectContext, ran kv.KeyRange, lim
 			return nil, errors.Trace(err)
 		}
 		if row != nil {
+			if ctx.topn {
+				if err = rs.evalTopN(ctx, row); err != nil {
+					return nil, errors.Trace(err)
+				}
+				continue
+			}
 			rows = append(rows, row)
 			limit--
 		}
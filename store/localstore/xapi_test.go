@@ -113,6 +113,53 @@ func (s *testXAPISuite) TestSelect(c *C) {
 	store.Close()
 }
 
+func (s *testXAPISuite) TestSelectTopN(c *C) {
+	defer testleak.AfterTest(c)()
+	store := createMemStore(time.Now().Nanosecond())
+	count := int64(10)
+	err := prepareTableData(store, tbInfo, count, genValues)
+	c.Check(err, IsNil)
+
+	txn, err := store.Begin()
+	c.Check(err, IsNil)
+	client := txn.GetClient()
+	c.Check(client.SupportRequestType(kv.ReqTypeSelect, kv.ReqSubTypeTopN), IsTrue)
+	selReq := new(tipb.SelectRequest)
+	selReq.TableInfo = tbInfo.toPBTableInfo()
+	selReq.StartTs = proto.Uint64(txn.StartTS())
+	selReq.Ranges = []*tipb.KeyRange{fullPBTableRange}
+	// Order by the double column in descending order.
+	selReq.OrderBy = []*tipb.ByItem{{
+		Expr: &tipb.Expr{Tp: tipb.ExprType_ColumnRef.Enum(), Val: codec.EncodeInt(nil, tbInfo.cIDs[1])},
+		Desc: proto.Bool(true),
+	}}
+	selReq.Limit = proto.Int64(3)
+	data, err := proto.Marshal(selReq)
+	c.Check(err, IsNil)
+	req := &kv.Request{
+		Tp:          kv.ReqTypeSelect,
+		Concurrency: 1,
+		KeyRanges:   []kv.KeyRange{fullTableRange(tbInfo.tID)},
+		Data:        data,
+	}
+	resp := client.Send(req)
+	subResp, err := resp.Next()
+	c.Check(err, IsNil)
+	data, err = ioutil.ReadAll(subResp)
+	c.Check(err, IsNil)
+	selResp := new(tipb.SelectResponse)
+	proto.Unmarshal(data, selResp)
+	c.Check(selResp.Rows, HasLen, 3)
+	for i, row := range selResp.Rows {
+		datums, err := codec.Decode(row.Handle)
+		c.Check(err, IsNil)
+		c.Check(datums[0].GetInt64(), Equals, count-int64(i))
+	}
+	txn.Commit()
+
+	store.Close()
+}
+
 // simpleTableInfo just have the minimum information enough to describe the table.
 // The first column is pk handle column.
 type simpleTableInfo struct {
@@ -16,6 +16,7 @@ package tikv
 
 import (
 	"sync/atomic"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
@@ -56,7 +57,10 @@ func newRPCClient() *rpcClient {
 }
 
 // SendCopReq sends a Request to co-processor and receives Response.
-func (c *rpcClient) SendCopReq(addr string, req *coprocessor.Request) (*coprocessor.Response, error) {
+func (c *rpcClient) SendCopReq(addr string, req *coprocessor.Request) (resp *coprocessor.Response, err error) {
+	defer func(start time.Time) {
+		rpcHistogram.WithLabelValues("Cop", resultLabel(err)).Observe(time.Since(start).Seconds())
+	}(time.Now())
 	conn, err := c.p.GetConn(addr)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -77,7 +81,10 @@ func (c *rpcClient) SendCopReq(addr string, req *coprocessor.Request) (*coproces
 }
 
 // SendKVReq sends a Request to kv server and receives Response.
-func (c *rpcClient) SendKVReq(addr string, req *kvrpcpb.Request) (*kvrpcpb.Response, error) {
+func (c *rpcClient) SendKVReq(addr string, req *kvrpcpb.Request) (resp *kvrpcpb.Response, err error) {
+	defer func(start time.Time) {
+		rpcHistogram.WithLabelValues(req.GetType().String(), resultLabel(err)).Observe(time.Since(start).Seconds())
+	}(time.Now())
 	conn, err := c.p.GetConn(addr)
 	if err != nil {
 		return nil, errors.Trace(err)
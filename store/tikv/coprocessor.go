@@ -18,6 +18,7 @@ import (
 	"io"
 	"io/ioutil"
 	"sync"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/juju/errors"
@@ -38,7 +39,7 @@ func (c *CopClient) SupportRequestType(reqType, subType int64) bool {
 	switch reqType {
 	case kv.ReqTypeSelect:
 		switch subType {
-		case kv.ReqSubTypeGroupBy:
+		case kv.ReqSubTypeGroupBy, kv.ReqSubTypeTopN:
 			return true
 		default:
 			return supportExpr(tipb.ExprType(subType))
@@ -63,7 +64,8 @@ func supportExpr(exprType tipb.ExprType) bool {
 		tipb.ExprType_In, tipb.ExprType_ValueList,
 		tipb.ExprType_Like, tipb.ExprType_Not:
 		return true
-	case tipb.ExprType_Count, tipb.ExprType_First:
+	case tipb.ExprType_Count, tipb.ExprType_First, tipb.ExprType_Sum, tipb.ExprType_Avg,
+		tipb.ExprType_Max, tipb.ExprType_Min:
 		return true
 	case kv.ReqSubTypeDesc:
 		return true
@@ -78,6 +80,7 @@ func (c *CopClient) Send(req *kv.Request) kv.Response {
 	if err != nil {
 		return copErrorResponse{err}
 	}
+	req.Stats.AddTasks(len(tasks))
 	it := &copIterator{
 		store:       c.store,
 		req:         req,
@@ -95,6 +98,11 @@ func (c *CopClient) Send(req *kv.Request) kv.Response {
 		it.respChan = make(chan *coprocessor.Response, it.concurrency)
 	}
 	it.errChan = make(chan error, 1)
+	it.closed = make(chan struct{})
+	if !req.Deadline.IsZero() {
+		it.timer = time.NewTimer(req.Deadline.Sub(time.Now()))
+		it.timeout = it.timer.C
+	}
 	if len(it.tasks) == 0 {
 		it.Close()
 	}
@@ -199,6 +207,12 @@ type copIterator struct {
 	respChan    chan *coprocessor.Response
 	errChan     chan error
 	finished    bool
+	// closed is closed by Close to stop the workers.
+	closed    chan struct{}
+	closeOnce sync.Once
+	// timeout fires at the deadline of the request, it's nil if the request has no deadline.
+	timer   *time.Timer
+	timeout <-chan time.Time
 }
 
 // Pick the next new copTask and send request to tikv-server.
@@ -206,7 +220,7 @@ func (it *copIterator) work() {
 	for {
 		it.mu.Lock()
 		if it.finished {
-			it.mu.Lock()
+			it.mu.Unlock()
 			break
 		}
 		// Find the next task to send.
@@ -225,13 +239,20 @@ func (it *copIterator) work() {
 		it.mu.Unlock()
 		resp, err := it.handleTask(task)
 		if err != nil {
-			it.errChan <- err
+			select {
+			case it.errChan <- err:
+			case <-it.closed:
+			}
 			break
 		}
+		respChan := task.respChan
 		if !it.req.KeepOrder {
-			it.respChan <- resp
-		} else {
-			task.respChan <- resp
+			respChan = it.respChan
+		}
+		select {
+		case respChan <- resp:
+		case <-it.closed:
+			return
 		}
 	}
 }
@@ -259,6 +280,8 @@ func (it *copIterator) Next() (io.ReadCloser, error) {
 		select {
 		case resp = <-it.respChan:
 		case err = <-it.errChan:
+		case <-it.timeout:
+			err = kv.ErrQueryTimeout
 		}
 	} else {
 		var task *copTask
@@ -277,6 +300,8 @@ func (it *copIterator) Next() (io.ReadCloser, error) {
 		select {
 		case resp = <-task.respChan:
 		case err = <-it.errChan:
+		case <-it.timeout:
+			err = kv.ErrQueryTimeout
 		}
 		it.mu.Lock()
 		task.status = taskDone
@@ -299,6 +324,10 @@ func (it *copIterator) Next() (io.ReadCloser, error) {
 func (it *copIterator) handleTask(task *copTask) (*coprocessor.Response, error) {
 	var backoffErr error
 	for backoff := rpcBackoff(); backoffErr == nil; backoffErr = backoff() {
+		// Don't send the request if the deadline has passed, or it keeps the tikv-server busy for nothing.
+		if !it.req.Deadline.IsZero() && time.Now().After(it.req.Deadline) {
+			return nil, errors.Trace(kv.ErrQueryTimeout)
+		}
 		req := &coprocessor.Request{
 			Context: task.region.GetContext(),
 			Tp:      proto.Int64(it.req.Tp),
@@ -313,6 +342,7 @@ func (it *copIterator) handleTask(task *copTask) (*coprocessor.Response, error)
 				return nil, errors.Trace(err1)
 			}
 			log.Warnf("send coprocessor request error: %v, try next peer later", err)
+			it.req.Stats.AddRetries(1)
 			continue
 		}
 		if e := resp.GetRegionError(); e != nil {
@@ -326,12 +356,14 @@ func (it *copIterator) handleTask(task *copTask) (*coprocessor.Response, error)
 				return nil, errors.Trace(err)
 			}
 			log.Warnf("coprocessor region error: %v, retry later", e)
+			it.req.Stats.AddRetries(1)
 			continue
 		}
 		if e := resp.GetLocked(); e != nil {
 			lock := newLock(it.store, e.GetPrimaryLock(), e.GetLockVersion(), e.GetKey(), e.GetLockVersion())
 			_, lockErr := lock.cleanup()
 			if lockErr == nil || terror.ErrorEqual(lockErr, errInnerRetryable) {
+				it.req.Stats.AddRetries(1)
 				continue
 			}
 			log.Warnf("cleanup lock error: %v", lockErr)
@@ -357,6 +389,8 @@ func (it *copIterator) rebuildCurrentTask(task *copTask) error {
 		// TODO: check this, this should never happen.
 		return nil
 	}
+	// The current task is retried, the others are new.
+	it.req.Stats.AddTasks(len(newTasks) - 1)
 	it.mu.Lock()
 	defer it.mu.Unlock()
 	// We should put the original task back to the original place in the task list.
@@ -376,6 +410,12 @@ func (it *copIterator) rebuildCurrentTask(task *copTask) error {
 
 func (it *copIterator) Close() error {
 	it.finished = true
+	it.closeOnce.Do(func() {
+		close(it.closed)
+		if it.timer != nil {
+			it.timer.Stop()
+		}
+	})
 	return nil
 }
 
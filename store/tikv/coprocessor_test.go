@@ -14,9 +14,12 @@
 package tikv
 
 import (
+	"time"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/store/tikv/mock-tikv"
+	"github.com/pingcap/tidb/terror"
 )
 
 type testCoprocessorSuite struct{}
@@ -138,6 +141,27 @@ func (s *testCoprocessorSuite) TestRebuild(c *C) {
 	s.taskEqual(c, iter.tasks[0], regionIDs[2], "q", "z")
 }
 
+func (s *testCoprocessorSuite) TestDeadline(c *C) {
+	store := NewMockTikvStore().(*tikvStore)
+	defer store.Close()
+	client := &CopClient{store: store}
+	for _, keepOrder := range []bool{false, true} {
+		resp := client.Send(&kv.Request{
+			Tp:          kv.ReqTypeSelect,
+			KeyRanges:   s.buildKeyRanges("a", "z"),
+			KeepOrder:   keepOrder,
+			Concurrency: 1,
+			Deadline:    time.Now().Add(-time.Second),
+		})
+		_, err := resp.Next()
+		c.Assert(terror.ErrorEqual(err, kv.ErrQueryTimeout), IsTrue)
+		// The iterator is closed after the error.
+		data, err := resp.Next()
+		c.Assert(err, IsNil)
+		c.Assert(data, IsNil)
+	}
+}
+
 func (s *testCoprocessorSuite) buildKeyRanges(keys ...string) []kv.KeyRange {
 	var ranges []kv.KeyRange
 	for i := 0; i < len(keys); i += 2 {
@@ -127,6 +127,11 @@ func (s *tikvStore) UUID() string {
 	return s.uuid
 }
 
+// GetRegionCache returns the RegionCache of the store.
+func (s *tikvStore) GetRegionCache() *RegionCache {
+	return s.regionCache
+}
+
 func (s *tikvStore) CurrentVersion() (kv.Version, error) {
 	startTS, err := s.getTimestampWithRetry()
 	if err != nil {
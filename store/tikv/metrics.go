@@ -0,0 +1,46 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	rpcHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "tikvclient",
+			Name:      "request_seconds",
+			Help:      "Bucketed histogram of sending request duration (s) to TiKV.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 20),
+		}, []string{"type", "result"})
+	regionCacheCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "tikvclient",
+			Name:      "region_cache_operations_total",
+			Help:      "Counter of region cache lookups.",
+		}, []string{"type"})
+)
+
+func init() {
+	prometheus.MustRegister(rpcHistogram)
+	prometheus.MustRegister(regionCacheCounter)
+}
+
+func resultLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
@@ -0,0 +1,188 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mocktikv
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tipb/go-tipb"
+)
+
+var singleGroup = []byte("SingleGroup")
+
+func (h *rpcHandler) getGroupKey(ctx *selectContext) ([]byte, error) {
+	items := ctx.sel.GetGroupBy()
+	if len(items) == 0 {
+		return singleGroup, nil
+	}
+	vals := make([]types.Datum, 0, len(items))
+	for _, item := range items {
+		v, err := ctx.eval.Eval(item.Expr)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		vals = append(vals, v)
+	}
+	bs, err := codec.EncodeValue(nil, vals...)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return bs, nil
+}
+
+// aggregate updates the aggregate functions with the row.
+func (h *rpcHandler) aggregate(ctx *selectContext, row *tipb.Row) error {
+	if err := setRowToEval(ctx, row); err != nil {
+		return errors.Trace(err)
+	}
+	gk, err := h.getGroupKey(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, ok := ctx.groups[string(gk)]; !ok {
+		ctx.groups[string(gk)] = true
+		ctx.groupKeys = append(ctx.groupKeys, gk)
+	}
+	for _, agg := range ctx.aggregates {
+		agg.currentGroup = gk
+		args := make([]types.Datum, 0, len(agg.expr.Children))
+		for _, x := range agg.expr.Children {
+			cv, err := ctx.eval.Eval(x)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			args = append(args, cv)
+		}
+		if err = agg.update(args); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// getRowsFromAgg converts the partial aggregate results to rows.
+// The first column of a row is the group key, every aggregate function has one or two columns:
+// count for COUNT, count and sum for AVG, the value for SUM, MAX, MIN and FIRSTROW.
+func (h *rpcHandler) getRowsFromAgg(ctx *selectContext) ([]*tipb.Row, error) {
+	rows := make([]*tipb.Row, 0, len(ctx.groupKeys))
+	for _, gk := range ctx.groupKeys {
+		row := new(tipb.Row)
+		rowData := make([]types.Datum, 0, 1+2*len(ctx.aggregates))
+		rowData = append(rowData, types.NewBytesDatum(gk))
+		for _, agg := range ctx.aggregates {
+			agg.currentGroup = gk
+			rowData = append(rowData, agg.toDatums()...)
+		}
+		var err error
+		row.Data, err = codec.EncodeValue(nil, rowData...)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// aggItem is the partial result of an aggregate function for a group.
+type aggItem struct {
+	count     uint64
+	value     types.Datum
+	evaluated bool
+}
+
+type aggregateFuncExpr struct {
+	expr         *tipb.Expr
+	currentGroup []byte
+	items        map[string]*aggItem
+}
+
+func (n *aggregateFuncExpr) getAggItem() *aggItem {
+	if n.items == nil {
+		n.items = make(map[string]*aggItem)
+	}
+	item, ok := n.items[string(n.currentGroup)]
+	if !ok {
+		item = &aggItem{}
+		n.items[string(n.currentGroup)] = item
+	}
+	return item
+}
+
+func (n *aggregateFuncExpr) update(args []types.Datum) error {
+	item := n.getAggItem()
+	switch n.expr.GetTp() {
+	case tipb.ExprType_Count:
+		for _, a := range args {
+			if a.IsNull() {
+				return nil
+			}
+		}
+		item.count++
+	case tipb.ExprType_First:
+		if len(args) != 1 {
+			return errors.New("Wrong number of args for AggFuncFirstRow")
+		}
+		if !item.evaluated {
+			item.value = args[0]
+			item.evaluated = true
+		}
+	case tipb.ExprType_Sum, tipb.ExprType_Avg:
+		if len(args) != 1 {
+			return errors.New("Wrong number of args for AggFuncSum")
+		}
+		if args[0].IsNull() {
+			return nil
+		}
+		sum, err := types.CalculateSum(item.value.GetValue(), args[0].GetValue())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		item.value.SetValue(sum)
+		item.count++
+	case tipb.ExprType_Max, tipb.ExprType_Min:
+		if len(args) != 1 {
+			return errors.New("Wrong number of args for AggFuncMaxMin")
+		}
+		if args[0].IsNull() {
+			return nil
+		}
+		if !item.evaluated {
+			item.value = args[0]
+			item.evaluated = true
+			return nil
+		}
+		cmp, err := item.value.CompareDatum(args[0])
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if (n.expr.GetTp() == tipb.ExprType_Max && cmp < 0) || (n.expr.GetTp() == tipb.ExprType_Min && cmp > 0) {
+			item.value = args[0]
+		}
+	default:
+		return errors.Errorf("Unknown AggExpr: %v", n.expr.GetTp())
+	}
+	return nil
+}
+
+func (n *aggregateFuncExpr) toDatums() []types.Datum {
+	item := n.getAggItem()
+	switch n.expr.GetTp() {
+	case tipb.ExprType_Count:
+		return []types.Datum{types.NewUintDatum(item.count)}
+	case tipb.ExprType_Avg:
+		return []types.Datum{types.NewUintDatum(item.count), item.value}
+	}
+	return []types.Datum{item.value}
+}
@@ -34,6 +34,13 @@ type selectContext struct {
 	sel          *tipb.SelectRequest
 	eval         *xeval.Evaluator
 	whereColumns map[int64]*tipb.ColumnInfo
+	groups       map[string]bool
+	groupKeys    [][]byte
+	aggregates   []*aggregateFuncExpr
+	aggregate    bool
+	// topn is true if the request asks for the first rows in the order of some expressions.
+	topn     bool
+	topnHeap *topnHeap
 }
 
 func (h *rpcHandler) handleCopRequest(req *coprocessor.Request) (*coprocessor.Response, error) {
@@ -54,11 +61,25 @@ func (h *rpcHandler) handleCopRequest(req *coprocessor.Request) (*coprocessor.Re
 		ctx := &selectContext{
 			sel: sel,
 		}
+		ctx.eval = &xeval.Evaluator{Row: make(map[int64]types.Datum)}
 		if sel.Where != nil {
-			ctx.eval = &xeval.Evaluator{Row: make(map[int64]types.Datum)}
 			ctx.whereColumns = make(map[int64]*tipb.ColumnInfo)
 			collectColumnsInWhere(sel.Where, ctx)
 		}
+		ctx.aggregate = len(sel.Aggregates) > 0 || len(sel.GetGroupBy()) > 0
+		if ctx.aggregate {
+			for _, agg := range sel.Aggregates {
+				ctx.aggregates = append(ctx.aggregates, &aggregateFuncExpr{expr: agg})
+			}
+			ctx.groups = make(map[string]bool)
+		}
+		ctx.topn = len(sel.OrderBy) > 0 && sel.OrderBy[0].Expr != nil && sel.Limit != nil
+		if ctx.topn {
+			ctx.topnHeap = &topnHeap{
+				orderByItems: sel.OrderBy,
+				totalCount:   int(sel.GetLimit()),
+			}
+		}
 		var rows []*tipb.Row
 		if req.GetTp() == kv.ReqTypeSelect {
 			rows, err = h.getRowsFromSelectReq(ctx)
@@ -112,6 +133,18 @@ func collectColumnsInWhere(expr *tipb.Expr, ctx *selectContext) error {
 	return nil
 }
 
+// setRowToEval puts the column values of the row into the evaluator.
+func setRowToEval(ctx *selectContext, row *tipb.Row) error {
+	datums, err := codec.Decode(row.Data)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for i, col := range ctx.sel.TableInfo.Columns {
+		ctx.eval.Row[col.GetColumnId()] = datums[i]
+	}
+	return nil
+}
+
 func toPBError(err error) *tipb.Error {
 	if err == nil {
 		return nil
@@ -128,7 +161,7 @@ func (h *rpcHandler) getRowsFromSelectReq(ctx *selectContext) ([]*tipb.Row, erro
 	kvRanges, desc := h.extractKVRanges(ctx.sel)
 	var rows []*tipb.Row
 	limit := int64(-1)
-	if ctx.sel.Limit != nil {
+	if ctx.sel.Limit != nil && !ctx.topn {
 		limit = ctx.sel.GetLimit()
 	}
 	for _, ran := range kvRanges {
@@ -142,6 +175,12 @@ func (h *rpcHandler) getRowsFromSelectReq(ctx *selectContext) ([]*tipb.Row, erro
 		rows = append(rows, ranRows...)
 		limit -= int64(len(ranRows))
 	}
+	if ctx.aggregate {
+		return h.getRowsFromAgg(ctx)
+	}
+	if ctx.topn {
+		return h.getRowsFromTopN(ctx)
+	}
 	return rows, nil
 }
 
@@ -180,7 +219,8 @@ func (h *rpcHandler) extractKVRanges(sel *tipb.SelectRequest) (kvRanges []kv.Key
 		kvr.EndKey = kv.Key(minEndKey(upperKey, h.endKey))
 		kvRanges = append(kvRanges, kvr)
 	}
-	if sel.OrderBy != nil {
+	// The order by items of expressions are for the top n rows, the scan order is ascending.
+	if sel.OrderBy != nil && sel.OrderBy[0].Expr == nil {
 		desc = *sel.OrderBy[0].Desc
 	}
 	if desc {
@@ -226,6 +266,12 @@ func (h *rpcHandler) getRowsFromRange(ctx *selectContext, ran kv.KeyRange, limit
 			return nil, errors.Trace(err)
 		}
 		if row != nil {
+			if ctx.aggregate {
+				return nil, errors.Trace(h.aggregate(ctx, row))
+			}
+			if ctx.topn {
+				return nil, errors.Trace(h.evalTopN(ctx, row))
+			}
 			rows = append(rows, row)
 		}
 		return rows, nil
@@ -287,6 +333,18 @@ func (h *rpcHandler) getRowsFromRange(ctx *selectContext, ran kv.KeyRange, limit
 			return nil, errors.Trace(err)
 		}
 		if row != nil {
+			if ctx.aggregate {
+				if err = h.aggregate(ctx, row); err != nil {
+					return nil, errors.Trace(err)
+				}
+				continue
+			}
+			if ctx.topn {
+				if err = h.evalTopN(ctx, row); err != nil {
+					return nil, errors.Trace(err)
+				}
+				continue
+			}
 			rows = append(rows, row)
 			limit--
 		}
@@ -0,0 +1,132 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mocktikv
+
+import (
+	"container/heap"
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tipb/go-tipb"
+)
+
+// sortRow binds a row to the values of the order by items.
+type sortRow struct {
+	key []types.Datum
+	row *tipb.Row
+}
+
+// topnHeap keeps the first totalCount rows in the order of the order by items,
+// the root of the heap is the last one of them.
+type topnHeap struct {
+	rows         []*sortRow
+	orderByItems []*tipb.ByItem
+	totalCount   int
+	err          error
+}
+
+// Len implements heap.Interface Len interface.
+func (t *topnHeap) Len() int {
+	return len(t.rows)
+}
+
+// Swap implements heap.Interface Swap interface.
+func (t *topnHeap) Swap(i, j int) {
+	t.rows[i], t.rows[j] = t.rows[j], t.rows[i]
+}
+
+// Less implements heap.Interface Less interface, the row in the back of the order is less.
+func (t *topnHeap) Less(i, j int) bool {
+	return t.compare(t.rows[i], t.rows[j]) > 0
+}
+
+// Push implements heap.Interface Push interface.
+func (t *topnHeap) Push(x interface{}) {
+	t.rows = append(t.rows, x.(*sortRow))
+}
+
+// Pop implements heap.Interface Pop interface.
+func (t *topnHeap) Pop() interface{} {
+	n := len(t.rows)
+	x := t.rows[n-1]
+	t.rows = t.rows[:n-1]
+	return x
+}
+
+// compare returns a negative number if r1 is in front of r2 in the order.
+func (t *topnHeap) compare(r1, r2 *sortRow) int {
+	for i, item := range t.orderByItems {
+		cmp, err := r1.key[i].CompareDatum(r2.key[i])
+		if err != nil {
+			t.err = err
+			return 0
+		}
+		if item.GetDesc() {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+// tryToAddRow adds the row to the heap if it's one of the first totalCount rows.
+func (t *topnHeap) tryToAddRow(row *sortRow) {
+	if t.totalCount == 0 {
+		return
+	}
+	if len(t.rows) < t.totalCount {
+		heap.Push(t, row)
+		return
+	}
+	if t.compare(row, t.rows[0]) < 0 {
+		t.rows[0] = row
+		heap.Fix(t, 0)
+	}
+}
+
+// evalTopN evaluates the order by items for the row and adds it to the top n rows.
+func (h *rpcHandler) evalTopN(ctx *selectContext, row *tipb.Row) error {
+	if err := setRowToEval(ctx, row); err != nil {
+		return errors.Trace(err)
+	}
+	sr := &sortRow{
+		key: make([]types.Datum, 0, len(ctx.sel.OrderBy)),
+		row: row,
+	}
+	for _, item := range ctx.sel.OrderBy {
+		v, err := ctx.eval.Eval(item.Expr)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		sr.key = append(sr.key, v)
+	}
+	ctx.topnHeap.tryToAddRow(sr)
+	return errors.Trace(ctx.topnHeap.err)
+}
+
+// getRowsFromTopN returns the top n rows in order.
+func (h *rpcHandler) getRowsFromTopN(ctx *selectContext) ([]*tipb.Row, error) {
+	sort.Sort(sort.Reverse(ctx.topnHeap))
+	if ctx.topnHeap.err != nil {
+		return nil, errors.Trace(ctx.topnHeap.err)
+	}
+	rows := make([]*tipb.Row, 0, len(ctx.topnHeap.rows))
+	for _, sr := range ctx.topnHeap.rows {
+		rows = append(rows, sr.row)
+	}
+	return rows, nil
+}
@@ -60,8 +60,10 @@ func (c *RegionCache) GetRegion(key []byte) (*Region, error) {
 	r := c.getRegionFromCache(key)
 	c.mu.RUnlock()
 	if r != nil {
+		regionCacheCounter.WithLabelValues("hit").Inc()
 		return r, nil
 	}
+	regionCacheCounter.WithLabelValues("miss").Inc()
 	r, err := c.loadRegion(key)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -71,6 +73,24 @@ func (c *RegionCache) GetRegion(key []byte) (*Region, error) {
 	return c.insertRegionToCache(r), nil
 }
 
+// ListRegionsInRange lists the Regions covering the key range [startKey, endKey),
+// an empty endKey means the end of the key space.
+func (c *RegionCache) ListRegionsInRange(startKey, endKey []byte) ([]*Region, error) {
+	var regions []*Region
+	key := startKey
+	for {
+		r, err := c.GetRegion(key)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		regions = append(regions, r)
+		if len(r.EndKey()) == 0 || (len(endKey) > 0 && bytes.Compare(r.EndKey(), endKey) >= 0) {
+			return regions, nil
+		}
+		key = r.EndKey()
+	}
+}
+
 // GroupKeysByRegion separates keys into groups by their belonging Regions.
 // Specially it also returns the first key's region which may be used as the
 // 'PrimaryLockKey' and should be committed ahead of others.
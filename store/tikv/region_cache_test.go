@@ -186,6 +186,29 @@ func (s *testRegionCacheSuite) TestSplit(c *C) {
 	s.checkCache(c, 1)
 }
 
+func (s *testRegionCacheSuite) TestListRegionsInRange(c *C) {
+	// split to ['' - 'm' - 'z']
+	region2 := s.cluster.AllocID()
+	newPeers := s.cluster.AllocIDs(2)
+	s.cluster.Split(s.region1, region2, []byte("m"), newPeers, newPeers[0])
+
+	regions, err := s.cache.ListRegionsInRange([]byte("a"), []byte("c"))
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 1)
+	c.Assert(regions[0].GetID(), Equals, s.region1)
+
+	regions, err = s.cache.ListRegionsInRange([]byte("a"), []byte("x"))
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 2)
+	c.Assert(regions[0].GetID(), Equals, s.region1)
+	c.Assert(regions[1].GetID(), Equals, region2)
+
+	regions, err = s.cache.ListRegionsInRange([]byte("m"), nil)
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 1)
+	c.Assert(regions[0].GetID(), Equals, region2)
+}
+
 func (s *testRegionCacheSuite) TestMerge(c *C) {
 	// ['' - 'm' - 'z']
 	region2 := s.cluster.AllocID()
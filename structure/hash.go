@@ -199,6 +199,44 @@ func (t *TxStructure) HGetAll(key []byte) ([]HashPair, error) {
 	return res, errors.Trace(err)
 }
 
+// HGetLastN gets the last num fields and values in a hash, the last field is the first.
+func (t *TxStructure) HGetLastN(key []byte, num int) ([]HashPair, error) {
+	if num <= 0 {
+		return nil, nil
+	}
+	res := make([]HashPair, 0, num)
+	err := t.iterReverseHash(key, func(field []byte, value []byte) (bool, error) {
+		pair := HashPair{
+			Field: append([]byte{}, field...),
+			Value: append([]byte{}, value...),
+		}
+		res = append(res, pair)
+		return len(res) < num, nil
+	})
+	if terror.ErrorNotEqual(err, kv.ErrNotImplemented) {
+		return res, errors.Trace(err)
+	}
+
+	// The store doesn't support the reverse seek, so keeps the last num pairs while iterating the hash forward.
+	res = res[:0]
+	err = t.iterateHash(key, func(field []byte, value []byte) error {
+		pair := HashPair{
+			Field: append([]byte{}, field...),
+			Value: append([]byte{}, value...),
+		}
+		if len(res) == num {
+			copy(res, res[1:])
+			res = res[:num-1]
+		}
+		res = append(res, pair)
+		return nil
+	})
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return res, errors.Trace(err)
+}
+
 // HClear removes the hash value of the key.
 func (t *TxStructure) HClear(key []byte) error {
 	metaKey := t.encodeHashMetaKey(key)
@@ -251,6 +289,43 @@ func (t *TxStructure) iterateHash(key []byte, fn func(k []byte, v []byte) error)
 	return nil
 }
 
+// iterReverseHash iterates the hash from the last field, it stops when fn returns false.
+func (t *TxStructure) iterReverseHash(key []byte, fn func(k []byte, v []byte) (bool, error)) error {
+	dataPrefix := t.hashDataKeyPrefix(key)
+	it, err := t.txn.SeekReverse(dataPrefix.PrefixNext())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer it.Close()
+
+	var (
+		field []byte
+		more  bool
+	)
+	for it.Valid() {
+		if !it.Key().HasPrefix(dataPrefix) {
+			break
+		}
+
+		_, field, err = t.decodeHashDataKey(it.Key())
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		more, err = fn(field, it.Value())
+		if err != nil || !more {
+			return errors.Trace(err)
+		}
+
+		err = it.Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	return nil
+}
+
 func (t *TxStructure) loadHashMeta(metaKey []byte) (hashMeta, error) {
 	v, err := t.txn.Get(metaKey)
 	if terror.ErrorEqual(err, kv.ErrNotExist) {
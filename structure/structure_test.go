@@ -209,6 +209,15 @@ func (s *testTxStructureSuite) TestHash(c *C) {
 		{[]byte("1"), []byte("1")},
 		{[]byte("2"), []byte("2")}})
 
+	res, err = tx.HGetLastN(key, 1)
+	c.Assert(err, IsNil)
+	c.Assert(res, DeepEquals, []HashPair{{[]byte("2"), []byte("2")}})
+	res, err = tx.HGetLastN(key, 3)
+	c.Assert(err, IsNil)
+	c.Assert(res, DeepEquals, []HashPair{
+		{[]byte("2"), []byte("2")},
+		{[]byte("1"), []byte("1")}})
+
 	err = tx.HDel(key, []byte("1"))
 	c.Assert(err, IsNil)
 
@@ -52,6 +52,8 @@ var (
 	ErrIndexStateCantNone = terror.ClassTable.New(codeIndexStateCantNone, "index can not be in none state")
 	// ErrInvalidRecordKey returns for invalid record key.
 	ErrInvalidRecordKey = terror.ClassTable.New(codeInvalidRecordKey, "invalid record key")
+	// ErrNoPartitionForValue returns for the row whose partition column value isn't in any partition.
+	ErrNoPartitionForValue = terror.ClassTable.New(codeNoPartitionForValue, "table has no partition for the value")
 )
 
 // RecordIterFunc is used for low-level record iteration.
@@ -116,6 +118,23 @@ type Table interface {
 	Seek(ctx context.Context, h int64) (handle int64, found bool, err error)
 }
 
+// PartitionedTable is a table partitioned by the partition column, the rows of every partition are stored in
+// a physical table whose ID is the ID of the partition. The rows are written to the partitions which their
+// partition column values belong to, and the rows are read from all the partitions.
+type PartitionedTable interface {
+	Table
+
+	// Partitions returns the physical tables of the partitions in the order of the partition definitions.
+	Partitions() []Table
+
+	// GetPartition returns the physical table of the partition by its ID, it returns nil if the partition
+	// doesn't exist.
+	GetPartition(id int64) Table
+
+	// LocatePartition returns the physical table of the partition which the row belongs to.
+	LocatePartition(r []types.Datum) (Table, error)
+}
+
 // TableFromMeta builds a table.Table from *model.TableInfo.
 // Currently, it is assigned to tables.TableFromMeta in tidb package's init function.
 var TableFromMeta func(alloc autoid.Allocator, tblInfo *model.TableInfo) (Table, error)
@@ -139,6 +158,8 @@ const (
 	codeUnknownColumn   = 1054
 	codeDuplicateColumn = 1110
 	codeNoDefaultValue  = 1364
+
+	codeNoPartitionForValue = 1526
 )
 
 func init() {
@@ -147,6 +168,8 @@ func init() {
 		codeUnknownColumn:   mysql.ErrBadField,
 		codeDuplicateColumn: mysql.ErrFieldSpecifiedTwice,
 		codeNoDefaultValue:  mysql.ErrNoDefaultForField,
+
+		codeNoPartitionForValue: mysql.ErrNoPartitionForGivenValue,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassTable] = tableMySQLErrCodes
 }
@@ -0,0 +1,305 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tables
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta/autoid"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/types"
+)
+
+var _ table.PartitionedTable = &PartitionedTable{}
+
+// PartitionedTable implements table.PartitionedTable interface.
+// The embedded Table is the logical table, its prefixes have no data, and the rows are stored in the partitions.
+type PartitionedTable struct {
+	*Table
+
+	partitions []*Table
+	// column is the partition column.
+	column *table.Column
+}
+
+// partitionAllocator allocates the auto IDs of a partition from the allocator of the partitioned table, so the
+// handles of the rows are unique in the partitioned table and a row can be moved to another partition.
+type partitionAllocator struct {
+	autoid.Allocator
+	tableID int64
+}
+
+// Alloc implements autoid.Allocator Alloc interface.
+func (a *partitionAllocator) Alloc(tableID int64) (int64, error) {
+	return a.Allocator.Alloc(a.tableID)
+}
+
+// Rebase implements autoid.Allocator Rebase interface.
+func (a *partitionAllocator) Rebase(tableID, newBase int64, allocIDs bool) error {
+	return a.Allocator.Rebase(a.tableID, newBase, allocIDs)
+}
+
+// newPartitionedTable constructs the partitioned table from the logical table.
+func newPartitionedTable(t *Table, tblInfo *model.TableInfo) (*PartitionedTable, error) {
+	pi := tblInfo.Partition
+	pt := &PartitionedTable{Table: t, column: table.FindCol(t.Columns, pi.Column.L)}
+	if pt.column == nil {
+		return nil, errors.Errorf("unknown partition column %s", pi.Column)
+	}
+	alloc := &partitionAllocator{Allocator: t.alloc, tableID: t.ID}
+	for _, def := range pi.Definitions {
+		// The partition is a physical table which has the meta of the partitioned table except the ID.
+		partInfo := tblInfo.Clone()
+		partInfo.ID = def.ID
+		partInfo.Partition = nil
+		p, err := TableFromMeta(alloc, partInfo)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		pt.partitions = append(pt.partitions, p.(*Table))
+	}
+	return pt, nil
+}
+
+// Partitions implements table.PartitionedTable Partitions interface.
+func (t *PartitionedTable) Partitions() []table.Table {
+	ps := make([]table.Table, 0, len(t.partitions))
+	for _, p := range t.partitions {
+		ps = append(ps, p)
+	}
+	return ps
+}
+
+// GetPartition implements table.PartitionedTable GetPartition interface.
+func (t *PartitionedTable) GetPartition(id int64) table.Table {
+	for _, p := range t.partitions {
+		if p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// LocatePartition implements table.PartitionedTable LocatePartition interface.
+func (t *PartitionedTable) LocatePartition(r []types.Datum) (table.Table, error) {
+	p, err := t.locatePartition(r)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return p, nil
+}
+
+// locatePartition returns the partition which the row belongs to by the value of its partition expression.
+func (t *PartitionedTable) locatePartition(r []types.Datum) (*Table, error) {
+	d := r[t.column.Offset]
+	v, err := t.meta.Partition.Eval(d)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	i, err := t.meta.Partition.LocatePartition(v)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if i < 0 {
+		s, err := v.ToString()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return nil, table.ErrNoPartitionForValue.Gen("Table has no partition for value %s", s)
+	}
+	return t.partitions[i], nil
+}
+
+// rowPartition returns the partition which stores the row with the handle, it returns nil if the row doesn't exist.
+func (t *PartitionedTable) rowPartition(ctx context.Context, h int64) (*Table, error) {
+	txn, err := ctx.GetTxn(false)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, p := range t.partitions {
+		// The row lock key exists for every row.
+		_, err = txn.Get(p.RecordKey(h, nil))
+		if terror.ErrorEqual(err, kv.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return p, nil
+	}
+	return nil, nil
+}
+
+// IterRecords implements table.Table IterRecords interface.
+// The partitions are iterated in order, starting from the partition which startKey belongs to, or from the first
+// partition if startKey doesn't belong to any partition.
+func (t *PartitionedTable) IterRecords(ctx context.Context, startKey kv.Key, cols []*table.Column,
+	fn table.RecordIterFunc) error {
+	start := 0
+	for i, p := range t.partitions {
+		if startKey.HasPrefix(p.RecordPrefix()) {
+			start = i
+			break
+		}
+	}
+	more := true
+	iterFn := func(h int64, rec []types.Datum, cols []*table.Column) (bool, error) {
+		var err error
+		more, err = fn(h, rec, cols)
+		return more, errors.Trace(err)
+	}
+	for i := start; i < len(t.partitions) && more; i++ {
+		p := t.partitions[i]
+		key := p.FirstKey()
+		if i == start && startKey.HasPrefix(p.RecordPrefix()) {
+			key = startKey
+		}
+		if err := p.IterRecords(ctx, key, cols, iterFn); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// RowWithCols implements table.Table RowWithCols interface.
+func (t *PartitionedTable) RowWithCols(ctx context.Context, h int64, cols []*table.Column) ([]types.Datum, error) {
+	p, err := t.rowPartition(ctx, h)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if p == nil {
+		return nil, errors.Trace(kv.ErrNotExist)
+	}
+	r, err := p.RowWithCols(ctx, h, cols)
+	return r, errors.Trace(err)
+}
+
+// Row implements table.Table Row interface.
+func (t *PartitionedTable) Row(ctx context.Context, h int64) ([]types.Datum, error) {
+	r, err := t.RowWithCols(ctx, h, t.Cols())
+	return r, errors.Trace(err)
+}
+
+// Truncate implements table.Table Truncate interface.
+func (t *PartitionedTable) Truncate(ctx context.Context) error {
+	for _, p := range t.partitions {
+		if err := p.Truncate(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// AddRecord implements table.Table AddRecord interface.
+func (t *PartitionedTable) AddRecord(ctx context.Context, r []types.Datum) (recordID int64, err error) {
+	p, err := t.locatePartition(r)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	recordID, err = p.genRecordID(r)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if h, err := p.addRecord(ctx, recordID, r); err != nil {
+		return h, errors.Trace(err)
+	}
+
+	sessVars := variable.GetSessionVars(ctx)
+	sessVars.AddAffectedRows(1)
+	sessVars.UpdateDeltaForTable(t.ID, 1, 1)
+	return recordID, nil
+}
+
+// UpdateRecord implements table.Table UpdateRecord interface.
+// If the partition column is changed to a value of another partition, the row is moved to the partition.
+func (t *PartitionedTable) UpdateRecord(ctx context.Context, h int64, oldData []types.Datum, newData []types.Datum, touched map[int]bool) error {
+	from, err := t.locatePartition(oldData)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	to, err := t.locatePartition(newData)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if from == to {
+		err = from.updateRecord(ctx, h, oldData, newData, touched)
+	} else {
+		err = t.moveRecord(ctx, from, to, h, oldData, newData, touched)
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	variable.GetSessionVars(ctx).UpdateDeltaForTable(t.ID, 0, 1)
+	return nil
+}
+
+// moveRecord removes the row from the partition from and adds the updated row to the partition to with the same
+// handle.
+func (t *PartitionedTable) moveRecord(ctx context.Context, from, to *Table, h int64, oldData []types.Datum,
+	newData []types.Datum, touched map[int]bool) error {
+	currentData := make([]types.Datum, len(t.writableCols()))
+	copy(currentData, newData)
+	if err := t.setOnUpdateData(ctx, touched, currentData); err != nil {
+		return errors.Trace(err)
+	}
+	if err := from.removeRecord(ctx, h, oldData); err != nil {
+		return errors.Trace(err)
+	}
+	_, err := to.addRecord(ctx, h, currentData)
+	return errors.Trace(err)
+}
+
+// RemoveRecord implements table.Table RemoveRecord interface.
+func (t *PartitionedTable) RemoveRecord(ctx context.Context, h int64, r []types.Datum) error {
+	p, err := t.locatePartition(r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err = p.removeRecord(ctx, h, r); err != nil {
+		return errors.Trace(err)
+	}
+	variable.GetSessionVars(ctx).UpdateDeltaForTable(t.ID, -1, 1)
+	return nil
+}
+
+// LockRow implements table.Table LockRow interface.
+func (t *PartitionedTable) LockRow(ctx context.Context, h int64, forRead bool) error {
+	p, err := t.rowPartition(ctx, h)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if p == nil {
+		return nil
+	}
+	return errors.Trace(p.LockRow(ctx, h, forRead))
+}
+
+// Seek implements table.Table Seek interface.
+func (t *PartitionedTable) Seek(ctx context.Context, h int64) (int64, bool, error) {
+	var handle int64
+	var found bool
+	for _, p := range t.partitions {
+		ph, ok, err := p.Seek(ctx, h)
+		if err != nil {
+			return 0, false, errors.Trace(err)
+		}
+		if ok && (!found || ph < handle) {
+			handle, found = ph, true
+		}
+	}
+	return handle, found, nil
+}
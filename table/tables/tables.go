@@ -93,6 +93,9 @@ func TableFromMeta(alloc autoid.Allocator, tblInfo *model.TableInfo) (table.Tabl
 	}
 
 	t.meta = tblInfo
+	if tblInfo.Partition != nil {
+		return newPartitionedTable(t, tblInfo)
+	}
 	return t, nil
 }
 
@@ -193,6 +196,14 @@ func (t *Table) Truncate(ctx context.Context) error {
 
 // UpdateRecord implements table.Table UpdateRecord interface.
 func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData []types.Datum, newData []types.Datum, touched map[int]bool) error {
+	if err := t.updateRecord(ctx, h, oldData, newData, touched); err != nil {
+		return errors.Trace(err)
+	}
+	variable.GetSessionVars(ctx).UpdateDeltaForTable(t.ID, 0, 1)
+	return nil
+}
+
+func (t *Table) updateRecord(ctx context.Context, h int64, oldData []types.Datum, newData []types.Datum, touched map[int]bool) error {
 	// We should check whether this table has on update column which state is write only.
 	currentData := make([]types.Datum, len(t.writableCols()))
 	copy(currentData, newData)
@@ -212,7 +223,7 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData []types.Datum
 	defer bs.Release()
 
 	// set new value
-	if err = t.setNewData(bs, h, touched, currentData); err != nil {
+	if err = t.setNewData(ctx, bs, h, touched, currentData); err != nil {
 		return errors.Trace(err)
 	}
 
@@ -222,11 +233,7 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData []types.Datum
 	}
 
 	err = bs.SaveTo(txn)
-	if err != nil {
-		return errors.Trace(err)
-	}
-
-	return nil
+	return errors.Trace(err)
 }
 
 func (t *Table) setOnUpdateData(ctx context.Context, touched map[int]bool, data []types.Datum) error {
@@ -244,7 +251,7 @@ func (t *Table) setOnUpdateData(ctx context.Context, touched map[int]bool, data
 	}
 	return nil
 }
-func (t *Table) setNewData(rm kv.RetrieverMutator, h int64, touched map[int]bool, data []types.Datum) error {
+func (t *Table) setNewData(ctx context.Context, rm kv.RetrieverMutator, h int64, touched map[int]bool, data []types.Datum) error {
 	for _, col := range t.Cols() {
 		if !touched[col.Offset] {
 			continue
@@ -256,9 +263,55 @@ func (t *Table) setNewData(rm kv.RetrieverMutator, h int64, touched map[int]bool
 		}
 	}
 
+	for _, col := range t.writableCols() {
+		from := t.changeFromCol(col)
+		if from == nil || !touched[from.Offset] {
+			continue
+		}
+
+		value, err := castChangedValue(ctx, data[from.Offset], from, col)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		k := t.RecordKey(h, col)
+		if err = SetColValue(rm, k, value); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	return nil
+}
+
+// changeFromCol returns the public column whose values are written to the non-public column which is being changed
+// by MODIFY/CHANGE COLUMN, it returns nil if the column isn't being changed.
+func (t *Table) changeFromCol(col *table.Column) *table.Column {
+	if col.ChangeFromID == 0 || col.State == model.StatePublic {
+		return nil
+	}
+	for _, c := range t.Cols() {
+		if c.ID == col.ChangeFromID {
+			return c
+		}
+	}
 	return nil
 }
 
+// castChangedValue converts the value of the column from which col is changed from to the type of col.
+// After the new column replaces the old column, the old column is changed from the new column until it's dropped.
+// Its values are only read by the servers with the previous schema, so the value which can't be converted back to
+// the old type is written as NULL instead of failing the write. The new column always has a larger ID, as the IDs
+// are allocated increasingly.
+func castChangedValue(ctx context.Context, val types.Datum, from *table.Column, col *table.Column) (types.Datum, error) {
+	casted, err := table.CastValue(ctx, val, col)
+	if err == nil {
+		err = col.CheckNotNull(casted)
+	}
+	if err != nil && col.ID < from.ID {
+		return types.Datum{}, nil
+	}
+	return casted, errors.Trace(err)
+}
+
 func (t *Table) rebuildIndices(rm kv.RetrieverMutator, h int64, touched map[int]bool, oldData []types.Datum, newData []types.Datum) error {
 	for _, idx := range t.Indices() {
 		idxTouched := false
@@ -295,20 +348,33 @@ func (t *Table) rebuildIndices(rm kv.RetrieverMutator, h int64, touched map[int]
 
 // AddRecord implements table.Table AddRecord interface.
 func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64, err error) {
-	var hasRecordID bool
+	recordID, err = t.genRecordID(r)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if h, err := t.addRecord(ctx, recordID, r); err != nil {
+		return h, errors.Trace(err)
+	}
+
+	sessVars := variable.GetSessionVars(ctx)
+	sessVars.AddAffectedRows(1)
+	sessVars.UpdateDeltaForTable(t.ID, 1, 1)
+	return recordID, nil
+}
+
+// genRecordID returns the handle of the new row, it's the value of the PK handle column or an allocated ID.
+func (t *Table) genRecordID(r []types.Datum) (int64, error) {
 	for _, col := range t.Cols() {
 		if col.IsPKHandleColumn(t.meta) {
-			recordID = r[col.Offset].GetInt64()
-			hasRecordID = true
-			break
-		}
-	}
-	if !hasRecordID {
-		recordID, err = t.alloc.Alloc(t.ID)
-		if err != nil {
-			return 0, errors.Trace(err)
+			return r[col.Offset].GetInt64(), nil
 		}
 	}
+	recordID, err := t.alloc.Alloc(t.ID)
+	return recordID, errors.Trace(err)
+}
+
+// addRecord adds the row with the handle, it returns the handle of the duplicate row if the row is duplicate.
+func (t *Table) addRecord(ctx context.Context, recordID int64, r []types.Datum) (int64, error) {
 	txn, err := ctx.GetTxn(false)
 	if err != nil {
 		return 0, errors.Trace(err)
@@ -329,6 +395,21 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64,
 		if col.IsPKHandleColumn(t.meta) {
 			continue
 		}
+		if from := t.changeFromCol(col); from != nil {
+			// The column is being changed, it's added with the converted value of the column it's changed from.
+			var value types.Datum
+			value, err = castChangedValue(ctx, r[from.Offset], from, col)
+			if err != nil {
+				return 0, errors.Trace(err)
+			}
+			if value.IsNull() {
+				continue
+			}
+			if err = SetColValue(txn, t.RecordKey(recordID, col), value); err != nil {
+				return 0, errors.Trace(err)
+			}
+			continue
+		}
 		if col.DefaultValue == nil && r[col.Offset].IsNull() {
 			// Save storage space by not storing null value.
 			continue
@@ -357,9 +438,7 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64,
 	if err = bs.SaveTo(txn); err != nil {
 		return 0, errors.Trace(err)
 	}
-
-	variable.GetSessionVars(ctx).AddAffectedRows(1)
-	return recordID, nil
+	return 0, nil
 }
 
 // Generate index content string representation.
@@ -506,17 +585,20 @@ func (t *Table) LockRow(ctx context.Context, h int64, forRead bool) error {
 
 // RemoveRecord implements table.Table RemoveRecord interface.
 func (t *Table) RemoveRecord(ctx context.Context, h int64, r []types.Datum) error {
-	err := t.removeRowData(ctx, h)
-	if err != nil {
+	if err := t.removeRecord(ctx, h, r); err != nil {
 		return errors.Trace(err)
 	}
+	variable.GetSessionVars(ctx).UpdateDeltaForTable(t.ID, -1, 1)
+	return nil
+}
 
-	err = t.removeRowIndices(ctx, h, r)
+func (t *Table) removeRecord(ctx context.Context, h int64, r []types.Datum) error {
+	err := t.removeRowData(ctx, h)
 	if err != nil {
 		return errors.Trace(err)
 	}
-
-	return nil
+	err = t.removeRowIndices(ctx, h, r)
+	return errors.Trace(err)
 }
 
 func (t *Table) removeRowData(ctx context.Context, h int64) error {
@@ -0,0 +1,146 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/server"
+)
+
+// config is the configuration of tidb-server. It's loaded from the file given by --config,
+// the command line flags which are set explicitly override the values in the file.
+// See config.toml.example for the description of the options.
+type config struct {
+	Store       string            `toml:"store"`
+	Path        string            `toml:"path"`
+	Lease       int               `toml:"lease"`
+	Log         logConfig         `toml:"log"`
+	Server      server.Config     `toml:"server"`
+	Performance performanceConfig `toml:"performance"`
+}
+
+type logConfig struct {
+	Level  string `toml:"level"`
+	File   string `toml:"file"`
+	Rotate string `toml:"rotate"`
+}
+
+type performanceConfig struct {
+	CopConcurrency int `toml:"cop_concurrency"`
+	RetryLimit     int `toml:"retry_limit"`
+}
+
+// defaultConfig returns the configuration used when there is no configuration file,
+// the values are the same as the defaults of the flags.
+func defaultConfig() *config {
+	return &config{
+		Store: "goleveldb",
+		Path:  "/tmp/tidb",
+		Lease: 1,
+		Log: logConfig{
+			Level: "debug",
+		},
+		Server: server.Config{
+			Addr:       ":4000",
+			StatusAddr: ":10080",
+			TokenLimit: 100,
+		},
+		Performance: performanceConfig{
+			CopConcurrency: 10,
+			RetryLimit:     10,
+		},
+	}
+}
+
+// loadConfig loads the configuration file, the options not in the file have the default values.
+func loadConfig(path string) (*config, error) {
+	cfg := defaultConfig()
+	if path == "" {
+		return cfg, nil
+	}
+	md, err := toml.DecodeFile(path, cfg)
+	if err != nil {
+		return nil, errors.Annotatef(err, "load config file %s", path)
+	}
+	// Reject the misspelled options instead of ignoring them silently.
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, errors.Errorf("load config file %s: unknown options %v", path, undecoded)
+	}
+	return cfg, nil
+}
+
+// overrideWithFlags overrides the configuration with the command line flags which are set explicitly.
+func (cfg *config) overrideWithFlags() {
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "store":
+			cfg.Store = *store
+		case "path":
+			cfg.Path = *storePath
+		case "L":
+			cfg.Log.Level = *logLevel
+		case "P":
+			cfg.Server.Addr = fmt.Sprintf(":%s", *port)
+		case "status":
+			cfg.Server.StatusAddr = fmt.Sprintf(":%s", *statusPort)
+		case "lease":
+			cfg.Lease = *lease
+		case "socket":
+			cfg.Server.Socket = *socket
+		case "ssl-ca":
+			cfg.Server.SSLCA = *sslCA
+		case "ssl-cert":
+			cfg.Server.SSLCert = *sslCert
+		case "ssl-key":
+			cfg.Server.SSLKey = *sslKey
+		case "ssl-verify-client":
+			cfg.Server.SSLVerifyClient = *sslVerify
+		}
+	})
+	cfg.Server.LogLevel = cfg.Log.Level
+}
+
+func (cfg *config) validate() error {
+	if cfg.Lease < 0 {
+		return errors.Errorf("invalid lease seconds %d", cfg.Lease)
+	}
+	switch strings.ToLower(cfg.Log.Level) {
+	case "debug", "info", "warn", "error", "fatal":
+	default:
+		return errors.Errorf("invalid log level %s", cfg.Log.Level)
+	}
+	switch cfg.Log.Rotate {
+	case "", "day", "hour":
+	default:
+		return errors.Errorf("invalid log rotate %s, it must be day or hour", cfg.Log.Rotate)
+	}
+	if cfg.Log.Rotate != "" && cfg.Log.File == "" {
+		return errors.New("log rotate requires log file")
+	}
+	if cfg.Server.TokenLimit <= 0 {
+		return errors.Errorf("invalid token limit %d", cfg.Server.TokenLimit)
+	}
+	if cfg.Performance.CopConcurrency <= 0 {
+		return errors.Errorf("invalid coprocessor concurrency %d", cfg.Performance.CopConcurrency)
+	}
+	if cfg.Performance.RetryLimit < 0 {
+		return errors.Errorf("invalid retry limit %d", cfg.Performance.RetryLimit)
+	}
+	return nil
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/metric"
 	"github.com/pingcap/tidb/server"
 	"github.com/pingcap/tidb/store/localstore/boltdb"
@@ -33,6 +34,7 @@ import (
 )
 
 var (
+	configPath = flag.String("config", "", "path of the configuration file in TOML format")
 	store      = flag.String("store", "goleveldb", "registered store name, [memory, goleveldb, boltdb, tikv]")
 	storePath  = flag.String("path", "/tmp/tidb", "tidb storage path")
 	logLevel   = flag.String("L", "debug", "log level: info, debug, warn, error, fatal")
@@ -40,6 +42,10 @@ var (
 	statusPort = flag.String("status", "10080", "tidb server status port")
 	lease      = flag.Int("lease", 1, "schema lease seconds, very dangerous to change only if you know what you do")
 	socket     = flag.String("socket", "", "The socket file to use for connection.")
+	sslCA      = flag.String("ssl-ca", "", "path of the CA certificate used to verify client certificates")
+	sslCert    = flag.String("ssl-cert", "", "path of the server certificate, enables TLS with ssl-key")
+	sslKey     = flag.String("ssl-key", "", "path of the server private key")
+	sslVerify  = flag.Bool("ssl-verify-client", false, "require clients to present a certificate signed by ssl-ca")
 )
 
 func main() {
@@ -52,21 +58,21 @@ func main() {
 
 	flag.Parse()
 
-	if *lease < 0 {
-		log.Fatalf("invalid lease seconds %d", *lease)
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatal(errors.ErrorStack(err))
 	}
-
-	tidb.SetSchemaLease(time.Duration(*lease) * time.Second)
-
-	cfg := &server.Config{
-		Addr:       fmt.Sprintf(":%s", *port),
-		LogLevel:   *logLevel,
-		StatusAddr: fmt.Sprintf(":%s", *statusPort),
-		Socket:     *socket,
+	cfg.overrideWithFlags()
+	if err = cfg.validate(); err != nil {
+		log.Fatal(errors.ErrorStack(err))
 	}
 
-	log.SetLevelByString(cfg.LogLevel)
-	store, err := tidb.NewStore(fmt.Sprintf("%s://%s", *store, *storePath))
+	setupLog(&cfg.Log)
+	tidb.SetSchemaLease(time.Duration(cfg.Lease) * time.Second)
+	tidb.SetCommitRetryLimit(cfg.Performance.RetryLimit)
+	executor.SetCopConcurrency(cfg.Performance.CopConcurrency)
+
+	store, err := tidb.NewStore(fmt.Sprintf("%s://%s", cfg.Store, cfg.Path))
 	if err != nil {
 		log.Fatal(errors.ErrorStack(err))
 	}
@@ -80,7 +86,7 @@ func main() {
 	var driver server.IDriver
 	driver = server.NewTiDBDriver(store)
 	var svr *server.Server
-	svr, err = server.NewServer(cfg, driver)
+	svr, err = server.NewServer(&cfg.Server, driver)
 	if err != nil {
 		log.Fatal(errors.ErrorStack(err))
 	}
@@ -101,3 +107,19 @@ func main() {
 
 	log.Error(svr.Run())
 }
+
+func setupLog(cfg *logConfig) {
+	log.SetLevelByString(cfg.Level)
+	if cfg.File == "" {
+		return
+	}
+	if err := log.SetOutputByName(cfg.File); err != nil {
+		log.Fatal(errors.ErrorStack(err))
+	}
+	switch cfg.Rotate {
+	case "day":
+		log.SetRotateByDay()
+	case "hour":
+		log.SetRotateByHour()
+	}
+}
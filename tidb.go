@@ -26,6 +26,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
@@ -96,6 +97,9 @@ var (
 	// but you must know that too little may cause badly performance degradation.
 	// For production, you should set a big schema lease, like 300s+.
 	schemaLease = 1 * time.Second
+
+	// commitRetryLimit is the max times a transaction is retried when the commit conflicts.
+	commitRetryLimit = 10
 )
 
 // SetSchemaLease changes the default schema lease time for DDL.
@@ -105,6 +109,11 @@ func SetSchemaLease(lease time.Duration) {
 	schemaLease = lease
 }
 
+// SetCommitRetryLimit changes the max retry times of the transactions in the new sessions.
+func SetCommitRetryLimit(limit int) {
+	commitRetryLimit = limit
+}
+
 // What character set should the server translate a statement to after receiving it?
 // For this, the server uses the character_set_connection and collation_connection system variables.
 // It converts statements sent by the client from character_set_client to character_set_connection
@@ -147,6 +156,10 @@ func runStmt(ctx context.Context, s ast.Statement, args ...interface{}) (ast.Rec
 	var rs ast.RecordSet
 	// before every execution, we must clear affectedrows.
 	variable.GetSessionVars(ctx).SetAffectedRows(0)
+	// A kill that arrives before the statement starts only affects the previous one.
+	if killed := variable.GetSessionVars(ctx).Killed; killed != nil {
+		atomic.StoreUint32(killed, 0)
+	}
 	if s.IsDDL() {
 		err = ctx.CommitTxn()
 		if err != nil {
@@ -277,6 +290,11 @@ func IsQuery(sql string) bool {
 	return false
 }
 
+// GetDomain gets the domain of the store, the domain is created if it doesn't exist.
+func GetDomain(store kv.Storage) (*domain.Domain, error) {
+	return domap.Get(store)
+}
+
 var tpsMetrics metric.TPSMetrics
 
 // GetTPS gets tidb tps.
@@ -0,0 +1,101 @@
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"time"
+
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
+)
+
+// ProcessInfo is the information of a connection, it's used by show processlist.
+type ProcessInfo struct {
+	ID      uint64
+	User    string
+	Host    string
+	DB      string
+	Command string
+	Time    time.Time // The start time of the current command.
+	State   uint16    // The server status flags of the session.
+	Info    string    // The SQL of the current command.
+}
+
+// processInfoMaxInfoLen is the max length of the info shown by show processlist without FULL.
+const processInfoMaxInfoLen = 100
+
+// ToRow returns the row of show processlist and information_schema.PROCESSLIST for the process info,
+// the info is truncated to 100 characters if full is false.
+func (pi *ProcessInfo) ToRow(full bool) []interface{} {
+	var db, info interface{}
+	if pi.DB != "" {
+		db = pi.DB
+	}
+	if pi.Info != "" {
+		runes := []rune(pi.Info)
+		if !full && len(runes) > processInfoMaxInfoLen {
+			runes = runes[:processInfoMaxInfoLen]
+		}
+		info = string(runes)
+	}
+	state := ""
+	if pi.State&mysql.ServerStatusInTrans > 0 {
+		state = "in transaction"
+	}
+	return []interface{}{
+		pi.ID,
+		pi.User,
+		pi.Host,
+		db,
+		pi.Command,
+		uint64(time.Since(pi.Time) / time.Second),
+		state,
+		info,
+	}
+}
+
+// SessionManager is the interface for managing the sessions of the server,
+// show processlist and kill statements rely on it.
+type SessionManager interface {
+	// ShowProcessList returns the process info of all the connections.
+	ShowProcessList() []ProcessInfo
+	// Kill interrupts the running statement of the connection with connectionID,
+	// the connection is closed too if query is false.
+	// It returns false if the connection doesn't exist.
+	Kill(connectionID uint64, query bool) bool
+}
+
+// sessionManagerKeyType is a dummy type to avoid naming collision in context.
+type sessionManagerKeyType int
+
+// String defines a Stringer function for debugging and pretty printing.
+func (k sessionManagerKeyType) String() string {
+	return "session_manager"
+}
+
+const sessionManagerKey sessionManagerKeyType = 0
+
+// BindSessionManager binds SessionManager to context.
+func BindSessionManager(ctx context.Context, sm SessionManager) {
+	ctx.SetValue(sessionManagerKey, sm)
+}
+
+// GetSessionManager gets SessionManager from context, it returns nil if
+// the context is not created by a server.
+func GetSessionManager(ctx context.Context) SessionManager {
+	if sm, ok := ctx.Value(sessionManagerKey).(SessionManager); ok {
+		return sm
+	}
+	return nil
+}
@@ -16,6 +16,7 @@ package xapi
 import (
 	"io"
 	"io/ioutil"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/juju/errors"
@@ -130,12 +131,16 @@ func (r *SubResult) Close() error {
 }
 
 // Select do a select request, returns SelectResult.
-func Select(client kv.Client, req *tipb.SelectRequest, concurrency int) (*SelectResult, error) {
+// If deadline is not zero, the request is stopped after it.
+// If stats is not nil, the statistics of the coprocessor requests are added to it.
+func Select(client kv.Client, req *tipb.SelectRequest, concurrency int, deadline time.Time, stats *kv.CopStats) (*SelectResult, error) {
 	// Convert tipb.*Request to kv.Request
 	kvReq, err := composeRequest(req, concurrency)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	kvReq.Deadline = deadline
+	kvReq.Stats = stats
 	resp := client.Send(kvReq)
 	if resp == nil {
 		return nil, errors.New("client returns nil response")